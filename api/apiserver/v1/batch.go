@@ -0,0 +1,71 @@
+package v1
+
+import "encoding/json"
+
+// Modes of BatchRequest.
+const (
+	// BatchAtomic commits all operations, or none of them if one fails, operations after it are skipped.
+	BatchAtomic = "atomic"
+	// BatchContinue rolls back only the failed operation, and goes on with others.
+	BatchContinue = "continue"
+)
+
+// Methods and resources of BatchOperation.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+
+	BatchUsers    = "users"
+	BatchSecrets  = "secrets"
+	BatchPolicies = "policies"
+)
+
+// Status of BatchResult.
+const (
+	BatchSucceeded = "succeeded"
+	BatchFailed    = "failed"
+	BatchSkipped   = "skipped"
+	// BatchRolledBack is operation succeeded but not saved, because batch isn't committed.
+	BatchRolledBack = "rolledBack"
+)
+
+// BatchRequest runs operations in order in one transaction.
+type BatchRequest struct {
+	// Mode is BatchAtomic if empty.
+	Mode string `json:"mode,omitempty" validate:"omitempty,oneof=atomic continue"`
+
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+// BatchOperation is one change of users, secrets or policies, it's checked as the single api does.
+type BatchOperation struct {
+	Method   string `json:"method"   validate:"required,oneof=create update delete"`
+	Resource string `json:"resource" validate:"required,oneof=users secrets policies"`
+
+	// Name is username, secret id or policy name to update or delete, it's ignored by create.
+	Name string `json:"name,omitempty"`
+
+	// Body is resource to create, or fields to update: UserPatch of user,
+	// expires and description of secret, policy of policy. Omitted fields are not changed.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchResult is result of operation at the same index.
+type BatchResult struct {
+	Status string `json:"status"`
+
+	// Data is resource created or updated, password of user is never returned.
+	Data interface{} `json:"data,omitempty"`
+
+	// Code and Message are set if operation failed, same as error of the single api.
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type BatchResponse struct {
+	// Committed tells whether changes of succeeded operations are saved.
+	Committed bool `json:"committed"`
+
+	Results []BatchResult `json:"results"`
+}
@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"gorm.io/gorm"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+)
+
+// Group is a named set of users, members are the users whose Groups contain its name.
+// Users may also get groups from federated login or directory without a Group created.
+type Group struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+
+	// ExternalID is the id of group in provisioning client.
+	ExternalID string `json:"externalID,omitempty" gorm:"column:externalID"`
+}
+
+func (g *Group) TableName() string {
+	return "user_group"
+}
+
+func (g *Group) AfterCreate(tx *gorm.DB) error {
+	var err error
+	if g.InstanceID, err = idutil.GetInstanceId(g.ID, "group", 6); err != nil {
+		return err
+	}
+
+	return tx.Save(g).Error
+}
+
+type GroupList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Group `json:"items"`
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"gorm.io/gorm"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"time"
+)
+
+// Invitation lets one person register when self-registration is invite-only, its code can be used once.
+type Invitation struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Code is only returned when invitation is created, its hash is stored.
+	Code     string `json:"code,omitempty" gorm:"-"`
+	CodeHash string `json:"-" gorm:"column:codeHash"`
+
+	// Email restricts who can use invitation, it's optional.
+	Email string `json:"email,omitempty" gorm:"column:email" validate:"omitempty,email"`
+
+	// Groups are given to the registered user.
+	Groups []string `json:"groups,omitempty" gorm:"-"`
+
+	// GroupsShadow is the shadow of Groups. DO NOT modify directly.
+	GroupsShadow string `json:"-" gorm:"column:groups"`
+
+	// Policies are names of policies owned by CreatedBy, they are copied to the registered user.
+	Policies []string `json:"policies,omitempty" gorm:"-"`
+
+	// PoliciesShadow is the shadow of Policies. DO NOT modify directly.
+	PoliciesShadow string `json:"-" gorm:"column:policies"`
+
+	CreatedBy string    `json:"createdBy" gorm:"column:createdBy"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"column:expiresAt"`
+
+	// UsedBy is the user registered with invitation, empty if unused.
+	UsedBy string     `json:"usedBy,omitempty" gorm:"column:usedBy"`
+	UsedAt *time.Time `json:"usedAt,omitempty" gorm:"column:usedAt"`
+}
+
+func (i *Invitation) TableName() string {
+	return "invitation"
+}
+
+// Usable tells whether invitation is neither used nor expired.
+func (i *Invitation) Usable() bool {
+	return i.UsedBy == "" && time.Now().Before(i.ExpiresAt)
+}
+
+func (i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if err := i.ObjectMeta.BeforeCreate(tx); err != nil {
+		return err
+	}
+
+	return i.saveShadow()
+}
+
+func (i *Invitation) BeforeUpdate(tx *gorm.DB) error {
+	if err := i.ObjectMeta.BeforeUpdate(tx); err != nil {
+		return err
+	}
+
+	return i.saveShadow()
+}
+
+func (i *Invitation) AfterFind(tx *gorm.DB) error {
+	if err := i.ObjectMeta.AfterFind(tx); err != nil {
+		return err
+	}
+
+	if err := unmarshalShadow(i.GroupsShadow, &i.Groups); err != nil {
+		return err
+	}
+	return unmarshalShadow(i.PoliciesShadow, &i.Policies)
+}
+
+func (i *Invitation) saveShadow() error {
+	var err error
+	if i.GroupsShadow, err = marshalShadow(i.Groups); err != nil {
+		return err
+	}
+	i.PoliciesShadow, err = marshalShadow(i.Policies)
+	return err
+}
+
+func (i *Invitation) AfterCreate(tx *gorm.DB) error {
+	var err error
+	if i.InstanceID, err = idutil.GetInstanceId(i.ID, "invitation", 6); err != nil {
+		return err
+	}
+
+	return tx.Save(i).Error
+}
+
+type InvitationList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Invitation `json:"items"`
+}
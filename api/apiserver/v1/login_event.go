@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"gorm.io/gorm"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"time"
+)
+
+// Login schemes recorded in LoginEvent.
+const (
+	LoginSchemeBasic = "basic"
+	LoginSchemeJWT   = "jwt"
+	LoginSchemeMFA   = "mfa"
+)
+
+// LoginEvent is an attempt to login, successful or not. It's only appended.
+type LoginEvent struct {
+	ID uint64 `json:"id,omitempty" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+
+	Username string `json:"username" gorm:"column:username"`
+	Success  bool   `json:"success" gorm:"column:success"`
+	// Reason tells why it failed.
+	Reason string `json:"reason,omitempty" gorm:"column:reason"`
+
+	Scheme    string `json:"scheme" gorm:"column:scheme"`
+	IP        string `json:"ip" gorm:"column:ip"`
+	UserAgent string `json:"userAgent" gorm:"column:userAgent"`
+
+	// Anomalies lists rules this attempt triggered, empty means it looks normal.
+	Anomalies []string `json:"anomalies,omitempty" gorm:"-"`
+
+	// AnomaliesShadow is the shadow of Anomalies. DO NOT modify directly.
+	AnomaliesShadow string `json:"-" gorm:"column:anomalies"`
+
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+}
+
+func (e *LoginEvent) TableName() string {
+	return "login_event"
+}
+
+func (e *LoginEvent) BeforeCreate(tx *gorm.DB) error {
+	var err error
+	e.AnomaliesShadow, err = marshalShadow(e.Anomalies)
+	return err
+}
+
+func (e *LoginEvent) AfterFind(tx *gorm.DB) error {
+	return unmarshalShadow(e.AnomaliesShadow, &e.Anomalies)
+}
+
+type LoginEventList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*LoginEvent `json:"items"`
+}
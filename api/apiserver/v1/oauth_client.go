@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"gorm.io/gorm"
+	"istomyang.github.com/like-iam/component-base/auth"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"strings"
+)
+
+// OAuthClient is an application which logs user in with IAM through OpenID Connect.
+type OAuthClient struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Username is the owner who registered this client.
+	Username string `json:"username" gorm:"column:username" validate:"omitempty"`
+
+	ClientID string `json:"clientID" gorm:"column:clientID" validate:"omitempty"`
+
+	// ClientSecret is hashed, plain secret is only returned when creating.
+	ClientSecret string `json:"-" gorm:"column:clientSecret"`
+
+	// Public client, such as SPA and native app, can't keep secret, and must use PKCE.
+	Public bool `json:"public" gorm:"column:public"`
+
+	RedirectURIs []string `json:"redirectURIs" gorm:"-" validate:"required,min=1,dive,url"`
+
+	// RedirectURIsShadow is the shadow of RedirectURIs. DO NOT modify directly.
+	RedirectURIsShadow string `json:"-" gorm:"column:redirectURIs"`
+
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+}
+
+func (c *OAuthClient) TableName() string {
+	return "oauth_client"
+}
+
+// CompareSecret checks client secret, public client has no secret.
+func (c *OAuthClient) CompareSecret(secret string) bool {
+	if c.Public {
+		return false
+	}
+	return auth.Compare(c.ClientSecret, secret)
+}
+
+// AllowRedirectURI requires exact match, see: https://www.rfc-editor.org/rfc/rfc6749#section-3.1.2.2
+func (c *OAuthClient) AllowRedirectURI(uri string) bool {
+	for _, u := range c.RedirectURIs {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *OAuthClient) BeforeCreate(tx *gorm.DB) error {
+	if err := c.ObjectMeta.BeforeCreate(tx); err != nil {
+		return err
+	}
+	c.RedirectURIsShadow = strings.Join(c.RedirectURIs, " ")
+	return nil
+}
+
+func (c *OAuthClient) BeforeUpdate(tx *gorm.DB) error {
+	if err := c.ObjectMeta.BeforeUpdate(tx); err != nil {
+		return err
+	}
+	c.RedirectURIsShadow = strings.Join(c.RedirectURIs, " ")
+	return nil
+}
+
+func (c *OAuthClient) AfterFind(tx *gorm.DB) error {
+	if err := c.ObjectMeta.AfterFind(tx); err != nil {
+		return err
+	}
+	c.RedirectURIs = strings.Fields(c.RedirectURIsShadow)
+	return nil
+}
+
+func (c *OAuthClient) AfterCreate(tx *gorm.DB) error {
+	var err error
+	if c.InstanceID, err = idutil.GetInstanceId(c.ID, "oauthclient", 6); err != nil {
+		return err
+	}
+
+	return tx.Save(c).Error
+}
+
+type OAuthClientList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*OAuthClient `json:"items"`
+}
@@ -19,7 +19,7 @@ func (ap AuthzPolicy) String() string {
 	return string(data)
 }
 
-func (ap AuthzPolicy) Load(policyShadow string) error {
+func (ap *AuthzPolicy) Load(policyShadow string) error {
 	if err := json.Unmarshal([]byte(policyShadow), &ap); err != nil {
 		return err
 	}
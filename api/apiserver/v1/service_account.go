@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"gorm.io/gorm"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"strings"
+)
+
+// ServiceAccountPrefix marks principal of service account, so it never collides with username.
+const ServiceAccountPrefix = "serviceaccount:"
+
+// ServiceAccount is a non-human identity such as CI bot, it can't login with password,
+// and only authenticates with secrets or client certificate.
+type ServiceAccount struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Owner is the user who manages this account.
+	Owner string `json:"owner" gorm:"column:owner" validate:"omitempty"`
+
+	// OwnerGroup lets all members of the group manage this account, it's optional.
+	OwnerGroup string `json:"ownerGroup,omitempty" gorm:"column:ownerGroup" validate:"omitempty"`
+
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+
+	// Disabled account fails to authenticate, its secrets are kept.
+	Disabled bool `json:"disabled" gorm:"column:disabled"`
+}
+
+func (a *ServiceAccount) TableName() string {
+	return "service_account"
+}
+
+// Principal is used as username of its secrets and subject of policies.
+func (a *ServiceAccount) Principal() string {
+	return ServiceAccountPrincipal(a.Name)
+}
+
+func (a *ServiceAccount) AfterCreate(tx *gorm.DB) error {
+	var err error
+	if a.InstanceID, err = idutil.GetInstanceId(a.ID, "serviceaccount", 6); err != nil {
+		return err
+	}
+
+	return tx.Save(a).Error
+}
+
+type ServiceAccountList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*ServiceAccount `json:"items"`
+}
+
+// ServiceAccountPrincipal returns principal of service account with name.
+func ServiceAccountPrincipal(name string) string {
+	return ServiceAccountPrefix + name
+}
+
+// ServiceAccountName returns name of service account if principal is one.
+func ServiceAccountName(principal string) (string, bool) {
+	if !strings.HasPrefix(principal, ServiceAccountPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(principal, ServiceAccountPrefix), true
+}
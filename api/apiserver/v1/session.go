@@ -0,0 +1,31 @@
+package v1
+
+import (
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"time"
+)
+
+// Session is a login of user, tokens refreshed from the login belong to the same session.
+type Session struct {
+	// ID is jti of tokens.
+	ID       string `json:"id"`
+	Username string `json:"username"`
+
+	// Device is guessed from UserAgent, such as mobile, desktop and cli.
+	Device    string `json:"device"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"userAgent"`
+
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+
+	// Current marks the session of token sending request.
+	Current bool `json:"current,omitempty"`
+}
+
+type SessionList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Session `json:"items"`
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"github.com/ory/ladon"
+	"strings"
+)
+
+// TemporarySecretIDPrefix marks secret id of temporary credential, secret id of Secret never contains '-'.
+const TemporarySecretIDPrefix = "sts-"
+
+// AssumeRequest exchanges secret of caller for temporary credential.
+type AssumeRequest struct {
+	// SessionName tells who uses the credential, it's recorded for audit.
+	SessionName string `json:"sessionName,omitempty" validate:"omitempty,max=64"`
+
+	// DurationSeconds is lifetime of credential, server default is used if 0.
+	DurationSeconds int64 `json:"durationSeconds,omitempty"`
+
+	// Policy down-scopes the credential, request is allowed only if both it and policies of caller allow.
+	// Subjects matches all if empty.
+	Policy *ladon.DefaultPolicy `json:"policy,omitempty"`
+}
+
+// TemporaryCredential signs request like Secret, and must send SessionToken in X-Iam-Security-Token header.
+type TemporaryCredential struct {
+	// Username is the principal who assumed it.
+	Username    string `json:"username"`
+	SessionName string `json:"sessionName,omitempty"`
+
+	SecretID     string `json:"secretID"`
+	SecretKey    string `json:"secretKey"`
+	SessionToken string `json:"sessionToken"`
+
+	Expires int64 `json:"expires"`
+
+	Policy *ladon.DefaultPolicy `json:"policy,omitempty"`
+}
+
+// IsTemporarySecretID tells whether secret id belongs to temporary credential.
+func IsTemporarySecretID(secretID string) bool {
+	return strings.HasPrefix(secretID, TemporarySecretIDPrefix)
+}
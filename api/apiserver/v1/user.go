@@ -1,13 +1,18 @@
 package v1
 
 import (
+	"encoding/json"
 	"gorm.io/gorm"
 	"istomyang.github.com/like-iam/component-base/auth"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
 	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"strconv"
 	"time"
 )
 
+// LDAPIdentityProvider is IdentityProvider of users synced from directory.
+const LDAPIdentityProvider = "ldap"
+
 type User struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
@@ -15,13 +20,107 @@ type User struct {
 
 	Password string `json:"password,omitempty" gorm:"password"`
 
+	// Email is used to deliver message such as password reset token.
+	Email string `json:"email,omitempty" gorm:"column:email" validate:"omitempty,email"`
+
 	IsAdmin string `json:"isAdmin,omitempty" gorm:"isAdmin"`
 
 	LoginAt time.Time `json:"loginAt" gorm:"loginAt"`
 
+	// Groups is put into id token, relying party can authorize user by it.
+	Groups []string `json:"groups,omitempty" gorm:"-"`
+
+	// GroupsShadow is the shadow of Groups. DO NOT modify directly.
+	GroupsShadow string `json:"-" gorm:"column:groups"`
+
+	// PasswordChangedAt is the time when password was set last time, used to check password expiry.
+	PasswordChangedAt time.Time `json:"passwordChangedAt" gorm:"column:passwordChangedAt"`
+
+	// PasswordHistory saves hashes of previous passwords, will not be stored in db.
+	PasswordHistory []string `json:"-" gorm:"-"`
+
+	// PasswordHistoryShadow is the shadow of PasswordHistory. DO NOT modify directly.
+	PasswordHistoryShadow string `json:"-" gorm:"column:passwordHistory"`
+
+	// MFAEnabled tells whether user has enrolled TOTP, secret is saved but unused until enrolment verified.
+	MFAEnabled bool `json:"mfaEnabled" gorm:"column:mfaEnabled"`
+
+	// MFARequired forces this user to enrol MFA, even if it's not required globally.
+	MFARequired bool `json:"mfaRequired" gorm:"column:mfaRequired"`
+
+	MFASecret string `json:"-" gorm:"column:mfaSecret"`
+
+	// RecoveryCodes saves hashes of unused recovery codes, will not be stored in db.
+	RecoveryCodes []string `json:"-" gorm:"-"`
+
+	// RecoveryCodesShadow is the shadow of RecoveryCodes. DO NOT modify directly.
+	RecoveryCodesShadow string `json:"-" gorm:"column:recoveryCodes"`
+
+	// IdentityProvider is the issuer of upstream provider for user created by federated login,
+	// or LDAPIdentityProvider for user synced from directory.
+	IdentityProvider string `json:"identityProvider,omitempty" gorm:"column:identityProvider"`
+
+	// ExternalID is the subject of user in IdentityProvider, or DN in directory.
+	ExternalID string `json:"externalID,omitempty" gorm:"column:externalID"`
+
+	// Disabled user fails to authenticate, it's set by provisioning client when account is deprovisioned.
+	Disabled bool `json:"disabled" gorm:"column:disabled"`
+
 	TotalPolicy int64 `json:"totalPolicy" gorm:"-" validate:"omitempty"`
 }
 
+// UserPatch is fields of user to update, omitted fields are not changed. Password and MFA are changed by
+// their own apis, which check the old ones.
+type UserPatch struct {
+	Email *string `json:"email,omitempty" validate:"omitempty,email"`
+
+	// IsAdmin, Groups, Disabled and MFARequired are privileges, which are only changed by admin.
+	IsAdmin     *string   `json:"isAdmin,omitempty"`
+	Groups      *[]string `json:"groups,omitempty"`
+	Disabled    *bool     `json:"disabled,omitempty"`
+	MFARequired *bool     `json:"mfaRequired,omitempty"`
+}
+
+// Privileged tells whether patch changes privileges.
+func (p *UserPatch) Privileged() bool {
+	return p.IsAdmin != nil || p.Groups != nil || p.Disabled != nil || p.MFARequired != nil
+}
+
+// Apply copies fields of patch into user.
+func (p *UserPatch) Apply(user *User) {
+	if p.Email != nil {
+		user.Email = *p.Email
+	}
+	if p.IsAdmin != nil {
+		user.IsAdmin = *p.IsAdmin
+	}
+	if p.Groups != nil {
+		user.Groups = *p.Groups
+	}
+	if p.Disabled != nil {
+		user.Disabled = *p.Disabled
+	}
+	if p.MFARequired != nil {
+		user.MFARequired = *p.MFARequired
+	}
+}
+
+// HasGroup tells whether user is a member of group.
+func (u *User) HasGroup(group string) bool {
+	for _, g := range u.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Admin tells whether IsAdmin is set to true or 1.
+func (u *User) Admin() bool {
+	admin, _ := strconv.ParseBool(u.IsAdmin)
+	return admin
+}
+
 func (u *User) TableName() string {
 	return "user"
 }
@@ -30,6 +129,137 @@ func (u *User) Compare(password string) bool {
 	return auth.Compare(u.Password, password)
 }
 
+// PasswordReused checks password against current one and the previous historyCount-1 ones,
+// so that the last historyCount passwords can not be used again.
+func (u *User) PasswordReused(password string, historyCount int) bool {
+	if historyCount <= 0 {
+		return false
+	}
+	if u.Compare(password) {
+		return true
+	}
+	for i, hashed := range u.PasswordHistory {
+		if i >= historyCount-1 {
+			break
+		}
+		if auth.Compare(hashed, password) {
+			return true
+		}
+	}
+	return false
+}
+
+// RotatePassword replaces password with a hashed one, and moves current one into PasswordHistory.
+func (u *User) RotatePassword(hashed string, historyCount int) {
+	if historyCount > 1 && u.Password != "" {
+		u.PasswordHistory = append([]string{u.Password}, u.PasswordHistory...)
+		if len(u.PasswordHistory) > historyCount-1 {
+			u.PasswordHistory = u.PasswordHistory[:historyCount-1]
+		}
+	} else {
+		u.PasswordHistory = nil
+	}
+	u.Password = hashed
+	u.PasswordChangedAt = time.Now()
+}
+
+// PasswordSetAt returns the time password was set, falls back to CreatedAt for users created before tracking.
+func (u *User) PasswordSetAt() time.Time {
+	if u.PasswordChangedAt.IsZero() {
+		return u.CreatedAt
+	}
+	return u.PasswordChangedAt
+}
+
+// Federated tells whether password of user is managed by upstream provider or directory.
+func (u *User) Federated() bool {
+	return u.IdentityProvider != ""
+}
+
+// FromLDAP tells whether user is synced from directory.
+func (u *User) FromLDAP() bool {
+	return u.IdentityProvider == LDAPIdentityProvider
+}
+
+// UseRecoveryCode consumes a matched recovery code, a code can be used only once.
+func (u *User) UseRecoveryCode(code string) bool {
+	for i, hashed := range u.RecoveryCodes {
+		if auth.Compare(hashed, code) {
+			u.RecoveryCodes = append(u.RecoveryCodes[:i], u.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ResetMFA clears secret and recovery codes.
+func (u *User) ResetMFA() {
+	u.MFAEnabled = false
+	u.MFASecret = ""
+	u.RecoveryCodes = nil
+}
+
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if err := u.ObjectMeta.BeforeCreate(tx); err != nil {
+		return err
+	}
+
+	return u.saveShadow()
+}
+
+func (u *User) BeforeUpdate(tx *gorm.DB) error {
+	if err := u.ObjectMeta.BeforeUpdate(tx); err != nil {
+		return err
+	}
+
+	return u.saveShadow()
+}
+
+func (u *User) AfterFind(tx *gorm.DB) error {
+	if err := u.ObjectMeta.AfterFind(tx); err != nil {
+		return err
+	}
+
+	if err := unmarshalShadow(u.GroupsShadow, &u.Groups); err != nil {
+		return err
+	}
+	if err := unmarshalShadow(u.PasswordHistoryShadow, &u.PasswordHistory); err != nil {
+		return err
+	}
+	return unmarshalShadow(u.RecoveryCodesShadow, &u.RecoveryCodes)
+}
+
+func (u *User) saveShadow() error {
+	var err error
+	if u.GroupsShadow, err = marshalShadow(u.Groups); err != nil {
+		return err
+	}
+	if u.PasswordHistoryShadow, err = marshalShadow(u.PasswordHistory); err != nil {
+		return err
+	}
+	u.RecoveryCodesShadow, err = marshalShadow(u.RecoveryCodes)
+	return err
+}
+
+func marshalShadow(items []string) (string, error) {
+	if len(items) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func unmarshalShadow(shadow string, items *[]string) error {
+	*items = nil
+	if shadow == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(shadow), items)
+}
+
 func (u *User) AfterCreate(tx *gorm.DB) error {
 	var err error
 	if u.InstanceID, err = idutil.GetInstanceId(u.ID, "user", 6); err != nil {
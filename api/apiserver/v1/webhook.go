@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"gorm.io/gorm"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"strings"
+	"time"
+)
+
+// Types of WebhookEvent, which are <resource>.<action>.
+const (
+	EventUserCreated   = "user.created"
+	EventUserUpdated   = "user.updated"
+	EventUserDeleted   = "user.deleted"
+	EventSecretCreated = "secret.created"
+	EventSecretUpdated = "secret.updated"
+	EventSecretDeleted = "secret.deleted"
+	EventPolicyCreated = "policy.created"
+	EventPolicyUpdated = "policy.updated"
+	EventPolicyDeleted = "policy.deleted"
+
+	EventServiceAccountCreated = "serviceaccount.created"
+	EventServiceAccountUpdated = "serviceaccount.updated"
+	EventServiceAccountDeleted = "serviceaccount.deleted"
+)
+
+// Webhook receives events of resources owned by its owner, or all resources if owner is admin.
+type Webhook struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Username is the owner of webhook.
+	Username string `json:"username" gorm:"column:username" validate:"omitempty"`
+
+	URL string `json:"url" gorm:"column:url" validate:"required,url"`
+
+	// Events filters types of event, such as user.created or secret.*, empty means all.
+	Events []string `json:"events,omitempty" gorm:"-"`
+
+	// EventsShadow is the shadow of Events. DO NOT modify directly.
+	EventsShadow string `json:"-" gorm:"column:events"`
+
+	// Secret signs payloads, it's only returned when webhook is created.
+	Secret string `json:"secret,omitempty" gorm:"column:secret"`
+
+	Disabled    bool   `json:"disabled" gorm:"column:disabled"`
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+}
+
+func (w *Webhook) TableName() string {
+	return "webhook"
+}
+
+// Subscribed tells whether event type passes filter of webhook.
+func (w *Webhook) Subscribed(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == "*" || e == event || (strings.HasSuffix(e, ".*") && strings.HasPrefix(event, strings.TrimSuffix(e, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if err := w.ObjectMeta.BeforeCreate(tx); err != nil {
+		return err
+	}
+
+	var err error
+	w.EventsShadow, err = marshalShadow(w.Events)
+	return err
+}
+
+func (w *Webhook) BeforeUpdate(tx *gorm.DB) error {
+	if err := w.ObjectMeta.BeforeUpdate(tx); err != nil {
+		return err
+	}
+
+	var err error
+	w.EventsShadow, err = marshalShadow(w.Events)
+	return err
+}
+
+func (w *Webhook) AfterFind(tx *gorm.DB) error {
+	if err := w.ObjectMeta.AfterFind(tx); err != nil {
+		return err
+	}
+
+	return unmarshalShadow(w.EventsShadow, &w.Events)
+}
+
+func (w *Webhook) AfterCreate(tx *gorm.DB) error {
+	var err error
+	if w.InstanceID, err = idutil.GetInstanceId(w.ID, "webhook", 6); err != nil {
+		return err
+	}
+
+	return tx.Save(w).Error
+}
+
+type WebhookList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Webhook `json:"items"`
+}
+
+// WebhookEvent is the payload posted to webhook.
+type WebhookEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+
+	// Username owns the changed resource, Name is its name, which is username for user events.
+	Username string `json:"username"`
+	Name     string `json:"name"`
+
+	// Actor is who made the change, empty if it's made by server.
+	Actor string `json:"actor,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// States of WebhookDelivery.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one webhook, it's kept as delivery log.
+type WebhookDelivery struct {
+	ID uint64 `json:"id,omitempty" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+
+	// Webhook is name of webhook owned by Username.
+	Webhook  string `json:"webhook" gorm:"column:webhook"`
+	Username string `json:"username" gorm:"column:username"`
+
+	EventID string `json:"eventID" gorm:"column:eventID"`
+	Event   string `json:"event" gorm:"column:event"`
+	Payload string `json:"payload" gorm:"column:payload"`
+
+	Status   string `json:"status" gorm:"column:status"`
+	Attempts int    `json:"attempts" gorm:"column:attempts"`
+
+	// ResponseCode and Error are result of the last attempt.
+	ResponseCode int    `json:"responseCode,omitempty" gorm:"column:responseCode"`
+	Error        string `json:"error,omitempty" gorm:"column:error"`
+
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"column:nextAttemptAt"`
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty" gorm:"column:deliveredAt"`
+
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (d *WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+type WebhookDeliveryList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*WebhookDelivery `json:"items"`
+}
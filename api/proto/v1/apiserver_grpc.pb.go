@@ -24,6 +24,8 @@ const _ = grpc.SupportPackageIsVersion7
 type CacheClient interface {
 	ListSecrets(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListSecretsReply, error)
 	ListPolicies(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListPoliciesReply, error)
+	StreamSecrets(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (Cache_StreamSecretsClient, error)
+	StreamPolicies(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (Cache_StreamPoliciesClient, error)
 }
 
 type cacheClient struct {
@@ -52,12 +54,78 @@ func (c *cacheClient) ListPolicies(ctx context.Context, in *ListRequest, opts ..
 	return out, nil
 }
 
+func (c *cacheClient) StreamSecrets(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (Cache_StreamSecretsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Cache_ServiceDesc.Streams[0], "/proto.Cache/StreamSecrets", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cacheStreamSecretsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cache_StreamSecretsClient interface {
+	Recv() (*ListSecretsReply, error)
+	grpc.ClientStream
+}
+
+type cacheStreamSecretsClient struct {
+	grpc.ClientStream
+}
+
+func (x *cacheStreamSecretsClient) Recv() (*ListSecretsReply, error) {
+	m := new(ListSecretsReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cacheClient) StreamPolicies(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (Cache_StreamPoliciesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Cache_ServiceDesc.Streams[1], "/proto.Cache/StreamPolicies", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cacheStreamPoliciesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cache_StreamPoliciesClient interface {
+	Recv() (*ListPoliciesReply, error)
+	grpc.ClientStream
+}
+
+type cacheStreamPoliciesClient struct {
+	grpc.ClientStream
+}
+
+func (x *cacheStreamPoliciesClient) Recv() (*ListPoliciesReply, error) {
+	m := new(ListPoliciesReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // CacheServer is the server API for Cache service.
 // All implementations must embed UnimplementedCacheServer
 // for forward compatibility
 type CacheServer interface {
 	ListSecrets(context.Context, *ListRequest) (*ListSecretsReply, error)
 	ListPolicies(context.Context, *ListRequest) (*ListPoliciesReply, error)
+	StreamSecrets(*ListRequest, Cache_StreamSecretsServer) error
+	StreamPolicies(*ListRequest, Cache_StreamPoliciesServer) error
 	mustEmbedUnimplementedCacheServer()
 }
 
@@ -71,6 +139,12 @@ func (UnimplementedCacheServer) ListSecrets(context.Context, *ListRequest) (*Lis
 func (UnimplementedCacheServer) ListPolicies(context.Context, *ListRequest) (*ListPoliciesReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListPolicies not implemented")
 }
+func (UnimplementedCacheServer) StreamSecrets(*ListRequest, Cache_StreamSecretsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSecrets not implemented")
+}
+func (UnimplementedCacheServer) StreamPolicies(*ListRequest, Cache_StreamPoliciesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPolicies not implemented")
+}
 func (UnimplementedCacheServer) mustEmbedUnimplementedCacheServer() {}
 
 // UnsafeCacheServer may be embedded to opt out of forward compatibility for this service.
@@ -120,6 +194,48 @@ func _Cache_ListPolicies_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Cache_StreamSecrets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CacheServer).StreamSecrets(m, &cacheStreamSecretsServer{stream})
+}
+
+type Cache_StreamSecretsServer interface {
+	Send(*ListSecretsReply) error
+	grpc.ServerStream
+}
+
+type cacheStreamSecretsServer struct {
+	grpc.ServerStream
+}
+
+func (x *cacheStreamSecretsServer) Send(m *ListSecretsReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Cache_StreamPolicies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CacheServer).StreamPolicies(m, &cacheStreamPoliciesServer{stream})
+}
+
+type Cache_StreamPoliciesServer interface {
+	Send(*ListPoliciesReply) error
+	grpc.ServerStream
+}
+
+type cacheStreamPoliciesServer struct {
+	grpc.ServerStream
+}
+
+func (x *cacheStreamPoliciesServer) Send(m *ListPoliciesReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Cache_ServiceDesc is the grpc.ServiceDesc for Cache service.
 // It's only intended for direct use with apiserver.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -136,6 +252,17 @@ var Cache_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Cache_ListPolicies_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSecrets",
+			Handler:       _Cache_StreamSecrets_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamPolicies",
+			Handler:       _Cache_StreamPolicies_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "v1/apiserver.proto",
 }
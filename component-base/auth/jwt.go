@@ -10,11 +10,11 @@ const (
 	JwtExpireProduction  = time.Hour * 2
 )
 
-// Sign put secretId into header section and signs jwt-token with secretKey.
+// Sign put secretId into header section and signs jwt-token with secretKey in HS256.
 // issuer is signer name, audience is consumer of this token.
 func Sign(secretId, secretKey, issuer, audience string, expire time.Duration) (string, error) {
 	// https://datatracker.ietf.org/doc/html/rfc7519#section-4-1
-	var token = jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+	var token = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
 		Issuer:    issuer,
 		Audience:  []string{audience},
 		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
@@ -22,5 +22,5 @@ func Sign(secretId, secretKey, issuer, audience string, expire time.Duration) (s
 		IssuedAt:  jwt.NewNumericDate(time.Now()),
 	})
 	token.Header["kid"] = secretId
-	return token.SignedString(secretKey)
+	return token.SignedString([]byte(secretKey))
 }
@@ -0,0 +1,373 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Asymmetric signing algorithms supported by KeyRing.
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+const keyFileExt = ".pem"
+
+var (
+	ErrUnknownKID       = errors.New("unknown kid")
+	ErrUnsupportedKey   = errors.New("unsupported key type")
+	ErrNoActiveKey      = errors.New("no active signing key")
+	ErrUnexpectedMethod = errors.New("unexpected signing method")
+)
+
+// SigningKey is a private key identified by ID, which is put into jwt header as kid.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	Private   crypto.Signer
+}
+
+// GenerateSigningKey generates a key of alg, ID is derived from public key.
+func GenerateSigningKey(alg string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch alg {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newSigningKey("", private, time.Now())
+}
+
+// ParseSigningKey parses PKCS#8, PKCS#1 or SEC 1 pem, empty kid will be derived from public key.
+func ParseSigningKey(kid string, data []byte, createdAt time.Time) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid pem of key %s", kid)
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+	return newSigningKey(kid, private, createdAt)
+}
+
+func newSigningKey(kid string, private crypto.Signer, createdAt time.Time) (*SigningKey, error) {
+	k := &SigningKey{ID: kid, CreatedAt: createdAt, Private: private}
+
+	switch p := private.(type) {
+	case *rsa.PrivateKey:
+		k.Algorithm = AlgRS256
+	case *ecdsa.PrivateKey:
+		if p.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: curve %s", ErrUnsupportedKey, p.Curve.Params().Name)
+		}
+		k.Algorithm = AlgES256
+	case ed25519.PrivateKey:
+		k.Algorithm = AlgEdDSA
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	if k.ID == "" {
+		der, err := x509.MarshalPKIXPublicKey(private.Public())
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(der)
+		k.ID = base64.RawURLEncoding.EncodeToString(sum[:12])
+	}
+	return k, nil
+}
+
+// MarshalPEM encodes private key in PKCS#8.
+func (k *SigningKey) MarshalPEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// SaveTo writes key into dir with name <kid>.pem.
+func (k *SigningKey) SaveTo(dir string) error {
+	data, err := k.MarshalPEM()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, k.ID+keyFileExt), data, 0600)
+}
+
+// LoadKeyDir loads all <kid>.pem in dir, file modification time is used as CreatedAt.
+func LoadKeyDir(dir string) ([]*SigningKey, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*SigningKey
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), keyFileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		k, err := ParseSigningKey(strings.TrimSuffix(e.Name(), keyFileExt), data, info.ModTime())
+		if err != nil {
+			return nil, fmt.Errorf("load key %s fail: %w", e.Name(), err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// KeyRing holds signing keys, the newest one signs tokens and all of them verify tokens,
+// so that tokens signed by previous keys are still valid after rotation.
+type KeyRing struct {
+	mu sync.RWMutex
+	// keys is sorted by CreatedAt, newest first.
+	keys []*SigningKey
+}
+
+func NewKeyRing(keys ...*SigningKey) *KeyRing {
+	r := &KeyRing{}
+	r.Set(keys...)
+	return r
+}
+
+// Set replaces all keys.
+func (r *KeyRing) Set(keys ...*SigningKey) {
+	sorted := append([]*SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = sorted
+}
+
+// Add puts a key into ring, it becomes active if it's the newest.
+func (r *KeyRing) Add(k *SigningKey) {
+	r.Set(append(r.Keys(), k)...)
+}
+
+// Keys returns a copy of keys, newest first.
+func (r *KeyRing) Keys() []*SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*SigningKey(nil), r.keys...)
+}
+
+// Active returns the key used to sign, nil if ring is empty.
+func (r *KeyRing) Active() *SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.keys) == 0 {
+		return nil
+	}
+	return r.keys[0]
+}
+
+// Prune removes keys retired longer than grace, a key retires when a newer key is created.
+// grace should cover the max lifetime of tokens signed by it.
+func (r *KeyRing) Prune(grace time.Duration) []*SigningKey {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var kept, removed []*SigningKey
+	for i, k := range r.keys {
+		if i > 0 && time.Since(r.keys[i-1].CreatedAt) > grace {
+			removed = append(removed, k)
+			continue
+		}
+		kept = append(kept, k)
+	}
+	r.keys = kept
+	return removed
+}
+
+func (r *KeyRing) get(kid string) *SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, k := range r.keys {
+		if k.ID == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// Sign signs claims with active key and puts its kid into header.
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	k := r.Active()
+	if k == nil {
+		return "", ErrNoActiveKey
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(k.Algorithm), claims)
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.Private)
+}
+
+// KeyFunc is used in jwt.Parse to find public key by kid.
+func (r *KeyRing) KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k := r.get(kid)
+	if k == nil {
+		return nil, ErrUnknownKID
+	}
+	if token.Method.Alg() != k.Algorithm {
+		return nil, ErrUnexpectedMethod
+	}
+	return k.Private.Public(), nil
+}
+
+// JWK is a public key in JSON Web Key format, see: https://www.rfc-editor.org/rfc/rfc7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns public keys of all keys in ring.
+func (r *KeyRing) JWKS() *JWKSet {
+	set := &JWKSet{Keys: []JWK{}}
+	for _, k := range r.Keys() {
+		set.Keys = append(set.Keys, k.JWK())
+	}
+	return set
+}
+
+// JWK returns public key of k.
+func (k *SigningKey) JWK() JWK {
+	j := JWK{Use: "sig", Alg: k.Algorithm, Kid: k.ID}
+	enc := base64.RawURLEncoding
+
+	switch p := k.Private.Public().(type) {
+	case *rsa.PublicKey:
+		j.Kty = "RSA"
+		j.N = enc.EncodeToString(p.N.Bytes())
+		j.E = enc.EncodeToString(big.NewInt(int64(p.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (p.Curve.Params().BitSize + 7) / 8
+		j.Kty = "EC"
+		j.Crv = p.Curve.Params().Name
+		j.X = enc.EncodeToString(p.X.FillBytes(make([]byte, size)))
+		j.Y = enc.EncodeToString(p.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		j.Kty = "OKP"
+		j.Crv = "Ed25519"
+		j.X = enc.EncodeToString(p)
+	}
+	return j
+}
+
+// PublicKey parses public key from k, it's used to verify tokens signed by others.
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	dec := base64.RawURLEncoding
+
+	switch j.Kty {
+	case "RSA":
+		n, err := dec.DecodeString(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := dec.DecodeString(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, ErrUnsupportedKey
+		}
+		x, err := dec.DecodeString(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := dec.DecodeString(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := dec.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, ErrUnsupportedKey
+}
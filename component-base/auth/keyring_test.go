@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"testing"
+	"time"
+)
+
+func TestKeyRing_SignAndVerify(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		k, err := GenerateSigningKey(alg)
+		if err != nil {
+			t.Fatalf("generate %s key: %v", alg, err)
+		}
+		r := NewKeyRing(k)
+
+		token, err := r.Sign(jwt.MapClaims{"sub": "alice"})
+		if err != nil {
+			t.Fatalf("sign with %s: %v", alg, err)
+		}
+
+		parsed, err := jwt.Parse(token, r.KeyFunc)
+		if err != nil || !parsed.Valid {
+			t.Fatalf("verify %s token: %v", alg, err)
+		}
+		if parsed.Header["kid"] != k.ID {
+			t.Errorf("kid got %v, want %s", parsed.Header["kid"], k.ID)
+		}
+
+		jwk := r.JWKS().Keys[0]
+		if jwk.Alg != alg || jwk.Kid != k.ID {
+			t.Errorf("unexpected jwk: %+v", jwk)
+		}
+	}
+}
+
+func TestKeyRing_Rotate(t *testing.T) {
+	old, _ := GenerateSigningKey(AlgES256)
+	old.CreatedAt = time.Now().Add(-time.Hour)
+	r := NewKeyRing(old)
+
+	token, err := r.Sign(jwt.MapClaims{"sub": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k, _ := GenerateSigningKey(AlgEdDSA)
+	r.Add(k)
+	if r.Active().ID != k.ID {
+		t.Fatalf("newest key should be active")
+	}
+	if _, err = jwt.Parse(token, r.KeyFunc); err != nil {
+		t.Errorf("token signed by previous key should be valid: %v", err)
+	}
+
+	if removed := r.Prune(time.Minute); len(removed) != 0 {
+		t.Errorf("key retired just now should be kept")
+	}
+	k.CreatedAt = time.Now().Add(-2 * time.Minute)
+	if removed := r.Prune(time.Minute); len(removed) != 1 || removed[0].ID != old.ID {
+		t.Errorf("previous key should be removed after grace")
+	}
+	if _, err = jwt.Parse(token, r.KeyFunc); err == nil {
+		t.Errorf("token signed by removed key should be invalid")
+	}
+}
+
+func TestKeyDir(t *testing.T) {
+	dir := t.TempDir()
+
+	k, _ := GenerateSigningKey(AlgRS256)
+	if err := k.SaveTo(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadKeyDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].ID != k.ID || keys[0].Algorithm != AlgRS256 {
+		t.Errorf("unexpected keys loaded: %+v", keys)
+	}
+}
+
+func TestSign(t *testing.T) {
+	token, err := Sign("id", "secret-key", "iam", "aud", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		return []byte("secret-key"), nil
+	})
+	if err != nil || parsed.Header["kid"] != "id" {
+		t.Errorf("verify signed token fail: %v", err)
+	}
+}
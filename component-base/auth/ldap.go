@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"strings"
+	"time"
+)
+
+var (
+	ErrLDAPUserNotFound       = errors.New("ldap user not found")
+	ErrLDAPInvalidCredentials = errors.New("ldap invalid credentials")
+)
+
+// LDAPConfig tells how to find user and check password in directory.
+type LDAPConfig struct {
+	// URL is ldap://host:389 or ldaps://host:636.
+	URL string
+
+	// StartTLS upgrades ldap:// connection to TLS before binding.
+	StartTLS bool
+
+	TLSConfig *tls.Config
+
+	// BindDN and BindPassword are used to search user, anonymous bind is used if BindDN is empty.
+	BindDN       string
+	BindPassword string
+
+	BaseDN string
+
+	// UserFilter must contain one %s, which is replaced by escaped username, such as (uid=%s).
+	UserFilter string
+
+	UsernameAttribute string
+	EmailAttribute    string
+
+	// GroupAttribute is the attribute of user entry listing DN of its groups, such as memberOf.
+	GroupAttribute string
+
+	// GroupMapping maps DN or CN of directory group to IAM group, case-insensitive.
+	// Only mapped groups are kept if it's not empty, otherwise CN is used as IAM group.
+	GroupMapping map[string]string
+
+	Timeout time.Duration
+}
+
+// LDAPIdentity is the user found in directory.
+type LDAPIdentity struct {
+	DN       string
+	Username string
+	Email    string
+	Groups   []string
+}
+
+// LDAPAuthenticator checks password by binding as the user, a connection is dialed for each check.
+type LDAPAuthenticator struct {
+	cfg LDAPConfig
+}
+
+func NewLDAPAuthenticator(cfg LDAPConfig) *LDAPAuthenticator {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(uid=%s)"
+	}
+	if cfg.UsernameAttribute == "" {
+		cfg.UsernameAttribute = "uid"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	mapping := make(map[string]string, len(cfg.GroupMapping))
+	for k, v := range cfg.GroupMapping {
+		mapping[strings.ToLower(k)] = v
+	}
+	cfg.GroupMapping = mapping
+	return &LDAPAuthenticator{cfg: cfg}
+}
+
+// Authenticate searches user with service account, then binds as it with password.
+func (a *LDAPAuthenticator) Authenticate(username, password string) (*LDAPIdentity, error) {
+	// Bind with empty password is unauthenticated bind, which always succeeds.
+	if username == "" || password == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if a.cfg.BindDN != "" {
+		err = conn.Bind(a.cfg.BindDN, a.cfg.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldap bind service account: %w", err)
+	}
+
+	attributes := []string{a.cfg.UsernameAttribute}
+	if a.cfg.EmailAttribute != "" {
+		attributes = append(attributes, a.cfg.EmailAttribute)
+	}
+	if a.cfg.GroupAttribute != "" {
+		attributes = append(attributes, a.cfg.GroupAttribute)
+	}
+	res, err := conn.Search(ldap.NewSearchRequest(
+		a.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(a.cfg.Timeout.Seconds()), false,
+		fmt.Sprintf(a.cfg.UserFilter, ldap.EscapeFilter(username)), attributes, nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("ldap search user: %w", err)
+	}
+	// Ambiguous user is treated as not found.
+	if res == nil || len(res.Entries) != 1 {
+		return nil, ErrLDAPUserNotFound
+	}
+	entry := res.Entries[0]
+
+	if err = conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrLDAPInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap bind user: %w", err)
+	}
+
+	id := &LDAPIdentity{
+		DN:       entry.DN,
+		Username: entry.GetAttributeValue(a.cfg.UsernameAttribute),
+		Groups:   []string{},
+	}
+	if id.Username == "" {
+		id.Username = username
+	}
+	if a.cfg.EmailAttribute != "" {
+		id.Email = entry.GetAttributeValue(a.cfg.EmailAttribute)
+	}
+	if a.cfg.GroupAttribute != "" {
+		id.Groups = a.mapGroups(entry.GetAttributeValues(a.cfg.GroupAttribute))
+	}
+	return id, nil
+}
+
+func (a *LDAPAuthenticator) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(a.cfg.URL, ldap.DialWithTLSConfig(a.cfg.TLSConfig))
+	if err != nil {
+		return nil, fmt.Errorf("ldap dial: %w", err)
+	}
+	conn.SetTimeout(a.cfg.Timeout)
+
+	if a.cfg.StartTLS {
+		if err = conn.StartTLS(a.cfg.TLSConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap start tls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (a *LDAPAuthenticator) mapGroups(dns []string) []string {
+	groups := []string{}
+	for _, dn := range dns {
+		cn := groupCN(dn)
+		if len(a.cfg.GroupMapping) == 0 {
+			if cn != "" {
+				groups = append(groups, cn)
+			}
+			continue
+		}
+		if g, ok := a.cfg.GroupMapping[strings.ToLower(dn)]; ok {
+			groups = append(groups, g)
+		} else if g, ok = a.cfg.GroupMapping[strings.ToLower(cn)]; ok && cn != "" {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// groupCN returns the first cn of dn, or empty if dn is invalid.
+func groupCN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 {
+		return ""
+	}
+	for _, attr := range parsed.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+	return ""
+}
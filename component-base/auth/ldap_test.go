@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stubDirectory is an in-process LDAP server, which only supports simple bind and search by filter.
+type stubDirectory struct {
+	listener  net.Listener
+	passwords map[string]string
+	// entries are keyed by search filter.
+	entries map[string][]*ldap.Entry
+}
+
+func newStubDirectory(t *testing.T, tlsConfig *tls.Config) *stubDirectory {
+	var l net.Listener
+	var err error
+	if tlsConfig != nil {
+		l, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		l, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	d := &stubDirectory{
+		listener: l,
+		passwords: map[string]string{
+			"cn=admin,dc=example,dc=com":            "admin-secret",
+			"uid=alice,ou=people,dc=example,dc=com": "alice-secret",
+		},
+		entries: map[string][]*ldap.Entry{
+			"(uid=alice)": {ldap.NewEntry("uid=alice,ou=people,dc=example,dc=com", map[string][]string{
+				"uid":  {"alice"},
+				"mail": {"alice@example.com"},
+				"memberOf": {
+					"cn=developers,ou=groups,dc=example,dc=com",
+					"cn=Ops,ou=groups,dc=example,dc=com",
+				},
+			})},
+			"(uid=twin)": {
+				ldap.NewEntry("uid=twin,ou=a,dc=example,dc=com", nil),
+				ldap.NewEntry("uid=twin,ou=b,dc=example,dc=com", nil),
+			},
+		},
+	}
+	go d.serve()
+	return d
+}
+
+func (d *stubDirectory) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *stubDirectory) handle(conn net.Conn) {
+	defer conn.Close()
+
+	bound := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			code := uint16(ldap.LDAPResultInvalidCredentials)
+			if want, ok := d.passwords[dn]; dn == "" || ok && want == password {
+				code, bound = ldap.LDAPResultSuccess, dn
+			}
+			_, _ = conn.Write(stubResponse(id, ldap.ApplicationBindResponse, stubResult(code)...).Bytes())
+		case ldap.ApplicationSearchRequest:
+			// Only service account can search.
+			if bound != "cn=admin,dc=example,dc=com" {
+				_, _ = conn.Write(stubResponse(id, ldap.ApplicationSearchResultDone,
+					stubResult(ldap.LDAPResultInsufficientAccessRights)...).Bytes())
+				continue
+			}
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for _, e := range d.entries[filter] {
+				attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+				for _, a := range e.Attributes {
+					attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+					attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, a.Name, ""))
+					vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+					for _, v := range a.Values {
+						vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, ""))
+					}
+					attr.AppendChild(vals)
+					attrs.AppendChild(attr)
+				}
+				name := ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.DN, "")
+				_, _ = conn.Write(stubResponse(id, ldap.ApplicationSearchResultEntry, name, attrs).Bytes())
+			}
+			_, _ = conn.Write(stubResponse(id, ldap.ApplicationSearchResultDone, stubResult(ldap.LDAPResultSuccess)...).Bytes())
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func stubResponse(id int64, tag ber.Tag, children ...*ber.Packet) *ber.Packet {
+	p := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	for _, c := range children {
+		op.AppendChild(c)
+	}
+	p.AppendChild(op)
+	return p
+}
+
+func stubResult(code uint16) []*ber.Packet {
+	return []*ber.Packet{
+		ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), ""),
+		ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""),
+		ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""),
+	}
+}
+
+func newTestLDAPAuthenticator(url string, tlsConfig *tls.Config, mapping map[string]string) *LDAPAuthenticator {
+	return NewLDAPAuthenticator(LDAPConfig{
+		URL:            url,
+		TLSConfig:      tlsConfig,
+		BindDN:         "cn=admin,dc=example,dc=com",
+		BindPassword:   "admin-secret",
+		BaseDN:         "dc=example,dc=com",
+		EmailAttribute: "mail",
+		GroupAttribute: "memberOf",
+		GroupMapping:   mapping,
+		Timeout:        time.Second,
+	})
+}
+
+func TestLDAPAuthenticator_Authenticate(t *testing.T) {
+	d := newStubDirectory(t, nil)
+	a := newTestLDAPAuthenticator("ldap://"+d.listener.Addr().String(), nil, nil)
+
+	id, err := a.Authenticate("alice", "alice-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &LDAPIdentity{
+		DN:       "uid=alice,ou=people,dc=example,dc=com",
+		Username: "alice",
+		Email:    "alice@example.com",
+		Groups:   []string{"developers", "Ops"},
+	}
+	if !reflect.DeepEqual(id, want) {
+		t.Errorf("got %+v, want %+v", id, want)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     error
+	}{
+		{"wrong password", "alice", "wrong", ErrLDAPInvalidCredentials},
+		{"empty password", "alice", "", ErrLDAPInvalidCredentials},
+		{"unknown user", "bob", "alice-secret", ErrLDAPUserNotFound},
+		{"ambiguous user", "twin", "secret", ErrLDAPUserNotFound},
+		{"filter injection", "*", "alice-secret", ErrLDAPUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := a.Authenticate(tt.username, tt.password); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticator_GroupMapping(t *testing.T) {
+	d := newStubDirectory(t, nil)
+	a := newTestLDAPAuthenticator("ldap://"+d.listener.Addr().String(), nil, map[string]string{
+		"CN=Developers,OU=Groups,DC=example,DC=com": "dev",
+		"ops": "sre",
+	})
+
+	id, err := a.Authenticate("alice", "alice-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(id.Groups, []string{"dev", "sre"}) {
+		t.Errorf("unexpected groups: %v", id.Groups)
+	}
+}
+
+func TestLDAPAuthenticator_TLS(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	d := newStubDirectory(t, &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}})
+	url := "ldaps://" + d.listener.Addr().String()
+
+	if _, err = newTestLDAPAuthenticator(url, &tls.Config{RootCAs: pool}, nil).Authenticate("alice", "alice-secret"); err != nil {
+		t.Errorf("trusted server: %v", err)
+	}
+	if _, err = newTestLDAPAuthenticator(url, &tls.Config{}, nil).Authenticate("alice", "alice-secret"); err == nil {
+		t.Errorf("untrusted server should fail")
+	}
+}
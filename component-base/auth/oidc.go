@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keysRefreshInterval limits how often jwks is fetched again when a token is signed by unknown kid.
+const keysRefreshInterval = time.Minute
+
+var (
+	ErrIDTokenMissing = errors.New("id_token missing in token response")
+	ErrIssuerMismatch = errors.New("issuer mismatch")
+	ErrNonceMismatch  = errors.New("nonce mismatch")
+	ErrClaimMissing   = errors.New("claim missing")
+)
+
+// idTokenMethods are algorithms accepted in id token, "none" and HMAC are never accepted.
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", AlgEdDSA}
+
+// OIDCProviderMetadata is the part of discovery document used by OIDCRelyingParty, see:
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type OIDCProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCRelyingParty logs user in with an upstream OpenID Connect provider by authorization code flow.
+// Provider metadata and keys are fetched on first use and cached.
+type OIDCRelyingParty struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mu            sync.RWMutex
+	metadata      *OIDCProviderMetadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewOIDCRelyingParty returns a relying party of issuer, scope openid is always requested.
+func NewOIDCRelyingParty(issuer, clientID, clientSecret, redirectURL string, scopes []string) *OIDCRelyingParty {
+	s := []string{"openid"}
+	for _, scope := range scopes {
+		if scope != "openid" {
+			s = append(s, scope)
+		}
+	}
+	return &OIDCRelyingParty{
+		Issuer:       strings.TrimSuffix(issuer, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       s,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Metadata returns provider metadata, fetching it from discovery endpoint at first time.
+func (p *OIDCRelyingParty) Metadata(ctx context.Context) (*OIDCProviderMetadata, error) {
+	p.mu.RLock()
+	m := p.metadata
+	p.mu.RUnlock()
+	if m != nil {
+		return m, nil
+	}
+
+	m = &OIDCProviderMetadata{}
+	if err := p.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", m); err != nil {
+		return nil, fmt.Errorf("discover provider: %w", err)
+	}
+	// See: https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfigurationValidation
+	if strings.TrimSuffix(m.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("discover provider: %w: got %s", ErrIssuerMismatch, m.Issuer)
+	}
+
+	p.mu.Lock()
+	p.metadata = m
+	p.mu.Unlock()
+	return m, nil
+}
+
+// AuthCodeURL returns the url of provider to redirect user to, codeChallenge is S256 of PKCE verifier.
+func (p *OIDCRelyingParty) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	m, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(m.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", p.RedirectURL)
+	q.Set("scope", strings.Join(p.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	if codeChallenge != "" {
+		q.Set("code_challenge", codeChallenge)
+		q.Set("code_challenge_method", "S256")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange redeems code at token endpoint and returns the raw id token, which must be verified by VerifyIDToken.
+func (p *OIDCRelyingParty) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	m, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.RedirectURL},
+	}
+	if codeVerifier != "" {
+		form.Set("code_verifier", codeVerifier)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+
+	var res struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = p.doJSON(req, &res); err != nil && res.Error == "" {
+		return "", fmt.Errorf("exchange code: %w", err)
+	}
+	if res.Error != "" {
+		return "", fmt.Errorf("exchange code: %s: %s", res.Error, res.ErrorDescription)
+	}
+	if res.IDToken == "" {
+		return "", ErrIDTokenMissing
+	}
+	return res.IDToken, nil
+}
+
+// VerifyIDToken checks signature, issuer, audience, time and nonce of id token, see:
+// https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation
+func (p *OIDCRelyingParty) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (jwt.MapClaims, error) {
+	m, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(idTokenMethods))
+	if _, err = parser.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.publicKey(ctx, m, kid)
+	}); err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(m.Issuer, true) {
+		return nil, ErrIssuerMismatch
+	}
+	if !claims.VerifyAudience(p.ClientID, true) {
+		return nil, jwt.ErrTokenInvalidAudience
+	}
+	// Authorized party must be us when there are multiple audiences.
+	if azp, ok := claims["azp"].(string); ok && azp != p.ClientID {
+		return nil, jwt.ErrTokenInvalidAudience
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, jwt.ErrTokenExpired
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, ErrNonceMismatch
+	}
+	return claims, nil
+}
+
+// publicKey finds key by kid, jwks is fetched again if kid is unknown in case provider rotated keys.
+func (p *OIDCRelyingParty) publicKey(ctx context.Context, m *OIDCProviderMetadata, kid string) (crypto.PublicKey, error) {
+	p.mu.RLock()
+	key, ok := p.keys[kid]
+	fetchedAt := p.keysFetchedAt
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if time.Since(fetchedAt) < keysRefreshInterval {
+		return nil, ErrUnknownKID
+	}
+
+	var set JWKSet
+	if err := p.getJSON(ctx, m.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.PublicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	if key, ok = keys[kid]; !ok {
+		return nil, ErrUnknownKID
+	}
+	return key, nil
+}
+
+func (p *OIDCRelyingParty) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	return p.doJSON(req, v)
+}
+
+// doJSON decodes body into v, even if status is not 200 so that oauth errors can be read.
+func (p *OIDCRelyingParty) doJSON(req *http.Request, v interface{}) error {
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	decodeErr := json.Unmarshal(body, v)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", req.URL, resp.StatusCode)
+	}
+	return decodeErr
+}
+
+// ClaimMapping tells which claims of id token are mapped to local user fields.
+type ClaimMapping struct {
+	Username string
+	Email    string
+	Groups   string
+}
+
+// FederatedIdentity is the user described by id token.
+type FederatedIdentity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	Groups   []string
+}
+
+// Map extracts identity from claims of a verified id token.
+// Groups claim can be an array or a string separated by space or comma.
+func (m ClaimMapping) Map(claims jwt.MapClaims) (*FederatedIdentity, error) {
+	id := &FederatedIdentity{}
+	id.Issuer, _ = claims["iss"].(string)
+	id.Subject, _ = claims["sub"].(string)
+	if id.Subject == "" {
+		return nil, fmt.Errorf("%w: sub", ErrClaimMissing)
+	}
+
+	id.Username, _ = claims[m.Username].(string)
+	if id.Username == "" {
+		return nil, fmt.Errorf("%w: %s", ErrClaimMissing, m.Username)
+	}
+	// Unverified email is dropped, it may be used to deliver message such as password reset token.
+	if verified, ok := claims["email_verified"].(bool); m.Email != "" && (!ok || verified) {
+		id.Email, _ = claims[m.Email].(string)
+	}
+	if m.Groups != "" {
+		switch groups := claims[m.Groups].(type) {
+		case []interface{}:
+			for _, g := range groups {
+				if s, ok := g.(string); ok && s != "" {
+					id.Groups = append(id.Groups, s)
+				}
+			}
+		case string:
+			id.Groups = strings.FieldsFunc(groups, func(r rune) bool { return r == ' ' || r == ',' })
+		}
+	}
+	return id, nil
+}
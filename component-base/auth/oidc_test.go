@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// stubIssuer is a minimal OpenID provider, it issues id token with claims for any code.
+type stubIssuer struct {
+	*httptest.Server
+	ring   *KeyRing
+	claims jwt.MapClaims
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	k, err := GenerateSigningKey(AlgES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &stubIssuer{ring: NewKeyRing(k)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&OIDCProviderMetadata{
+			Issuer:                s.URL,
+			AuthorizationEndpoint: s.URL + "/authorize",
+			TokenEndpoint:         s.URL + "/token",
+			JWKSURI:               s.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(s.ring.JWKS())
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "iam" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		if r.PostFormValue("code") != "good" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		token, _ := s.ring.Sign(s.claims)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": token, "token_type": "Bearer"})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	s.claims = jwt.MapClaims{
+		"iss":   s.URL,
+		"sub":   "248289761001",
+		"aud":   "iam",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": "n-0S6_WzA2Mj",
+		"login": "alice",
+		"mail":  "alice@example.com",
+		"teams": []string{"dev", "ops"},
+	}
+	return s
+}
+
+func TestOIDCRelyingParty_Login(t *testing.T) {
+	issuer := newStubIssuer(t)
+	p := NewOIDCRelyingParty(issuer.URL, "iam", "secret", "http://127.0.0.1:8080/login/federated/callback", []string{"email"})
+	ctx := context.Background()
+
+	u, err := p.AuthCodeURL(ctx, "af0ifjsldkj", "n-0S6_WzA2Mj", "challenge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := url.Parse(u)
+	q := parsed.Query()
+	if parsed.Path != "/authorize" || q.Get("scope") != "openid email" || q.Get("state") != "af0ifjsldkj" ||
+		q.Get("code_challenge_method") != "S256" {
+		t.Errorf("unexpected auth code url: %s", u)
+	}
+
+	raw, err := p.Exchange(ctx, "good", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := p.VerifyIDToken(ctx, raw, "n-0S6_WzA2Mj")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := ClaimMapping{Username: "login", Email: "mail", Groups: "teams"}.Map(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != "248289761001" || id.Username != "alice" || id.Email != "alice@example.com" ||
+		len(id.Groups) != 2 || id.Groups[1] != "ops" {
+		t.Errorf("unexpected identity: %+v", id)
+	}
+
+	if _, err = p.Exchange(ctx, "bad", ""); err == nil {
+		t.Errorf("bad code should fail")
+	}
+}
+
+func TestOIDCRelyingParty_VerifyIDToken(t *testing.T) {
+	issuer := newStubIssuer(t)
+	p := NewOIDCRelyingParty(issuer.URL, "iam", "secret", "", nil)
+	ctx := context.Background()
+
+	sign := func(modify func(c jwt.MapClaims)) string {
+		c := jwt.MapClaims{}
+		for k, v := range issuer.claims {
+			c[k] = v
+		}
+		modify(c)
+		token, _ := issuer.ring.Sign(c)
+		return token
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c jwt.MapClaims)
+		want   error
+	}{
+		{"valid", func(c jwt.MapClaims) {}, nil},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, ErrIssuerMismatch},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other" }, jwt.ErrTokenInvalidAudience},
+		{"other azp", func(c jwt.MapClaims) { c["aud"] = []string{"iam", "other"}; c["azp"] = "other" }, jwt.ErrTokenInvalidAudience},
+		{"wrong nonce", func(c jwt.MapClaims) { c["nonce"] = "replayed" }, ErrNonceMismatch},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, jwt.ErrTokenExpired},
+		{"no exp", func(c jwt.MapClaims) { delete(c, "exp") }, jwt.ErrTokenExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.VerifyIDToken(ctx, sign(tt.modify), "n-0S6_WzA2Mj")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Token signed with shared secret must not be accepted.
+	hs, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.claims).SignedString([]byte("secret"))
+	if _, err := p.VerifyIDToken(ctx, hs, "n-0S6_WzA2Mj"); err == nil {
+		t.Errorf("HS256 id token should be rejected")
+	}
+
+	// Token signed by a key not in jwks.
+	k, _ := GenerateSigningKey(AlgES256)
+	forged, _ := NewKeyRing(k).Sign(issuer.claims)
+	if _, err := p.VerifyIDToken(ctx, forged, "n-0S6_WzA2Mj"); !errors.Is(err, ErrUnknownKID) {
+		t.Errorf("got %v, want %v", err, ErrUnknownKID)
+	}
+}
+
+func TestOIDCRelyingParty_KeyRotation(t *testing.T) {
+	issuer := newStubIssuer(t)
+	p := NewOIDCRelyingParty(issuer.URL, "iam", "secret", "", nil)
+	ctx := context.Background()
+
+	token, _ := issuer.ring.Sign(issuer.claims)
+	if _, err := p.VerifyIDToken(ctx, token, "n-0S6_WzA2Mj"); err != nil {
+		t.Fatal(err)
+	}
+
+	k, _ := GenerateSigningKey(AlgRS256)
+	issuer.ring.Add(k)
+	token, _ = issuer.ring.Sign(issuer.claims)
+
+	if _, err := p.VerifyIDToken(ctx, token, "n-0S6_WzA2Mj"); !errors.Is(err, ErrUnknownKID) {
+		t.Errorf("jwks should not be fetched again within refresh interval, got %v", err)
+	}
+	p.keysFetchedAt = time.Now().Add(-keysRefreshInterval)
+	if _, err := p.VerifyIDToken(ctx, token, "n-0S6_WzA2Mj"); err != nil {
+		t.Errorf("token signed by rotated key should be valid: %v", err)
+	}
+}
+
+func TestClaimMapping_Map(t *testing.T) {
+	m := ClaimMapping{Username: "preferred_username", Email: "email", Groups: "groups"}
+
+	id, err := m.Map(jwt.MapClaims{
+		"sub":                "1",
+		"preferred_username": "bob",
+		"email":              "bob@example.com",
+		"email_verified":     false,
+		"groups":             "dev,ops admin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Email != "" {
+		t.Errorf("unverified email should be dropped, got %s", id.Email)
+	}
+	if len(id.Groups) != 3 || id.Groups[2] != "admin" {
+		t.Errorf("unexpected groups: %v", id.Groups)
+	}
+
+	if _, err = m.Map(jwt.MapClaims{"sub": "1"}); !errors.Is(err, ErrClaimMissing) {
+		t.Errorf("got %v, want %v", err, ErrClaimMissing)
+	}
+}
@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Request signing works like AWS Signature Version 4, the Authorization header looks like:
+//
+//	IAM-HMAC-SHA256 Credential=<secretID>, SignedHeaders=host;x-iam-content-sha256;x-iam-date, Signature=<hex>
+//
+// Signature is HMAC-SHA256 of string to sign with secret key, see StringToSign and CanonicalRequest.
+const (
+	SignAlgorithm = "IAM-HMAC-SHA256"
+
+	HeaderDate          = "X-Iam-Date"
+	HeaderContentSHA256 = "X-Iam-Content-Sha256"
+
+	// HeaderSecurityToken carries session token of temporary credential, it's signed if set.
+	HeaderSecurityToken = "X-Iam-Security-Token"
+
+	// SignDateFormat is the format of HeaderDate, in UTC.
+	SignDateFormat = "20060102T150405Z"
+)
+
+// requiredSignedHeaders must be signed, so that signature can't be used for other host, time or body.
+var requiredSignedHeaders = []string{"host", strings.ToLower(HeaderContentSHA256), strings.ToLower(HeaderDate)}
+
+var (
+	ErrSignatureFormat   = errors.New("invalid signature format")
+	ErrSignatureHeaders  = errors.New("host, x-iam-content-sha256 and x-iam-date must be signed")
+	ErrSignatureDate     = errors.New("invalid x-iam-date")
+	ErrSignatureBody     = errors.New("x-iam-content-sha256 doesn't match body")
+	ErrSignatureMismatch = errors.New("signature mismatch")
+	ErrSecurityToken     = errors.New("x-iam-security-token must be signed and match temporary credential")
+	ErrBodyTooLarge      = errors.New("request body too large")
+)
+
+// RequestSignature is parsed from Authorization header.
+type RequestSignature struct {
+	Credential    string
+	SignedHeaders []string
+	Signature     string
+}
+
+// SignRequest sets date, body hash and Authorization headers, body must be the same as req.Body.
+func SignRequest(req *http.Request, secretID, secretKey string, body []byte, t time.Time) {
+	req.Header.Set(HeaderDate, t.UTC().Format(SignDateFormat))
+	req.Header.Set(HeaderContentSHA256, hashHex(body))
+
+	signed := append([]string{}, requiredSignedHeaders...)
+	if req.Header.Get(HeaderSecurityToken) != "" {
+		signed = append(signed, strings.ToLower(HeaderSecurityToken))
+	}
+	sig := hex.EncodeToString(hmacSHA256([]byte(secretKey), StringToSign(req, signed)))
+
+	req.Header.Set("Authorization", SignAlgorithm+" Credential="+secretID+
+		", SignedHeaders="+strings.Join(signed, ";")+", Signature="+sig)
+}
+
+// ParseRequestSignature parses Authorization header of signed request.
+func ParseRequestSignature(authorization string) (*RequestSignature, error) {
+	if !strings.HasPrefix(authorization, SignAlgorithm+" ") {
+		return nil, ErrSignatureFormat
+	}
+	params := strings.TrimPrefix(authorization, SignAlgorithm+" ")
+
+	s := &RequestSignature{}
+	for _, p := range strings.Split(params, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if !ok {
+			return nil, ErrSignatureFormat
+		}
+		switch k {
+		case "Credential":
+			s.Credential = v
+		case "SignedHeaders":
+			s.SignedHeaders = strings.Split(v, ";")
+		case "Signature":
+			s.Signature = v
+		}
+	}
+	if s.Credential == "" || s.Signature == "" || len(s.SignedHeaders) == 0 {
+		return nil, ErrSignatureFormat
+	}
+	return s, nil
+}
+
+// VerifySecurityToken checks signed HeaderSecurityToken is token, call it after Verify.
+func (s *RequestSignature) VerifySecurityToken(req *http.Request, token string) error {
+	if !contains(s.SignedHeaders, strings.ToLower(HeaderSecurityToken)) ||
+		!hmac.Equal([]byte(req.Header.Get(HeaderSecurityToken)), []byte(token)) {
+		return ErrSecurityToken
+	}
+	return nil
+}
+
+// SignedAt returns time in HeaderDate of req.
+func SignedAt(req *http.Request) (time.Time, error) {
+	t, err := time.Parse(SignDateFormat, req.Header.Get(HeaderDate))
+	if err != nil {
+		return t, ErrSignatureDate
+	}
+	return t, nil
+}
+
+// Verify checks body hash and signature of req, time skew is left to caller.
+func (s *RequestSignature) Verify(req *http.Request, body []byte, secretKey string) error {
+	for _, h := range requiredSignedHeaders {
+		if !contains(s.SignedHeaders, h) {
+			return ErrSignatureHeaders
+		}
+	}
+	if _, err := SignedAt(req); err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(req.Header.Get(HeaderContentSHA256)), []byte(hashHex(body))) {
+		return ErrSignatureBody
+	}
+
+	got, err := hex.DecodeString(s.Signature)
+	if err != nil {
+		return ErrSignatureFormat
+	}
+	if !hmac.Equal(got, hmacSHA256([]byte(secretKey), StringToSign(req, s.SignedHeaders))) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// StringToSign is algorithm, date and hash of canonical request separated by new line.
+func StringToSign(req *http.Request, signedHeaders []string) string {
+	return SignAlgorithm + "\n" + req.Header.Get(HeaderDate) + "\n" + hashHex([]byte(CanonicalRequest(req, signedHeaders)))
+}
+
+// CanonicalRequest is method, path, sorted query, signed headers and body hash separated by new line.
+func CanonicalRequest(req *http.Request, signedHeaders []string) string {
+	headers := make([]string, len(signedHeaders))
+	for i, h := range signedHeaders {
+		headers[i] = strings.ToLower(h)
+	}
+	sort.Strings(headers)
+
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteByte('\n')
+	b.WriteString(canonicalPath(req.URL))
+	b.WriteByte('\n')
+	b.WriteString(canonicalQuery(req.URL.Query()))
+	b.WriteByte('\n')
+	for _, h := range headers {
+		b.WriteString(h)
+		b.WriteByte(':')
+		b.WriteString(canonicalHeaderValue(req, h))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	b.WriteString(strings.Join(headers, ";"))
+	b.WriteByte('\n')
+	b.WriteString(req.Header.Get(HeaderContentSHA256))
+	return b.String()
+}
+
+func canonicalPath(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// canonicalQuery sorts by key then value, and encodes space as %20.
+func canonicalQuery(q url.Values) string {
+	pairs := make([]string, 0, len(q))
+	for k, vs := range q {
+		for _, v := range vs {
+			pairs = append(pairs, escape(k)+"="+escape(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaderValue(req *http.Request, h string) string {
+	// Host is removed from header by net/http.
+	if h == "host" {
+		if req.Host != "" {
+			return req.Host
+		}
+		return req.URL.Host
+	}
+	// Values shares slice with header, request must not be changed by signing.
+	raw := req.Header.Values(h)
+	values := make([]string, len(raw))
+	for i, v := range raw {
+		values[i] = strings.Join(strings.Fields(v), " ")
+	}
+	return strings.Join(values, ",")
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func contains(items []string, s string) bool {
+	for _, i := range items {
+		if strings.EqualFold(i, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadBody reads body of req and puts it back, so that it can be read again by handlers.
+// Body larger than limit is an error.
+func ReadBody(req *http.Request, limit int64) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err == nil && int64(len(body)) > limit {
+		err = ErrBodyTooLarge
+	}
+	return body, err
+}
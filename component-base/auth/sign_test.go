@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSignedRequest(t *testing.T, body []byte) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, "https://iam.example.com/v1/authz?b=2&a=hello world&a=1", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SignRequest(req, "secret-id", "secret-key", body, time.Date(2022, 12, 1, 8, 0, 0, 0, time.UTC))
+	return req
+}
+
+func TestSignRequest(t *testing.T) {
+	body := []byte(`{"subject":"users:alice"}`)
+	req := newSignedRequest(t, body)
+
+	if got := req.Header.Get(HeaderDate); got != "20221201T080000Z" {
+		t.Errorf("date got %s", got)
+	}
+	want := "POST\n/v1/authz\na=1&a=hello%20world&b=2\n" +
+		"host:iam.example.com\nx-iam-content-sha256:" + hashHex(body) + "\nx-iam-date:20221201T080000Z\n\n" +
+		"host;x-iam-content-sha256;x-iam-date\n" + hashHex(body)
+	if got := CanonicalRequest(req, []string{"x-iam-date", "host", "x-iam-content-sha256"}); got != want {
+		t.Errorf("canonical request got:\n%s\nwant:\n%s", got, want)
+	}
+
+	sig, err := ParseRequestSignature(req.Header.Get("Authorization"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Credential != "secret-id" {
+		t.Errorf("credential got %s", sig.Credential)
+	}
+	if err = sig.Verify(req, body, "secret-key"); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+}
+
+func TestCanonicalRequest_KeepsHeader(t *testing.T) {
+	req := newSignedRequest(t, nil)
+	req.Header.Add("X-Custom", "  a   b ")
+	req.Header.Add("X-Custom", "c")
+
+	want := "x-custom:a b,c\n"
+	if got := CanonicalRequest(req, []string{"x-custom"}); !strings.Contains(got, want) {
+		t.Errorf("canonical request got:\n%s\nwant line %q", got, want)
+	}
+	if got := req.Header.Values("X-Custom"); len(got) != 2 || got[0] != "  a   b " {
+		t.Errorf("header should not be changed, got %q", got)
+	}
+}
+
+func TestRequestSignature_Verify(t *testing.T) {
+	body := []byte(`{"subject":"users:alice"}`)
+
+	tests := []struct {
+		name   string
+		modify func(req *http.Request, sig *RequestSignature) []byte
+		key    string
+		want   error
+	}{
+		{"wrong key", func(req *http.Request, sig *RequestSignature) []byte { return body }, "other", ErrSignatureMismatch},
+		{"body changed", func(req *http.Request, sig *RequestSignature) []byte { return []byte(`{}`) }, "secret-key", ErrSignatureBody},
+		{"query changed", func(req *http.Request, sig *RequestSignature) []byte {
+			req.URL.RawQuery = "a=1&b=3"
+			return body
+		}, "secret-key", ErrSignatureMismatch},
+		{"method changed", func(req *http.Request, sig *RequestSignature) []byte {
+			req.Method = http.MethodPut
+			return body
+		}, "secret-key", ErrSignatureMismatch},
+		{"date changed", func(req *http.Request, sig *RequestSignature) []byte {
+			req.Header.Set(HeaderDate, "20221201T080001Z")
+			return body
+		}, "secret-key", ErrSignatureMismatch},
+		{"date unsigned", func(req *http.Request, sig *RequestSignature) []byte {
+			sig.SignedHeaders = []string{"host", "x-iam-content-sha256"}
+			return body
+		}, "secret-key", ErrSignatureHeaders},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newSignedRequest(t, body)
+			sig, err := ParseRequestSignature(req.Header.Get("Authorization"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = sig.Verify(req, tt.modify(req, sig), tt.key); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestSignature_VerifySecurityToken(t *testing.T) {
+	body := []byte(`{}`)
+	req, err := http.NewRequest(http.MethodPost, "https://iam.example.com/v1/authz", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(HeaderSecurityToken, "session-token")
+	SignRequest(req, "STS-id", "secret-key", body, time.Now())
+
+	sig, err := ParseRequestSignature(req.Header.Get("Authorization"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sig.Verify(req, body, "secret-key"); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err = sig.VerifySecurityToken(req, "session-token"); err != nil {
+		t.Errorf("verify token: %v", err)
+	}
+	if err = sig.VerifySecurityToken(req, "other"); !errors.Is(err, ErrSecurityToken) {
+		t.Errorf("other token: got %v, want %v", err, ErrSecurityToken)
+	}
+
+	// Token can't be replaced after signing.
+	req.Header.Set(HeaderSecurityToken, "other")
+	if err = sig.Verify(req, body, "secret-key"); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("token changed: got %v, want %v", err, ErrSignatureMismatch)
+	}
+
+	// Token which isn't signed is rejected.
+	unsigned := newSignedRequest(t, body)
+	unsigned.Header.Set(HeaderSecurityToken, "session-token")
+	if sig, err = ParseRequestSignature(unsigned.Header.Get("Authorization")); err != nil {
+		t.Fatal(err)
+	}
+	if err = sig.VerifySecurityToken(unsigned, "session-token"); !errors.Is(err, ErrSecurityToken) {
+		t.Errorf("unsigned token: got %v, want %v", err, ErrSecurityToken)
+	}
+}
+
+func TestParseRequestSignature(t *testing.T) {
+	for _, h := range []string{
+		"Bearer token",
+		SignAlgorithm + " Credential=id",
+		SignAlgorithm + " Credential=id, SignedHeaders, Signature=00",
+	} {
+		if _, err := ParseRequestSignature(h); !errors.Is(err, ErrSignatureFormat) {
+			t.Errorf("%q: got %v, want %v", h, err, ErrSignatureFormat)
+		}
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte("12345")))
+	body, err := ReadBody(req, 5)
+	if err != nil || string(body) != "12345" {
+		t.Fatalf("got %q, %v", body, err)
+	}
+	again, _ := ReadBody(req, 5)
+	if string(again) != "12345" {
+		t.Errorf("body should be put back, got %q", again)
+	}
+	if _, err = ReadBody(req, 4); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("got %v, want %v", err, ErrBodyTooLarge)
+	}
+}
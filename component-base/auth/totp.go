@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters refer to RFC 6238, which are default values of most authenticator apps.
+const (
+	TOTPDigits = 6
+	TOTPPeriod = 30 * time.Second
+
+	totpSecretSize = 20
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random base32 secret shared with authenticator app.
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTP computes code of secret at time t with HMAC-SHA1.
+func TOTP(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, uint64(t.Unix()/int64(TOTPPeriod/time.Second))), nil
+}
+
+// ValidateTOTP checks code at time t, allowing skew periods before and after for clock drift.
+func ValidateTOTP(secret, code string, t time.Time, skew int) bool {
+	if len(code) != TOTPDigits {
+		return false
+	}
+	for i := -skew; i <= skew; i++ {
+		expected, err := TOTP(secret, t.Add(time.Duration(i)*TOTPPeriod))
+		if err != nil {
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// TOTPProvisioningURI returns otpauth uri, which can be rendered into QR code for authenticator app.
+// See: https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(TOTPDigits))
+	v.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// hotp refers to RFC 4226.
+func hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, code%mod)
+}
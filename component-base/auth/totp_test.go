@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors come from RFC 6238 Appendix B, truncated to 6 digits.
+func TestTOTP(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTP(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("TOTP at %d got %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	prev, _ := TOTP(secret, now.Add(-TOTPPeriod))
+	old, _ := TOTP(secret, now.Add(-3*TOTPPeriod))
+
+	if !ValidateTOTP(secret, prev, now, 1) {
+		t.Errorf("code of previous period should be valid with skew 1")
+	}
+	if ValidateTOTP(secret, prev, now, 0) && prev != mustTOTP(t, secret, now) {
+		t.Errorf("code of previous period should be invalid with skew 0")
+	}
+	if ValidateTOTP(secret, old, now, 1) && old != mustTOTP(t, secret, now) {
+		t.Errorf("old code should be invalid")
+	}
+	if ValidateTOTP(secret, "12345", now, 1) {
+		t.Errorf("code with wrong length should be invalid")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("iam", "alice", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/iam:alice?") || !strings.Contains(uri, "secret=ABC") {
+		t.Errorf("unexpected uri: %s", uri)
+	}
+}
+
+func mustTOTP(t *testing.T, secret string, at time.Time) string {
+	code, err := TOTP(secret, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook payloads are signed like Stripe, the signature header looks like:
+//
+//	t=1669881600,v1=<hex>
+//
+// v1 is HMAC-SHA256 of "<t>.<body>" with signing secret of webhook, so that
+// receiver can check the sender and reject payloads replayed after tolerance.
+const (
+	HeaderWebhookSignature = "X-Iam-Webhook-Signature"
+	HeaderWebhookEvent     = "X-Iam-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Iam-Webhook-Delivery"
+)
+
+var (
+	ErrWebhookSignatureFormat   = errors.New("invalid webhook signature format")
+	ErrWebhookSignatureExpired  = errors.New("webhook signature expired")
+	ErrWebhookSignatureMismatch = errors.New("webhook signature mismatch")
+)
+
+// SignWebhook returns value of HeaderWebhookSignature.
+func SignWebhook(secret string, body []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(hmacSHA256([]byte(secret), ts+"."+string(body)))
+}
+
+// VerifyWebhook checks signature header of payload, signature made before now - tolerance fails.
+func VerifyWebhook(secret, signature string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(signature, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return ErrWebhookSignatureFormat
+		}
+		switch kv[0] {
+		case "t":
+			ts = kv[1]
+		case "v1":
+			sig = kv[1]
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrWebhookSignatureFormat
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return ErrWebhookSignatureExpired
+	}
+
+	want := hmacSHA256([]byte(secret), ts+"."+string(body))
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, want) {
+		return ErrWebhookSignatureMismatch
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(`{"type":"secret.created"}`)
+	at := time.Date(2022, 12, 1, 8, 0, 0, 0, time.UTC)
+	sig := SignWebhook("whsec", body, at)
+
+	tests := []struct {
+		name   string
+		secret string
+		sig    string
+		body   []byte
+		now    time.Time
+		want   error
+	}{
+		{"ok", "whsec", sig, body, at.Add(time.Minute), nil},
+		{"wrong secret", "other", sig, body, at, ErrWebhookSignatureMismatch},
+		{"body changed", "whsec", sig, []byte(`{"type":"secret.deleted"}`), at, ErrWebhookSignatureMismatch},
+		{"expired", "whsec", sig, body, at.Add(10 * time.Minute), ErrWebhookSignatureExpired},
+		{"no timestamp", "whsec", "v1=00", body, at, ErrWebhookSignatureFormat},
+		{"bad format", "whsec", "garbage", body, at, ErrWebhookSignatureFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyWebhook(tt.secret, tt.sig, tt.body, 5*time.Minute, tt.now); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
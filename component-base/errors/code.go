@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
 )
 
@@ -51,7 +52,7 @@ func (c defaultCoder) Reference() string {
 var mu sync.Mutex
 
 // codes saves all register Coder in memory.
-var codes map[int]Coder
+var codes = map[int]Coder{unknownCode.Code(): unknownCode}
 
 var unknownCode Coder = &defaultCoder{
 	code:      0,
@@ -60,6 +61,11 @@ var unknownCode Coder = &defaultCoder{
 	reference: "",
 }
 
+// NewCoder creates a Coder to register, reference is optional.
+func NewCoder(code, httpCode int, message, reference string) Coder {
+	return &defaultCoder{code: code, httpCode: httpCode, message: message, reference: reference}
+}
+
 // Register registers a coder with override strategy.
 func Register(coder Coder) {
 	if coder.Code() == 0 {
@@ -94,6 +100,16 @@ func AsCode(err error) Coder {
 	return unknownCode
 }
 
+// Code returns the code err created by WithCode or WrapC carries, no matter whether it's registered,
+// it's the code of unknownCode if err carries no code.
+func Code(err error) int {
+	var c *withCode
+	if As(err, &c) {
+		return c.code
+	}
+	return unknownCode.Code()
+}
+
 // IsCode checks whether err is associated with coder deeply.
 func IsCode(err error, coder Coder) bool {
 	if err == nil {
@@ -116,7 +132,15 @@ func Registered(code int) bool {
 	return has
 }
 
-func init() {
-	codes = make(map[int]Coder)
-	codes[unknownCode.Code()] = unknownCode
+// Coders returns all registered coders in order of code, unknownCode is the first.
+func Coders() []Coder {
+	mu.Lock()
+	defer mu.Unlock()
+
+	coders := make([]Coder, 0, len(codes))
+	for _, coder := range codes {
+		coders = append(coders, coder)
+	}
+	sort.Slice(coders, func(i, j int) bool { return coders[i].Code() < coders[j].Code() })
+	return coders
 }
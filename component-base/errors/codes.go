@@ -77,3 +77,51 @@ const (
 	// ErrDecodingYaml - 500: Yaml data could not be decoded.
 	ErrDecodingYaml
 )
+
+// request codes must be 10_04_xx.
+const (
+	// ErrIdempotencyKeyReused - 422: Idempotency key is reused with a different request.
+	ErrIdempotencyKeyReused int = iota + 100401
+
+	// ErrIdempotencyKeyInFlight - 409: Request with the same idempotency key is being processed.
+	ErrIdempotencyKeyInFlight
+
+	// ErrRequestBodyTooLarge - 413: Request body is too large.
+	ErrRequestBodyTooLarge
+)
+
+func init() {
+	for _, c := range []struct {
+		code     int
+		httpCode int
+		message  string
+	}{
+		{ErrSuccess, 200, "OK."},
+		{ErrUnknown, 500, "Internal server error."},
+		{ErrBind, 400, "Error occurred while binding the request body to the struct."},
+		{ErrValidation, 400, "Validation failed."},
+		{ErrTokenInvalid, 401, "Token invalid."},
+		{ErrPageNotFound, 404, "Page not found."},
+		{ErrDatabase, 500, "Database error."},
+		{ErrEncrypt, 401, "Error occurred while encrypting the user password."},
+		{ErrSignatureInvalid, 401, "Signature is invalid."},
+		{ErrExpired, 401, "Token expired."},
+		{ErrInvalidAuthHeader, 401, "Invalid authorize header."},
+		{ErrMissingHeader, 401, "The `Authorization` header was empty."},
+		{ErrPasswordIncorrect, 401, "Password was incorrect."},
+		{ErrPermissionDenied, 403, "Permission denied."},
+		{ErrEncodingFailed, 500, "Encoding failed due to an error with the data."},
+		{ErrDecodingFailed, 500, "Decoding failed due to an error with the data."},
+		{ErrInvalidJSON, 500, "Data is not valid JSON."},
+		{ErrEncodingJSON, 500, "JSON data could not be encoded."},
+		{ErrDecodingJSON, 500, "JSON data could not be decoded."},
+		{ErrInvalidYaml, 500, "Data is not valid Yaml."},
+		{ErrEncodingYaml, 500, "Yaml data could not be encoded."},
+		{ErrDecodingYaml, 500, "Yaml data could not be decoded."},
+		{ErrIdempotencyKeyReused, 422, "Idempotency key is reused with a different request."},
+		{ErrIdempotencyKeyInFlight, 409, "Request with the same idempotency key is being processed."},
+		{ErrRequestBodyTooLarge, 413, "Request body is too large."},
+	} {
+		MustRegister(NewCoder(c.code, c.httpCode, c.message, ""))
+	}
+}
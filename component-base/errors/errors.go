@@ -272,7 +272,7 @@ type withCode struct {
 func WithCode(code int, format string, a ...any) error {
 	return &withCode{
 		code:  code,
-		error: fmt.Errorf(format, a),
+		error: fmt.Errorf(format, a...),
 		cause: nil,
 		stack: callers(),
 	}
@@ -285,13 +285,18 @@ func WrapC(err error, code int, format string, a ...any) error {
 	}
 	return &withCode{
 		code:  code,
-		error: fmt.Errorf(format, a),
+		error: fmt.Errorf(format, a...),
 		cause: err,
 		stack: callers(),
 	}
 }
 
-func (c *withCode) Error() string { return c.error.Error() + ": " + c.cause.Error() }
+func (c *withCode) Error() string {
+	if c.cause == nil {
+		return c.error.Error()
+	}
+	return c.error.Error() + ": " + c.cause.Error()
+}
 
 func (c *withCode) Cause() error { return c.cause }
 
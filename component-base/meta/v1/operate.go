@@ -18,10 +18,10 @@ type ListOperateMeta struct {
 	TimeoutSeconds *int64 `json:"timeout-seconds,omitempty"`
 
 	// Offset specify the number of records to skip before starting to return the records.
-	Offset *int64 `json:"offset,omitempty" form:"offset"`
+	Offset *int64 `json:"offset,omitempty" form:"offset" validate:"omitempty,min=0"`
 
 	// Limit specify the number of records to be retrieved.
-	Limit *int64 `json:"limit,omitempty" form:"limit"`
+	Limit *int64 `json:"limit,omitempty" form:"limit" validate:"omitempty,min=0"`
 }
 
 type GetOperateMeta struct {
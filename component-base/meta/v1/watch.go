@@ -0,0 +1,23 @@
+package v1
+
+import "encoding/json"
+
+// Types of WatchEvent.
+const (
+	WatchAdded    = "ADDED"
+	WatchModified = "MODIFIED"
+	WatchDeleted  = "DELETED"
+	// WatchError ends the stream, Object carries message.
+	WatchError = "ERROR"
+)
+
+// WatchEvent is a change of resource streamed by list with watch=true.
+type WatchEvent struct {
+	Type string `json:"type"`
+
+	// ResourceVersion is position of event in log, watching from it again resumes after this event.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	// Object is the resource, only identifying fields are set for WatchDeleted.
+	Object json.RawMessage `json:"object,omitempty"`
+}
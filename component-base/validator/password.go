@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 )
 
@@ -33,65 +35,134 @@ const (
 	maxPassWordLength = 16
 )
 
-func checkPassword(pw string) error {
+// commonPasswords is a built-in blocklist, which satisfies default character classes but is easy to guess.
+var commonPasswords = []string{
+	"P@ssw0rd", "P@ssword1", "Passw0rd!", "Password1!", "Password@1", "Password@123",
+	"Admin@123", "Admin@2021", "Admin@2022", "Admin@2023", "Admin123!", "Root@123",
+	"Qwerty@123", "Qwerty123!", "Welcome@123", "Welcome1!", "Abc@1234", "Abc@123456",
+	"Aa123456!", "Aa@123456", "Test@123", "Test@1234", "Changeme1!", "Iloveyou1!",
+	"Letmein1!", "Summer@2023", "Winter@2023", "Zaq1@wsx", "1qaz@WSX", "!QAZ2wsx",
+}
+
+// PasswordPolicy defines rules a password must satisfy.
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+
+	RequireUpper  bool
+	RequireLower  bool
+	RequireNumber bool
+	RequireSymbol bool
+
+	// Blocklist saves common passwords which are compared case-insensitively.
+	Blocklist []string
+
+	// HistoryCount forbids reusing the last N passwords, zero disables it.
+	HistoryCount int
+
+	// MaxAge forces user to change password after this duration, zero disables it.
+	MaxAge time.Duration
+}
+
+// DefaultPasswordPolicy returns policy with 8-16 length, all character classes and built-in blocklist.
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:     minPassWordLength,
+		MaxLength:     maxPassWordLength,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireNumber: true,
+		RequireSymbol: true,
+		Blocklist:     commonPasswords,
+	}
+}
+
+var (
+	policy   = DefaultPasswordPolicy()
+	policyMu sync.RWMutex
+)
+
+// SetPasswordPolicy replaces the policy used by CheckPassword and "password" tag.
+func SetPasswordPolicy(p *PasswordPolicy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policy = p
+}
+
+// GetPasswordPolicy returns the policy in use.
+func GetPasswordPolicy() *PasswordPolicy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return policy
+}
+
+// Expired tells whether a password changed at changedAt should be changed now.
+func (p *PasswordPolicy) Expired(changedAt time.Time) bool {
+	if p.MaxAge <= 0 {
+		return false
+	}
+	return time.Now().After(changedAt.Add(p.MaxAge))
+}
+
+// Check checks password with length, character classes and blocklist.
+func (p *PasswordPolicy) Check(pw string) error {
 	var hasUpper bool
 	var hasLower bool
 	var hasNumber bool
-	var hasPunctuation bool
+	var hasSymbol bool
 	var hasSpace bool
 
 	var length = 0
-	var invalids string
-
-	var appendInvalidMessage = func(message string) {
-		msg := strings.TrimSpace(message)
-		if invalids == "" {
-			invalids = msg
-		} else {
-			invalids = invalids + "," + message
-		}
-	}
+	var invalids []string
 
 	for _, w := range pw {
 		switch {
+		case unicode.IsSpace(w):
+			hasSpace = true
+			continue
 		case unicode.IsLower(w):
 			hasLower = true
-			length += 1
 		case unicode.IsUpper(w):
 			hasUpper = true
-			length += 1
 		case unicode.IsNumber(w):
 			hasNumber = true
-			length += 1
-		case unicode.IsPunct(w):
-			hasPunctuation = true
-			length += 1
-		case unicode.IsSpace(w):
-			hasSpace = true
+		case unicode.IsPunct(w) || unicode.IsSymbol(w):
+			hasSymbol = true
 		}
+		length += 1
 	}
 
-	if !hasNumber {
-		appendInvalidMessage("number letter missing.")
+	if p.RequireNumber && !hasNumber {
+		invalids = append(invalids, "number letter missing.")
 	}
-	if !hasUpper {
-		appendInvalidMessage("upper letter missing.")
+	if p.RequireUpper && !hasUpper {
+		invalids = append(invalids, "upper letter missing.")
 	}
-	if !hasLower {
-		appendInvalidMessage("lower letter missing.")
+	if p.RequireLower && !hasLower {
+		invalids = append(invalids, "lower letter missing.")
 	}
-	if !hasPunctuation {
-		appendInvalidMessage("punctuation letter missing.")
+	if p.RequireSymbol && !hasSymbol {
+		invalids = append(invalids, "punctuation letter missing.")
 	}
 	if hasSpace {
-		appendInvalidMessage("space latter must remove.")
+		invalids = append(invalids, "space latter must remove.")
+	}
+	if length < p.MinLength || (p.MaxLength > 0 && length > p.MaxLength) {
+		invalids = append(invalids, fmt.Sprintf("password length must between %d and %d", p.MinLength, p.MaxLength))
 	}
-	if length < minPassWordLength && length > maxPassWordLength {
-		appendInvalidMessage(fmt.Sprintf("password length must between %d and %d", minPassWordLength, maxPassWordLength))
+	for _, b := range p.Blocklist {
+		if strings.EqualFold(pw, b) {
+			invalids = append(invalids, "password is too common.")
+			break
+		}
 	}
 
-	if invalids != "" {
-		return fmt.Errorf(invalids)
+	if len(invalids) > 0 {
+		return fmt.Errorf("%s", strings.Join(invalids, ","))
 	}
 	return nil
 }
+
+func checkPassword(pw string) error {
+	return GetPasswordPolicy().Check(pw)
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	p := DefaultPasswordPolicy()
+
+	tests := []struct {
+		pw    string
+		valid bool
+	}{
+		{"Ab1!xyzw", true},
+		{"Ab1$xyzw", true},
+		{"Ab1!xyz", false},           // too short
+		{"Ab1!xyzwAb1!xyzwA", false}, // too long
+		{"ab1!xyzw", false},          // no upper
+		{"AB1!XYZW", false},          // no lower
+		{"Abc!xyzw", false},          // no number
+		{"Ab12xyzw", false},          // no symbol
+		{"Ab1! xyzw", false},         // space
+		{"p@ssw0rd", false},          // blocklist, case-insensitive
+	}
+
+	for _, tt := range tests {
+		if err := p.Check(tt.pw); (err == nil) != tt.valid {
+			t.Errorf("Check(%q) got err %v, want valid %v", tt.pw, err, tt.valid)
+		}
+	}
+}
+
+func TestPasswordPolicy_Relaxed(t *testing.T) {
+	p := &PasswordPolicy{MinLength: 4, RequireLower: true}
+
+	if err := p.Check("abcdefghijklmnopqrstuvwxyz"); err != nil {
+		t.Errorf("unlimited max length got err: %v", err)
+	}
+	if err := p.Check("abc"); err == nil {
+		t.Errorf("min length not checked")
+	}
+}
+
+func TestPasswordPolicy_Expired(t *testing.T) {
+	p := &PasswordPolicy{}
+	if p.Expired(time.Now().Add(-time.Hour * 24 * 365)) {
+		t.Errorf("zero max age must never expire")
+	}
+
+	p.MaxAge = time.Hour
+	if !p.Expired(time.Now().Add(-time.Hour * 2)) {
+		t.Errorf("password should be expired")
+	}
+	if p.Expired(time.Now()) {
+		t.Errorf("password should not be expired")
+	}
+}
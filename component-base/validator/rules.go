@@ -0,0 +1,168 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/ory/ladon"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxNameLength        = 64
+	maxDescriptionLength = 255
+)
+
+// nameRegexp allows letters, digits, '-', '_' and '.', which must start and end with letter or digit.
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_.-]*[a-zA-Z0-9])?$`)
+
+// inline marks embedded struct without json name, it's removed from field path.
+const inline = "~"
+
+// WithName registers "name" tag, empty name passes because it's generated when creating.
+func WithName() Option {
+	return func(v *validator.Validate) error {
+		return v.RegisterValidation("name", func(fl validator.FieldLevel) bool {
+			name := fl.Field().String()
+			return name == "" || (len(name) <= maxNameLength && nameRegexp.MatchString(name))
+		})
+	}
+}
+
+// WithDescription registers "description" tag.
+func WithDescription() Option {
+	return func(v *validator.Validate) error {
+		return v.RegisterValidation("description", func(fl validator.FieldLevel) bool {
+			return utf8.RuneCountInString(fl.Field().String()) <= maxDescriptionLength
+		})
+	}
+}
+
+// WithPolicy checks structure of every ladon.DefaultPolicy in struct, it requires resources, actions
+// and effect. Subjects may be empty, like session policy of temporary credential.
+func WithPolicy() Option {
+	return func(v *validator.Validate) error {
+		v.RegisterStructValidation(func(sl validator.StructLevel) {
+			p := sl.Current().Interface().(ladon.DefaultPolicy)
+			if len(p.Resources) == 0 {
+				sl.ReportError(p.Resources, "resources", "Resources", "required", "")
+			}
+			if len(p.Actions) == 0 {
+				sl.ReportError(p.Actions, "actions", "Actions", "required", "")
+			}
+			if p.Effect != ladon.AllowAccess && p.Effect != ladon.DenyAccess {
+				sl.ReportError(p.Effect, "effect", "Effect", "oneof", ladon.AllowAccess+" "+ladon.DenyAccess)
+			}
+			for _, items := range [][]string{p.Subjects, p.Resources, p.Actions} {
+				for _, item := range items {
+					// Ladon compiles content between delimiters as regexp.
+					if strings.Count(item, "<") != strings.Count(item, ">") {
+						sl.ReportError(item, "pattern", "", "delimiter", "<>")
+					}
+				}
+			}
+		}, ladon.DefaultPolicy{})
+		return nil
+	}
+}
+
+// withJSONName reports fields in json name, or form name for query parameters.
+func withJSONName() Option {
+	return func(v *validator.Validate) error {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, key := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			if f.Anonymous {
+				return inline
+			}
+			return ""
+		})
+		return nil
+	}
+}
+
+// BuiltinOptions registers all rules tagged on api types.
+func BuiltinOptions() []Option {
+	return []Option{withJSONName(), WithName(), WithDescription(), WithPolicy(), WithCheckPassword()}
+}
+
+// Validate validates struct s by `validate` tags with singleton validator, all failed fields are
+// aggregated into one error with code errors.ErrValidation.
+// Singleton is created with BuiltinOptions if it hasn't been created.
+func Validate(s interface{}) error {
+	v, _ := GetValidator(BuiltinOptions()...)
+	return v.Struct(s)
+}
+
+// Struct validates struct s like Validate.
+func (v *Validator) Struct(s interface{}) error {
+	err := v.validator.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return errors.WithCode(errors.ErrValidation, "%s", err.Error())
+	}
+
+	var messages []string
+	for _, fe := range fieldErrors {
+		messages = append(messages, field(fe.Namespace())+" "+message(fe))
+	}
+	return errors.WithCode(errors.ErrValidation, "%s", strings.Join(messages, "; "))
+}
+
+// field removes root struct and inline segments from namespace, like User.metadata.name to metadata.name.
+func field(namespace string) string {
+	segments := strings.Split(namespace, ".")
+	var path []string
+	for _, s := range segments[1:] {
+		if s != inline {
+			path = append(path, s)
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+func message(fe validator.FieldError) string {
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	} else if fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map {
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "name":
+		return fmt.Sprintf("must be at most %d letters, digits, '-', '_' or '.', and start and end with letter or digit", maxNameLength)
+	case "description":
+		return fmt.Sprintf("must be at most %d characters", maxDescriptionLength)
+	case "password":
+		return "is too weak: " + CheckPasswordErr(fmt.Sprint(fe.Value())).Error()
+	case "delimiter":
+		return fmt.Sprintf("has unbalanced delimiters %s", fe.Param())
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	case "email", "url":
+		return "must be a valid " + fe.Tag()
+	default:
+		return strings.TrimSpace("failed on " + fe.Tag() + " " + fe.Param())
+	}
+}
@@ -0,0 +1,114 @@
+package validator
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/ory/ladon"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testPolicy struct {
+	ladon.DefaultPolicy
+}
+
+type testResource struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Description string     `json:"description" validate:"description"`
+	Policy      testPolicy `json:"policy" validate:"omitempty"`
+}
+
+func validPolicy() testPolicy {
+	return testPolicy{ladon.DefaultPolicy{
+		Subjects:  []string{"<.*>"},
+		Resources: []string{"resources:articles:<.*>"},
+		Actions:   []string{"get"},
+		Effect:    ladon.AllowAccess,
+	}}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      testResource
+		fields []string
+	}{
+		{"valid", testResource{Policy: validPolicy()}, nil},
+		{"generated name", testResource{ObjectMeta: metav1.ObjectMeta{Name: ""}, Policy: validPolicy()}, nil},
+		{"name", testResource{ObjectMeta: metav1.ObjectMeta{Name: "-bad name"}, Policy: validPolicy()}, []string{"metadata.name"}},
+		{"long name", testResource{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 65)}, Policy: validPolicy()}, []string{"metadata.name"}},
+		{"description", testResource{Description: strings.Repeat("描", 256), Policy: validPolicy()}, []string{"description"}},
+		{"empty policy", testResource{}, []string{"policy.resources", "policy.actions", "policy.effect"}},
+	}
+
+	for _, tt := range tests {
+		err := Validate(&tt.r)
+		if tt.fields == nil {
+			if err != nil {
+				t.Errorf("%s: got err %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: want err of %v", tt.name, tt.fields)
+			continue
+		}
+		if errors.Code(err) != errors.ErrValidation {
+			t.Errorf("%s: got code %d", tt.name, errors.Code(err))
+		}
+		for _, f := range tt.fields {
+			if !strings.Contains(err.Error(), f+" ") {
+				t.Errorf("%s: %q doesn't report %s", tt.name, err.Error(), f)
+			}
+		}
+	}
+}
+
+func TestValidate_Delimiter(t *testing.T) {
+	r := testResource{Policy: validPolicy()}
+	r.Policy.Resources = []string{"resources:<.*"}
+
+	if err := Validate(&r); err == nil || !strings.Contains(err.Error(), "policy.pattern") {
+		t.Errorf("unbalanced delimiters got err %v", err)
+	}
+}
+
+func TestValidate_Query(t *testing.T) {
+	offset := int64(-1)
+	if err := Validate(&metav1.ListOperateMeta{Offset: &offset}); err == nil || !strings.Contains(err.Error(), "offset") {
+		t.Errorf("negative offset got err %v", err)
+	}
+	if err := Validate(&metav1.ListOperateMeta{}); err != nil {
+		t.Errorf("empty query got err %v", err)
+	}
+}
+
+func TestValidate_Response(t *testing.T) {
+	r := testResource{ObjectMeta: metav1.ObjectMeta{Name: "-bad name"}}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	web.WriteResponse(c, Validate(&r), nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	var body web.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != errors.ErrValidation {
+		t.Errorf("code got %d, want %d", body.Code, errors.ErrValidation)
+	}
+	for _, f := range []string{"metadata.name", "policy.resources", "policy.actions", "policy.effect"} {
+		if !strings.Contains(body.Message, f+" ") {
+			t.Errorf("message %q doesn't report %s", body.Message, f)
+		}
+	}
+}
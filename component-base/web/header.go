@@ -0,0 +1,4 @@
+package web
+
+// HeaderIdempotencyKey makes retries of a create request with the same key and body get the first response.
+const HeaderIdempotencyKey = "Idempotency-Key"
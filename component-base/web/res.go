@@ -40,9 +40,14 @@ func (er *ErrorResponse) GoString() string {
 func WriteResponse(c *gin.Context, err error, data any) {
 	if err != nil {
 		coder := errors.AsCode(err)
+		message := coder.Message()
+		// Client must know which fields are wrong, messages of other errors may leak details of server.
+		if coder.Code() == errors.ErrBind || coder.Code() == errors.ErrValidation {
+			message = err.Error()
+		}
 		c.JSON(coder.HTTPCode(), ErrorResponse{
 			Code:      coder.Code(),
-			Message:   coder.Message(),
+			Message:   message,
 			Reference: coder.Reference(),
 		})
 		return
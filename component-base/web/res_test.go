@@ -1,7 +1,13 @@
 package web
 
 import (
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -15,3 +21,52 @@ func TestErrorResponse_String(t *testing.T) {
 	fmt.Println(er)
 	fmt.Println(er.String())
 }
+
+func TestWriteResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    int
+		message string
+	}{
+		{"validation", errors.WithCode(errors.ErrValidation, "metadata.name is invalid"), http.StatusBadRequest, errors.ErrValidation, "metadata.name is invalid"},
+		{"bind", errors.WithCode(errors.ErrBind, "unexpected EOF"), http.StatusBadRequest, errors.ErrBind, "unexpected EOF"},
+		{"permission", errors.WithCode(errors.ErrPermissionDenied, "only admin can list users."), http.StatusForbidden, errors.ErrPermissionDenied, "Permission denied."},
+		{"token", errors.WithCode(errors.ErrTokenInvalid, "bad signature"), http.StatusUnauthorized, errors.ErrTokenInvalid, "Token invalid."},
+		{"database", errors.WithCode(errors.ErrDatabase, "dial tcp 10.0.0.1:3306"), http.StatusInternalServerError, errors.ErrDatabase, "Database error."},
+		{"unregistered", errors.WithCode(999999, "oops"), http.StatusInternalServerError, 0, "An internal server error occurs."},
+		{"no code", stderrors.New("oops"), http.StatusInternalServerError, 0, "An internal server error occurs."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			WriteResponse(c, tt.err, nil)
+
+			if w.Code != tt.status {
+				t.Errorf("status got %d, want %d", w.Code, tt.status)
+			}
+			var body ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.code || body.Message != tt.message {
+				t.Errorf("body got %d %q, want %d %q", body.Code, body.Message, tt.code, tt.message)
+			}
+		})
+	}
+}
+
+func TestWriteResponse_Data(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	WriteResponse(c, nil, map[string]string{"name": "alice"})
+
+	if w.Code != http.StatusOK || w.Body.String() != `{"name":"alice"}` {
+		t.Errorf("got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/auth"
 	"istomyang.github.com/like-iam/component-base/errors"
 	"istomyang.github.com/like-iam/component-base/web"
 	"strings"
@@ -23,6 +24,8 @@ func (m *Manager) AuthFunc() gin.HandlerFunc {
 type AutoScheme struct {
 	basic *BasicScheme
 	jwt   *JwtScheme
+	hmac  *HMACScheme
+	cert  *CertScheme
 }
 
 func NewAutoScheme(basic *BasicScheme, jwt *JwtScheme) *AutoScheme {
@@ -32,9 +35,26 @@ func NewAutoScheme(basic *BasicScheme, jwt *JwtScheme) *AutoScheme {
 	}
 }
 
+// WithHMAC accepts requests signed by auth.SignRequest besides Basic and Bearer.
+func (a *AutoScheme) WithHMAC(hmac *HMACScheme) *AutoScheme {
+	a.hmac = hmac
+	return a
+}
+
+// WithCert authenticates requests without Authorization header by verified client certificate.
+func (a *AutoScheme) WithCert(cert *CertScheme) *AutoScheme {
+	a.cert = cert
+	return a
+}
+
 func (a *AutoScheme) AuthFunc() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		h := c.GetHeader("Authorization")
+		if h == "" && a.cert != nil && VerifiedClientCert(c) != nil {
+			a.cert.AuthFunc()(c)
+			c.Next()
+			return
+		}
 		if h == "" {
 			web.WriteResponse(c,
 				errors.WithCode(errors.ErrPermissionDenied, "Don't has `Authorization` header."),
@@ -61,6 +81,15 @@ func (a *AutoScheme) AuthFunc() gin.HandlerFunc {
 			m.SetScheme(a.basic)
 		case "Bearer":
 			m.SetScheme(a.jwt)
+		case auth.SignAlgorithm:
+			if a.hmac == nil {
+				web.WriteResponse(c,
+					errors.WithCode(errors.ErrInvalidAuthHeader, "Signed request is not supported."),
+					nil)
+				c.Abort()
+				return
+			}
+			m.SetScheme(a.hmac)
 		default:
 			web.WriteResponse(
 				c,
@@ -9,7 +9,8 @@ import (
 	"strings"
 )
 
-type CheckFunc = func(username, password string) bool
+// CheckFunc tells whether username and password is correct, c is given to know where request comes from.
+type CheckFunc = func(c *gin.Context, username, password string) bool
 
 // Check whether BasicScheme impl Scheme or not.
 // If not, must throw error.
@@ -41,7 +42,7 @@ func (b *BasicScheme) AuthFunc() gin.HandlerFunc {
 		payload, _ := base64.StdEncoding.DecodeString(auths[1])
 		up := strings.SplitN(string(payload), ":", 2)
 
-		if len(up) != 2 && b.check(up[0], up[1]) {
+		if len(up) != 2 || !b.check(c, up[0], up[1]) {
 			web.WriteResponse(c,
 				errors.WithCode(errors.ErrSignatureInvalid, "Authorization header format is wrong."),
 				nil)
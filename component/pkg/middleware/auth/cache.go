@@ -22,6 +22,8 @@ type Secret struct {
 	// Key is a secret key to encrypt plain jwt string.
 	Key     string
 	Expires int64
+	// SessionToken must be signed in request if set, only temporary credential has it.
+	SessionToken string
 }
 
 // CacheScheme defines a authn Scheme using cache-solution in redis and memory.
@@ -45,7 +47,7 @@ func (s *CacheScheme) AuthFunc() gin.HandlerFunc {
 		}
 
 		var token string
-		_, _ = fmt.Sscanf(h, "Bearer %s", token)
+		_, _ = fmt.Sscanf(h, "Bearer %s", &token)
 
 		var secret *Secret
 
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/x509"
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+)
+
+// CertCheckFunc returns username mapped from identities of client certificate, empty if none is mapped.
+type CertCheckFunc = func(identities []string) (string, error)
+
+var _ Scheme = &CertScheme{}
+
+// CertScheme authenticates client by its certificate verified in TLS handshake, see SecureServerOpts.ClientTLSConfig.
+type CertScheme struct {
+	check CertCheckFunc
+}
+
+func NewCertScheme(checkFunc CertCheckFunc) *CertScheme {
+	return &CertScheme{check: checkFunc}
+}
+
+func (s *CertScheme) AuthFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cert := VerifiedClientCert(c)
+		if cert == nil {
+			web.WriteResponse(c, errors.WithCode(errors.ErrPermissionDenied, "No verified client certificate."), nil)
+			c.Abort()
+			return
+		}
+
+		username, err := s.check(CertIdentities(cert))
+		if err != nil || username == "" {
+			web.WriteResponse(c, errors.WithCode(errors.ErrPermissionDenied,
+				"Client certificate %s is not mapped to any user.", cert.Subject.CommonName), nil)
+			c.Abort()
+			return
+		}
+
+		c.Set(middleware.UserNameKey, username)
+
+		c.Next()
+	}
+}
+
+// VerifiedClientCert returns leaf certificate verified by client CA, nil if not given or on insecure listener.
+func VerifiedClientCert(c *gin.Context) *x509.Certificate {
+	if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 || len(c.Request.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return c.Request.TLS.VerifiedChains[0][0]
+}
+
+// CertIdentities lists SAN in order of URI, DNS and email, then CN.
+func CertIdentities(cert *x509.Certificate) []string {
+	var ids []string
+	for _, u := range cert.URIs {
+		ids = append(ids, u.String())
+	}
+	ids = append(ids, cert.DNSNames...)
+	ids = append(ids, cert.EmailAddresses...)
+	if cert.Subject.CommonName != "" {
+		ids = append(ids, cert.Subject.CommonName)
+	}
+	return ids
+}
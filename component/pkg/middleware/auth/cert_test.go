@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCertIdentities(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://mesh/ns/default/sa/deployer")
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "cn"},
+		URIs:           []*url.URL{spiffe},
+		DNSNames:       []string{"dns1", "dns2"},
+		EmailAddresses: []string{"a@example.com"},
+	}
+
+	got := strings.Join(CertIdentities(cert), ",")
+	want := "spiffe://mesh/ns/default/sa/deployer,dns1,dns2,a@example.com,cn"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if ids := CertIdentities(&x509.Certificate{}); len(ids) != 0 {
+		t.Errorf("got %v, want nothing without CN", ids)
+	}
+}
+
+func TestAutoScheme_Cert(t *testing.T) {
+	basic := NewBasicScheme(func(c *gin.Context, username, password string) bool {
+		return password == "pass"
+	})
+	cert := NewCertScheme(func(identities []string) (string, error) {
+		for _, id := range identities {
+			if id == "alice" || id == "bob" {
+				return id, nil
+			}
+		}
+		return "", nil
+	})
+
+	tests := []struct {
+		name   string
+		scheme *AutoScheme
+		header string
+		cn     string
+		want   string
+		code   int
+	}{
+		{"cert without header", NewAutoScheme(basic, nil).WithCert(cert), "", "alice", "alice", http.StatusOK},
+		{"header before cert", NewAutoScheme(basic, nil).WithCert(cert), "bob:pass", "alice", "bob", http.StatusOK},
+		{"wrong header not fall back", NewAutoScheme(basic, nil).WithCert(cert), "bob:wrong", "alice", "", http.StatusUnauthorized},
+		{"unmapped cert", NewAutoScheme(basic, nil).WithCert(cert), "", "nobody", "", http.StatusForbidden},
+		{"no cert", NewAutoScheme(basic, nil).WithCert(cert), "", "", "", http.StatusForbidden},
+		{"cert not enabled", NewAutoScheme(basic, nil), "", "alice", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/v1/users", nil)
+		if tt.header != "" {
+			c.Request.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(tt.header)))
+		}
+		if tt.cn != "" {
+			leaf := &x509.Certificate{Subject: pkix.Name{CommonName: tt.cn}}
+			c.Request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf}}}
+		}
+
+		tt.scheme.AuthFunc()(c)
+
+		if got := c.GetString(middleware.UserNameKey); got != tt.want {
+			t.Errorf("%s: username got %q, want %q", tt.name, got, tt.want)
+		}
+		if w.Code != tt.code {
+			t.Errorf("%s: status got %d, want %d", tt.name, w.Code, tt.code)
+		}
+	}
+}
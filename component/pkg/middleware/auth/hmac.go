@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"time"
+)
+
+// maxSignedBodySize limits body read into memory to verify its hash.
+const maxSignedBodySize = 4 << 20
+
+var _ Scheme = &HMACScheme{}
+
+// HMACScheme verifies requests signed with secret key by auth.SignRequest, so that a captured request
+// can't be changed, and can only be replayed within skew.
+type HMACScheme struct {
+	// get query Secret by secret id in Credential.
+	get  func(secretID string) (*Secret, error)
+	skew time.Duration
+}
+
+func NewHMACScheme(get func(secretID string) (*Secret, error), skew time.Duration) *HMACScheme {
+	return &HMACScheme{get: get, skew: skew}
+}
+
+func (s *HMACScheme) AuthFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sig, err := auth.ParseRequestSignature(c.GetHeader("Authorization"))
+		if err != nil {
+			web.WriteResponse(c, errors.WithCode(errors.ErrInvalidAuthHeader, err.Error()), nil)
+			c.Abort()
+			return
+		}
+
+		signedAt, err := auth.SignedAt(c.Request)
+		if err != nil {
+			web.WriteResponse(c, errors.WithCode(errors.ErrSignatureInvalid, err.Error()), nil)
+			c.Abort()
+			return
+		}
+		if d := time.Since(signedAt); d > s.skew || d < -s.skew {
+			web.WriteResponse(c, errors.WithCode(errors.ErrExpired, "request signed at %s is out of %s.",
+				signedAt.Format(time.RFC3339), s.skew), nil)
+			c.Abort()
+			return
+		}
+
+		secret, err := s.get(sig.Credential)
+		if err != nil || secret == nil {
+			web.WriteResponse(c, errors.WithCode(errors.ErrSignatureInvalid, ErrMissingSecret.Error()), nil)
+			c.Abort()
+			return
+		}
+		if keyExpired(secret.Expires) {
+			tm := time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05")
+			web.WriteResponse(c, errors.WithCode(errors.ErrExpired, "expired at: %s", tm), nil)
+			c.Abort()
+			return
+		}
+
+		body, err := auth.ReadBody(c.Request, maxSignedBodySize)
+		if err != nil {
+			web.WriteResponse(c, errors.WithCode(errors.ErrBind, err.Error()), nil)
+			c.Abort()
+			return
+		}
+		if err = sig.Verify(c.Request, body, secret.Key); err != nil {
+			web.WriteResponse(c, errors.WithCode(errors.ErrSignatureInvalid, err.Error()), nil)
+			c.Abort()
+			return
+		}
+		if secret.SessionToken != "" {
+			if err = sig.VerifySecurityToken(c.Request, secret.SessionToken); err != nil {
+				web.WriteResponse(c, errors.WithCode(errors.ErrSignatureInvalid, err.Error()), nil)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(middleware.UserNameKey, secret.Username)
+		c.Set(middleware.SecretIDKey, secret.ID)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+)
+
+// ImpersonateUserHeader asks to act as another user, like `kubectl --as`.
+const ImpersonateUserHeader = "Impersonate-User"
+
+// ImpersonateCheckFunc returns error if actor can't impersonate target.
+type ImpersonateCheckFunc = func(c *gin.Context, actor, target string) error
+
+var _ Scheme = &ImpersonateScheme{}
+
+// ImpersonateScheme replaces authenticated user with the one in ImpersonateUserHeader, and keeps the
+// authenticated one as middleware.ActorKey. It must be installed after other schemes.
+type ImpersonateScheme struct {
+	check ImpersonateCheckFunc
+}
+
+func NewImpersonateScheme(check ImpersonateCheckFunc) *ImpersonateScheme {
+	return &ImpersonateScheme{check: check}
+}
+
+func (s *ImpersonateScheme) AuthFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		target := c.GetHeader(ImpersonateUserHeader)
+		actor := c.GetString(middleware.UserNameKey)
+		if target == "" || target == actor {
+			c.Next()
+			return
+		}
+		if actor == "" {
+			web.WriteResponse(c, errors.WithCode(errors.ErrPermissionDenied, "Must authenticate before impersonating."), nil)
+			c.Abort()
+			return
+		}
+
+		if err := s.check(c, actor, target); err != nil {
+			log.L(c).Warnf("user %s is denied to impersonate %s: %s", actor, target, err.Error())
+			web.WriteResponse(c, errors.WithCode(errors.ErrPermissionDenied, "Can't impersonate %s: %s", target, err.Error()), nil)
+			c.Abort()
+			return
+		}
+
+		c.Set(middleware.ActorKey, actor)
+		c.Set(middleware.UserNameKey, target)
+		c.Set(log.ActorKey, actor)
+		c.Set(log.UserNameKey, target)
+
+		log.L(c).Infof("user %s impersonates %s: %s %s", actor, target, c.Request.Method, c.Request.URL.Path)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"io"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"time"
+)
+
+// maxIdempotentBodySize limits body read into memory to hash, which is far larger than any create or update.
+const maxIdempotentBodySize = 1 << 20
+
+// idempotentRecord is stored in redis, Status is 0 until first request completes.
+type idempotentRecord struct {
+	Hash        string `json:"hash"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// idempotentWriter keeps a copy of response body.
+type idempotentWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotentWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotentWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response to a POST or PATCH retried with the same Idempotency-Key header
+// and body, and rejects with ErrIdempotencyKeyReused if the key is reused with a different request. Keys are scoped by user.
+// Responses of server error are not stored, so that the request can be retried. It passes if redis fails.
+func Idempotency(keyPrefix string, ttl time.Duration, client func() redis.UniversalClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idemKey := c.GetHeader(web.HeaderIdempotencyKey)
+		if idemKey == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abort(c, errors.WithCode(errors.ErrRequestBodyTooLarge, "request body is larger than %d bytes.", maxIdempotentBodySize))
+				return
+			}
+			abort(c, errors.WithCode(errors.ErrBind, err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		h := sha256.New()
+		h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		h.Write(body)
+		hash := hex.EncodeToString(h.Sum(nil))
+
+		key := keyPrefix + c.GetString(UserNameKey) + ":" + idemKey
+		rdb := client()
+
+		pending, _ := json.Marshal(&idempotentRecord{Hash: hash})
+		ok, err := rdb.SetNX(c, key, pending, ttl).Result()
+		if err != nil {
+			log.L(c).Errorf("save idempotency key %s fail: %s", idemKey, err.Error())
+			c.Next()
+			return
+		}
+		if !ok {
+			replay(c, rdb, key, hash)
+			return
+		}
+
+		w := &idempotentWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			rdb.Del(c, key)
+			return
+		}
+		record, _ := json.Marshal(&idempotentRecord{
+			Hash:        hash,
+			Status:      c.Writer.Status(),
+			ContentType: c.Writer.Header().Get("Content-Type"),
+			Body:        w.body.Bytes(),
+		})
+		if err = rdb.Set(c, key, record, ttl).Err(); err != nil {
+			log.L(c).Errorf("save response of idempotency key %s fail: %s", idemKey, err.Error())
+		}
+	}
+}
+
+func replay(c *gin.Context, rdb redis.UniversalClient, key, hash string) {
+	data, err := rdb.Get(c, key).Bytes()
+	if err != nil {
+		log.L(c).Errorf("get idempotency key fail: %s", err.Error())
+		abort(c, errors.WithCode(errors.ErrIdempotencyKeyInFlight, "idempotency key %s is in flight.", c.GetHeader(web.HeaderIdempotencyKey)))
+		return
+	}
+	var record idempotentRecord
+	if err = json.Unmarshal(data, &record); err != nil {
+		log.L(c).Errorf("decode idempotency record fail: %s", err.Error())
+		abort(c, errors.WithCode(errors.ErrIdempotencyKeyInFlight, "idempotency key %s is in flight.", c.GetHeader(web.HeaderIdempotencyKey)))
+		return
+	}
+
+	switch {
+	case record.Hash != hash:
+		abort(c, errors.WithCode(errors.ErrIdempotencyKeyReused, "idempotency key %s is reused.", c.GetHeader(web.HeaderIdempotencyKey)))
+	case record.Status == 0:
+		abort(c, errors.WithCode(errors.ErrIdempotencyKeyInFlight, "idempotency key %s is in flight.", c.GetHeader(web.HeaderIdempotencyKey)))
+	default:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(record.Status, record.ContentType, record.Body)
+		c.Abort()
+	}
+}
+
+func abort(c *gin.Context, err error) {
+	web.WriteResponse(c, err, nil)
+	c.Abort()
+}
@@ -0,0 +1,99 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdempotency(t *testing.T) {
+	s, err := redistest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	rdb := s.Client()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.Idempotency("idem.", time.Minute, func() redis.UniversalClient { return rdb }))
+	created := 0
+	r.POST("/v1/secrets", func(c *gin.Context) {
+		created++
+		c.JSON(http.StatusOK, gin.H{"created": created})
+	})
+
+	request := func(key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, newRequest("/v1/secrets", key, body))
+		return w
+	}
+
+	first := request("k1", `{"name":"a"}`)
+	retry := request("k1", `{"name":"a"}`)
+	if first.Code != http.StatusOK || retry.Body.String() != first.Body.String() || created != 1 {
+		t.Errorf("retry should replay %s, got %s, created %d", first.Body.String(), retry.Body.String(), created)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replayed response should be marked")
+	}
+
+	rejected := []struct {
+		key, body string
+		status    int
+		code      int
+	}{
+		{"k1", `{"name":"b"}`, http.StatusUnprocessableEntity, errors.ErrIdempotencyKeyReused},
+		{"k2", `{"name":"` + strings.Repeat("a", 2<<20) + `"}`, http.StatusRequestEntityTooLarge, errors.ErrRequestBodyTooLarge},
+	}
+	for _, tt := range rejected {
+		w := request(tt.key, tt.body)
+		var res web.ErrorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &res)
+		if w.Code != tt.status || res.Code != tt.code {
+			t.Errorf("key %s got %d %s, want %d with code %d", tt.key, w.Code, w.Body.String(), tt.status, tt.code)
+		}
+	}
+
+	// Retry while the first request is being processed.
+	release, started := make(chan struct{}), make(chan struct{})
+	r.POST("/v1/policies", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(httptest.NewRecorder(), newRequest("/v1/policies", "k3", `{}`))
+	}()
+	<-started
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newRequest("/v1/policies", "k3", `{}`))
+	close(release)
+	<-done
+	var res web.ErrorResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusConflict || res.Code != errors.ErrIdempotencyKeyInFlight {
+		t.Errorf("key in flight got %d %s, want %d with code %d", w.Code, w.Body.String(), http.StatusConflict, errors.ErrIdempotencyKeyInFlight)
+	}
+
+	if created != 1 {
+		t.Errorf("handler should not run for rejected requests, created %d", created)
+	}
+}
+
+func newRequest(target, key, body string) *http.Request {
+	req := httptest.NewRequest("POST", target, strings.NewReader(body))
+	req.Header.Set(web.HeaderIdempotencyKey, key)
+	return req
+}
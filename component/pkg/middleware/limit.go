@@ -3,8 +3,11 @@ package middleware
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	"golang.org/x/time/rate"
+	"istomyang.github.com/like-iam/log"
 	"net/http"
+	"time"
 )
 
 const ErrLimitExceeded = "limit exceeded"
@@ -20,3 +23,31 @@ func Limit(maxEventPerSec float64, maxBurstToken int) gin.HandlerFunc {
 		}
 	}
 }
+
+// LimitByClient prevents one client ip from more than max requests in window.
+// Requests are counted in redis, so that all servers share the limit, and they pass if redis fails.
+func LimitByClient(keyPrefix string, max int64, window time.Duration, client func() redis.UniversalClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := keyPrefix + c.ClientIP()
+
+		// Counter is created with expiry in the same transaction, so it never lives forever if server
+		// stops between commands.
+		var incr *redis.IntCmd
+		_, err := client().TxPipelined(c, func(p redis.Pipeliner) error {
+			p.SetNX(c, key, 0, window)
+			incr = p.Incr(c, key)
+			return nil
+		})
+		if err != nil {
+			log.L(c).Errorf("count requests of %s fail: %s", c.ClientIP(), err.Error())
+			c.Next()
+			return
+		}
+
+		if incr.Val() > max {
+			_ = c.AbortWithError(http.StatusTooManyRequests, errors.New(ErrLimitExceeded))
+			return
+		}
+		c.Next()
+	}
+}
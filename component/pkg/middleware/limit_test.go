@@ -0,0 +1,68 @@
+package middleware_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimitByClient(t *testing.T) {
+	s, err := redistest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	rdb := s.Client()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.LimitByClient("limit.", 2, time.Minute, func() redis.UniversalClient { return rdb }))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(ip string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if got := request("10.0.0.1"); got != want {
+			t.Errorf("request %d got %d, want %d", i, got, want)
+		}
+	}
+	if got := request("10.0.0.2"); got != http.StatusOK {
+		t.Errorf("other client got %d, want %d", got, http.StatusOK)
+	}
+
+	// Counter expires with window even if it's created by the first request only.
+	if ttl := s.TTL("limit.10.0.0.1"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("counter ttl got %s, want in window", ttl)
+	}
+}
+
+func TestLimitByClient_RedisDown(t *testing.T) {
+	s, err := redistest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdb := s.Client()
+	_ = s.Close()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.LimitByClient("limit.", 0, time.Minute, func() redis.UniversalClient { return rdb }))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("requests should pass if redis fails, got %d", w.Code)
+	}
+}
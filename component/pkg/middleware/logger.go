@@ -8,6 +8,12 @@ import (
 // UserNameKey defines username key string.
 const UserNameKey = "username"
 
+// SecretIDKey defines key of secret id which signs request.
+const SecretIDKey = "secretID"
+
+// ActorKey defines key of the authenticated user when it impersonates UserNameKey.
+const ActorKey = "actor"
+
 // Logger puts XRequestIDKey and UserNameKey 's value into Context with logger's key.
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileNotifier appends message into a file, DO NOT use in production.
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newFileNotifier(path string) Notifier {
+	return &fileNotifier{path: path}
+}
+
+func (n *fileNotifier) Notify(ctx context.Context, msg *Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "[%s] To: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages")
+	n := newFileNotifier(path)
+
+	for _, to := range []string{"alice@example.com", "bob@example.com"} {
+		if err := n.Notify(context.Background(), &Message{To: to, Subject: "Reset your password", Body: "token"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"To: alice@example.com\nSubject: Reset your password\n\ntoken", "To: bob@example.com\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("got %q, want containing %q", data, want)
+		}
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode got %v, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,18 @@
+package notify
+
+import (
+	"context"
+	"istomyang.github.com/like-iam/log"
+)
+
+// logNotifier prints message into log, DO NOT use in production.
+type logNotifier struct{}
+
+func newLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, msg *Message) error {
+	log.L(ctx).Infof("notify to: %s, subject: %s, body: %s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"sync"
+)
+
+// Message is sent to user through Notifier.
+type Message struct {
+	// To is the address of receiver, such as email.
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier sends message to user.
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
+
+var (
+	singletonNotifier Notifier
+	onceNotifier      sync.Once
+)
+
+// GetNotifierOr return a Notifier decided by opts.Type.
+// Call this more times will return singleton, and nil opts return singleton.
+func GetNotifierOr(opts *options.NotifierOpts) Notifier {
+	onceNotifier.Do(func() {
+		switch opts.Type {
+		case options.NotifierSMTP:
+			singletonNotifier = newSMTPNotifier(opts)
+		case options.NotifierFile:
+			singletonNotifier = newFileNotifier(opts.File)
+		default:
+			singletonNotifier = newLogNotifier()
+		}
+	})
+	return singletonNotifier
+}
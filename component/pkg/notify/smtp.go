@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// smtpNotifier sends message as a plain text mail.
+type smtpNotifier struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func newSMTPNotifier(opts *options.NotifierOpts) Notifier {
+	n := &smtpNotifier{
+		addr: net.JoinHostPort(opts.SMTPHost, strconv.Itoa(opts.SMTPPort)),
+		from: opts.SMTPFrom,
+	}
+	if opts.SMTPUsername != "" {
+		n.auth = smtp.PlainAuth("", opts.SMTPUsername, opts.SMTPPassword, opts.SMTPHost)
+	}
+	return n
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, msg *Message) error {
+	// Forbid header injection from user input.
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid mail header")
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + n.from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{msg.To}, []byte(b.String()))
+}
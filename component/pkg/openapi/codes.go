@@ -0,0 +1,13 @@
+package openapi
+
+import "istomyang.github.com/like-iam/component-base/errors"
+
+// RegisteredCodes returns codes registered in component-base/errors, so that document lists the same codes
+// as ErrorResponse carries. Code 0 is in response of error whose code is not registered.
+func RegisteredCodes() []Code {
+	var codes []Code
+	for _, coder := range errors.Coders() {
+		codes = append(codes, Code{Code: coder.Code(), HTTP: coder.HTTPCode(), Message: coder.Message()})
+	}
+	return codes
+}
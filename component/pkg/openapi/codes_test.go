@@ -0,0 +1,35 @@
+package openapi
+
+import (
+	"istomyang.github.com/like-iam/component-base/errors"
+	"testing"
+)
+
+func TestRegisteredCodes(t *testing.T) {
+	errors.Register(errors.NewCoder(990001, 418, "Teapot.", ""))
+
+	codes := RegisteredCodes()
+	if len(codes) == 0 || codes[0].Code != 0 || codes[0].HTTP != 500 {
+		t.Fatalf("unknown code should be the first, got %v", codes)
+	}
+
+	want := map[int]Code{
+		errors.ErrValidation:       {errors.ErrValidation, 400, "Validation failed."},
+		errors.ErrPermissionDenied: {errors.ErrPermissionDenied, 403, "Permission denied."},
+		990001:                     {990001, 418, "Teapot."},
+	}
+	for i, c := range codes {
+		if i > 0 && codes[i-1].Code >= c.Code {
+			t.Errorf("codes are not in order at %d", c.Code)
+		}
+		if w, ok := want[c.Code]; ok {
+			if c != w {
+				t.Errorf("code got %v, want %v", c, w)
+			}
+			delete(want, c.Code)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("codes not listed: %v", want)
+	}
+}
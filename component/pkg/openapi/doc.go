@@ -0,0 +1,4 @@
+// Package openapi generates OpenAPI 3 document from routes of gin engine, schemas of bodies are
+// reflected from go types, so that document follows code without annotations.
+// See https://spec.openapis.org/oas/v3.0.3
+package openapi
@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemas reflects go types into schemas, named structs are put into components and referenced.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// of returns schema of v, nil if v is nil.
+func (s *schemas) of(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// Encoded by itself, shape is unknown.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.register(t)}
+	default:
+		// interface and others accept any value.
+		return &Schema{}
+	}
+}
+
+// register puts schema of named struct into components, and returns its name.
+func (s *schemas) register(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, ok := s.components[name]; ok {
+		// Same name in other package, like v1.User and pb.User.
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	s.names[t] = name
+
+	// Placeholder breaks recursion of self-referencing types.
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+	return name
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(t, schema)
+	return schema
+}
+
+// fields adds fields of struct t into schema, follows the rules of encoding/json.
+func (s *schemas) fields(t reflect.Type, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		// Embedded struct without name, or marked inline, is flattened.
+		if f.Anonymous && ft.Kind() == reflect.Struct && (name == "" || strings.Contains(opts, "inline")) {
+			s.fields(ft, schema)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		schema.Properties[name] = s.schema(f.Type)
+		if required(f) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// required tells field is required by validator, which runs in bind of gin.
+func required(f reflect.StructField) bool {
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package openapi
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/web"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Route describes what gin route can't tell, all fields are optional.
+type Route struct {
+	Summary string
+
+	// Tags defaults to the first segment of path after version.
+	Tags []string
+
+	// Query is a struct whose fields with `form` tag are query parameters.
+	Query interface{}
+
+	// Request is the body, Response is the body when succeed, nil if no body.
+	Request, Response interface{}
+
+	// Public route needs no authentication.
+	Public bool
+}
+
+// Code is a business code may be in ErrorResponse.
+type Code struct {
+	Code    int
+	HTTP    int
+	Message string
+}
+
+// Spec builds Document from routes of gin engine.
+type Spec struct {
+	Title, Description, Version string
+
+	// Routes is keyed by method and path in gin, like "GET /v1/users/:name".
+	Routes map[string]Route
+
+	// Codes are listed in code of ErrorResponse.
+	Codes []Code
+
+	// SecuritySchemes are the ways to authenticate, any of them is accepted by routes not public.
+	SecuritySchemes map[string]*SecurityScheme
+
+	// Skip routes whose path has any of prefixes, like routes of pprof.
+	Skip []string
+}
+
+// Build generates document from routes, routes not described by Spec have only path parameters and errors.
+func (s *Spec) Build(routes gin.RoutesInfo) *Document {
+	reflector := newSchemas()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: s.Title, Description: s.Description, Version: s.Version},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Responses:       map[string]*Response{"Error": s.errorResponse(reflector)},
+			SecuritySchemes: s.SecuritySchemes,
+		},
+	}
+
+	var security []map[string][]string
+	for name := range s.SecuritySchemes {
+		security = append(security, map[string][]string{name: {}})
+	}
+	sort.Slice(security, func(i, j int) bool {
+		return fmt.Sprint(security[i]) < fmt.Sprint(security[j])
+	})
+	doc.Security = security
+
+	for _, r := range routes {
+		if s.skipped(r.Path) {
+			continue
+		}
+		path, params := convertPath(r.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(r.Method)] = s.operation(reflector, r, params)
+	}
+
+	doc.Components.Schemas = reflector.components
+	return doc
+}
+
+func (s *Spec) skipped(path string) bool {
+	for _, prefix := range s.Skip {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Spec) operation(reflector *schemas, r gin.RouteInfo, params []*Parameter) *Operation {
+	route := s.Routes[r.Method+" "+r.Path]
+
+	op := &Operation{
+		Summary:    route.Summary,
+		Tags:       route.Tags,
+		Parameters: append(params, queryParameters(reflector, route.Query)...),
+		Responses: map[string]*Response{
+			"200":     {Description: "OK"},
+			"default": {Ref: "#/components/responses/Error"},
+		},
+	}
+	if t := tag(r.Path); op.Tags == nil && t != "" {
+		op.Tags = []string{t}
+	}
+	if route.Public {
+		// Empty requirement overrides the one of document, allows anonymous.
+		op.Security = []map[string][]string{{}}
+	}
+
+	if schema := reflector.of(route.Request); schema != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{gin.MIMEJSON: {Schema: schema}},
+		}
+	}
+	if schema := reflector.of(route.Response); schema != nil {
+		op.Responses["200"].Content = map[string]*MediaType{gin.MIMEJSON: {Schema: schema}}
+	}
+	return op
+}
+
+// errorResponse is the body of web.WriteResponse when error occurs, with codes listed.
+func (s *Spec) errorResponse(reflector *schemas) *Response {
+	schema := reflector.of(web.ErrorResponse{})
+	errorResponse := reflector.components[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+
+	codes := append([]Code{}, s.Codes...)
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+
+	code := errorResponse.Properties["code"]
+	lines := []string{"Business error code:"}
+	for _, c := range codes {
+		code.Enum = append(code.Enum, c.Code)
+		lines = append(lines, fmt.Sprintf("- %d (%d): %s", c.Code, c.HTTP, c.Message))
+	}
+	code.Description = strings.Join(lines, "\n")
+
+	return &Response{
+		Description: "Error, http status follows code.",
+		Content:     map[string]*MediaType{gin.MIMEJSON: {Schema: schema}},
+	}
+}
+
+// convertPath converts gin path like /users/:name to /users/{name}, and returns its parameters.
+func convertPath(path string) (string, []*Parameter) {
+	var params []*Parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// queryParameters returns parameters of fields with `form` tag in struct query.
+func queryParameters(reflector *schemas, query interface{}) []*Parameter {
+	if query == nil {
+		return nil
+	}
+	t := reflect.TypeOf(query)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var params []*Parameter
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				walk(f.Type)
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("form"), ",")
+			if name == "" || name == "-" || !f.IsExported() {
+				continue
+			}
+			params = append(params, &Parameter{
+				Name:     name,
+				In:       "query",
+				Required: required(f),
+				Schema:   reflector.schema(f.Type),
+			})
+		}
+	}
+	walk(t)
+	return params
+}
+
+// tag returns the first segment of path after version, like users of /v1/users/:name.
+func tag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 1 && len(segments[0]) > 1 && segments[0][0] == 'v' && segments[0][1] >= '0' && segments[0][1] <= '9' {
+		return segments[1]
+	}
+	return segments[0]
+}
+
+// Handler serves document built from routes of engine, it's built once on first request,
+// when all routes have been added.
+func Handler(spec *Spec, engine *gin.Engine) gin.HandlerFunc {
+	var doc *Document
+	var once sync.Once
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc = spec.Build(engine.Routes())
+		})
+		c.JSON(http.StatusOK, doc)
+	}
+}
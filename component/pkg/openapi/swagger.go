@@ -0,0 +1,24 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"net/http"
+)
+
+// swaggerUI is the page of Swagger UI, scripts and styles are loaded from unpkg by browser.
+//
+//go:embed swagger/index.html
+var swaggerUI string
+
+// SwaggerUI serves Swagger UI which shows document at url.
+func SwaggerUI(url string) gin.HandlerFunc {
+	var page bytes.Buffer
+	_ = template.Must(template.New("swagger").Parse(swaggerUI)).Execute(&page, map[string]string{"URL": url})
+
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+	}
+}
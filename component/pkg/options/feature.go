@@ -8,12 +8,17 @@ type FeatureOptions struct {
 
 	// https://pkg.go.dev/github.com/gin-contrib/pprof
 	Profile bool `json:"profile" mapstructure:"profile"`
+
+	// SwaggerUI serves Swagger UI of /openapi.json at /swagger.
+	SwaggerUI bool `json:"swagger-ui" mapstructure:"swagger-ui"`
 }
 
 func NewFeatureOptions() *FeatureOptions {
 	return &FeatureOptions{
 		Metrics: true,
 		Profile: true,
+		// Off by default, page loads scripts from public CDN.
+		SwaggerUI: false,
 	}
 }
 
@@ -28,4 +33,7 @@ func (o *FeatureOptions) AddFlags(fs *pflag.FlagSet) {
 
 	fs.BoolVar(&o.Profile, "server.profile", o.Profile, ""+
 		"Enable Profile, see more: https://pkg.go.dev/github.com/gin-contrib/pprof .")
+
+	fs.BoolVar(&o.SwaggerUI, "server.swagger-ui", o.SwaggerUI, ""+
+		"Enable Swagger UI of OpenAPI document at /swagger.")
 }
@@ -0,0 +1,78 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+)
+
+// FederationOpts provides config for logging in with an upstream OpenID Connect provider, such as corporate SSO.
+type FederationOpts struct {
+	// Issuer of upstream provider, federated login is disabled if empty.
+	Issuer string `json:"issuer" mapstructure:"issuer"`
+
+	ClientID     string `json:"client-id" mapstructure:"client-id"`
+	ClientSecret string `json:"client-secret" mapstructure:"client-secret"`
+
+	// RedirectURL must be registered in upstream provider, it's path is /login/federated/callback.
+	RedirectURL string `json:"redirect-url" mapstructure:"redirect-url"`
+
+	Scopes []string `json:"scopes" mapstructure:"scopes"`
+
+	// AutoProvision creates user at first login, otherwise an admin must create the user
+	// and set its identityProvider to Issuer first.
+	AutoProvision bool `json:"auto-provision" mapstructure:"auto-provision"`
+
+	UsernameClaim string `json:"username-claim" mapstructure:"username-claim"`
+	EmailClaim    string `json:"email-claim" mapstructure:"email-claim"`
+	GroupsClaim   string `json:"groups-claim" mapstructure:"groups-claim"`
+}
+
+func NewFederationOpts() *FederationOpts {
+	return &FederationOpts{
+		Issuer:        "",
+		Scopes:        []string{"profile", "email"},
+		AutoProvision: true,
+		UsernameClaim: "preferred_username",
+		EmailClaim:    "email",
+		GroupsClaim:   "groups",
+	}
+}
+
+// Enabled tells whether upstream provider is configured.
+func (o *FederationOpts) Enabled() bool {
+	return o.Issuer != ""
+}
+
+func (o *FederationOpts) Validate() []error {
+	var err []error
+
+	if !o.Enabled() {
+		return err
+	}
+	if o.ClientID == "" {
+		err = append(err, fmt.Errorf("--federation.client-id must not be empty when --federation.issuer is set"))
+	}
+	if o.RedirectURL == "" {
+		err = append(err, fmt.Errorf("--federation.redirect-url must not be empty when --federation.issuer is set"))
+	}
+	if o.UsernameClaim == "" {
+		err = append(err, fmt.Errorf("--federation.username-claim must not be empty"))
+	}
+
+	return err
+}
+
+func (o *FederationOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Issuer, "federation.issuer", o.Issuer, ""+
+		"Issuer of upstream OpenID Connect provider, users can login with it at /login/federated. Empty to disable.")
+	fs.StringVar(&o.ClientID, "federation.client-id", o.ClientID, "Client id registered in upstream provider.")
+	fs.StringVar(&o.ClientSecret, "federation.client-secret", o.ClientSecret, "Client secret registered in upstream provider.")
+	fs.StringVar(&o.RedirectURL, "federation.redirect-url", o.RedirectURL, ""+
+		"Url of /login/federated/callback, it must be registered in upstream provider.")
+	fs.StringSliceVar(&o.Scopes, "federation.scopes", o.Scopes, "Scopes requested besides openid.")
+	fs.BoolVar(&o.AutoProvision, "federation.auto-provision", o.AutoProvision, ""+
+		"Create user at first federated login, otherwise user must be created by admin with identityProvider first.")
+	fs.StringVar(&o.UsernameClaim, "federation.username-claim", o.UsernameClaim, "Claim of id token mapped to username.")
+	fs.StringVar(&o.EmailClaim, "federation.email-claim", o.EmailClaim, "Claim of id token mapped to email, empty to ignore.")
+	fs.StringVar(&o.GroupsClaim, "federation.groups-claim", o.GroupsClaim, "Claim of id token mapped to groups, empty to ignore.")
+}
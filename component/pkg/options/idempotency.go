@@ -0,0 +1,34 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// IdempotencyOpts provides config for requests with Idempotency-Key header.
+type IdempotencyOpts struct {
+	// TTL is how long a response is kept for retries with the same key.
+	TTL time.Duration `json:"ttl" mapstructure:"ttl"`
+}
+
+func NewIdempotencyOpts() *IdempotencyOpts {
+	return &IdempotencyOpts{
+		TTL: 24 * time.Hour,
+	}
+}
+
+func (o *IdempotencyOpts) Validate() []error {
+	var err []error
+
+	if o.TTL <= 0 {
+		err = append(err, fmt.Errorf("--idempotency.ttl must be greater than 0, got: %s", o.TTL))
+	}
+
+	return err
+}
+
+func (o *IdempotencyOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.TTL, "idempotency.ttl", o.TTL, ""+
+		"How long a response of request with Idempotency-Key header is kept for retries.")
+}
@@ -13,6 +13,13 @@ type JwtOpts struct {
 	Key        string        `json:"key" mapstructure:"key"`
 	Timeout    time.Duration `json:"timeout" mapstructure:"timeout"`
 	MaxRefresh time.Duration `json:"max_refresh" mapstructure:"max_refresh"`
+
+	// SigningAlgorithm is one of HS256, RS256, ES256 and EdDSA, HS256 uses Key and others use keys in KeyDir.
+	SigningAlgorithm string `json:"signing_algorithm" mapstructure:"signing_algorithm"`
+	// KeyDir saves private keys named <kid>.pem, the newest one signs tokens.
+	KeyDir string `json:"key_dir" mapstructure:"key_dir"`
+	// RotationInterval generates a new key in KeyDir periodically, zero disables it.
+	RotationInterval time.Duration `json:"rotation_interval" mapstructure:"rotation_interval"`
 }
 
 func NewJwtOpts() *JwtOpts {
@@ -21,14 +28,30 @@ func NewJwtOpts() *JwtOpts {
 		Key:        "",
 		Timeout:    1 * time.Hour,
 		MaxRefresh: 1 * time.Hour,
+
+		SigningAlgorithm: "HS256",
+		KeyDir:           "",
+		RotationInterval: 0,
 	}
 }
 
 func (o *JwtOpts) Validate() []error {
 	var err []error
 
-	if !govalidator.StringLength(o.Key, "6", "32") {
-		err = append(err, fmt.Errorf("--secret-key must larger than 5 and little than 33"))
+	switch o.SigningAlgorithm {
+	case "HS256":
+		if !govalidator.StringLength(o.Key, "6", "32") {
+			err = append(err, fmt.Errorf("--secret-key must larger than 5 and little than 33"))
+		}
+	case "RS256", "ES256", "EdDSA":
+		if o.KeyDir == "" {
+			err = append(err, fmt.Errorf("--jwt.key-dir must be set when --jwt.signing-algorithm is %s", o.SigningAlgorithm))
+		}
+	default:
+		err = append(err, fmt.Errorf("--jwt.signing-algorithm must be one of HS256, RS256, ES256 and EdDSA, got: %s", o.SigningAlgorithm))
+	}
+	if o.RotationInterval < 0 {
+		err = append(err, fmt.Errorf("--jwt.rotation-interval must not be negative, got: %s", o.RotationInterval))
 	}
 
 	return err
@@ -40,4 +63,13 @@ func (o *JwtOpts) AddFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&o.Timeout, "jwt.timeout", o.Timeout, "JWT token timeout.")
 	fs.DurationVar(&o.MaxRefresh, "jwt.max-refresh", o.MaxRefresh, ""+
 		"This field allows clients to refresh their token until MaxRefresh has passed.")
+	fs.StringVar(&o.SigningAlgorithm, "jwt.signing-algorithm", o.SigningAlgorithm, ""+
+		"Algorithm to sign jwt token, one of HS256, RS256, ES256 and EdDSA. "+
+		"HS256 uses --jwt.key, others use keys in --jwt.key-dir and publish them at /.well-known/jwks.json.")
+	fs.StringVar(&o.KeyDir, "jwt.key-dir", o.KeyDir, ""+
+		"Directory of private keys named <kid>.pem, the newest one signs tokens and all of them verify tokens. "+
+		"A key is generated if directory is empty.")
+	fs.DurationVar(&o.RotationInterval, "jwt.rotation-interval", o.RotationInterval, ""+
+		"Generate a new key in --jwt.key-dir periodically, previous keys are removed after tokens signed by them expire. "+
+		"Set to zero to disable.")
 }
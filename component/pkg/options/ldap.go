@@ -0,0 +1,136 @@
+package options
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/spf13/pflag"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// LDAPOpts provides config for auth.LDAPAuthenticator.
+type LDAPOpts struct {
+	// URL of directory, LDAP authentication is disabled if empty.
+	URL string `json:"url" mapstructure:"url"`
+
+	StartTLS           bool   `json:"start-tls"            mapstructure:"start-tls"`
+	CAFile             string `json:"ca-file"              mapstructure:"ca-file"`
+	InsecureSkipVerify bool   `json:"insecure-skip-verify" mapstructure:"insecure-skip-verify"`
+
+	BindDN       string `json:"bind-dn"       mapstructure:"bind-dn"`
+	BindPassword string `json:"bind-password" mapstructure:"bind-password"`
+
+	BaseDN     string `json:"base-dn"     mapstructure:"base-dn"`
+	UserFilter string `json:"user-filter" mapstructure:"user-filter"`
+
+	UsernameAttribute string `json:"username-attribute" mapstructure:"username-attribute"`
+	EmailAttribute    string `json:"email-attribute"    mapstructure:"email-attribute"`
+	GroupAttribute    string `json:"group-attribute"    mapstructure:"group-attribute"`
+
+	// GroupMapping maps DN or CN of directory group to IAM group.
+	GroupMapping map[string]string `json:"group-mapping" mapstructure:"group-mapping"`
+
+	Timeout time.Duration `json:"timeout" mapstructure:"timeout"`
+}
+
+func NewLDAPOpts() *LDAPOpts {
+	return &LDAPOpts{
+		URL:               "",
+		UserFilter:        "(uid=%s)",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		GroupAttribute:    "memberOf",
+		GroupMapping:      map[string]string{},
+		Timeout:           10 * time.Second,
+	}
+}
+
+// Enabled tells whether directory is configured.
+func (o *LDAPOpts) Enabled() bool {
+	return o.URL != ""
+}
+
+func (o *LDAPOpts) Validate() []error {
+	var err []error
+
+	if !o.Enabled() {
+		return err
+	}
+	if !strings.HasPrefix(o.URL, "ldap://") && !strings.HasPrefix(o.URL, "ldaps://") {
+		err = append(err, fmt.Errorf("--ldap.url must start with ldap:// or ldaps://, got: %s", o.URL))
+	}
+	if o.StartTLS && strings.HasPrefix(o.URL, "ldaps://") {
+		err = append(err, fmt.Errorf("--ldap.start-tls can not be used with ldaps://"))
+	}
+	if o.BaseDN == "" {
+		err = append(err, fmt.Errorf("--ldap.base-dn must not be empty when --ldap.url is set"))
+	}
+	if strings.Count(o.UserFilter, "%s") != 1 {
+		err = append(err, fmt.Errorf("--ldap.user-filter must contain exactly one %%s, got: %s", o.UserFilter))
+	}
+	if o.Timeout <= 0 {
+		err = append(err, fmt.Errorf("--ldap.timeout must greater than 0, got: %s", o.Timeout))
+	}
+
+	return err
+}
+
+func (o *LDAPOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.URL, "ldap.url", o.URL, ""+
+		"Url of LDAP server such as ldaps://ldap.example.com:636, users not found locally are authenticated by it. Empty to disable.")
+	fs.BoolVar(&o.StartTLS, "ldap.start-tls", o.StartTLS, "Upgrade ldap:// connection to TLS with StartTLS.")
+	fs.StringVar(&o.CAFile, "ldap.ca-file", o.CAFile, "CA certificate to verify LDAP server, system roots are used if empty.")
+	fs.BoolVar(&o.InsecureSkipVerify, "ldap.insecure-skip-verify", o.InsecureSkipVerify, ""+
+		"Skip verifying certificate of LDAP server, only for test.")
+	fs.StringVar(&o.BindDN, "ldap.bind-dn", o.BindDN, "DN of service account to search users, anonymous if empty.")
+	fs.StringVar(&o.BindPassword, "ldap.bind-password", o.BindPassword, "Password of service account.")
+	fs.StringVar(&o.BaseDN, "ldap.base-dn", o.BaseDN, "Base DN to search users.")
+	fs.StringVar(&o.UserFilter, "ldap.user-filter", o.UserFilter, "Filter to search user, %s is replaced by username.")
+	fs.StringVar(&o.UsernameAttribute, "ldap.username-attribute", o.UsernameAttribute, "Attribute mapped to username.")
+	fs.StringVar(&o.EmailAttribute, "ldap.email-attribute", o.EmailAttribute, "Attribute mapped to email, empty to ignore.")
+	fs.StringVar(&o.GroupAttribute, "ldap.group-attribute", o.GroupAttribute, ""+
+		"Attribute of user listing DN of its groups, empty to ignore.")
+	fs.StringToStringVar(&o.GroupMapping, "ldap.group-mapping", o.GroupMapping, ""+
+		"Map DN or CN of LDAP group to IAM group, such as developers=dev. Only mapped groups are kept if set, otherwise CN is used.")
+	fs.DurationVar(&o.Timeout, "ldap.timeout", o.Timeout, "Timeout of each LDAP request.")
+}
+
+// Config returns config of auth.LDAPAuthenticator, CA file is loaded.
+func (o *LDAPOpts) Config() (auth.LDAPConfig, error) {
+	cfg := auth.LDAPConfig{
+		URL:               o.URL,
+		StartTLS:          o.StartTLS,
+		BindDN:            o.BindDN,
+		BindPassword:      o.BindPassword,
+		BaseDN:            o.BaseDN,
+		UserFilter:        o.UserFilter,
+		UsernameAttribute: o.UsernameAttribute,
+		EmailAttribute:    o.EmailAttribute,
+		GroupAttribute:    o.GroupAttribute,
+		GroupMapping:      o.GroupMapping,
+		Timeout:           o.Timeout,
+		TLSConfig:         &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify},
+	}
+	// StartTLS needs server name to verify certificate.
+	if u, err := url.Parse(o.URL); err == nil {
+		cfg.TLSConfig.ServerName = u.Hostname()
+	}
+
+	if o.CAFile == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(o.CAFile)
+	if err != nil {
+		return cfg, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return cfg, fmt.Errorf("no certificate found in %s", o.CAFile)
+	}
+	cfg.TLSConfig.RootCAs = pool
+	return cfg, nil
+}
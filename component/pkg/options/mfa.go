@@ -0,0 +1,56 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// MFAOpts provides config for TOTP multi-factor authentication.
+type MFAOpts struct {
+	// Required forces all users to enrol MFA, users without MFA can only use enrolment api after login.
+	Required bool `json:"required" mapstructure:"required"`
+
+	// Issuer is shown in authenticator app.
+	Issuer string `json:"issuer" mapstructure:"issuer"`
+
+	// ChallengeTTL is how long the challenge token issued after password check can be used.
+	ChallengeTTL time.Duration `json:"challenge-ttl" mapstructure:"challenge-ttl"`
+
+	// RecoveryCodes is the count of recovery codes generated when enrolling.
+	RecoveryCodes int `json:"recovery-codes" mapstructure:"recovery-codes"`
+}
+
+func NewMFAOpts() *MFAOpts {
+	return &MFAOpts{
+		Required:      false,
+		Issuer:        "iam",
+		ChallengeTTL:  5 * time.Minute,
+		RecoveryCodes: 10,
+	}
+}
+
+func (o *MFAOpts) Validate() []error {
+	var err []error
+
+	if o.Issuer == "" {
+		err = append(err, fmt.Errorf("--mfa.issuer must not be empty"))
+	}
+	if o.ChallengeTTL <= 0 {
+		err = append(err, fmt.Errorf("--mfa.challenge-ttl must greater than 0, got: %s", o.ChallengeTTL))
+	}
+	if o.RecoveryCodes < 0 {
+		err = append(err, fmt.Errorf("--mfa.recovery-codes must not be negative, got: %d", o.RecoveryCodes))
+	}
+
+	return err
+}
+
+func (o *MFAOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Required, "mfa.required", o.Required, ""+
+		"Require all users to enrol MFA, user can also be required one by one with field mfaRequired.")
+	fs.StringVar(&o.Issuer, "mfa.issuer", o.Issuer, "Issuer name shown in authenticator app.")
+	fs.DurationVar(&o.ChallengeTTL, "mfa.challenge-ttl", o.ChallengeTTL, ""+
+		"How long the challenge token issued after password check can be exchanged at /login/mfa.")
+	fs.IntVar(&o.RecoveryCodes, "mfa.recovery-codes", o.RecoveryCodes, "Count of recovery codes generated when enrolling.")
+}
@@ -0,0 +1,73 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+)
+
+const (
+	NotifierLog  = "log"
+	NotifierFile = "file"
+	NotifierSMTP = "smtp"
+)
+
+// NotifierOpts provides config to send message to user, such as password reset token.
+type NotifierOpts struct {
+	// Type is one of log, file and smtp, log and file are used in development.
+	Type string `json:"type" mapstructure:"type"`
+
+	// File is the path where message appended to when Type is file.
+	File string `json:"file" mapstructure:"file"`
+
+	SMTPHost     string `json:"smtp-host" mapstructure:"smtp-host"`
+	SMTPPort     int    `json:"smtp-port" mapstructure:"smtp-port"`
+	SMTPUsername string `json:"smtp-username" mapstructure:"smtp-username"`
+	SMTPPassword string `json:"smtp-password" mapstructure:"smtp-password"`
+	SMTPFrom     string `json:"smtp-from" mapstructure:"smtp-from"`
+}
+
+func NewNotifierOpts() *NotifierOpts {
+	return &NotifierOpts{
+		Type:         NotifierLog,
+		File:         "",
+		SMTPHost:     "",
+		SMTPPort:     587,
+		SMTPUsername: "",
+		SMTPPassword: "",
+		SMTPFrom:     "",
+	}
+}
+
+func (o *NotifierOpts) Validate() []error {
+	var err []error
+
+	switch o.Type {
+	case NotifierLog:
+	case NotifierFile:
+		if o.File == "" {
+			err = append(err, fmt.Errorf("--notifier.file must be set when --notifier.type is file"))
+		}
+	case NotifierSMTP:
+		if o.SMTPHost == "" || o.SMTPFrom == "" {
+			err = append(err, fmt.Errorf("--notifier.smtp-host and --notifier.smtp-from must be set when --notifier.type is smtp"))
+		}
+		if !(o.SMTPPort > 0 && o.SMTPPort <= 65535) {
+			err = append(err, fmt.Errorf("--notifier.smtp-port %d must between 1 to 65535", o.SMTPPort))
+		}
+	default:
+		err = append(err, fmt.Errorf("--notifier.type must be one of log, file and smtp, got: %s", o.Type))
+	}
+
+	return err
+}
+
+func (o *NotifierOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Type, "notifier.type", o.Type, ""+
+		"How to send message to user, one of log, file and smtp. log and file are only for development.")
+	fs.StringVar(&o.File, "notifier.file", o.File, "File which message appended to when --notifier.type is file.")
+	fs.StringVar(&o.SMTPHost, "notifier.smtp-host", o.SMTPHost, "Hostname of SMTP server.")
+	fs.IntVar(&o.SMTPPort, "notifier.smtp-port", o.SMTPPort, "Port of SMTP server.")
+	fs.StringVar(&o.SMTPUsername, "notifier.smtp-username", o.SMTPUsername, "Username for SMTP auth, empty means no auth.")
+	fs.StringVar(&o.SMTPPassword, "notifier.smtp-password", o.SMTPPassword, "Password for SMTP auth.")
+	fs.StringVar(&o.SMTPFrom, "notifier.smtp-from", o.SMTPFrom, "Sender address of mail.")
+}
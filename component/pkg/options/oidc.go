@@ -0,0 +1,48 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"net/url"
+	"time"
+)
+
+// OIDCOpts provides config for OpenID Connect provider.
+type OIDCOpts struct {
+	// Issuer is the external url of apiserver, which is iss of id token and prefix of endpoints in discovery.
+	Issuer string `json:"issuer" mapstructure:"issuer"`
+
+	CodeTTL         time.Duration `json:"code-ttl"          mapstructure:"code-ttl"`
+	IDTokenTTL      time.Duration `json:"id-token-ttl"      mapstructure:"id-token-ttl"`
+	RefreshTokenTTL time.Duration `json:"refresh-token-ttl" mapstructure:"refresh-token-ttl"`
+}
+
+func NewOIDCOpts() *OIDCOpts {
+	return &OIDCOpts{
+		Issuer:          "http://127.0.0.1:8080",
+		CodeTTL:         time.Minute,
+		IDTokenTTL:      time.Hour,
+		RefreshTokenTTL: 30 * 24 * time.Hour,
+	}
+}
+
+func (o *OIDCOpts) Validate() []error {
+	var err []error
+
+	if u, e := url.Parse(o.Issuer); e != nil || !u.IsAbs() || u.RawQuery != "" || u.Fragment != "" {
+		err = append(err, fmt.Errorf("--oidc.issuer must be an absolute url without query and fragment, got: %s", o.Issuer))
+	}
+	if o.CodeTTL <= 0 || o.IDTokenTTL <= 0 || o.RefreshTokenTTL <= 0 {
+		err = append(err, fmt.Errorf("--oidc.code-ttl, --oidc.id-token-ttl and --oidc.refresh-token-ttl must greater than 0"))
+	}
+
+	return err
+}
+
+func (o *OIDCOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Issuer, "oidc.issuer", o.Issuer, ""+
+		"External url of apiserver, used as iss of id token and prefix of endpoints in /.well-known/openid-configuration.")
+	fs.DurationVar(&o.CodeTTL, "oidc.code-ttl", o.CodeTTL, "How long an authorization code can be exchanged.")
+	fs.DurationVar(&o.IDTokenTTL, "oidc.id-token-ttl", o.IDTokenTTL, "How long an id token is valid.")
+	fs.DurationVar(&o.RefreshTokenTTL, "oidc.refresh-token-ttl", o.RefreshTokenTTL, "How long a refresh token can be used.")
+}
@@ -0,0 +1,123 @@
+package options
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/pflag"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"os"
+	"strings"
+	"time"
+)
+
+// PasswordPolicyOpts provides config for validator.PasswordPolicy.
+type PasswordPolicyOpts struct {
+	MinLength int `json:"min-length" mapstructure:"min-length"`
+	MaxLength int `json:"max-length" mapstructure:"max-length"`
+
+	RequireUpper  bool `json:"require-upper"  mapstructure:"require-upper"`
+	RequireLower  bool `json:"require-lower"  mapstructure:"require-lower"`
+	RequireNumber bool `json:"require-number" mapstructure:"require-number"`
+	RequireSymbol bool `json:"require-symbol" mapstructure:"require-symbol"`
+
+	// BlocklistFile contains one common password per line, which is appended to built-in blocklist.
+	BlocklistFile string `json:"blocklist-file" mapstructure:"blocklist-file"`
+
+	HistoryCount int           `json:"history-count" mapstructure:"history-count"`
+	MaxAge       time.Duration `json:"max-age"       mapstructure:"max-age"`
+
+	// ResetTokenTTL is how long a password reset token can be used.
+	ResetTokenTTL time.Duration `json:"reset-token-ttl" mapstructure:"reset-token-ttl"`
+}
+
+func NewPasswordPolicyOpts() *PasswordPolicyOpts {
+	d := validator.DefaultPasswordPolicy()
+	return &PasswordPolicyOpts{
+		MinLength:     d.MinLength,
+		MaxLength:     d.MaxLength,
+		RequireUpper:  d.RequireUpper,
+		RequireLower:  d.RequireLower,
+		RequireNumber: d.RequireNumber,
+		RequireSymbol: d.RequireSymbol,
+		BlocklistFile: "",
+		HistoryCount:  0,
+		MaxAge:        0,
+		ResetTokenTTL: 15 * time.Minute,
+	}
+}
+
+func (o *PasswordPolicyOpts) Validate() []error {
+	var err []error
+
+	if o.MinLength < 1 {
+		err = append(err, fmt.Errorf("--password.min-length must greater than 0, got: %d", o.MinLength))
+	}
+	if o.MaxLength != 0 && o.MaxLength < o.MinLength {
+		err = append(err, fmt.Errorf("--password.max-length %d must not less than min-length %d", o.MaxLength, o.MinLength))
+	}
+	if o.HistoryCount < 0 {
+		err = append(err, fmt.Errorf("--password.history-count must not be negative, got: %d", o.HistoryCount))
+	}
+	if o.MaxAge < 0 {
+		err = append(err, fmt.Errorf("--password.max-age must not be negative, got: %s", o.MaxAge))
+	}
+	if o.ResetTokenTTL <= 0 {
+		err = append(err, fmt.Errorf("--password.reset-token-ttl must greater than 0, got: %s", o.ResetTokenTTL))
+	}
+	if o.BlocklistFile != "" {
+		if _, e := os.Stat(o.BlocklistFile); e != nil {
+			err = append(err, fmt.Errorf("--password.blocklist-file is not readable: %s", e.Error()))
+		}
+	}
+
+	return err
+}
+
+func (o *PasswordPolicyOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.MinLength, "password.min-length", o.MinLength, "Minimum length of password.")
+	fs.IntVar(&o.MaxLength, "password.max-length", o.MaxLength, "Maximum length of password, 0 means no limit.")
+	fs.BoolVar(&o.RequireUpper, "password.require-upper", o.RequireUpper, "Password must contain upper letter.")
+	fs.BoolVar(&o.RequireLower, "password.require-lower", o.RequireLower, "Password must contain lower letter.")
+	fs.BoolVar(&o.RequireNumber, "password.require-number", o.RequireNumber, "Password must contain number.")
+	fs.BoolVar(&o.RequireSymbol, "password.require-symbol", o.RequireSymbol, "Password must contain punctuation or symbol.")
+	fs.StringVar(&o.BlocklistFile, "password.blocklist-file", o.BlocklistFile, ""+
+		"File containing common passwords one per line, which can not be used as password.")
+	fs.IntVar(&o.HistoryCount, "password.history-count", o.HistoryCount, ""+
+		"Forbid reusing the last N passwords, set to zero to disable.")
+	fs.DurationVar(&o.MaxAge, "password.max-age", o.MaxAge, ""+
+		"Password must be changed after this duration, set to zero to disable.")
+	fs.DurationVar(&o.ResetTokenTTL, "password.reset-token-ttl", o.ResetTokenTTL, ""+
+		"How long a password reset token can be used.")
+}
+
+// Policy builds validator.PasswordPolicy, reading blocklist file if set.
+func (o *PasswordPolicyOpts) Policy() (*validator.PasswordPolicy, error) {
+	p := validator.DefaultPasswordPolicy()
+	p.MinLength = o.MinLength
+	p.MaxLength = o.MaxLength
+	p.RequireUpper = o.RequireUpper
+	p.RequireLower = o.RequireLower
+	p.RequireNumber = o.RequireNumber
+	p.RequireSymbol = o.RequireSymbol
+	p.HistoryCount = o.HistoryCount
+	p.MaxAge = o.MaxAge
+
+	if o.BlocklistFile == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(o.BlocklistFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			p.Blocklist = append(p.Blocklist, line)
+		}
+	}
+
+	return p, scanner.Err()
+}
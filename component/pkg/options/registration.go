@@ -0,0 +1,68 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// Modes of self-registration at POST /v1/users.
+const (
+	RegistrationOpen       = "open"
+	RegistrationDisabled   = "disabled"
+	RegistrationInviteOnly = "invite-only"
+)
+
+// RegistrationOpts provides config for self-registration.
+type RegistrationOpts struct {
+	// Mode is one of open, disabled and invite-only, which requires an invitation code issued by admin.
+	Mode string `json:"mode" mapstructure:"mode"`
+
+	// InvitationTTL is used when admin doesn't give expiry of invitation.
+	InvitationTTL time.Duration `json:"invitation-ttl" mapstructure:"invitation-ttl"`
+
+	// RateLimit is the max signups from one client ip in RateWindow, 0 means no limit.
+	RateLimit  int           `json:"rate-limit" mapstructure:"rate-limit"`
+	RateWindow time.Duration `json:"rate-window" mapstructure:"rate-window"`
+}
+
+func NewRegistrationOpts() *RegistrationOpts {
+	return &RegistrationOpts{
+		Mode:          RegistrationOpen,
+		InvitationTTL: 7 * 24 * time.Hour,
+		RateLimit:     10,
+		RateWindow:    time.Hour,
+	}
+}
+
+func (o *RegistrationOpts) Validate() []error {
+	var err []error
+
+	switch o.Mode {
+	case RegistrationOpen, RegistrationDisabled, RegistrationInviteOnly:
+	default:
+		err = append(err, fmt.Errorf("--registration.mode must be one of %s, %s and %s, got: %s",
+			RegistrationOpen, RegistrationDisabled, RegistrationInviteOnly, o.Mode))
+	}
+	if o.InvitationTTL <= 0 {
+		err = append(err, fmt.Errorf("--registration.invitation-ttl must be greater than 0, got: %s", o.InvitationTTL))
+	}
+	if o.RateLimit < 0 {
+		err = append(err, fmt.Errorf("--registration.rate-limit must not be negative, got: %d", o.RateLimit))
+	}
+	if o.RateLimit > 0 && o.RateWindow <= 0 {
+		err = append(err, fmt.Errorf("--registration.rate-window must be greater than 0, got: %s", o.RateWindow))
+	}
+
+	return err
+}
+
+func (o *RegistrationOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Mode, "registration.mode", o.Mode, ""+
+		"Mode of self-registration, one of open, disabled and invite-only which requires invitation code issued by admin.")
+	fs.DurationVar(&o.InvitationTTL, "registration.invitation-ttl", o.InvitationTTL, ""+
+		"Lifetime of invitation if admin doesn't give one.")
+	fs.IntVar(&o.RateLimit, "registration.rate-limit", o.RateLimit, ""+
+		"Max signups from one client ip in --registration.rate-window, 0 means no limit.")
+	fs.DurationVar(&o.RateWindow, "registration.rate-window", o.RateWindow, "Window of --registration.rate-limit.")
+}
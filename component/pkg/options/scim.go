@@ -0,0 +1,49 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+)
+
+// SCIMOpts provides config for SCIM 2.0 provisioning endpoints, used by identity governance tools.
+type SCIMOpts struct {
+	// Token is the bearer token of provisioning client, endpoints are disabled if empty.
+	Token string `json:"token" mapstructure:"token"`
+
+	// MaxResults limits resources returned in one page.
+	MaxResults int `json:"max-results" mapstructure:"max-results"`
+}
+
+func NewSCIMOpts() *SCIMOpts {
+	return &SCIMOpts{
+		Token:      "",
+		MaxResults: 200,
+	}
+}
+
+// Enabled tells whether SCIM endpoints are served.
+func (o *SCIMOpts) Enabled() bool {
+	return o.Token != ""
+}
+
+func (o *SCIMOpts) Validate() []error {
+	var err []error
+
+	if !o.Enabled() {
+		return err
+	}
+	if len(o.Token) < 32 {
+		err = append(err, fmt.Errorf("--scim.token must be at least 32 characters"))
+	}
+	if o.MaxResults <= 0 {
+		err = append(err, fmt.Errorf("--scim.max-results must be greater than 0, got: %d", o.MaxResults))
+	}
+
+	return err
+}
+
+func (o *SCIMOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Token, "scim.token", o.Token, ""+
+		"Bearer token of SCIM provisioning client, endpoints at /scim/v2 are served if set.")
+	fs.IntVar(&o.MaxResults, "scim.max-results", o.MaxResults, "Max resources returned in one page of SCIM list.")
+}
@@ -1,9 +1,12 @@
 package options
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"github.com/spf13/pflag"
 	"net"
+	"os"
 )
 
 type SecureServerOpts struct {
@@ -25,6 +28,10 @@ type TlsConfig struct {
 	CertDirectory string `json:"cert-directory"    mapstructure:"cert-directory"`
 	// PairName is filename of cert files. E.g. pairName.key and pairName.crt
 	PairName string `json:"pair-name"         mapstructure:"pair-name"`
+
+	// ClientCAFile is CA bundle to verify client certificates, which is optional for clients.
+	// Client certificates are not requested if empty.
+	ClientCAFile string `json:"client-ca-file" mapstructure:"client-ca-file"`
 }
 
 func NewSecureServerOpts() *SecureServerOpts {
@@ -80,4 +87,30 @@ func (o *SecureServerOpts) AddFlags(fs *pflag.FlagSet) {
 
 	fs.StringVar(&o.Tls.KeyFile, "secure.tls.key-file", o.Tls.KeyFile, "File containing the default x509 "+
 		"private key matching --secure.tls.cert-key.cert-file.")
+
+	fs.StringVar(&o.Tls.ClientCAFile, "secure.tls.client-ca-file", o.Tls.ClientCAFile, ""+
+		"CA bundle to verify client certificates, clients with verified certificate can authenticate "+
+		"without Authorization header. Client certificates are not requested if empty.")
+}
+
+// ClientTLSConfig returns tls config verifying client certificates if given, nil if ClientCAFile is empty.
+func (o *SecureServerOpts) ClientTLSConfig() (*tls.Config, error) {
+	if o.Tls.ClientCAFile == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(o.Tls.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in %s", o.Tls.ClientCAFile)
+	}
+
+	return &tls.Config{
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		ClientCAs:  pool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
 }
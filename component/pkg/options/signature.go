@@ -0,0 +1,34 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// SignatureOpts provides config for requests signed with secret key.
+type SignatureOpts struct {
+	// Skew is the max difference between time in signed request and server, it also limits how long a request can be replayed.
+	Skew time.Duration `json:"skew" mapstructure:"skew"`
+}
+
+func NewSignatureOpts() *SignatureOpts {
+	return &SignatureOpts{
+		Skew: 5 * time.Minute,
+	}
+}
+
+func (o *SignatureOpts) Validate() []error {
+	var err []error
+
+	if o.Skew <= 0 {
+		err = append(err, fmt.Errorf("--signature.skew must greater than 0, got: %s", o.Skew))
+	}
+
+	return err
+}
+
+func (o *SignatureOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.Skew, "signature.skew", o.Skew, ""+
+		"Max difference between time of signed request and server, requests out of it are rejected.")
+}
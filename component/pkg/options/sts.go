@@ -0,0 +1,57 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// minSTSDuration is the shortest lifetime of temporary credential.
+const minSTSDuration = 15 * time.Minute
+
+// STSOpts provides config for temporary credentials exchanged with long-lived secret.
+type STSOpts struct {
+	// DefaultDuration is used when request doesn't ask for one.
+	DefaultDuration time.Duration `json:"default-duration" mapstructure:"default-duration"`
+	// MaxDuration limits how long temporary credential can live.
+	MaxDuration time.Duration `json:"max-duration" mapstructure:"max-duration"`
+}
+
+func NewSTSOpts() *STSOpts {
+	return &STSOpts{
+		DefaultDuration: time.Hour,
+		MaxDuration:     12 * time.Hour,
+	}
+}
+
+func (o *STSOpts) Validate() []error {
+	var err []error
+
+	if o.MaxDuration < minSTSDuration {
+		err = append(err, fmt.Errorf("--sts.max-duration must not be less than %s, got: %s", minSTSDuration, o.MaxDuration))
+	}
+	if o.DefaultDuration < minSTSDuration || o.DefaultDuration > o.MaxDuration {
+		err = append(err, fmt.Errorf("--sts.default-duration must be between %s and --sts.max-duration, got: %s", minSTSDuration, o.DefaultDuration))
+	}
+
+	return err
+}
+
+func (o *STSOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.DefaultDuration, "sts.default-duration", o.DefaultDuration, ""+
+		"Lifetime of temporary credential if request doesn't specify one.")
+	fs.DurationVar(&o.MaxDuration, "sts.max-duration", o.MaxDuration, ""+
+		"Max lifetime of temporary credential.")
+}
+
+// Duration returns lifetime of temporary credential asked by request, 0 means default.
+func (o *STSOpts) Duration(seconds int64) (time.Duration, error) {
+	if seconds == 0 {
+		return o.DefaultDuration, nil
+	}
+	d := time.Duration(seconds) * time.Second
+	if d < minSTSDuration || d > o.MaxDuration {
+		return 0, fmt.Errorf("durationSeconds must be between %d and %d", int64(minSTSDuration.Seconds()), int64(o.MaxDuration.Seconds()))
+	}
+	return d, nil
+}
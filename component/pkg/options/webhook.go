@@ -0,0 +1,76 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// WebhookOpts provides config for delivering events to webhooks.
+type WebhookOpts struct {
+	// Interval is how often due deliveries are polled.
+	Interval time.Duration `json:"interval" mapstructure:"interval"`
+
+	// Timeout of one attempt, webhook must respond 2xx in time.
+	Timeout time.Duration `json:"timeout" mapstructure:"timeout"`
+
+	// MaxAttempts of one delivery, the n-th retry waits Backoff * 2^(n-1), but no longer than MaxBackoff.
+	MaxAttempts int           `json:"max-attempts" mapstructure:"max-attempts"`
+	Backoff     time.Duration `json:"backoff" mapstructure:"backoff"`
+	MaxBackoff  time.Duration `json:"max-backoff" mapstructure:"max-backoff"`
+
+	// Workers is the max concurrent attempts of one server.
+	Workers int `json:"workers" mapstructure:"workers"`
+
+	// AllowPrivateNetwork lets webhooks reach loopback, private and link-local addresses, which are
+	// refused by default, so that users can't probe internal services through apiserver.
+	AllowPrivateNetwork bool `json:"allow-private-network" mapstructure:"allow-private-network"`
+}
+
+func NewWebhookOpts() *WebhookOpts {
+	return &WebhookOpts{
+		Interval:    5 * time.Second,
+		Timeout:     10 * time.Second,
+		MaxAttempts: 8,
+		Backoff:     30 * time.Second,
+		MaxBackoff:  time.Hour,
+		Workers:     4,
+	}
+}
+
+func (o *WebhookOpts) Validate() []error {
+	var err []error
+
+	if o.Interval <= 0 {
+		err = append(err, fmt.Errorf("--webhook.interval must be greater than 0, got: %s", o.Interval))
+	}
+	if o.Timeout <= 0 {
+		err = append(err, fmt.Errorf("--webhook.timeout must be greater than 0, got: %s", o.Timeout))
+	}
+	if o.MaxAttempts <= 0 {
+		err = append(err, fmt.Errorf("--webhook.max-attempts must be greater than 0, got: %d", o.MaxAttempts))
+	}
+	if o.Backoff <= 0 {
+		err = append(err, fmt.Errorf("--webhook.backoff must be greater than 0, got: %s", o.Backoff))
+	}
+	if o.MaxBackoff < o.Backoff {
+		err = append(err, fmt.Errorf("--webhook.max-backoff must not be less than --webhook.backoff, got: %s", o.MaxBackoff))
+	}
+	if o.Workers <= 0 {
+		err = append(err, fmt.Errorf("--webhook.workers must be greater than 0, got: %d", o.Workers))
+	}
+
+	return err
+}
+
+func (o *WebhookOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.Interval, "webhook.interval", o.Interval, "How often due webhook deliveries are polled.")
+	fs.DurationVar(&o.Timeout, "webhook.timeout", o.Timeout, "Timeout of one attempt to deliver event to webhook.")
+	fs.IntVar(&o.MaxAttempts, "webhook.max-attempts", o.MaxAttempts, ""+
+		"Max attempts of one delivery, after which it's marked failed and can only be redelivered manually.")
+	fs.DurationVar(&o.Backoff, "webhook.backoff", o.Backoff, "Wait before the first retry, it doubles every retry.")
+	fs.DurationVar(&o.MaxBackoff, "webhook.max-backoff", o.MaxBackoff, "Max wait between retries.")
+	fs.IntVar(&o.Workers, "webhook.workers", o.Workers, "Max concurrent delivery attempts of one server.")
+	fs.BoolVar(&o.AllowPrivateNetwork, "webhook.allow-private-network", o.AllowPrivateNetwork, ""+
+		"Allow webhooks to loopback, private and link-local addresses, such as in development.")
+}
@@ -10,6 +10,7 @@ import (
 	"golang.org/x/sync/errgroup"
 	"istomyang.github.com/like-iam/component-base/web"
 	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/component/pkg/openapi"
 	"istomyang.github.com/like-iam/component/pkg/options"
 	"istomyang.github.com/like-iam/log"
 	"net/http"
@@ -27,6 +28,9 @@ type GeneralApiServer struct {
 	// gin.Engine satisfies http.Handler interface.
 	*gin.Engine
 
+	// OpenAPI describes routes of Engine, document is served at /openapi.json if it's given.
+	OpenAPI *openapi.Spec
+
 	insecure, secure *http.Server
 }
 
@@ -61,6 +65,14 @@ func (s *GeneralApiServer) Install() (errors []error) {
 		pprof.Register(s.Engine)
 	}
 
+	if s.OpenAPI != nil {
+		s.OpenAPI.Skip = append(s.OpenAPI.Skip, "/openapi.json", "/swagger", pprof.DefaultPrefix, "/metrics")
+		s.GET("/openapi.json", openapi.Handler(s.OpenAPI, s.Engine))
+		if s.SwaggerUI {
+			s.GET("/swagger", openapi.SwaggerUI("/openapi.json"))
+		}
+	}
+
 	gin.SetMode(s.Mode)
 
 	// TODO: Version tag
@@ -80,6 +92,11 @@ func (s *GeneralApiServer) Run() error {
 		Addr:    fmt.Sprintf("%s:%d", s.SecureServerOpts.Address, s.SecureServerOpts.Port),
 		Handler: s,
 	}
+	tlsConfig, err := s.SecureServerOpts.ClientTLSConfig()
+	if err != nil {
+		return err
+	}
+	s.secure.TLSConfig = tlsConfig
 
 	eg, c := errgroup.WithContext(context.Background())
 
@@ -32,6 +32,9 @@ var _ Client = &client{}
 
 func (c *client) createBaseRequest() Request {
 	var req = newRequest(c.u, c.coderName, c.c.CertPEMData, c.c.KeyPEMData, c.c.CAData, c.c.Insecure)
+	if c.c.SecretID != "" {
+		return req.Signer(&Signer{SecretID: c.c.SecretID, SecretKey: c.c.SecretKey, SessionToken: c.c.SessionToken})
+	}
 	req.Header("Authorization", c.c.Token)
 	return req
 }
@@ -2,10 +2,14 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"io"
+	"istomyang.github.com/like-iam/component-base/web"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"regexp"
 	"runtime"
@@ -58,3 +62,49 @@ func TestCopy(t *testing.T) {
 	p3.a = "456"
 	fmt.Println(p.a == p3.a)
 }
+
+func TestIdempotencyKey(t *testing.T) {
+	r := newRequest(&url.URL{}, "", nil, nil, nil, false).Verb(VerbPost).(*request)
+	key := r.header.Get(web.HeaderIdempotencyKey)
+	if key == "" {
+		t.Fatal("post request must have idempotency key")
+	}
+	r.Header(web.HeaderIdempotencyKey, "k1").Verb(VerbPost)
+	if r.header.Get(web.HeaderIdempotencyKey) != "k1" {
+		t.Fatal("idempotency key given by caller must be kept")
+	}
+	if g := newRequest(&url.URL{}, "", nil, nil, nil, false).Verb(VerbGET).(*request); g.header != nil {
+		t.Fatal("get request must not have idempotency key")
+	}
+}
+
+type jsonDecoder struct{}
+
+func (jsonDecoder) Decode(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func TestWatcher(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"type":"ADDED","resourceVersion":"1-0","object":{"name":"p1"}}` + "\n\n"))
+		_, _ = w.Write([]byte(`{"type":"DELETED","resourceVersion":"2-0","object":{"name":"p1"}}` + "\n"))
+	}))
+	defer svr.Close()
+
+	res, err := http.Get(svr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newWatcher(res, jsonDecoder{}, func() {})
+
+	var got []string
+	for e := range w.ResultChan() {
+		got = append(got, e.Type+" "+e.ResourceVersion)
+	}
+	if len(got) != 2 || got[0] != "ADDED 1-0" || got[1] != "DELETED 2-0" {
+		t.Errorf("got events %v", got)
+	}
+	if !errors.Is(w.Err(), io.ErrUnexpectedEOF) {
+		t.Errorf("stream closed by server must be an error, got %v", w.Err())
+	}
+}
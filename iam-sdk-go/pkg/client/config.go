@@ -11,6 +11,12 @@ type Config struct {
 	// Token is Http Header `Authorization` 's value
 	Token string
 
+	// SecretID and SecretKey sign every request instead of Token if set.
+	SecretID  string `json:"secret-id,omitempty" mapstructure:"secret-id"`
+	SecretKey string `json:"secret-key,omitempty" mapstructure:"secret-key"`
+	// SessionToken is required if SecretID is of temporary credential.
+	SessionToken string `json:"session-token,omitempty" mapstructure:"session-token"`
+
 	// CoderName is service name to get corresponding base.Coder.
 	CoderName string
 
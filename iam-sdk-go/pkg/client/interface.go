@@ -23,6 +23,9 @@ const (
 	ResUser   Res = "users"
 	ResPolicy Res = "policies"
 	ResSecret Res = "secrets"
+
+	ResServiceAccount Res = "serviceaccounts"
+	ResSTS            Res = "sts"
 )
 
 type V string
@@ -68,8 +71,13 @@ type Request interface {
 	// Body is http request's body.
 	Body(v any) Request
 
+	// Signer signs request with secret when sending, instead of Authorization header.
+	Signer(s *Signer) Request
+
 	// Send does the real request work.
 	Send(ctx context.Context) Response
+	// Watch sends request with watch=true in Params and keeps receiving events until ctx done or Stop.
+	Watch(ctx context.Context) (Watcher, error)
 }
 
 // Response defines interface of result for response.
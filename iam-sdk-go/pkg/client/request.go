@@ -10,6 +10,7 @@ import (
 	"golang.org/x/text/language"
 	"io"
 	"istomyang.github.com/like-iam/component-base/base"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
 	"istomyang.github.com/like-iam/component-base/web"
 	"istomyang.github.com/like-iam/iam-sdk-go/pkg/util/coder"
 	"net/http"
@@ -55,6 +56,9 @@ type request struct {
 
 	// u contains baseUrl.
 	u *url.URL
+
+	// signer signs request if not nil.
+	signer *Signer
 }
 
 // newRequest advises on using Client to create Request.
@@ -71,8 +75,14 @@ func newRequest(u *url.URL, coderName string, cert, key, ca []byte, insecure boo
 
 var _ Request = &request{}
 
+// Verb of POST sets a random Idempotency-Key header, so that sending the request again after timeout
+// gets the first response instead of creating twice. Use Header to give your own key.
 func (r *request) Verb(v Verb) Request {
 	r.verb = string(v)
+	if v == VerbPost && (r.header == nil || r.header.Get(web.HeaderIdempotencyKey) == "") {
+		key, _ := idutil.GetRandString(idutil.AlphabetL+idutil.Number, 32)
+		r.Header(web.HeaderIdempotencyKey, key)
+	}
 	return r
 }
 
@@ -129,6 +139,11 @@ func (r *request) Body(v any) Request {
 	return r
 }
 
+func (r *request) Signer(s *Signer) Request {
+	r.signer = s
+	return r
+}
+
 func (r *request) Send(ctx context.Context) Response {
 	build := r.build1
 	send := r.send1
@@ -144,10 +159,36 @@ func (r *request) Send(ctx context.Context) Response {
 	return newResponse(res, r.coder, nil)
 }
 
+func (r *request) Watch(ctx context.Context) (Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := r.build1(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res, err := r.send1(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxEventSize))
+		_ = res.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("%w: %s %s", ErrWatchStatus, res.Status, body)
+	}
+	return newWatcher(res, r.coder, cancel), nil
+}
+
 func (r *request) build1(ctx context.Context) (req *http.Request, err error) {
 	req, err = http.NewRequestWithContext(ctx, r.verb, r.buildUrl(), r.buildBody())
+	if err != nil {
+		return nil, err
+	}
 	{
-		req.Header = r.header
+		if r.header != nil {
+			req.Header = r.header
+		}
 		req.Header.Set("Accept", "application/json")
 		req.Header.Set("User-Agent", r.buildUA())
 
@@ -156,6 +197,9 @@ func (r *request) build1(ctx context.Context) (req *http.Request, err error) {
 			req.Header.Set("Content-Type", "application/json")
 		}
 	}
+	if r.signer != nil {
+		r.signer.Sign(req, r.body)
+	}
 	return
 }
 
@@ -216,8 +260,10 @@ func (r *request) buildUrl() string {
 			for k, _ := range map[string][]string(r.params) {
 				uv.Set(k, r.params.Get(k))
 			}
-			for k, _ := range map[string][]string(*r.meta) {
-				uv.Set(k, r.meta.Get(k))
+			if r.meta != nil {
+				for k, _ := range map[string][]string(*r.meta) {
+					uv.Set(k, r.meta.Get(k))
+				}
 			}
 			result.WriteString(uv.Encode())
 		}
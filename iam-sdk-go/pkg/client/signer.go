@@ -0,0 +1,24 @@
+package client
+
+import (
+	"istomyang.github.com/like-iam/component-base/auth"
+	"net/http"
+	"time"
+)
+
+// Signer signs request with secret, so that the secret key never leaves client and
+// a captured request can't be changed or replayed after skew window of server.
+type Signer struct {
+	SecretID  string
+	SecretKey string
+	// SessionToken is set when signing with temporary credential.
+	SessionToken string
+}
+
+// Sign sets X-Iam-Date, X-Iam-Content-Sha256 and Authorization headers, body must be the same as req.Body.
+func (s *Signer) Sign(req *http.Request, body []byte) {
+	if s.SessionToken != "" {
+		req.Header.Set(auth.HeaderSecurityToken, s.SessionToken)
+	}
+	auth.SignRequest(req, s.SecretID, s.SecretKey, body, time.Now())
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"istomyang.github.com/like-iam/component-base/base"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"net/http"
+	"sync"
+)
+
+// maxEventSize is the max size of one event in stream.
+const maxEventSize = 1 << 20
+
+// Watcher receives events streamed by list with watch=true.
+type Watcher interface {
+	// ResultChan is closed when stream ends or Stop is called.
+	ResultChan() <-chan metav1.WatchEvent
+	// Stop closes stream.
+	Stop()
+	// Err returns why stream ends, it's nil after Stop. Watch again from ResourceVersion of the last event to resume.
+	Err() error
+}
+
+type watcher struct {
+	res     *http.Response
+	decoder base.Decoder
+	cancel  context.CancelFunc
+	result  chan metav1.WatchEvent
+
+	mu  sync.Mutex
+	err error
+}
+
+func newWatcher(res *http.Response, decoder base.Decoder, cancel context.CancelFunc) Watcher {
+	w := &watcher{res: res, decoder: decoder, cancel: cancel, result: make(chan metav1.WatchEvent)}
+	go w.receive()
+	return w
+}
+
+func (w *watcher) ResultChan() <-chan metav1.WatchEvent {
+	return w.result
+}
+
+func (w *watcher) Stop() {
+	w.cancel()
+}
+
+func (w *watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *watcher) setErr(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.err = err
+}
+
+// receive decodes newline delimited json, empty lines are heartbeats.
+func (w *watcher) receive() {
+	defer close(w.result)
+	defer w.res.Body.Close()
+
+	ctx := w.res.Request.Context()
+	scanner := bufio.NewScanner(w.res.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxEventSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e metav1.WatchEvent
+		if err := w.decoder.Decode(line, &e); err != nil {
+			w.setErr(err)
+			return
+		}
+		select {
+		case w.result <- e:
+		case <-ctx.Done():
+			return
+		}
+		if e.Type == metav1.WatchError {
+			w.setErr(fmt.Errorf("watch ends: %s", e.Object))
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		w.setErr(err)
+		return
+	}
+	if ctx.Err() == nil {
+		w.setErr(io.ErrUnexpectedEOF)
+	}
+}
+
+var _ Watcher = &watcher{}
+
+// ErrWatchStatus is returned by Watch when server doesn't respond 200, 410 means resourceVersion is too old.
+var ErrWatchStatus = errors.New("watch fail")
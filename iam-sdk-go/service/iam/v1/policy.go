@@ -18,6 +18,8 @@ type Policy interface {
 	DeleteCollection(ctx context.Context, opts metaV1.DeleteOperateMeta, listOpts metaV1.ListOperateMeta) error
 	Get(ctx context.Context, name string, opts metaV1.GetOperateMeta) (*v1.Policy, error)
 	List(ctx context.Context, opts metaV1.ListOperateMeta) (*v1.PolicyList, error)
+	// Watch streams changes after resourceVersion, empty means changes after now and "0" means all changes kept by server.
+	Watch(ctx context.Context, resourceVersion string) (client.Watcher, error)
 }
 
 type policy struct {
@@ -84,4 +86,8 @@ func (p *policy) List(ctx context.Context, opts metaV1.ListOperateMeta) (lst *v1
 	return
 }
 
+func (p *policy) Watch(ctx context.Context, resourceVersion string) (client.Watcher, error) {
+	return p.prepare().Verb(client.VerbGET).Params(watchParams(resourceVersion)).Watch(ctx)
+}
+
 var _ Policy = &policy{}
@@ -18,6 +18,8 @@ type Secret interface {
 	DeleteCollection(ctx context.Context, opts metaV1.DeleteOperateMeta, listOpts metaV1.ListOperateMeta) error
 	Get(ctx context.Context, name string, opts metaV1.GetOperateMeta) (*v1.Secret, error)
 	List(ctx context.Context, opts metaV1.ListOperateMeta) (*v1.SecretList, error)
+	// Watch streams changes after resourceVersion, empty means changes after now and "0" means all changes kept by server.
+	Watch(ctx context.Context, resourceVersion string) (client.Watcher, error)
 }
 
 type secret struct {
@@ -84,4 +86,8 @@ func (s *secret) List(ctx context.Context, opts metaV1.ListOperateMeta) (list *v
 	return
 }
 
+func (s *secret) Watch(ctx context.Context, resourceVersion string) (client.Watcher, error) {
+	return s.prepare().Verb(client.VerbGET).Params(watchParams(resourceVersion)).Watch(ctx)
+}
+
 var _ Secret = &secret{}
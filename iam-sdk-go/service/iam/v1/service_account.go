@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metaV1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/iam-sdk-go/pkg/client"
+	"istomyang.github.com/like-iam/iam-sdk-go/pkg/util/coder"
+	"istomyang.github.com/like-iam/iam-sdk-go/service/iam"
+)
+
+type ServiceAccount interface {
+	Create(ctx context.Context, account *v1.ServiceAccount, opts metaV1.CreateOperateMeta) (*v1.ServiceAccount, error)
+	Update(ctx context.Context, account *v1.ServiceAccount, opts metaV1.UpdateOperateMeta) error
+	Delete(ctx context.Context, name string, opts metaV1.DeleteOperateMeta) error
+	Get(ctx context.Context, name string, opts metaV1.GetOperateMeta) (*v1.ServiceAccount, error)
+	List(ctx context.Context, opts metaV1.ListOperateMeta) (*v1.ServiceAccountList, error)
+
+	Disable(ctx context.Context, name string) error
+	Enable(ctx context.Context, name string) error
+
+	// CreateSecret returns the new secret with its key, which can't be got again.
+	CreateSecret(ctx context.Context, name string, secret *v1.Secret) (*v1.Secret, error)
+	ListSecrets(ctx context.Context, name string, opts metaV1.ListOperateMeta) (*v1.SecretList, error)
+	DeleteSecret(ctx context.Context, name, secretID string) error
+}
+
+type serviceAccount struct {
+	client client.Client
+}
+
+func newServiceAccount(client client.Client) ServiceAccount {
+	return &serviceAccount{client: client}
+}
+
+// prepare is a template for this page.
+func (s *serviceAccount) prepare() client.Request {
+	return s.client.Get().Resource(client.ResServiceAccount).Version(client.V1)
+}
+
+// handleResErr is a template to return error.
+func (s *serviceAccount) handleResErr(res client.Response) error {
+	if err := res.Error(); err != nil {
+		return err
+	}
+	raw, err := res.Raw()
+	if err != nil {
+		return err
+	}
+	if es := web.IsErrResponse(raw, coder.Get(iam.CoderRegisterName)); es != nil {
+		return fmt.Errorf(es.String())
+	}
+	return nil
+}
+
+func (s *serviceAccount) Create(ctx context.Context, account *v1.ServiceAccount, opts metaV1.CreateOperateMeta) (*v1.ServiceAccount, error) {
+	res := s.prepare().Verb(client.VerbPost).Meta(opts).Body(account).Send(ctx)
+	if err := s.handleResErr(res); err != nil {
+		return nil, err
+	}
+	created := &v1.ServiceAccount{}
+	return created, res.Into(created)
+}
+
+func (s *serviceAccount) Update(ctx context.Context, account *v1.ServiceAccount, opts metaV1.UpdateOperateMeta) error {
+	res := s.prepare().Verb(client.VerbPUT).Meta(opts).Name(account.Name).Body(account).Send(ctx)
+	return s.handleResErr(res)
+}
+
+func (s *serviceAccount) Delete(ctx context.Context, name string, opts metaV1.DeleteOperateMeta) error {
+	res := s.prepare().Verb(client.VerbDelete).Meta(opts).Name(name).Send(ctx)
+	return s.handleResErr(res)
+}
+
+func (s *serviceAccount) Get(ctx context.Context, name string, opts metaV1.GetOperateMeta) (*v1.ServiceAccount, error) {
+	res := s.prepare().Verb(client.VerbGET).Meta(opts).Name(name).Send(ctx)
+	if err := s.handleResErr(res); err != nil {
+		return nil, err
+	}
+	account := &v1.ServiceAccount{}
+	return account, res.Into(account)
+}
+
+func (s *serviceAccount) List(ctx context.Context, opts metaV1.ListOperateMeta) (*v1.ServiceAccountList, error) {
+	res := s.prepare().Verb(client.VerbGET).Meta(opts).Send(ctx)
+	if err := s.handleResErr(res); err != nil {
+		return nil, err
+	}
+	list := &v1.ServiceAccountList{}
+	return list, res.Into(list)
+}
+
+func (s *serviceAccount) Disable(ctx context.Context, name string) error {
+	res := s.prepare().Verb(client.VerbPost).Name(name).Action("disable").Send(ctx)
+	return s.handleResErr(res)
+}
+
+func (s *serviceAccount) Enable(ctx context.Context, name string) error {
+	res := s.prepare().Verb(client.VerbPost).Name(name).Action("enable").Send(ctx)
+	return s.handleResErr(res)
+}
+
+func (s *serviceAccount) CreateSecret(ctx context.Context, name string, secret *v1.Secret) (*v1.Secret, error) {
+	res := s.prepare().Verb(client.VerbPost).Name(name).Action("secrets").Body(secret).Send(ctx)
+	if err := s.handleResErr(res); err != nil {
+		return nil, err
+	}
+	created := &v1.Secret{}
+	return created, res.Into(created)
+}
+
+func (s *serviceAccount) ListSecrets(ctx context.Context, name string, opts metaV1.ListOperateMeta) (*v1.SecretList, error) {
+	res := s.prepare().Verb(client.VerbGET).Meta(opts).Name(name).Action("secrets").Send(ctx)
+	if err := s.handleResErr(res); err != nil {
+		return nil, err
+	}
+	list := &v1.SecretList{}
+	return list, res.Into(list)
+}
+
+func (s *serviceAccount) DeleteSecret(ctx context.Context, name, secretID string) error {
+	res := s.prepare().Verb(client.VerbDelete).Name(name).Action("secrets/" + secretID).Send(ctx)
+	return s.handleResErr(res)
+}
+
+var _ ServiceAccount = &serviceAccount{}
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/iam-sdk-go/pkg/client"
+	"istomyang.github.com/like-iam/iam-sdk-go/pkg/util/coder"
+	"istomyang.github.com/like-iam/iam-sdk-go/service/iam"
+)
+
+type STS interface {
+	// Assume exchanges secret of client for temporary credential, set its SecretID, SecretKey and
+	// SessionToken in client.Config to use it.
+	Assume(ctx context.Context, request *v1.AssumeRequest) (*v1.TemporaryCredential, error)
+}
+
+type sts struct {
+	client client.Client
+}
+
+func newSTS(client client.Client) STS {
+	return &sts{client: client}
+}
+
+func (s *sts) Assume(ctx context.Context, request *v1.AssumeRequest) (*v1.TemporaryCredential, error) {
+	res := s.client.Post().Resource(client.ResSTS).Version(client.V1).Action("assume").Body(request).Send(ctx)
+	if err := res.Error(); err != nil {
+		return nil, err
+	}
+	raw, err := res.Raw()
+	if err != nil {
+		return nil, err
+	}
+	if es := web.IsErrResponse(raw, coder.Get(iam.CoderRegisterName)); es != nil {
+		return nil, fmt.Errorf(es.String())
+	}
+
+	cred := &v1.TemporaryCredential{}
+	return cred, res.Into(cred)
+}
+
+var _ STS = &sts{}
@@ -20,6 +20,8 @@ type User interface {
 	DeleteCollection(ctx context.Context, names []string, opts metaV1.DeleteOperateMeta) error
 	Get(ctx context.Context, name string, opts metaV1.GetOperateMeta) (*v1.User, error)
 	List(ctx context.Context, opts metaV1.ListOperateMeta) (*v1.UserList, error)
+	// Watch streams changes after resourceVersion, empty means changes after now and "0" means all changes kept by server.
+	Watch(ctx context.Context, resourceVersion string) (client.Watcher, error)
 }
 
 type user struct {
@@ -100,4 +102,8 @@ func (u *user) List(ctx context.Context, opts metaV1.ListOperateMeta) (list *v1.
 	return
 }
 
+func (u *user) Watch(ctx context.Context, resourceVersion string) (client.Watcher, error) {
+	return u.prepare().Verb(client.VerbGET).Params(watchParams(resourceVersion)).Watch(ctx)
+}
+
 var _ User = &user{}
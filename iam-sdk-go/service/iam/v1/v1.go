@@ -1,11 +1,16 @@
 package v1
 
-import "istomyang.github.com/like-iam/iam-sdk-go/pkg/client"
+import (
+	"istomyang.github.com/like-iam/iam-sdk-go/pkg/client"
+	"net/url"
+)
 
 type Api interface {
 	User() User
 	Secret() Secret
 	Policy() Policy
+	ServiceAccount() ServiceAccount
+	STS() STS
 }
 
 type apiV1 struct {
@@ -28,4 +33,22 @@ func (a *apiV1) Policy() Policy {
 	return newPolicy(a.client)
 }
 
+func (a *apiV1) ServiceAccount() ServiceAccount {
+	return newServiceAccount(a.client)
+}
+
+func (a *apiV1) STS() STS {
+	return newSTS(a.client)
+}
+
 var _ Api = &apiV1{}
+
+// watchParams turns list into watch.
+func watchParams(resourceVersion string) url.Values {
+	ps := url.Values{}
+	ps.Set("watch", "true")
+	if resourceVersion != "" {
+		ps.Set("resourceVersion", resourceVersion)
+	}
+	return ps
+}
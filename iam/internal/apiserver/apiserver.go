@@ -8,13 +8,17 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 	v1 "istomyang.github.com/like-iam/api/proto/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
 	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/component/pkg/notify"
 	"istomyang.github.com/like-iam/component/pkg/server"
 	"istomyang.github.com/like-iam/component/pkg/shutdown"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/cache"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/rpc"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/store/mysql"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/webhook"
 	"istomyang.github.com/like-iam/log"
 )
 
@@ -23,6 +27,8 @@ type apiServer struct {
 	redis *conn.RedisClient
 	grpc  *server.GeneralGRPCServer
 
+	webhook *webhook.Deliverer
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -35,13 +41,19 @@ func newApiServer(options *Options) *apiServer {
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 
 	initSingletonStore(options)
+	initPasswordPolicy(options)
+	initLDAP(options)
 
 	// in create stage.
 	auth.GetJwtSchemeOr(options.jwtOptions)
+	auth.GetHMACSchemeOr(options.signatureOptions)
+	auth.SetMFAOpts(options.mfaOptions)
+	notify.GetNotifierOr(options.notifierOptions)
 
 	s.svr = createSvr(options)
 	s.redis = createRedis(options)
 	s.grpc = createGRpc(options)
+	s.webhook = webhook.NewDeliverer(store.Client(), options.webhookOptions)
 	s.shutdown = shutdown.CreateDefaultShutdown(s.close)
 
 	return s
@@ -50,6 +62,9 @@ func newApiServer(options *Options) *apiServer {
 func (s *apiServer) run() error {
 	s.shutdown.Run()
 
+	go auth.RotateKeys(s.ctx)
+	go s.webhook.Run(s.ctx)
+
 	if err := s.redis.Run(); err != nil {
 		return err
 	}
@@ -95,6 +110,22 @@ func initSingletonStore(options *Options) {
 	store.SetClient(factory)
 }
 
+func initPasswordPolicy(options *Options) {
+	p, err := options.passwordOptions.Policy()
+	if err != nil {
+		log.Fatal(err.Error())
+		panic(err.Error())
+	}
+	validator.SetPasswordPolicy(p)
+}
+
+func initLDAP(options *Options) {
+	if err := auth.InitLDAP(options.ldapOptions); err != nil {
+		log.Fatal(err.Error())
+		panic(err.Error())
+	}
+}
+
 func createSvr(options *Options) *server.GeneralApiServer {
 	engine := gin.Default()
 
@@ -104,11 +135,12 @@ func createSvr(options *Options) *server.GeneralApiServer {
 		SecureServerOpts:   options.secureSvrOptions,
 		FeatureOptions:     options.featureOptions,
 		Engine:             engine,
+		OpenAPI:            newOpenAPI(),
 	}
 	svr.Install()
 
 	installMiddlewares(engine)
-	installRouter(engine)
+	installRouter(engine, options)
 
 	return svr
 }
@@ -124,11 +156,18 @@ func createGRpc(options *Options) *server.GeneralGRPCServer {
 		log.Error(err.Error())
 		panic(err.Error())
 	}
-	svr := server.NewGeneralGRpcServer(addr, grpc.MaxRecvMsgSize(options.gRPCOptions.MaxMsgSize), grpc.Creds(file))
+	svr := server.NewGeneralGRpcServer(addr,
+		grpc.MaxRecvMsgSize(options.gRPCOptions.MaxMsgSize),
+		grpc.Creds(file),
+		grpc.UnaryInterceptor(rpc.UnaryInterceptor()),
+	)
 
 	if err := svr.Install(func(g *grpc.Server) error {
 
 		v1.RegisterCacheServer(g, cache.NewCache(store.Client()))
+		v1.RegisterUsersServer(g, rpc.NewUsers(store.Client()))
+		v1.RegisterSecretsServer(g, rpc.NewSecrets(store.Client()))
+		v1.RegisterPoliciesServer(g, rpc.NewPolicies(store.Client()))
 
 		// TODO:
 		// To see server status info.
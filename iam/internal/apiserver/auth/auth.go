@@ -3,11 +3,13 @@ package auth
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
 	"istomyang.github.com/like-iam/component-base/validator"
 	"istomyang.github.com/like-iam/component/pkg/middleware"
 	"istomyang.github.com/like-iam/component/pkg/middleware/auth"
@@ -21,27 +23,41 @@ import (
 )
 
 func GetAutoScheme() auth.Scheme {
-	return auth.NewAutoScheme(GetBasicScheme().(*auth.BasicScheme), GetJwtSchemeOr(nil).(*auth.JwtScheme))
+	return auth.NewAutoScheme(GetBasicScheme().(*auth.BasicScheme), GetJwtSchemeOr(nil).(*auth.JwtScheme)).
+		WithHMAC(GetHMACSchemeOr(nil).(*auth.HMACScheme)).
+		WithCert(GetCertScheme().(*auth.CertScheme))
 }
 
 func GetBasicScheme() auth.Scheme {
-	return auth.NewBasicScheme(func(username, password string) bool {
-		user, err := store.Client().User().Get(context.TODO(), username, metav1.GetOperateMeta{})
+	return auth.NewBasicScheme(func(c *gin.Context, username, password string) bool {
+		user, err := Authenticate(c, username, password)
 		if err != nil {
 			log.Errorf("basic error: %s", err.Error())
+			RecordLogin(c, username, v1.LoginSchemeBasic, err)
 			return false
 		}
 
-		if user.Compare(password) {
-			user.LoginAt = time.Now()
-			if store.Client().User().Update(context.TODO(), user, metav1.UpdateOperateMeta{}) != nil {
-				log.Errorf("basic error: %s", err.Error())
-				return false
-			}
-			return true
+		// Expired password can only be used to login with jwt and change password.
+		if PasswordExpired(user) {
+			log.Warnf("basic error: password of user %s expired", username)
+			RecordLogin(c, username, v1.LoginSchemeBasic, ErrPasswordExpired)
+			return false
+		}
+		// Basic has no way to carry second factor.
+		if user.MFAEnabled || MFARequired(user) {
+			log.Warnf("basic error: user %s must login with mfa", username)
+			RecordLogin(c, username, v1.LoginSchemeBasic, ErrMFARequired)
+			return false
 		}
 
-		return false
+		user.LoginAt = time.Now()
+		if err = store.Client().User().Update(c, user, metav1.UpdateOperateMeta{}); err != nil {
+			log.Errorf("basic error: %s", err.Error())
+			return false
+		}
+		// Basic authenticates every request rather than logins, only failures are recorded, otherwise
+		// each client would look like high-frequency attempts and fill up events.
+		return true
 	})
 }
 
@@ -50,6 +66,22 @@ var (
 	jwtOnce sync.Once
 )
 
+const (
+	// passwordExpiredKey marks in gin context that current user's password expired.
+	passwordExpiredKey = "passwordExpired"
+
+	// claimPasswordExpired marks token can only be used to change password.
+	claimPasswordExpired = "pwd_expired"
+
+	changePasswordPath = "/v1/users/:name/change-password"
+
+	claimJTI      = "jti"
+	claimIssuedAt = "iat"
+	claimUsername = middleware.UserNameKey
+
+	jtiLength = 32
+)
+
 // GetJwtSchemeOr should run in `create stage`, ensures can be used in GetAutoScheme.
 func GetJwtSchemeOr(opts *options.JwtOpts) auth.Scheme {
 	if jwtAuth == nil && opts == nil {
@@ -57,67 +89,158 @@ func GetJwtSchemeOr(opts *options.JwtOpts) auth.Scheme {
 		return nil
 	}
 
-	var jwtScheme *auth.JwtScheme
-
 	jwtOnce.Do(func() {
-		jwtScheme = auth.NewJwtScheme(&jwt.GinJWTMiddleware{
-			Realm:            opts.Realm,
-			SigningAlgorithm: "HS256",
-			Key:              []byte(opts.Key),
-			Timeout:          opts.Timeout,
-			MaxRefresh:       opts.MaxRefresh,
-			Authenticator:    loginAuthenticator(),
-			Authorizator: func(data interface{}, c *gin.Context) bool {
+		mw, err := newJwtMiddleware(opts)
+		if err != nil {
+			log.Fatal(err.Error())
+			panic(err.Error())
+		}
+
+		jwtAuth = auth.NewJwtScheme(mw)
+	})
+
+	return jwtAuth
+}
+
+func newJwtMiddleware(opts *options.JwtOpts) (*jwt.GinJWTMiddleware, error) {
+	if err := initKeyRing(opts); err != nil {
+		return nil, err
+	}
+
+	mw := &jwt.GinJWTMiddleware{
+		Realm: opts.Realm,
+		// Tokens are signed by generateToken and verified by keyFunc, which handle keys in keyRing, so
+		// gin-jwt is kept in HS256, otherwise it asks for key files of RS256.
+		SigningAlgorithm: hmacAlgorithm,
+		Key:              []byte(opts.Key),
+		KeyFunc:          keyFunc,
+		Timeout:          opts.Timeout,
+		MaxRefresh:       opts.MaxRefresh,
+		IdentityKey:      middleware.UserNameKey,
+		Authenticator:    loginAuthenticator(),
+		Authorizator: func(data interface{}, c *gin.Context) bool {
+			claims := jwt.ExtractClaims(c)
+			// Access tokens of oauth clients and id tokens only work where they are issued for.
+			if !apiserverToken(claims) {
+				return false
+			}
+			if tokenRevoked(c, claims) {
+				c.Set(tokenRevokedKey, true)
+				return false
+			}
+			if jti, _ := claims[claimJTI].(string); jti != "" {
+				touchSession(c, jti)
+			}
+
+			expired, _ := claims[claimPasswordExpired].(bool)
+			setup, _ := claims[claimMFASetup].(bool)
+			if !expired && !setup {
 				return true
-			},
-			PayloadFunc: func(data interface{}) jwt.MapClaims {
-				claims := jwt.MapClaims{
-					"iss": "apiserver",
-					"aud": "apiserver.iam.com",
+			}
+
+			// Restricted token can only be used on user self.
+			if username, _ := claims[middleware.UserNameKey].(string); username != c.Param("name") {
+				return false
+			}
+			if expired {
+				c.Set(passwordExpiredKey, true)
+				if c.FullPath() == changePasswordPath {
+					return true
 				}
-				if user, ok := data.(*v1.User); ok {
-					claims["sub"] = user.Username
-					claims[middleware.UserNameKey] = user.Username
+			}
+			if setup {
+				c.Set(mfaSetupKey, true)
+				if strings.HasPrefix(c.FullPath(), mfaPathPrefix) {
+					return true
 				}
-				return claims
-			},
-			Unauthorized: func(c *gin.Context, code int, message string) {
-				c.JSON(code, gin.H{
-					"code":    code,
-					"message": message,
-				})
-			},
-			LoginResponse: func(c *gin.Context, i int, s string, t time.Time) {
-				c.JSON(http.StatusOK, gin.H{
-					"code":   http.StatusOK,
-					"token":  s,
-					"expire": t.Format(time.RFC3339),
-				})
-			},
-			LogoutResponse: logout(),
-			RefreshResponse: func(c *gin.Context, i int, s string, t time.Time) {
-				c.JSON(http.StatusOK, gin.H{
-					"code":   http.StatusOK,
-					"token":  s,
-					"expire": t.Format(time.RFC3339),
-				})
-			},
-			IdentityHandler:       nil,
-			TokenLookup:           "",
-			TokenHeadName:         "",
-			TimeFunc:              nil,
-			HTTPStatusMessageFunc: nil,
-			PrivKeyFile:           "",
-			PubKeyFile:            "",
-			SendCookie:            false,
-			SecureCookie:          false,
-			SendAuthorization:     false,
-		})
-
-		jwtAuth = jwtScheme
-	})
-
-	return jwtScheme
+			}
+			return false
+		},
+		PayloadFunc: func(data interface{}) jwt.MapClaims {
+			claims := jwt.MapClaims{
+				"iss": "apiserver",
+				"aud": apiserverAudience,
+			}
+			if user, ok := data.(*v1.User); ok {
+				claims["sub"] = user.Username
+				claims[claimUsername] = user.Username
+				claims[claimJTI], _ = idutil.GetRandString(idutil.AlphabetL+idutil.Number, jtiLength)
+				// Millisecond precision to tell tokens issued just after RevokeUser.
+				claims[claimIssuedAt] = float64(time.Now().UnixMilli()) / 1000
+				// Password and MFA of federated user are managed by upstream provider, LDAP only manages password.
+				if PasswordExpired(user) {
+					claims[claimPasswordExpired] = true
+				}
+				if !(user.Federated() && !user.FromLDAP()) && !user.MFAEnabled && MFARequired(user) {
+					claims[claimMFASetup] = true
+				}
+			}
+			return claims
+		},
+		Unauthorized: func(c *gin.Context, code int, message string) {
+			if c.GetBool(tokenRevokedKey) {
+				code = http.StatusUnauthorized
+			}
+			res := gin.H{
+				"code":    code,
+				"message": message,
+			}
+			if token := c.GetString(mfaChallengeKey); token != "" {
+				res["mfaToken"] = token
+				res["expire"] = time.Now().Add(GetMFAOpts().ChallengeTTL).Format(time.RFC3339)
+			}
+			c.JSON(code, res)
+		},
+		LoginResponse: func(c *gin.Context, i int, s string, t time.Time) {
+			res := gin.H{
+				"code":   http.StatusOK,
+				"token":  s,
+				"expire": t.Format(time.RFC3339),
+			}
+			if c.GetBool(passwordExpiredKey) {
+				res["passwordExpired"] = true
+				res["message"] = "password expired, must change."
+			}
+			if c.GetBool(mfaSetupKey) {
+				res["mfaSetupRequired"] = true
+				res["message"] = "mfa required, must enrol."
+			}
+			c.JSON(http.StatusOK, res)
+		},
+		LogoutResponse: logout(),
+		RefreshResponse: func(c *gin.Context, i int, s string, t time.Time) {
+			c.JSON(http.StatusOK, gin.H{
+				"code":   http.StatusOK,
+				"token":  s,
+				"expire": t.Format(time.RFC3339),
+			})
+		},
+		IdentityHandler: nil,
+		TokenLookup:     "",
+		TokenHeadName:   "",
+		TimeFunc:        nil,
+		HTTPStatusMessageFunc: func(e error, c *gin.Context) string {
+			if e == jwt.ErrForbidden && c.GetBool(tokenRevokedKey) {
+				return "token has been revoked."
+			}
+			if e == jwt.ErrForbidden && c.GetBool(passwordExpiredKey) {
+				return "password expired, must change."
+			}
+			if e == jwt.ErrForbidden && c.GetBool(mfaSetupKey) {
+				return "mfa required, must enrol."
+			}
+			return e.Error()
+		},
+		PrivKeyFile:       "",
+		PubKeyFile:        "",
+		SendCookie:        false,
+		SecureCookie:      false,
+		SendAuthorization: false,
+	}
+	if err := mw.MiddlewareInit(); err != nil {
+		return nil, err
+	}
+	return mw, nil
 }
 
 type login struct {
@@ -127,6 +250,10 @@ type login struct {
 
 func loginAuthenticator() func(c *gin.Context) (interface{}, error) {
 	return func(c *gin.Context) (interface{}, error) {
+		if c.FullPath() == "/login/mfa" {
+			return mfaAuthenticator(c)
+		}
+
 		var ln *login
 		var err error
 		if c.GetHeader("Authorization") != "" {
@@ -139,42 +266,80 @@ func loginAuthenticator() func(c *gin.Context) (interface{}, error) {
 		}
 
 		var user *v1.User
-		user, err = store.Client().User().Get(c, ln.Username, metav1.GetOperateMeta{})
-		if err != nil {
+		if user, err = Authenticate(c, ln.Username, ln.Password); err != nil {
+			RecordLogin(c, ln.Username, v1.LoginSchemeJWT, err)
 			return nil, err
 		}
 
-		if !validator.CheckPassword(user.Password) {
-			return nil, jwt.ErrFailedAuthentication
+		// Second factor is checked at /login/mfa.
+		if user.MFAEnabled {
+			var token string
+			if token, err = issueMFAChallenge(c, user.Username); err != nil {
+				return nil, err
+			}
+			c.Set(mfaChallengeKey, token)
+			return nil, ErrMFARequired
+		}
+
+		if PasswordExpired(user) {
+			c.Set(passwordExpiredKey, true)
+		}
+		if MFARequired(user) {
+			c.Set(mfaSetupKey, true)
 		}
 
 		user.LoginAt = time.Now()
 		if err = store.Client().User().Update(c, user, metav1.UpdateOperateMeta{}); err != nil {
 			return nil, err
 		}
+		RecordLogin(c, user.Username, v1.LoginSchemeJWT, nil)
 
 		return user, nil
 	}
 }
 
-func logout() func(c *gin.Context, code int) {
-	return func(c *gin.Context, code int) {
+// Authenticate checks password of user, other checks such as MFA are left to caller.
+// Users not found locally or synced from LDAP are checked by directory if LDAP is enabled.
+// Service account, which has no password, and disabled user always fail.
+func Authenticate(ctx context.Context, username, password string) (*v1.User, error) {
+	if _, ok := v1.ServiceAccountName(username); ok {
+		return nil, jwt.ErrFailedAuthentication
 	}
+
+	user, err := store.Client().User().Get(ctx, username, metav1.GetOperateMeta{})
+	// Deprovisioned user keeps its data but can't login in any way.
+	if err == nil && user != nil && user.Disabled {
+		return nil, jwt.ErrFailedAuthentication
+	}
+	if ldapAuth != nil && (err != nil || user == nil || user.FromLDAP()) {
+		if err != nil || user == nil {
+			user = nil
+		}
+		return authenticateLDAP(ctx, user, username, password)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.Compare(password) {
+		return nil, jwt.ErrFailedAuthentication
+	}
+	return user, nil
 }
 
 func parseWithHeader(c *gin.Context) (*login, error) {
 	// "Authorization Basic username:password"
-	authArr := strings.SplitN(c.GetHeader("Authorization"), "", 2)
-	if len(authArr) != 2 && authArr[0] != "Basic" {
+	authArr := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(authArr) != 2 || authArr[0] != "Basic" {
 		log.Errorf("parse token fail: %v", authArr)
 		return nil, jwt.ErrFailedAuthentication
 	}
-	decodeString, err := base64.StdEncoding.DecodeString(authArr[2])
+	decodeString, err := base64.StdEncoding.DecodeString(authArr[1])
 	if err != nil {
-		log.Errorf("decode base64 token fail: %v", authArr[2])
+		log.Errorf("decode base64 token fail: %v", authArr[1])
 		return nil, jwt.ErrFailedAuthentication
 	}
-	up := strings.SplitN(string(decodeString), "", 2)
+	up := strings.SplitN(string(decodeString), ":", 2)
 	if len(up) != 2 {
 		log.Errorf("format wrong, must username:password, got: %v", up)
 		return nil, jwt.ErrFailedAuthentication
@@ -196,3 +361,11 @@ func parseWithBody(c *gin.Context) (*login, error) {
 	return ln, nil
 
 }
+
+// ErrPasswordExpired means password is right, but it can only be used to login with jwt and change password.
+var ErrPasswordExpired = errors.New("password expired")
+
+// PasswordExpired tells whether password of user must be changed, password of federated user is managed by others.
+func PasswordExpired(user *v1.User) bool {
+	return !user.Federated() && validator.GetPasswordPolicy().Expired(user.PasswordSetAt())
+}
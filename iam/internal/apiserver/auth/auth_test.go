@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/test/redistest"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	redistest.Use()
+
+	opts := options.NewJwtOpts()
+	opts.Key = "test-secret"
+	GetJwtSchemeOr(opts)
+
+	os.Exit(m.Run())
+}
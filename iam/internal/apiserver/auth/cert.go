@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
+)
+
+// GetCertScheme maps identity of client certificate to the user with the same name,
+// or to the service account whose principal is the identity, SAN is tried before CN.
+func GetCertScheme() auth.Scheme {
+	return auth.NewCertScheme(func(identities []string) (string, error) {
+		for _, id := range identities {
+			if _, ok := v1.ServiceAccountName(id); ok {
+				if err := checkServiceAccount(id); err != nil {
+					log.Warnf("cert error: %s", err.Error())
+					continue
+				}
+				return id, nil
+			}
+			user, err := store.Client().User().Get(context.TODO(), id, metav1.GetOperateMeta{})
+			if err != nil || user == nil || user.Disabled {
+				continue
+			}
+			return user.Username, nil
+		}
+		log.Warnf("cert error: no user mapped from %v", identities)
+		return "", nil
+	})
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestGetCertScheme(t *testing.T) {
+	ctx := context.Background()
+	factory := fake.NewFactory()
+	store.SetClient(factory)
+	defer store.SetClient(nil)
+
+	for _, user := range []*v1.User{
+		{Username: "alice"},
+		{Username: "bob"},
+		{Username: "mallory", Disabled: true},
+	} {
+		if err := factory.User().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, account := range []*v1.ServiceAccount{
+		{ObjectMeta: metav1.ObjectMeta{Name: "deployer"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "retired"}, Disabled: true},
+	} {
+		if err := factory.ServiceAccount().Create(ctx, account, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spiffe, _ := url.Parse("spiffe://mesh/deployer")
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want string
+	}{
+		{"cn", &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}}, "alice"},
+		{"san before cn", &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}, DNSNames: []string{"bob"}}, "bob"},
+		{"unknown san falls back to cn", &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}, URIs: []*url.URL{spiffe}}, "alice"},
+		{"service account", &x509.Certificate{Subject: pkix.Name{CommonName: v1.ServiceAccountPrincipal("deployer")}}, v1.ServiceAccountPrincipal("deployer")},
+		{"email san", &x509.Certificate{EmailAddresses: []string{"bob"}}, "bob"},
+		{"disabled user", &x509.Certificate{Subject: pkix.Name{CommonName: "mallory"}}, ""},
+		{"disabled service account", &x509.Certificate{Subject: pkix.Name{CommonName: v1.ServiceAccountPrincipal("retired")}}, ""},
+		{"disabled skipped", &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}, DNSNames: []string{"mallory"}}, "alice"},
+		{"unknown", &x509.Certificate{Subject: pkix.Name{CommonName: "nobody"}}, ""},
+		{"no certificate", nil, ""},
+	}
+
+	scheme := GetCertScheme()
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/v1/users", nil)
+		if tt.cert != nil {
+			c.Request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{tt.cert}}}
+		}
+
+		scheme.AuthFunc()(c)
+
+		if got := c.GetString(middleware.UserNameKey); got != tt.want {
+			t.Errorf("%s: username got %q, want %q", tt.name, got, tt.want)
+		}
+		if tt.want == "" && (!c.IsAborted() || w.Code != http.StatusForbidden) {
+			t.Errorf("%s: got status %d aborted %v, want 403 and aborted", tt.name, w.Code, c.IsAborted())
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware/auth"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
+	"sync"
+)
+
+var (
+	hmacAuth *auth.HMACScheme
+	hmacOnce sync.Once
+)
+
+// GetHMACSchemeOr returns scheme verifying requests signed with secret key, opts is required at first call.
+func GetHMACSchemeOr(opts *options.SignatureOpts) auth.Scheme {
+	if hmacAuth == nil && opts == nil {
+		log.Error("hmac scheme options must not be nil")
+		return nil
+	}
+
+	hmacOnce.Do(func() {
+		hmacAuth = auth.NewHMACScheme(func(secretID string) (*auth.Secret, error) {
+			secret, err := store.Client().Secret().GetByID(context.TODO(), secretID, metav1.GetOperateMeta{})
+			if err != nil {
+				return nil, err
+			}
+			if err = checkServiceAccount(secret.Username); err != nil {
+				return nil, err
+			}
+			if err = checkUserDisabled(secret.Username); err != nil {
+				return nil, err
+			}
+			return &auth.Secret{
+				Username: secret.Username,
+				ID:       secret.SecretID,
+				Key:      secret.SecretKey,
+				Expires:  secret.Expires,
+			}, nil
+		}, opts.Skew)
+	})
+
+	return hmacAuth
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/ory/ladon"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"net/http/httptest"
+	"testing"
+)
+
+func impersonatePolicy(owner, name, subject string) *v1.Policy {
+	return &v1.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Username:   owner,
+		Policy: v1.AuthzPolicy{DefaultPolicy: ladon.DefaultPolicy{
+			ID:        name,
+			Subjects:  []string{subject},
+			Effect:    ladon.AllowAccess,
+			Resources: []string{impersonateResourcePrefix + "<.*>"},
+			Actions:   []string{ImpersonateAction},
+		}},
+	}
+}
+
+func TestCheckImpersonate(t *testing.T) {
+	ctx := context.Background()
+	factory := fake.NewFactory()
+	store.SetClient(factory)
+	defer store.SetClient(nil)
+
+	for _, user := range []*v1.User{
+		{Username: "root", IsAdmin: "true"},
+		{Username: "support"},
+		{Username: "mallory"},
+		{Username: "alice"},
+	} {
+		if err := factory.User().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Admin grants support, mallory grants itself.
+	for _, p := range []*v1.Policy{
+		impersonatePolicy("root", "support-impersonate", "support"),
+		impersonatePolicy("mallory", "self-impersonate", "mallory"),
+	} {
+		if err := factory.Policy().Create(ctx, p, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/v1/secrets", nil)
+
+	tests := []struct {
+		actor, target string
+		allowed       bool
+	}{
+		{"support", "alice", true},
+		{"support", "root", false},
+		{"support", "nobody", false},
+		{"support", v1.ServiceAccountPrincipal("deployer"), false},
+		{"mallory", "alice", false},
+		{"alice", "mallory", false},
+	}
+	for _, tt := range tests {
+		err := checkImpersonate(c, tt.actor, tt.target)
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("%s impersonates %s: got allowed %v, want %v, err: %v", tt.actor, tt.target, allowed, tt.allowed, err)
+		}
+	}
+}
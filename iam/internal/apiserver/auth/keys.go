@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	hmacAlgorithm = "HS256"
+
+	// apiserverAudience is audience of tokens accepted by jwt scheme.
+	apiserverAudience = "apiserver.iam.com"
+)
+
+// ErrClientTokenInvalid means token is not an access token of oauth client, or it's revoked.
+var ErrClientTokenInvalid = errors.New("invalid client access token")
+
+var (
+	// keyRing signs tokens with asymmetric keys, nil means HS256 with shared key.
+	keyRing *auth.KeyRing
+	// signingAlgorithm is the configured one, gin-jwt always runs in HS256, see GetJwtSchemeOr.
+	signingAlgorithm string
+
+	keyDir           string
+	rotationInterval time.Duration
+)
+
+func initKeyRing(opts *options.JwtOpts) error {
+	signingAlgorithm = opts.SigningAlgorithm
+	if opts.SigningAlgorithm == hmacAlgorithm {
+		return nil
+	}
+
+	keyDir = opts.KeyDir
+	rotationInterval = opts.RotationInterval
+
+	if err := os.MkdirAll(keyDir, 0700); err != nil {
+		return err
+	}
+	keys, err := auth.LoadKeyDir(keyDir)
+	if err != nil {
+		return err
+	}
+	keyRing = auth.NewKeyRing(keys...)
+
+	if active := keyRing.Active(); active == nil || active.Algorithm != opts.SigningAlgorithm {
+		return generateKey(opts.SigningAlgorithm)
+	}
+	return nil
+}
+
+func generateKey(alg string) error {
+	k, err := auth.GenerateSigningKey(alg)
+	if err != nil {
+		return err
+	}
+	if err = k.SaveTo(keyDir); err != nil {
+		return err
+	}
+	keyRing.Add(k)
+	log.Infof("generate jwt signing key %s with %s.", k.ID, alg)
+	return nil
+}
+
+// RotateKeys reloads key dir to pick up keys generated by other apiservers, generates a new key
+// when the active one is older than rotation interval, and removes keys no token can use.
+// It blocks until ctx done.
+func RotateKeys(ctx context.Context) {
+	if keyRing == nil || rotationInterval <= 0 {
+		return
+	}
+
+	check := time.Minute
+	if rotationInterval < check {
+		check = rotationInterval
+	}
+	ticker := time.NewTicker(check)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := rotateKeys(); err != nil {
+				log.Errorf("rotate jwt signing keys fail: %s", err.Error())
+			}
+		}
+	}
+}
+
+func rotateKeys() error {
+	keys, err := auth.LoadKeyDir(keyDir)
+	if err != nil {
+		return err
+	}
+	keyRing.Set(keys...)
+
+	if active := keyRing.Active(); active == nil || time.Since(active.CreatedAt) >= rotationInterval {
+		if err = generateKey(signingAlgorithm); err != nil {
+			return err
+		}
+	}
+
+	// Tokens signed by a retired key can be used or refreshed until Timeout + MaxRefresh.
+	for _, k := range keyRing.Prune(jwtAuth.Timeout + jwtAuth.MaxRefresh) {
+		log.Infof("remove retired jwt signing key %s.", k.ID)
+		if err = os.Remove(filepath.Join(keyDir, k.ID+".pem")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyFunc verifies token with shared key in HS256 or public key found by kid.
+func keyFunc(t *jwtv4.Token) (interface{}, error) {
+	if keyRing != nil {
+		return keyRing.KeyFunc(t)
+	}
+	if t.Method.Alg() != hmacAlgorithm {
+		return nil, jwt.ErrInvalidSigningAlgorithm
+	}
+	return jwtAuth.Key, nil
+}
+
+// generateToken signs claims like gin-jwt, but supports asymmetric algorithms and kid.
+func generateToken(claims map[string]interface{}) (string, time.Time, error) {
+	c := jwtv4.MapClaims{}
+	for k, v := range claims {
+		c[k] = v
+	}
+	expire := time.Now().Add(jwtAuth.Timeout)
+	c["exp"] = expire.Unix()
+	c["orig_iat"] = time.Now().Unix()
+
+	token, err := SignClaims(c)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: %s", jwt.ErrFailedTokenCreation, err.Error())
+	}
+	return token, expire, nil
+}
+
+// GenerateClientToken issues access token of user for oauth client, its audience is client id, so that it's
+// refused by jwt scheme and only accepted by ParseClientToken, such as at userinfo.
+func GenerateClientToken(user *v1.User, clientID string) (string, time.Time, error) {
+	jti, err := idutil.GetRandString(idutil.AlphabetL+idutil.Number, jtiLength)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := time.Now()
+	expire := now.Add(jwtAuth.Timeout)
+	token, err := SignClaims(map[string]interface{}{
+		"iss":         "apiserver",
+		"aud":         clientID,
+		"sub":         user.Username,
+		"exp":         expire.Unix(),
+		claimUsername: user.Username,
+		claimJTI:      jti,
+		claimIssuedAt: float64(now.UnixMilli()) / 1000,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: %s", jwt.ErrFailedTokenCreation, err.Error())
+	}
+	return token, expire, nil
+}
+
+// ParseClientToken verifies access token issued by GenerateClientToken, and returns username in it.
+// Tokens of apiserver itself are refused.
+func ParseClientToken(ctx context.Context, token string) (string, error) {
+	claims := jwtv4.MapClaims{}
+	if _, err := jwtv4.ParseWithClaims(token, claims, keyFunc); err != nil {
+		return "", err
+	}
+	if clientID, _ := claims["aud"].(string); clientID == "" || clientID == apiserverAudience {
+		return "", ErrClientTokenInvalid
+	}
+	if tokenRevoked(ctx, jwt.MapClaims(claims)) {
+		return "", ErrClientTokenInvalid
+	}
+	username, _ := claims[claimUsername].(string)
+	return username, nil
+}
+
+// apiserverToken tells whether token is issued for apiserver rather than oauth clients.
+func apiserverToken(claims jwt.MapClaims) bool {
+	return jwtv4.MapClaims(claims).VerifyAudience(apiserverAudience, true)
+}
+
+// SignClaims signs claims as they are, caller must set exp.
+func SignClaims(claims map[string]interface{}) (string, error) {
+	c := jwtv4.MapClaims(claims)
+	if keyRing != nil {
+		return keyRing.Sign(c)
+	}
+	return jwtv4.NewWithClaims(jwtv4.SigningMethodHS256, c).SignedString(jwtAuth.Key)
+}
+
+// Asymmetric tells whether tokens are signed by keys whose public keys are published in jwks.
+func Asymmetric() bool {
+	return keyRing != nil
+}
+
+// SigningAlgorithm returns algorithm of tokens signed by apiserver.
+func SigningAlgorithm() string {
+	return signingAlgorithm
+}
+
+// unauthorized is same as the one in gin-jwt.
+func unauthorized(c *gin.Context, code int, message string) {
+	c.Header("WWW-Authenticate", "JWT realm="+jwtAuth.Realm)
+	c.Abort()
+	jwtAuth.Unauthorized(c, code, message)
+}
+
+// LoginHandler replaces gin-jwt's to sign token by generateToken.
+func LoginHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := jwtAuth.Authenticator(c)
+		if err != nil {
+			unauthorized(c, http.StatusUnauthorized, jwtAuth.HTTPStatusMessageFunc(err, c))
+			return
+		}
+
+		loginResponse(c, data)
+	}
+}
+
+// LoginResponse responds token same as login, for user authenticated by other ways such as federated login.
+func LoginResponse(c *gin.Context, user *v1.User) {
+	loginResponse(c, user)
+}
+
+func loginResponse(c *gin.Context, data interface{}) {
+	claims := jwtAuth.PayloadFunc(data)
+	token, expire, err := generateToken(claims)
+	if err != nil {
+		log.L(c).Errorf("generate token fail: %s", err.Error())
+		unauthorized(c, http.StatusUnauthorized, jwtAuth.HTTPStatusMessageFunc(jwt.ErrFailedTokenCreation, c))
+		return
+	}
+	if err = recordSession(c, claims, expire); err != nil {
+		log.L(c).Errorf("record session fail: %s", err.Error())
+	}
+
+	jwtAuth.LoginResponse(c, http.StatusOK, token, expire)
+}
+
+// JWKSHandler publishes public keys, so that other services can verify tokens offline.
+// It's empty when tokens are signed in HS256.
+func JWKSHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if keyRing == nil {
+			c.JSON(http.StatusOK, &auth.JWKSet{Keys: []auth.JWK{}})
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keyRing.JWKS())
+	}
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewJwtMiddleware_Asymmetric(t *testing.T) {
+	defer func(ring *auth.KeyRing, alg string) {
+		keyRing, signingAlgorithm = ring, alg
+	}(keyRing, signingAlgorithm)
+
+	for _, alg := range []string{auth.AlgRS256, auth.AlgES256, auth.AlgEdDSA} {
+		opts := options.NewJwtOpts()
+		opts.SigningAlgorithm = alg
+		opts.KeyDir = t.TempDir()
+
+		mw, err := newJwtMiddleware(opts)
+		if err != nil {
+			t.Fatalf("start with %s: %v", alg, err)
+		}
+		if SigningAlgorithm() != alg || keyRing.Active().Algorithm != alg {
+			t.Fatalf("want signing in %s, got %s", alg, SigningAlgorithm())
+		}
+
+		token, err := keyRing.Sign(jwtv4.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := mw.ParseTokenString(token)
+		if err != nil || !parsed.Valid || parsed.Method.Alg() != alg {
+			t.Errorf("token signed in %s should be verified: %v", alg, err)
+		}
+
+		hmac, _ := jwtv4.NewWithClaims(jwtv4.SigningMethodHS256, jwtv4.MapClaims{"sub": "alice"}).SignedString([]byte(opts.Key))
+		if _, err = mw.ParseTokenString(hmac); err == nil {
+			t.Errorf("HS256 token must be rejected when signing in %s", alg)
+		}
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+	user := &v1.User{Username: "alice"}
+
+	serve := func(token string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/v1/users", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+		jwtAuth.MiddlewareFunc()(c)
+		return w.Code
+	}
+
+	clientToken, _, err := GenerateClientToken(user, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loginToken, _, err := generateToken(jwtAuth.PayloadFunc(user))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if username, err := ParseClientToken(ctx, clientToken); err != nil || username != "alice" {
+		t.Errorf("client token got %q, %v, want alice", username, err)
+	}
+	if code := serve(clientToken); code != http.StatusForbidden {
+		t.Errorf("client token on apiserver got %d, want 403", code)
+	}
+
+	if _, err = ParseClientToken(ctx, loginToken); err != ErrClientTokenInvalid {
+		t.Errorf("login token got %v, want ErrClientTokenInvalid", err)
+	}
+	if code := serve(loginToken); code != http.StatusOK {
+		t.Errorf("login token on apiserver got %d, want 200", code)
+	}
+
+	if err = RevokeUser(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ParseClientToken(ctx, clientToken); err != ErrClientTokenInvalid {
+		t.Errorf("revoked client token got %v, want ErrClientTokenInvalid", err)
+	}
+}
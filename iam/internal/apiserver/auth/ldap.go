@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	jwt "github.com/appleboy/gin-jwt/v2"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
+)
+
+// ldapAuth checks password of users from directory, nil means LDAP is disabled.
+var ldapAuth *auth.LDAPAuthenticator
+
+// InitLDAP enables LDAP authentication if configured, should run in `create stage`.
+func InitLDAP(opts *options.LDAPOpts) error {
+	if !opts.Enabled() {
+		return nil
+	}
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	ldapAuth = auth.NewLDAPAuthenticator(cfg)
+	return nil
+}
+
+// authenticateLDAP binds user to directory, and syncs it into user table.
+// user is nil when it's the first successful bind.
+func authenticateLDAP(ctx context.Context, user *v1.User, username, password string) (*v1.User, error) {
+	id, err := ldapAuth.Authenticate(username, password)
+	if err != nil {
+		if !errors.Is(err, auth.ErrLDAPInvalidCredentials) && !errors.Is(err, auth.ErrLDAPUserNotFound) {
+			log.L(ctx).Errorf("ldap authenticate user %s fail: %s", username, err.Error())
+		}
+		return nil, jwt.ErrFailedAuthentication
+	}
+
+	if user == nil {
+		log.L(ctx).Infof("sync ldap user %s from %s.", username, id.DN)
+		// Password is left empty, it's checked by directory.
+		user = &v1.User{
+			Username:         username,
+			Email:            id.Email,
+			Groups:           id.Groups,
+			IdentityProvider: v1.LDAPIdentityProvider,
+			ExternalID:       id.DN,
+		}
+		if err = store.Client().User().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	user.Email = id.Email
+	user.Groups = id.Groups
+	user.ExternalID = id.DN
+	return user, nil
+}
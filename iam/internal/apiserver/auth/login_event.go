@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg"
+	"istomyang.github.com/like-iam/iam/internal/pkg/analytics"
+	"istomyang.github.com/like-iam/log"
+	"net"
+	"strings"
+	"time"
+)
+
+// Anomaly rules of login, see detectAnomalies.
+const (
+	// AnomalyNewIPRange is a successful login from /24 (or /48 for IPv6) not seen in recent successful logins.
+	AnomalyNewIPRange = "new-ip-range"
+	// AnomalyHighFrequency is too many attempts in a short time, no human logins like this.
+	AnomalyHighFrequency = "high-frequency"
+	// AnomalyManyFailures is too many failed attempts recently, password may be guessed.
+	AnomalyManyFailures = "many-failures"
+)
+
+const (
+	// historyWindow and historyLimit bound recent events to check with.
+	historyWindow = 30 * 24 * time.Hour
+	historyLimit  = 100
+
+	frequencyWindow    = time.Minute
+	frequencyThreshold = 10
+
+	failuresWindow    = 15 * time.Minute
+	failuresThreshold = 5
+
+	// suspiciousLoginTTL is how long pumps keep the record.
+	suspiciousLoginTTL = 7 * 24 * time.Hour
+)
+
+// RecordLogin saves attempt to login asynchronously, err is nil if it succeeds. Attempts of unknown users are
+// not saved. If it triggers any anomaly rule, a suspicious login record is raised to analytics pumps.
+func RecordLogin(c *gin.Context, username, scheme string, err error) {
+	if username == "" {
+		return
+	}
+
+	event := &v1.LoginEvent{
+		Username:  username,
+		Success:   err == nil,
+		Scheme:    scheme,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		CreatedAt: time.Now(),
+	}
+	if err != nil {
+		event.Reason = err.Error()
+	}
+
+	go recordLogin(context.Background(), event)
+}
+
+func recordLogin(ctx context.Context, event *v1.LoginEvent) {
+	// Anyone can try any number of usernames, events of unknown ones would only fill the table.
+	if user, err := store.Client().User().Get(ctx, event.Username, metav1.GetOperateMeta{}); err != nil || user == nil {
+		return
+	}
+
+	history, err := store.Client().LoginEvent().ListSince(ctx, event.Username, event.CreatedAt.Add(-historyWindow), historyLimit)
+	if err != nil {
+		log.Errorf("list login events fail: %s", err.Error())
+	} else {
+		event.Anomalies = detectAnomalies(event, history)
+	}
+
+	if err = store.Client().LoginEvent().Create(ctx, event, metav1.CreateOperateMeta{}); err != nil {
+		log.Errorf("record login event fail: %s", err.Error())
+	}
+
+	if len(event.Anomalies) == 0 {
+		return
+	}
+	log.Warnf("suspicious login of user %s from %s: %s", event.Username, event.IP, strings.Join(event.Anomalies, ","))
+	if err = raiseSuspiciousLogin(ctx, event); err != nil {
+		log.Errorf("raise suspicious login fail: %s", err.Error())
+	}
+}
+
+// detectAnomalies checks event with history before it, newest first.
+func detectAnomalies(event *v1.LoginEvent, history []*v1.LoginEvent) []string {
+	var anomalies []string
+
+	attempts, failures := 1, 0
+	if !event.Success {
+		failures++
+	}
+	seenSuccess, seenRange := false, false
+	for _, e := range history {
+		age := event.CreatedAt.Sub(e.CreatedAt)
+		if age <= frequencyWindow {
+			attempts++
+		}
+		if age <= failuresWindow && !e.Success {
+			failures++
+		}
+		if e.Success {
+			seenSuccess = true
+			if sameIPRange(e.IP, event.IP) {
+				seenRange = true
+			}
+		}
+	}
+
+	// The first login of user has nothing to compare with.
+	if event.Success && seenSuccess && !seenRange {
+		anomalies = append(anomalies, AnomalyNewIPRange)
+	}
+	if attempts > frequencyThreshold {
+		anomalies = append(anomalies, AnomalyHighFrequency)
+	}
+	if failures >= failuresThreshold {
+		anomalies = append(anomalies, AnomalyManyFailures)
+	}
+	return anomalies
+}
+
+func sameIPRange(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a == b
+	}
+	mask := net.CIDRMask(48, 128)
+	if ipA.To4() != nil && ipB.To4() != nil {
+		ipA, ipB = ipA.To4(), ipB.To4()
+		mask = net.CIDRMask(24, 32)
+	}
+	return ipA.Mask(mask).Equal(ipB.Mask(mask))
+}
+
+// raiseSuspiciousLogin pushes record to the list which pumper pops from, like authzserver does. Record is
+// typed, pumps only write it if their filter includes type suspicious-login.
+func raiseSuspiciousLogin(ctx context.Context, event *v1.LoginEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	info := &analytics.RecordInfo{
+		Type:       analytics.RecordTypeSuspiciousLogin,
+		Timestamp:  event.CreatedAt.Unix(),
+		ExpireAt:   event.CreatedAt.Add(suspiciousLoginTTL),
+		UserName:   event.Username,
+		Conclusion: strings.Join(event.Anomalies, ","),
+		Request:    string(data),
+	}
+	bytes, err := info.Marshal()
+	if err != nil {
+		return err
+	}
+	return conn.GetRedisClient().UniversalClient().RPush(ctx, pkg.AnalyticsKey, bytes).Err()
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	now := time.Now()
+	event := func(ip string, success bool, ago time.Duration) *v1.LoginEvent {
+		return &v1.LoginEvent{Username: "alice", IP: ip, Success: success, CreatedAt: now.Add(-ago)}
+	}
+	repeat := func(n int, e *v1.LoginEvent) []*v1.LoginEvent {
+		events := make([]*v1.LoginEvent, n)
+		for i := range events {
+			events[i] = e
+		}
+		return events
+	}
+
+	tests := []struct {
+		name    string
+		event   *v1.LoginEvent
+		history []*v1.LoginEvent
+		want    []string
+	}{
+		{
+			name:  "first login",
+			event: event("10.0.0.1", true, 0),
+		},
+		{
+			name:    "same ip range",
+			event:   event("10.0.0.200", true, 0),
+			history: []*v1.LoginEvent{event("10.0.0.1", true, time.Hour)},
+		},
+		{
+			name:    "new ip range",
+			event:   event("10.0.1.1", true, 0),
+			history: []*v1.LoginEvent{event("10.0.0.1", true, time.Hour)},
+			want:    []string{AnomalyNewIPRange},
+		},
+		{
+			name:    "range only seen in failures",
+			event:   event("10.0.1.1", true, 0),
+			history: []*v1.LoginEvent{event("10.0.1.2", false, time.Hour), event("10.0.0.1", true, 2*time.Hour)},
+			want:    []string{AnomalyNewIPRange},
+		},
+		{
+			name:    "failure from new range",
+			event:   event("10.0.1.1", false, 0),
+			history: []*v1.LoginEvent{event("10.0.0.1", true, time.Hour)},
+		},
+		{
+			name:    "high frequency",
+			event:   event("10.0.0.1", true, 0),
+			history: repeat(frequencyThreshold, event("10.0.0.1", true, time.Second)),
+			want:    []string{AnomalyHighFrequency},
+		},
+		{
+			name:    "frequency out of window",
+			event:   event("10.0.0.1", true, 0),
+			history: repeat(frequencyThreshold, event("10.0.0.1", true, 2*frequencyWindow)),
+		},
+		{
+			name:    "many failures",
+			event:   event("10.0.0.1", false, 0),
+			history: repeat(failuresThreshold-1, event("10.0.0.1", false, 5*time.Minute)),
+			want:    []string{AnomalyManyFailures},
+		},
+		{
+			name:    "failures out of window",
+			event:   event("10.0.0.1", false, 0),
+			history: repeat(failuresThreshold-1, event("10.0.0.1", false, 2*failuresWindow)),
+		},
+		{
+			name:  "all",
+			event: event("192.168.0.1", true, 0),
+			history: append(repeat(frequencyThreshold, event("10.0.0.1", false, time.Second)),
+				event("10.0.0.1", true, time.Hour)),
+			want: []string{AnomalyNewIPRange, AnomalyHighFrequency, AnomalyManyFailures},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectAnomalies(tt.event, tt.history); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameIPRange(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"10.0.0.1", "10.0.0.254", true},
+		{"10.0.0.1", "10.0.1.1", false},
+		{"2001:db8:1:a::1", "2001:db8:1:b::1", true},
+		{"2001:db8:1::1", "2001:db8:2::1", false},
+		{"10.0.0.1", "::ffff:10.0.0.2", true},
+		{"10.0.0.1", "2001:db8::1", false},
+		{"unknown", "unknown", true},
+		{"unknown", "10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := sameIPRange(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameIPRange(%s, %s) got %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRecordLogin(t *testing.T) {
+	ctx := context.Background()
+	factory := fake.NewFactory()
+	store.SetClient(factory)
+	defer store.SetClient(nil)
+
+	if err := factory.User().Create(ctx, &v1.User{Username: "alice"}, metav1.CreateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, username := range []string{"alice", "mallory"} {
+		recordLogin(ctx, &v1.LoginEvent{Username: username, IP: "10.0.0.1", CreatedAt: time.Now()})
+	}
+
+	for _, tt := range []struct {
+		username string
+		want     int64
+	}{{"alice", 1}, {"mallory", 0}} {
+		events, err := factory.LoginEvent().List(ctx, tt.username, metav1.ListOperateMeta{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if events.TotalCount != tt.want {
+			t.Errorf("events of %s got %d, want %d", tt.username, events.TotalCount, tt.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrMFARequired means password is right, client should exchange challenge token with code at /login/mfa.
+var ErrMFARequired = errors.New("mfa code required")
+
+const (
+	// mfaChallengeKey saves challenge token in gin context for Unauthorized response.
+	mfaChallengeKey = "mfaChallenge"
+
+	// mfaSetupKey marks in gin context that current user must enrol MFA.
+	mfaSetupKey = "mfaSetup"
+
+	// claimMFASetup marks token can only be used to enrol MFA.
+	claimMFASetup = "mfa_setup"
+
+	mfaPathPrefix = "/v1/users/:name/mfa"
+
+	mfaChallengeKeyPrefix = "iam.mfa-challenge."
+	mfaAttemptsKeyPrefix  = "iam.mfa-attempts."
+	mfaUsedKeyPrefix      = "iam.mfa-used."
+	mfaChallengeLength    = 32
+	mfaMaxAttempts        = 5
+
+	// mfaSkew allows code of one period before and after for clock drift.
+	mfaSkew = 1
+)
+
+var (
+	mfaOpts   = options.NewMFAOpts()
+	mfaOptsMu sync.RWMutex
+)
+
+// SetMFAOpts replaces global MFA config, should run in `create stage`.
+func SetMFAOpts(opts *options.MFAOpts) {
+	mfaOptsMu.Lock()
+	defer mfaOptsMu.Unlock()
+	mfaOpts = opts
+}
+
+// GetMFAOpts returns global MFA config in use.
+func GetMFAOpts() *options.MFAOpts {
+	mfaOptsMu.RLock()
+	defer mfaOptsMu.RUnlock()
+	return mfaOpts
+}
+
+// MFARequired tells whether user must use MFA, by global or per-user policy.
+func MFARequired(user *v1.User) bool {
+	return GetMFAOpts().Required || user.MFARequired
+}
+
+// VerifyTOTP checks TOTP code with secret of user, recovery code is not accepted.
+func VerifyTOTP(user *v1.User, code string) bool {
+	return auth.ValidateTOTP(user.MFASecret, strings.TrimSpace(code), time.Now(), mfaSkew)
+}
+
+// VerifyMFACode checks TOTP code or recovery code, recovery code is removed from user if matched,
+// so caller must save user when it returns true.
+func VerifyMFACode(user *v1.User, code string) bool {
+	if VerifyTOTP(user, code) {
+		return true
+	}
+	return user.UseRecoveryCode(strings.TrimSpace(code))
+}
+
+// VerifyMFACodeLimited is VerifyMFACode for logins, such as /login/mfa and oauth authorize. Wrong codes are
+// counted per user rather than per challenge, because each password login issues a new challenge. After
+// mfaMaxAttempts all codes are refused until ChallengeTTL passes since the last wrong one. A TOTP code
+// accepted once is refused while it's still valid, so that a code seen by others can't be replayed.
+func VerifyMFACodeLimited(ctx context.Context, user *v1.User, code string) bool {
+	client := conn.GetRedisClient().UniversalClient()
+	key := mfaAttemptsKeyPrefix + user.Username
+
+	n, err := client.Get(ctx, key).Int64()
+	if err != nil && err != redis.Nil {
+		log.Errorf("get mfa attempts fail: %s", err.Error())
+		return false
+	}
+	if n >= mfaMaxAttempts {
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+	ok := VerifyTOTP(user, code) && claimTOTP(ctx, user.Username, code) || user.UseRecoveryCode(code)
+	if ok {
+		client.Del(ctx, key)
+		return true
+	}
+	countMFAFailure(ctx, key)
+	return false
+}
+
+// claimTOTP marks code of user used until it expires, false means it has been used or redis fails.
+func claimTOTP(ctx context.Context, username, code string) bool {
+	ttl := time.Duration(2*mfaSkew+1) * auth.TOTPPeriod
+	ok, err := conn.GetRedisClient().UniversalClient().SetNX(ctx, mfaUsedKeyPrefix+username+"."+code, 1, ttl).Result()
+	if err != nil {
+		log.Errorf("claim mfa code fail: %s", err.Error())
+		return false
+	}
+	return ok
+}
+
+type mfaLogin struct {
+	MFAToken string `form:"mfaToken" json:"mfaToken" binding:"required"`
+	Code     string `form:"code" json:"code" binding:"required"`
+}
+
+// mfaAuthenticator exchanges challenge token and code for user at /login/mfa.
+func mfaAuthenticator(c *gin.Context) (interface{}, error) {
+	var ln mfaLogin
+	if err := c.ShouldBind(&ln); err != nil {
+		return nil, jwt.ErrMissingLoginValues
+	}
+
+	username, err := lookupMFAChallenge(c, ln.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	if username == "" {
+		return nil, jwt.ErrFailedAuthentication
+	}
+
+	var user *v1.User
+	user, err = store.Client().User().Get(c, username, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MFAEnabled || !VerifyMFACodeLimited(c, user, ln.Code) {
+		failMFAChallenge(c, ln.MFAToken)
+		RecordLogin(c, username, v1.LoginSchemeMFA, jwt.ErrFailedAuthentication)
+		return nil, jwt.ErrFailedAuthentication
+	}
+
+	// Challenge is single-use.
+	var ok bool
+	if ok, err = consumeMFAChallenge(c, ln.MFAToken); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, jwt.ErrFailedAuthentication
+	}
+
+	if PasswordExpired(user) {
+		c.Set(passwordExpiredKey, true)
+	}
+
+	user.LoginAt = time.Now()
+	if err = store.Client().User().Update(c, user, metav1.UpdateOperateMeta{}); err != nil {
+		return nil, err
+	}
+	RecordLogin(c, user.Username, v1.LoginSchemeMFA, nil)
+
+	return user, nil
+}
+
+// issueMFAChallenge saves hash of a new challenge token with username in redis.
+func issueMFAChallenge(ctx context.Context, username string) (string, error) {
+	token, err := idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, mfaChallengeLength)
+	if err != nil {
+		return "", err
+	}
+
+	client := conn.GetRedisClient().UniversalClient()
+	if err = client.Set(ctx, mfaChallengeRedisKey(token), username, GetMFAOpts().ChallengeTTL).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// lookupMFAChallenge returns username of token, empty username means token is invalid or expired.
+func lookupMFAChallenge(ctx context.Context, token string) (string, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	username, err := client.Get(ctx, mfaChallengeRedisKey(token)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return username, err
+}
+
+// consumeMFAChallenge deletes token, false means token has been used by others in the meantime.
+func consumeMFAChallenge(ctx context.Context, token string) (bool, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	key := mfaChallengeRedisKey(token)
+	n, err := client.Del(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	client.Del(ctx, key+".attempts")
+	return n == 1, nil
+}
+
+// failMFAChallenge counts wrong codes, and revokes token after too many attempts to stop guessing.
+func failMFAChallenge(ctx context.Context, token string) {
+	key := mfaChallengeRedisKey(token)
+	if countMFAFailure(ctx, key+".attempts") >= mfaMaxAttempts {
+		conn.GetRedisClient().UniversalClient().Del(ctx, key, key+".attempts")
+	}
+}
+
+// countMFAFailure increases counter of wrong codes living for ChallengeTTL, it returns 0 if redis fails.
+func countMFAFailure(ctx context.Context, key string) int64 {
+	var incr *redis.IntCmd
+	_, err := conn.GetRedisClient().UniversalClient().TxPipelined(ctx, func(p redis.Pipeliner) error {
+		incr = p.Incr(ctx, key)
+		p.Expire(ctx, key, GetMFAOpts().ChallengeTTL)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("count mfa attempts fail: %s", err.Error())
+		return 0
+	}
+	return incr.Val()
+}
+
+func mfaChallengeRedisKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return mfaChallengeKeyPrefix + hex.EncodeToString(sum[:])
+}
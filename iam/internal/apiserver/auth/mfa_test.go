@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/test/redistest"
+	"testing"
+	"time"
+)
+
+func TestVerifyMFACodeLimited(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &v1.User{Username: "alice", MFAEnabled: true, MFASecret: secret}
+	// Codes of adjacent periods are accepted too, an accepted one can't be used again.
+	now := time.Now()
+	prev, _ := auth.TOTP(secret, now.Add(-auth.TOTPPeriod))
+	code, _ := auth.TOTP(secret, now)
+	next, _ := auth.TOTP(secret, now.Add(auth.TOTPPeriod))
+	var wrong string
+	for _, w := range []string{"000000", "111111", "222222", "333333"} {
+		if w != prev && w != code && w != next {
+			wrong = w
+			break
+		}
+	}
+
+	if !VerifyMFACodeLimited(ctx, user, prev) {
+		t.Fatalf("right code should pass")
+	}
+
+	// Success resets counter.
+	for i := 0; i < mfaMaxAttempts-1; i++ {
+		VerifyMFACodeLimited(ctx, user, wrong)
+	}
+	if !VerifyMFACodeLimited(ctx, user, code) {
+		t.Fatalf("right code should pass before limit")
+	}
+
+	for i := 0; i < mfaMaxAttempts; i++ {
+		if VerifyMFACodeLimited(ctx, user, wrong) {
+			t.Fatalf("wrong code should fail")
+		}
+	}
+	if VerifyMFACodeLimited(ctx, user, next) {
+		t.Errorf("right code must be refused after too many attempts")
+	}
+	if !VerifyMFACodeLimited(ctx, &v1.User{Username: "bob", MFASecret: secret}, code) {
+		t.Errorf("attempts of one user should not lock others")
+	}
+}
+
+func TestVerifyMFACodeLimitedReplay(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &v1.User{Username: "alice", MFAEnabled: true, MFASecret: secret}
+	code, _ := auth.TOTP(secret, time.Now())
+
+	if !VerifyMFACodeLimited(ctx, user, code) {
+		t.Fatalf("right code should pass")
+	}
+	if VerifyMFACodeLimited(ctx, user, code) {
+		t.Errorf("used code must be refused")
+	}
+	if n, _ := conn.GetRedisClient().UniversalClient().Get(ctx, mfaAttemptsKeyPrefix+user.Username).Int(); n != 1 {
+		t.Errorf("attempts got %d, want 1", n)
+	}
+	if !VerifyMFACodeLimited(ctx, &v1.User{Username: "bob", MFASecret: secret}, code) {
+		t.Errorf("code used by one user should not be refused for others")
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// tokenRevokedKey marks in gin context that token has been revoked.
+	tokenRevokedKey = "tokenRevoked"
+
+	revokedTokenKeyPrefix = "iam.jwt-revoked."
+	revokedUserKeyPrefix  = "iam.jwt-revoked-user."
+)
+
+// RevokeToken puts jti into revocation list until all tokens refreshed from it expire.
+func RevokeToken(ctx context.Context, jti string, exp time.Time) error {
+	client := conn.GetRedisClient().UniversalClient()
+	return client.Set(ctx, revokedTokenKeyPrefix+jti, 1, revokeTTL(time.Until(exp))).Err()
+}
+
+// userRevocationTTL is the lifetime of credentials longer than jwt, such as oauth refresh token.
+var userRevocationTTL time.Duration
+
+// KeepUserRevocationFor makes RevokeUser cover credentials living longer than jwt, which check UserRevoked.
+func KeepUserRevocationFor(ttl time.Duration) {
+	if ttl > userRevocationTTL {
+		userRevocationTTL = ttl
+	}
+}
+
+// RevokeUser revokes all tokens issued to user before now, such as after password changed.
+func RevokeUser(ctx context.Context, username string) error {
+	client := conn.GetRedisClient().UniversalClient()
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	ttl := revokeTTL(jwtAuth.Timeout)
+	if userRevocationTTL > ttl {
+		ttl = userRevocationTTL
+	}
+	return client.Set(ctx, revokedUserKeyPrefix+username, now, ttl).Err()
+}
+
+// revokeTTL covers token refreshed in MaxRefresh, because refreshed token keeps jti and iat.
+func revokeTTL(ttl time.Duration) time.Duration {
+	if ttl < jwtAuth.Timeout {
+		ttl = jwtAuth.Timeout
+	}
+	return ttl + jwtAuth.MaxRefresh
+}
+
+// tokenRevoked checks claims with revocation list, it returns true when redis fails,
+// so that a revoked token can't be used during redis outage.
+func tokenRevoked(ctx context.Context, claims jwt.MapClaims) bool {
+	client := conn.GetRedisClient().UniversalClient()
+
+	jti, _ := claims[claimJTI].(string)
+	if jti == "" {
+		return true
+	}
+	n, err := client.Exists(ctx, revokedTokenKeyPrefix+jti).Result()
+	if err != nil {
+		log.Errorf("check revoked token fail: %s", err.Error())
+		return true
+	}
+	if n > 0 {
+		return true
+	}
+
+	username, _ := claims[claimUsername].(string)
+	iat, _ := claims[claimIssuedAt].(float64)
+	revoked, err := UserRevoked(ctx, username, time.UnixMilli(int64(iat*1000)))
+	if err != nil {
+		log.Errorf("check revoked user fail: %s", err.Error())
+		return true
+	}
+	return revoked
+}
+
+// UserRevoked tells whether credentials issued to user at issuedAt are revoked by RevokeUser.
+func UserRevoked(ctx context.Context, username string, issuedAt time.Time) (bool, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	revokedAt, err := client.Get(ctx, revokedUserKeyPrefix+username).Int64()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return issuedAt.UnixMilli() <= revokedAt, nil
+}
+
+// RefreshHandler refuses to refresh revoked token, and signs new token by generateToken.
+func RefreshHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := jwtAuth.CheckIfTokenExpire(c)
+		if err != nil {
+			unauthorized(c, http.StatusUnauthorized, jwtAuth.HTTPStatusMessageFunc(err, c))
+			return
+		}
+		// CheckIfTokenExpire returns claims of golang-jwt type.
+		if !apiserverToken(jwt.MapClaims(claims)) {
+			unauthorized(c, http.StatusUnauthorized, "token can't be refreshed.")
+			return
+		}
+		if tokenRevoked(c, jwt.MapClaims(claims)) {
+			unauthorized(c, http.StatusUnauthorized, "token has been revoked.")
+			return
+		}
+
+		token, expire, err := generateToken(claims)
+		if err != nil {
+			log.L(c).Errorf("generate token fail: %s", err.Error())
+			unauthorized(c, http.StatusUnauthorized, jwtAuth.HTTPStatusMessageFunc(jwt.ErrFailedTokenCreation, c))
+			return
+		}
+
+		if err = refreshSession(c, claims, expire); err != nil {
+			log.L(c).Errorf("refresh session fail: %s", err.Error())
+		}
+
+		jwtAuth.RefreshResponse(c, http.StatusOK, token, expire)
+	}
+}
+
+func logout() func(c *gin.Context, code int) {
+	return func(c *gin.Context, code int) {
+		claims, err := jwtAuth.GetClaimsFromJWT(c)
+		if err != nil {
+			unauthorized(c, http.StatusUnauthorized, jwtAuth.HTTPStatusMessageFunc(err, c))
+			return
+		}
+
+		jti, _ := claims[claimJTI].(string)
+		exp, _ := claims["exp"].(float64)
+		if err = RevokeToken(c, jti, time.Unix(int64(exp), 0)); err != nil {
+			log.L(c).Errorf("revoke token fail: %s", err.Error())
+			jwtAuth.Unauthorized(c, http.StatusInternalServerError, "revoke token fail.")
+			return
+		}
+		username, _ := claims[claimUsername].(string)
+		if err = removeSession(c, username, jti); err != nil {
+			log.L(c).Errorf("remove session fail: %s", err.Error())
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"code": http.StatusOK,
+		})
+	}
+}
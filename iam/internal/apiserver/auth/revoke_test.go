@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"istomyang.github.com/like-iam/test/redistest"
+	"testing"
+	"time"
+)
+
+func TestRevokeUser(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	before := time.Now().Add(-time.Second)
+	if revoked, err := UserRevoked(ctx, "alice", before); err != nil || revoked {
+		t.Fatalf("user not revoked yet, got %v, %v", revoked, err)
+	}
+
+	if err := RevokeUser(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, _ := UserRevoked(ctx, "alice", before); !revoked {
+		t.Errorf("token issued before revocation should be revoked")
+	}
+	if revoked, _ := UserRevoked(ctx, "alice", time.Now().Add(time.Second)); revoked {
+		t.Errorf("token issued after revocation should be valid")
+	}
+	if revoked, _ := UserRevoked(ctx, "bob", before); revoked {
+		t.Errorf("other users should not be revoked")
+	}
+}
+
+func TestRevokeUser_KeepsRevocationForRefresh(t *testing.T) {
+	s := redistest.Use()
+
+	if err := RevokeUser(context.Background(), "alice"); err != nil {
+		t.Fatal(err)
+	}
+	// Token issued just before revocation can be refreshed until MaxRefresh passes.
+	if ttl := s.TTL(revokedUserKeyPrefix + "alice"); ttl <= jwtAuth.Timeout {
+		t.Errorf("revocation should outlive token and refresh, got ttl %s", ttl)
+	}
+}
+
+func TestTokenRevoked(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+	iat := float64(time.Now().Add(-time.Second).UnixMilli()) / 1000
+
+	claims := jwt.MapClaims{claimJTI: "token-1", claimUsername: "alice", claimIssuedAt: iat}
+	if tokenRevoked(ctx, claims) {
+		t.Fatalf("fresh token should be valid")
+	}
+
+	if err := RevokeToken(ctx, "token-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if !tokenRevoked(ctx, claims) {
+		t.Errorf("token should be revoked by jti")
+	}
+
+	other := jwt.MapClaims{claimJTI: "token-2", claimUsername: "alice", claimIssuedAt: iat}
+	if tokenRevoked(ctx, other) {
+		t.Errorf("revoking one token should not affect others")
+	}
+	if err := RevokeUser(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if !tokenRevoked(ctx, other) {
+		t.Errorf("token should be revoked by user")
+	}
+
+	if !tokenRevoked(ctx, jwt.MapClaims{claimUsername: "alice"}) {
+		t.Errorf("token without jti should be refused")
+	}
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+)
+
+// checkServiceAccount fails if principal is a service account which is deleted or disabled,
+// other principals are users and pass.
+func checkServiceAccount(principal string) error {
+	name, ok := v1.ServiceAccountName(principal)
+	if !ok {
+		return nil
+	}
+	account, err := store.Client().ServiceAccount().Get(context.TODO(), name, metav1.GetOperateMeta{})
+	if err != nil {
+		return err
+	}
+	if account.Disabled {
+		return errors.WithCode(codes.ErrServiceAccountDisabled, "service account %s is disabled.", name)
+	}
+	return nil
+}
+
+// checkUserDisabled fails if user is disabled, users not stored such as ones only in directory pass.
+func checkUserDisabled(username string) error {
+	if _, ok := v1.ServiceAccountName(username); ok {
+		return nil
+	}
+	user, err := store.Client().User().Get(context.TODO(), username, metav1.GetOperateMeta{})
+	if err == nil && user != nil && user.Disabled {
+		return errors.WithCode(codes.ErrUserDisabled, "user %s is disabled.", username)
+	}
+	return nil
+}
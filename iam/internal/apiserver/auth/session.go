@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sessionKeyPrefix      = "iam.session."
+	userSessionsKeyPrefix = "iam.user-sessions."
+
+	sessionFieldUsername   = "username"
+	sessionFieldDevice     = "device"
+	sessionFieldIP         = "ip"
+	sessionFieldUserAgent  = "userAgent"
+	sessionFieldCreatedAt  = "createdAt"
+	sessionFieldLastUsedAt = "lastUsedAt"
+	sessionFieldExpiresAt  = "expiresAt"
+)
+
+// recordSession saves session of token issued by login, it lives as long as token can be refreshed.
+func recordSession(c *gin.Context, claims map[string]interface{}, expire time.Time) error {
+	jti, _ := claims[claimJTI].(string)
+	username, _ := claims[claimUsername].(string)
+	if jti == "" || username == "" {
+		return nil
+	}
+
+	// Created time is the same as iat, so that RevokeUser covers session exactly.
+	createdAt := time.Now()
+	if iat, ok := claims[claimIssuedAt].(float64); ok {
+		createdAt = time.UnixMilli(int64(iat * 1000))
+	}
+	now := strconv.FormatInt(createdAt.UnixMilli(), 10)
+	ttl := revokeTTL(jwtAuth.Timeout)
+
+	client := conn.GetRedisClient().UniversalClient()
+	_, err := client.TxPipelined(c, func(p redis.Pipeliner) error {
+		p.HSet(c, sessionKeyPrefix+jti, map[string]interface{}{
+			sessionFieldUsername:   username,
+			sessionFieldDevice:     deviceOf(c.Request.UserAgent()),
+			sessionFieldIP:         c.ClientIP(),
+			sessionFieldUserAgent:  c.Request.UserAgent(),
+			sessionFieldCreatedAt:  now,
+			sessionFieldLastUsedAt: now,
+			sessionFieldExpiresAt:  strconv.FormatInt(expire.UnixMilli(), 10),
+		})
+		p.Expire(c, sessionKeyPrefix+jti, ttl)
+		p.ZAdd(c, userSessionsKeyPrefix+username, &redis.Z{Score: float64(createdAt.UnixMilli()), Member: jti})
+		p.Expire(c, userSessionsKeyPrefix+username, ttl)
+		return nil
+	})
+	return err
+}
+
+// refreshSession extends session to the new token, session not recorded is ignored.
+func refreshSession(c *gin.Context, claims map[string]interface{}, expire time.Time) error {
+	jti, _ := claims[claimJTI].(string)
+	username, _ := claims[claimUsername].(string)
+	ttl := revokeTTL(jwtAuth.Timeout)
+
+	client := conn.GetRedisClient().UniversalClient()
+	n, err := client.Exists(c, sessionKeyPrefix+jti).Result()
+	if err != nil || n == 0 {
+		return err
+	}
+	_, err = client.TxPipelined(c, func(p redis.Pipeliner) error {
+		p.HSet(c, sessionKeyPrefix+jti,
+			sessionFieldLastUsedAt, strconv.FormatInt(time.Now().UnixMilli(), 10),
+			sessionFieldExpiresAt, strconv.FormatInt(expire.UnixMilli(), 10))
+		p.Expire(c, sessionKeyPrefix+jti, ttl)
+		p.Expire(c, userSessionsKeyPrefix+username, ttl)
+		return nil
+	})
+	return err
+}
+
+// touchScript sets field only if session exists, HSET on a missing key would create session without expiry.
+var touchScript = redis.NewScript(`if redis.call("EXISTS", KEYS[1]) == 1 then return redis.call("HSET", KEYS[1], ARGV[1], ARGV[2]) end return 0`)
+
+// touchSession updates last used time of session, failure doesn't stop request.
+func touchSession(ctx context.Context, jti string) {
+	client := conn.GetRedisClient().UniversalClient()
+	if err := touchScript.Run(ctx, client, []string{sessionKeyPrefix + jti},
+		sessionFieldLastUsedAt, strconv.FormatInt(time.Now().UnixMilli(), 10)).Err(); err != nil && err != redis.Nil {
+		log.Errorf("touch session fail: %s", err.Error())
+	}
+}
+
+// removeSession deletes record of session, it doesn't revoke tokens.
+func removeSession(ctx context.Context, username, jti string) error {
+	client := conn.GetRedisClient().UniversalClient()
+	_, err := client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, sessionKeyPrefix+jti)
+		p.ZRem(ctx, userSessionsKeyPrefix+username, jti)
+		return nil
+	})
+	return err
+}
+
+// ListSessions returns active sessions of user, newest first. Sessions revoked by RevokeUser are cleaned.
+func ListSessions(ctx context.Context, username string) ([]*v1.Session, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	ids, err := client.ZRange(ctx, userSessionsKeyPrefix+username, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*v1.Session
+	for _, id := range ids {
+		s, err := getSession(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil || s.Username != username {
+			_ = client.ZRem(ctx, userSessionsKeyPrefix+username, id).Err()
+			continue
+		}
+		revoked, err := UserRevoked(ctx, username, s.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			_ = removeSession(ctx, username, id)
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession revokes tokens of session, JWT scheme rejects them since next request.
+// It returns false if user has no such session.
+func RevokeSession(ctx context.Context, username, id string) (bool, error) {
+	s, err := getSession(ctx, id)
+	if err != nil || s == nil || s.Username != username {
+		return false, err
+	}
+	if err = RevokeToken(ctx, id, s.ExpiresAt); err != nil {
+		return false, err
+	}
+	return true, removeSession(ctx, username, id)
+}
+
+// CurrentSessionID returns jti of token sending request, empty if not authenticated by JWT.
+func CurrentSessionID(c *gin.Context) string {
+	jti, _ := jwt.ExtractClaims(c)[claimJTI].(string)
+	return jti
+}
+
+func getSession(ctx context.Context, id string) (*v1.Session, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	fields, err := client.HGetAll(ctx, sessionKeyPrefix+id).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return &v1.Session{
+		ID:         id,
+		Username:   fields[sessionFieldUsername],
+		Device:     fields[sessionFieldDevice],
+		IP:         fields[sessionFieldIP],
+		UserAgent:  fields[sessionFieldUserAgent],
+		CreatedAt:  unixMilli(fields[sessionFieldCreatedAt]),
+		LastUsedAt: unixMilli(fields[sessionFieldLastUsedAt]),
+		ExpiresAt:  unixMilli(fields[sessionFieldExpiresAt]),
+	}, nil
+}
+
+func unixMilli(s string) time.Time {
+	ms, _ := strconv.ParseInt(s, 10, 64)
+	return time.UnixMilli(ms)
+}
+
+// deviceOf roughly tells kind of device from user agent.
+func deviceOf(ua string) string {
+	l := strings.ToLower(ua)
+	switch {
+	case l == "":
+		return "unknown"
+	case strings.Contains(l, "ipad") || strings.Contains(l, "tablet"):
+		return "tablet"
+	case strings.Contains(l, "mobile") || strings.Contains(l, "android") || strings.Contains(l, "iphone"):
+		return "mobile"
+	case strings.Contains(l, "mozilla"):
+		return "desktop"
+	default:
+		return "cli"
+	}
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newSession records a session like LoginHandler and returns its id.
+func newSession(t *testing.T, username string, issuedAt time.Time) string {
+	t.Helper()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/login", nil)
+	c.Request.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0) Mobile")
+
+	jti := username + "-" + issuedAt.Format(time.RFC3339Nano)
+	claims := map[string]interface{}{
+		claimJTI:      jti,
+		claimUsername: username,
+		claimIssuedAt: float64(issuedAt.UnixMilli()) / 1000,
+	}
+	if err := recordSession(c, claims, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	return jti
+}
+
+func TestListSessions(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	older := newSession(t, "alice", time.Now().Add(-2*time.Minute))
+	newer := newSession(t, "alice", time.Now().Add(-time.Minute))
+	newSession(t, "bob", time.Now())
+
+	sessions, err := ListSessions(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != newer || sessions[1].ID != older {
+		t.Fatalf("want sessions of alice newest first, got %+v", sessions)
+	}
+	if sessions[0].Device != "mobile" || sessions[0].Username != "alice" {
+		t.Errorf("unexpected session: %+v", sessions[0])
+	}
+
+	if err = RevokeUser(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, _ = ListSessions(ctx, "alice"); len(sessions) != 0 {
+		t.Errorf("sessions revoked by user should be cleaned, got %d", len(sessions))
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	issuedAt := time.Now().Add(-time.Second)
+	id := newSession(t, "alice", issuedAt)
+
+	if found, err := RevokeSession(ctx, "bob", id); err != nil || found {
+		t.Fatalf("session of others must not be revoked, got %v, %v", found, err)
+	}
+	if found, err := RevokeSession(ctx, "alice", "missing"); err != nil || found {
+		t.Fatalf("missing session should not be found, got %v, %v", found, err)
+	}
+
+	if found, err := RevokeSession(ctx, "alice", id); err != nil || !found {
+		t.Fatalf("revoke session fail: %v, %v", found, err)
+	}
+	claims := jwt.MapClaims{claimJTI: id, claimUsername: "alice", claimIssuedAt: float64(issuedAt.UnixMilli()) / 1000}
+	if !tokenRevoked(ctx, claims) {
+		t.Errorf("token of revoked session should be rejected")
+	}
+	if sessions, _ := ListSessions(ctx, "alice"); len(sessions) != 0 {
+		t.Errorf("revoked session should be removed, got %d", len(sessions))
+	}
+}
+
+func TestDeviceOf(t *testing.T) {
+	tests := map[string]string{
+		"": "unknown",
+		"Mozilla/5.0 (iPad; CPU OS 16_0 like Mac OS X)":              "tablet",
+		"Mozilla/5.0 (Linux; Android 13) Mobile Safari/537.36":       "mobile",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/118.0.0.0": "desktop",
+		"curl/8.0.1": "cli",
+	}
+	for ua, want := range tests {
+		if got := deviceOf(ua); got != want {
+			t.Errorf("deviceOf(%q) got %s, want %s", ua, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	jwt "github.com/appleboy/gin-jwt/v2"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+)
+
+// AuthenticateToken verifies token issued by login as JwtScheme does, and returns username in it.
+// It's used by callers out of gin such as grpc. Restricted token, which is issued for expired password
+// or mfa setup, is refused, because it can only be used on few http paths.
+func AuthenticateToken(ctx context.Context, token string) (string, error) {
+	t, err := jwtAuth.ParseTokenString(token)
+	if err != nil {
+		return "", err
+	}
+	claims, ok := t.Claims.(jwtv4.MapClaims)
+	if !ok || !t.Valid {
+		return "", jwt.ErrInvalidAuthHeader
+	}
+
+	if tokenRevoked(ctx, jwt.MapClaims(claims)) {
+		return "", jwt.ErrForbidden
+	}
+	if expired, _ := claims[claimPasswordExpired].(bool); expired {
+		return "", ErrPasswordExpired
+	}
+	if setup, _ := claims[claimMFASetup].(bool); setup {
+		return "", ErrMFARequired
+	}
+
+	username, _ := claims[claimUsername].(string)
+	if username == "" {
+		return "", jwt.ErrFailedAuthentication
+	}
+	if jti, _ := claims[claimJTI].(string); jti != "" {
+		touchSession(ctx, jti)
+	}
+	return username, nil
+}
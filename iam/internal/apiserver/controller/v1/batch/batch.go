@@ -0,0 +1,59 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+)
+
+type Controller struct {
+	svc service.Service
+}
+
+func NewBatchController(store store.Factory) *Controller {
+	return &Controller{svc: service.NewService(store)}
+}
+
+// operator runs operations of a batch as caller, with permissions of the single api.
+type operator struct {
+	username string
+	admin    bool
+
+	// revoked saves users deleted or disabled, whose tokens are revoked after batch commits.
+	revoked []string
+}
+
+func (o *operator) run(ctx context.Context, svc service.Service, op *v1.BatchOperation) (interface{}, error) {
+	if op.Method != v1.BatchCreate && op.Name == "" {
+		return nil, errors.WithCode(errors.ErrValidation, "name is required to %s %s.", op.Method, op.Resource)
+	}
+
+	switch op.Resource {
+	case v1.BatchUsers:
+		return o.user(ctx, svc, op)
+	case v1.BatchSecrets:
+		return o.secret(ctx, svc, op)
+	case v1.BatchPolicies:
+		return o.policy(ctx, svc, op)
+	}
+	return nil, errors.WithCode(errors.ErrValidation, "unknown resource %s.", op.Resource)
+}
+
+// decode decodes body of operation into v.
+func decode(op *v1.BatchOperation, v interface{}) error {
+	if len(op.Body) == 0 {
+		return errors.WithCode(errors.ErrBind, "body is required to %s %s.", op.Method, op.Resource)
+	}
+	if err := json.Unmarshal(op.Body, v); err != nil {
+		return errors.WithCode(errors.ErrBind, "%s", err.Error())
+	}
+	return nil
+}
+
+func failed(err error) v1.BatchResult {
+	coder := errors.AsCode(err)
+	return v1.BatchResult{Status: v1.BatchFailed, Code: coder.Code(), Message: coder.Message()}
+}
@@ -0,0 +1,87 @@
+package batch
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Execute runs operations in order in one transaction. In atomic mode, the first failed operation rolls back
+// all, and the rest are skipped. In continue mode, failed operation rolls back only itself. Succeeded operations
+// are reported rolled back if the batch isn't committed.
+func (c *Controller) Execute(ctx *gin.Context) {
+	log.L(ctx).Info("execute batch operations.")
+
+	var r v1.BatchRequest
+
+	if err := ctx.ShouldBindJSON(&r); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&r); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	username := ctx.GetString(middleware.UserNameKey)
+	o := &operator{username: username, admin: c.svc.Users().IsAdmin(ctx, username)}
+
+	results := make([]v1.BatchResult, len(r.Operations))
+	for i := range results {
+		results[i].Status = v1.BatchSkipped
+	}
+
+	err := c.svc.Transaction(ctx, func(tx service.Service) error {
+		for i := range r.Operations {
+			op := &r.Operations[i]
+
+			var data interface{}
+			var err error
+			if r.Mode == v1.BatchContinue {
+				err = tx.Transaction(ctx, func(tx service.Service) error {
+					var e error
+					data, e = o.run(ctx, tx, op)
+					return e
+				})
+			} else {
+				data, err = o.run(ctx, tx, op)
+			}
+
+			if err != nil {
+				log.L(ctx).Warnf("batch operation %d %s %s fail: %s", i, op.Method, op.Resource, err.Error())
+				results[i] = failed(err)
+				if r.Mode != v1.BatchContinue {
+					return err
+				}
+				continue
+			}
+			results[i] = v1.BatchResult{Status: v1.BatchSucceeded, Data: data}
+		}
+		return nil
+	})
+	if err != nil {
+		log.L(ctx).Warnf("batch is rolled back: %s", err.Error())
+		for i := range results {
+			if results[i].Status == v1.BatchSucceeded {
+				results[i] = v1.BatchResult{Status: v1.BatchRolledBack}
+			}
+		}
+		web.WriteResponse(ctx, nil, &v1.BatchResponse{Committed: false, Results: results})
+		return
+	}
+
+	for _, name := range o.revoked {
+		if err = auth2.RevokeUser(ctx, name); err != nil {
+			log.L(ctx).Errorf("revoke tokens of user %s fail: %s", name, err.Error())
+		}
+	}
+
+	web.WriteResponse(ctx, nil, &v1.BatchResponse{Committed: true, Results: results})
+}
@@ -0,0 +1,180 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	redistest.Use()
+
+	opts := options.NewJwtOpts()
+	opts.Key = "test-secret"
+	auth2.GetJwtSchemeOr(opts)
+
+	os.Exit(m.Run())
+}
+
+func newController(t *testing.T) *Controller {
+	c := NewBatchController(fake.NewFactory())
+	for _, user := range []*v1.User{
+		{Username: "root", Email: "root@example.com", IsAdmin: "true"},
+		{Username: "bob", Email: "bob@example.com"},
+	} {
+		if err := c.svc.Users().Create(context.Background(), user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func execute(t *testing.T, c *Controller, r *v1.BatchRequest) *v1.BatchResponse {
+	body, _ := json.Marshal(r)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("POST", "/v1/batch", bytes.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Set(middleware.UserNameKey, "root")
+
+	c.Execute(ctx)
+
+	var resp v1.BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+	return &resp
+}
+
+func operation(method, name string, body interface{}) v1.BatchOperation {
+	data, _ := json.Marshal(body)
+	return v1.BatchOperation{Method: method, Resource: v1.BatchUsers, Name: name, Body: data}
+}
+
+// revision returns revision after events so far, which are in an earlier millisecond.
+func revision(t *testing.T) string {
+	time.Sleep(2 * time.Millisecond)
+	r, err := watch.Revision(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// changes returns users changed since revision, in order of events.
+func changes(t *testing.T, since string) []string {
+	cs, err := watch.Since(context.Background(), watch.ResourceUsers, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users []string
+	for _, c := range cs {
+		var user v1.User
+		_ = json.Unmarshal(c.Object, &user)
+		users = append(users, c.Type+" "+user.Username)
+	}
+	return users
+}
+
+func statuses(results []v1.BatchResult) []string {
+	var s []string
+	for _, r := range results {
+		s = append(s, r.Status)
+	}
+	return s
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestExecute_ContinueRollsBackOnlyFailed(t *testing.T) {
+	redistest.Use()
+	c := newController(t)
+	since := revision(t)
+	issuedAt := time.Now().Add(-time.Second)
+
+	disabled := true
+	resp := execute(t, c, &v1.BatchRequest{Mode: v1.BatchContinue, Operations: []v1.BatchOperation{
+		operation(v1.BatchCreate, "", &v1.User{Username: "alice", Email: "alice@example.com", Password: "Alice@2024!"}),
+		// bob exists, it fails and is rolled back to savepoint.
+		operation(v1.BatchCreate, "", &v1.User{Username: "bob", Email: "bob@example.com", Password: "Bob@2024!x"}),
+		operation(v1.BatchUpdate, "bob", &v1.UserPatch{Disabled: &disabled}),
+	}})
+
+	if !resp.Committed {
+		t.Fatalf("batch in continue mode should commit")
+	}
+	want := []string{v1.BatchSucceeded, v1.BatchFailed, v1.BatchSucceeded}
+	if got := statuses(resp.Results); !equal(got, want) {
+		t.Errorf("results got %v, want %v", got, want)
+	}
+
+	bob, err := c.svc.Users().Get(context.Background(), "bob", metav1.GetOperateMeta{})
+	if err != nil || !bob.Disabled {
+		t.Errorf("bob should be disabled, got %v, %v", bob, err)
+	}
+	if got, want := changes(t, since), []string{"ADDED alice", "MODIFIED bob"}; !equal(got, want) {
+		t.Errorf("events got %v, want %v", got, want)
+	}
+	if revoked, _ := auth2.UserRevoked(context.Background(), "bob", issuedAt); !revoked {
+		t.Errorf("tokens of disabled user should be revoked after commit")
+	}
+}
+
+func TestExecute_AtomicRollsBackAll(t *testing.T) {
+	redistest.Use()
+	c := newController(t)
+	since := revision(t)
+	issuedAt := time.Now().Add(-time.Second)
+
+	disabled := true
+	resp := execute(t, c, &v1.BatchRequest{Mode: v1.BatchAtomic, Operations: []v1.BatchOperation{
+		operation(v1.BatchCreate, "", &v1.User{Username: "alice", Email: "alice@example.com", Password: "Alice@2024!"}),
+		operation(v1.BatchUpdate, "bob", &v1.UserPatch{Disabled: &disabled}),
+		operation(v1.BatchUpdate, "nobody", &v1.UserPatch{Disabled: &disabled}),
+		operation(v1.BatchDelete, "bob", nil),
+	}})
+
+	if resp.Committed {
+		t.Fatalf("batch in atomic mode should roll back")
+	}
+	want := []string{v1.BatchRolledBack, v1.BatchRolledBack, v1.BatchFailed, v1.BatchSkipped}
+	if got := statuses(resp.Results); !equal(got, want) {
+		t.Errorf("results got %v, want %v", got, want)
+	}
+
+	if alice, err := c.svc.Users().Get(context.Background(), "alice", metav1.GetOperateMeta{}); err == nil && alice != nil {
+		t.Errorf("alice should be rolled back")
+	}
+	if bob, _ := c.svc.Users().Get(context.Background(), "bob", metav1.GetOperateMeta{}); bob == nil || bob.Disabled {
+		t.Errorf("bob should be rolled back, got %v", bob)
+	}
+	if got := changes(t, since); len(got) != 0 {
+		t.Errorf("events of rolled back batch should not be emitted, got %v", got)
+	}
+	if revoked, _ := auth2.UserRevoked(context.Background(), "bob", issuedAt); revoked {
+		t.Errorf("tokens should not be revoked if batch is rolled back")
+	}
+}
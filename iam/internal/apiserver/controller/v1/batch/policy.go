@@ -0,0 +1,62 @@
+package batch
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+)
+
+// policyFields are fields of policy to update.
+type policyFields struct {
+	Policy *v1.AuthzPolicy `json:"policy"`
+}
+
+// policy manages policies of caller.
+func (o *operator) policy(ctx context.Context, svc service.Service, op *v1.BatchOperation) (interface{}, error) {
+	switch op.Method {
+	case v1.BatchCreate:
+		var r v1.Policy
+		if err := decode(op, &r); err != nil {
+			return nil, err
+		}
+
+		policy := &v1.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: r.Name},
+			Username:   o.username,
+			Policy:     r.Policy,
+		}
+		if err := svc.Policies().Create(ctx, policy, metav1.CreateOperateMeta{}); err != nil {
+			return nil, err
+		}
+		return policy, nil
+
+	case v1.BatchUpdate:
+		var fields policyFields
+		if err := decode(op, &fields); err != nil {
+			return nil, err
+		}
+
+		policy, err := svc.Policies().Get(ctx, o.username, op.Name, metav1.GetOperateMeta{})
+		if err != nil {
+			return nil, err
+		}
+
+		if fields.Policy != nil {
+			policy.Policy = *fields.Policy
+		}
+		if err = svc.Policies().Update(ctx, policy, metav1.UpdateOperateMeta{}); err != nil {
+			return nil, err
+		}
+		return policy, nil
+
+	case v1.BatchDelete:
+		if err := svc.Policies().Delete(ctx, o.username, op.Name, metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return nil, errors.WithCode(errors.ErrValidation, "unknown method %s.", op.Method)
+}
@@ -0,0 +1,71 @@
+package batch
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+)
+
+// secretFields are fields of secret to update, secret id and key never change.
+type secretFields struct {
+	Expires     *int64  `json:"expires"`
+	Description *string `json:"description"`
+}
+
+// secret manages secrets of caller, secrets are identified by secret id.
+func (o *operator) secret(ctx context.Context, svc service.Service, op *v1.BatchOperation) (interface{}, error) {
+	switch op.Method {
+	case v1.BatchCreate:
+		var r v1.Secret
+		if err := decode(op, &r); err != nil {
+			return nil, err
+		}
+
+		secret := &v1.Secret{
+			ObjectMeta:  metav1.ObjectMeta{Name: r.Name},
+			Username:    o.username,
+			Expires:     r.Expires,
+			Description: r.Description,
+		}
+		secret.SecretID, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 36)
+		secret.SecretKey, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 32)
+
+		if err := svc.Secrets().Create(ctx, secret, metav1.CreateOperateMeta{}); err != nil {
+			return nil, err
+		}
+		return secret, nil
+
+	case v1.BatchUpdate:
+		var fields secretFields
+		if err := decode(op, &fields); err != nil {
+			return nil, err
+		}
+
+		secret, err := svc.Secrets().Get(ctx, o.username, op.Name, metav1.GetOperateMeta{})
+		if err != nil {
+			return nil, err
+		}
+
+		if fields.Expires != nil {
+			secret.Expires = *fields.Expires
+		}
+		if fields.Description != nil {
+			secret.Description = *fields.Description
+		}
+		if err = svc.Secrets().Update(ctx, secret, metav1.UpdateOperateMeta{}); err != nil {
+			return nil, err
+		}
+		return secret, nil
+
+	case v1.BatchDelete:
+		if err := svc.Secrets().Delete(ctx, o.username, op.Name, metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return nil, errors.WithCode(errors.ErrValidation, "unknown method %s.", op.Method)
+}
@@ -0,0 +1,75 @@
+package batch
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+)
+
+// user manages users as rpc does, only admin can create and delete users, others can update themselves.
+func (o *operator) user(ctx context.Context, svc service.Service, op *v1.BatchOperation) (interface{}, error) {
+	if op.Method != v1.BatchUpdate && !o.admin {
+		return nil, errors.WithCode(errors.ErrPermissionDenied, "only admin can %s users.", op.Method)
+	}
+
+	switch op.Method {
+	case v1.BatchCreate:
+		var r v1.User
+		if err := decode(op, &r); err != nil {
+			return nil, err
+		}
+
+		user := &v1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: r.Name},
+			Username:   r.Username,
+			Password:   r.Password,
+			Email:      r.Email,
+			IsAdmin:    r.IsAdmin,
+			Groups:     r.Groups,
+		}
+		if err := svc.Users().Register(ctx, user); err != nil {
+			return nil, err
+		}
+		return withoutPassword(user), nil
+
+	case v1.BatchUpdate:
+		if op.Name != o.username && !o.admin {
+			return nil, errors.WithCode(errors.ErrPermissionDenied, "only admin can update other users.")
+		}
+
+		var patch v1.UserPatch
+		if err := decode(op, &patch); err != nil {
+			return nil, err
+		}
+		if err := validator.Validate(&patch); err != nil {
+			return nil, err
+		}
+
+		user, err := svc.Users().Patch(ctx, op.Name, &patch, o.admin)
+		if err != nil {
+			return nil, err
+		}
+		if user.Disabled {
+			o.revoked = append(o.revoked, user.Username)
+		}
+		return withoutPassword(user), nil
+
+	case v1.BatchDelete:
+		if err := svc.Users().Delete(ctx, op.Name, metav1.DeleteOperateMeta{}); err != nil {
+			return nil, err
+		}
+		o.revoked = append(o.revoked, op.Name)
+		return nil, nil
+	}
+
+	return nil, errors.WithCode(errors.ErrValidation, "unknown method %s.", op.Method)
+}
+
+func withoutPassword(user *v1.User) *v1.User {
+	u := *user
+	u.Password = ""
+	return &u
+}
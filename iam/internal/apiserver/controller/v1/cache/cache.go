@@ -2,11 +2,21 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
 	pb "istomyang.github.com/like-iam/api/proto/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/rpc"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
+	"time"
 )
 
+// Cache serves secrets and policies to authzservers. Lists with since_revision only return changes after it,
+// revision of reply is used as since_revision of the next list, so that authzservers sync incrementally.
 type Cache struct {
 	svc service.Service
 	pb.UnimplementedCacheServer
@@ -16,10 +26,99 @@ func NewCache(store store.Factory) *Cache {
 	return &Cache{svc: service.NewService(store)}
 }
 
-func (c *Cache) ListSecrets(context.Context, *pb.ListRequest) (*pb.ListSecretsReply, error) {
-	return nil, nil
+// streamBatch is the max count of items in one reply of stream.
+const streamBatch = 500
+
+// all lists all items, -1 cancels offset and limit.
+func all() metav1.ListOperateMeta {
+	offset, limit := int64(-1), int64(-1)
+	return metav1.ListOperateMeta{Offset: &offset, Limit: &limit}
+}
+
+// page returns page in request, or all if it's not given as authzservers do.
+func page(r *pb.ListRequest) metav1.ListOperateMeta {
+	meta := all()
+	if r.Offset != nil {
+		meta.Offset = r.Offset
+	}
+	if r.Limit != nil {
+		meta.Limit = r.Limit
+	}
+	return meta
+}
+
+// toStatus also converts errors of reading changes.
+func toStatus(err error) error {
+	switch err {
+	case watch.ErrRevisionInvalid:
+		return status.Error(grpccodes.InvalidArgument, "invalid since_revision.")
+	case watch.ErrRevisionExpired:
+		return status.Error(grpccodes.OutOfRange, "since_revision is too old, list all again.")
+	}
+	return rpc.ToStatus(err)
+}
+
+// changedOwners returns principals whose items must be replaced because of changes of users
+// and service accounts since revision. Only deletions matter if deletedOnly.
+func changedOwners(ctx context.Context, revision string, deletedOnly bool) ([]string, error) {
+	var owners []string
+	seen := map[string]bool{}
+	add := func(owner string) {
+		if owner != "" && !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+
+	changes, err := watch.Since(ctx, watch.ResourceUsers, revision)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range changes {
+		if c.Type == metav1.WatchAdded || (deletedOnly && c.Type != metav1.WatchDeleted) {
+			continue
+		}
+		var user v1.User
+		if json.Unmarshal(c.Object, &user) == nil {
+			add(user.Username)
+		}
+	}
+
+	changes, err = watch.Since(ctx, watch.ResourceServiceAccounts, revision)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range changes {
+		if c.Type == metav1.WatchAdded || (deletedOnly && c.Type != metav1.WatchDeleted) {
+			continue
+		}
+		var account v1.ServiceAccount
+		if json.Unmarshal(c.Object, &account) == nil && account.Name != "" {
+			add(account.Principal())
+		}
+	}
+
+	return owners, nil
+}
+
+// enabled tells whether owner exists and is not disabled, results are kept in memo.
+func (c *Cache) enabled(ctx context.Context, memo map[string]bool, owner string) bool {
+	if ok, has := memo[owner]; has {
+		return ok
+	}
+
+	var ok bool
+	if name, isAccount := v1.ServiceAccountName(owner); isAccount {
+		account, err := c.svc.ServiceAccounts().Get(ctx, name, metav1.GetOperateMeta{})
+		ok = err == nil && account != nil && !account.Disabled
+	} else {
+		user, err := c.svc.Users().Get(ctx, owner, metav1.GetOperateMeta{})
+		ok = err == nil && user != nil && !user.Disabled
+	}
+	memo[owner] = ok
+	return ok
 }
 
-func (c *Cache) ListPolicies(context.Context, *pb.ListRequest) (*pb.ListPoliciesReply, error) {
-	return nil, nil
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
 }
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"github.com/ory/ladon"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
+	"istomyang.github.com/like-iam/test/redistest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	redistest.Use()
+	os.Exit(m.Run())
+}
+
+// newCache returns cache of users alice, bob and carol, with secrets and policies named by the first letter of owners.
+func newCache(t *testing.T) *Cache {
+	redistest.Use()
+	ctx := context.Background()
+	c := NewCache(fake.NewFactory())
+
+	for _, username := range []string{"alice", "bob", "carol"} {
+		user := &v1.User{Username: username, Email: username + "@example.com"}
+		if err := c.svc.Users().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"a1", "a2", "b1", "c1"} {
+		createSecret(t, c, id)
+	}
+	for _, name := range []string{"a1", "a2", "b1", "c1"} {
+		createPolicy(t, c, name)
+	}
+	return c
+}
+
+func owner(name string) string {
+	for _, username := range []string{"alice", "bob", "carol"} {
+		if username[0] == name[0] {
+			return username
+		}
+	}
+	return name
+}
+
+func createSecret(t *testing.T, c *Cache, id string) {
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: id}, Username: owner(id), SecretID: id, SecretKey: "key-" + id}
+	if err := c.svc.Secrets().Create(context.Background(), secret, metav1.CreateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createPolicy(t *testing.T, c *Cache, name string) {
+	policy := &v1.Policy{ObjectMeta: metav1.ObjectMeta{Name: name}, Username: owner(name),
+		Policy: v1.AuthzPolicy{DefaultPolicy: ladon.DefaultPolicy{
+			ID:        name,
+			Subjects:  []string{owner(name)},
+			Effect:    ladon.AllowAccess,
+			Resources: []string{"resources:" + name},
+			Actions:   []string{"get"},
+		}},
+	}
+	if err := c.svc.Policies().Create(context.Background(), policy, metav1.CreateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// revision returns current revision, changes logged before are in an earlier millisecond.
+func revision(t *testing.T) string {
+	time.Sleep(2 * time.Millisecond)
+	r, err := watch.Revision(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func sorted(s []string) []string {
+	sort.Strings(s)
+	return s
+}
+
+func equal(got, want []string) bool {
+	return strings.Join(got, ",") == strings.Join(want, ",")
+}
+
+// stream collects replies sent to it.
+type stream struct {
+	grpc.ServerStream
+	secrets  []*pb.ListSecretsReply
+	policies []*pb.ListPoliciesReply
+}
+
+func (s *stream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *stream) Send(r interface{}) error {
+	switch r := r.(type) {
+	case *pb.ListSecretsReply:
+		s.secrets = append(s.secrets, r)
+	case *pb.ListPoliciesReply:
+		s.policies = append(s.policies, r)
+	}
+	return nil
+}
+
+type secretStream struct{ *stream }
+
+func (s secretStream) Send(r *pb.ListSecretsReply) error { return s.stream.Send(r) }
+
+type policyStream struct{ *stream }
+
+func (s policyStream) Send(r *pb.ListPoliciesReply) error { return s.stream.Send(r) }
+
+func TestList_ExpiredRevision(t *testing.T) {
+	c := newCache(t)
+	ctx := context.Background()
+	since := revision(t)
+
+	// Log of secrets is trimmed to keep the latest events, the first event after since is lost.
+	for i := 0; i <= 10000; i++ {
+		watch.Publish(ctx, v1.EventSecretCreated, "alice", &v1.Secret{Username: "alice", SecretID: "a1"})
+	}
+
+	_, err := c.ListSecrets(ctx, &pb.ListRequest{SinceRevision: since})
+	if status.Code(err) != grpccodes.OutOfRange {
+		t.Errorf("list secrets got %v, want OutOfRange", err)
+	}
+	err = c.StreamSecrets(&pb.ListRequest{SinceRevision: since}, secretStream{&stream{}})
+	if status.Code(err) != grpccodes.OutOfRange {
+		t.Errorf("stream secrets got %v, want OutOfRange", err)
+	}
+
+	// Authzservers list all again without since_revision.
+	reply, err := c.ListSecrets(ctx, &pb.ListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Count != 4 || reply.Revision == "" {
+		t.Errorf("full list got count %d revision %q, want 4 and a revision", reply.Count, reply.Revision)
+	}
+	if _, err = c.ListSecrets(ctx, &pb.ListRequest{SinceRevision: reply.Revision}); err != nil {
+		t.Errorf("list since revision of full list got %v", err)
+	}
+
+	// Log of policies is short, nothing is lost.
+	if _, err = c.ListPolicies(ctx, &pb.ListRequest{SinceRevision: since}); err != nil {
+		t.Errorf("list policies got %v", err)
+	}
+}
+
+func TestList_InvalidRevision(t *testing.T) {
+	c := newCache(t)
+
+	_, err := c.ListSecrets(context.Background(), &pb.ListRequest{SinceRevision: "yesterday"})
+	if status.Code(err) != grpccodes.InvalidArgument {
+		t.Errorf("list secrets got %v, want InvalidArgument", err)
+	}
+	_, err = c.ListPolicies(context.Background(), &pb.ListRequest{SinceRevision: "yesterday"})
+	if status.Code(err) != grpccodes.InvalidArgument {
+		t.Errorf("list policies got %v, want InvalidArgument", err)
+	}
+}
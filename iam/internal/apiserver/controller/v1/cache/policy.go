@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+)
+
+// ListPolicies lists policies of all users. Offset and limit are ignored if since_revision is given.
+func (c *Cache) ListPolicies(ctx context.Context, r *pb.ListRequest) (*pb.ListPoliciesReply, error) {
+	log.L(ctx).Info("list policies for cache.")
+
+	revision, err := watch.Revision(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if r.SinceRevision != "" {
+		reply, err := c.policyChanges(ctx, r.SinceRevision)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		reply.Revision = revision
+		return reply, nil
+	}
+
+	policies, err := c.svc.Policies().ListAll(ctx, page(r))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	reply := &pb.ListPoliciesReply{Count: policies.TotalCount, Revision: revision}
+	for _, policy := range policies.Items {
+		reply.Items = append(reply.Items, toPolicyInfo(policy))
+	}
+	return reply, nil
+}
+
+// StreamPolicies is the same as ListPolicies, but sends items in batches, offset and limit are ignored.
+// Deleted items and replaced owners are sent in the first reply.
+func (c *Cache) StreamPolicies(r *pb.ListRequest, stream pb.Cache_StreamPoliciesServer) error {
+	ctx := stream.Context()
+	log.L(ctx).Info("stream policies for cache.")
+
+	revision, err := watch.Revision(ctx)
+	if err != nil {
+		return toStatus(err)
+	}
+	if r.SinceRevision != "" {
+		reply, err := c.policyChanges(ctx, r.SinceRevision)
+		if err != nil {
+			return toStatus(err)
+		}
+		reply.Revision = revision
+		items := reply.Items
+		for {
+			n := len(items)
+			if n > streamBatch {
+				n = streamBatch
+			}
+			reply.Items, items = items[:n], items[n:]
+			if err = stream.Send(reply); err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return nil
+			}
+			reply = &pb.ListPoliciesReply{Count: reply.Count, Revision: revision}
+		}
+	}
+
+	for offset := int64(0); ; offset += streamBatch {
+		limit := int64(streamBatch)
+		policies, err := c.svc.Policies().ListAll(ctx, metav1.ListOperateMeta{Offset: &offset, Limit: &limit})
+		if err != nil {
+			return toStatus(err)
+		}
+
+		reply := &pb.ListPoliciesReply{Count: policies.TotalCount, Revision: revision}
+		for _, policy := range policies.Items {
+			reply.Items = append(reply.Items, toPolicyInfo(policy))
+		}
+		if err = stream.Send(reply); err != nil {
+			return err
+		}
+		if offset+limit >= policies.TotalCount {
+			return nil
+		}
+	}
+}
+
+// policyChanges returns policies changed since revision. Deleted users make all policies of them replaced,
+// because they are deleted along with users.
+func (c *Cache) policyChanges(ctx context.Context, revision string) (*pb.ListPoliciesReply, error) {
+	owners, err := changedOwners(ctx, revision, true)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := watch.Since(ctx, watch.ResourcePolicies, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &pb.ListPoliciesReply{ReplacedOwners: owners}
+	replaced := map[string]bool{}
+	for _, owner := range owners {
+		replaced[owner] = true
+		policies, err := c.svc.Policies().List(ctx, owner, all())
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies.Items {
+			reply.Items = append(reply.Items, toPolicyInfo(policy))
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, change := range changes {
+		var p v1.Policy
+		if json.Unmarshal(change.Object, &p) != nil || p.Name == "" || replaced[p.Username] || seen[p.Username+"/"+p.Name] {
+			continue
+		}
+		seen[p.Username+"/"+p.Name] = true
+
+		policy, err := c.svc.Policies().Get(ctx, p.Username, p.Name, metav1.GetOperateMeta{})
+		if err != nil && errors.Code(err) != codes.ErrPolicyNotFound {
+			return nil, err
+		}
+		if policy == nil {
+			reply.Deleted = append(reply.Deleted, &pb.PolicyInfo{Name: p.Name, Username: p.Username})
+			continue
+		}
+		reply.Items = append(reply.Items, toPolicyInfo(policy))
+	}
+
+	reply.Count = int64(len(reply.Items))
+	return reply, nil
+}
+
+func toPolicyInfo(policy *v1.Policy) *pb.PolicyInfo {
+	return &pb.PolicyInfo{
+		Name:         policy.Name,
+		Username:     policy.Username,
+		PolicyStr:    policy.Policy.String(),
+		PolicyShadow: policy.PolicyShadow,
+		CreatedAt:    formatTime(policy.CreatedAt),
+	}
+}
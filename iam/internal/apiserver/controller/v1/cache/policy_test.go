@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"testing"
+)
+
+func policyNames(items []*pb.PolicyInfo) []string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Username+"/"+item.Name)
+	}
+	return sorted(names)
+}
+
+func TestPolicyChanges(t *testing.T) {
+	c := newCache(t)
+	ctx := context.Background()
+	since := revision(t)
+
+	policy, _ := c.svc.Policies().Get(ctx, "alice", "a1", metav1.GetOperateMeta{})
+	policy.Policy.Description = "changed"
+	if err := c.svc.Policies().Update(ctx, policy, metav1.UpdateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.svc.Policies().Delete(ctx, "alice", "a2", metav1.DeleteOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	createPolicy(t, c, "c2")
+
+	// Policies are deleted along with bob.
+	if err := c.svc.Users().Delete(ctx, "bob", metav1.DeleteOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	// Policies of disabled users are still served.
+	user, _ := c.svc.Users().Get(ctx, "carol", metav1.GetOperateMeta{})
+	user.Disabled = true
+	if err := c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := c.ListPolicies(ctx, &pb.ListRequest{SinceRevision: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := policyNames(reply.Items); !equal(names, []string{"alice/a1", "carol/c2"}) || reply.Count != 2 {
+		t.Errorf("items got %v count %d, want [alice/a1 carol/c2]", names, reply.Count)
+	}
+	for _, item := range reply.Items {
+		if item.Name == "a1" && item.PolicyStr != policy.Policy.String() {
+			t.Errorf("a1 got %s, want %s", item.PolicyStr, policy.Policy.String())
+		}
+	}
+	if names := policyNames(reply.Deleted); !equal(names, []string{"alice/a2"}) {
+		t.Errorf("deleted got %v, want [alice/a2]", names)
+	}
+	if !equal(reply.ReplacedOwners, []string{"bob"}) {
+		t.Errorf("replaced owners got %v, want [bob]", reply.ReplacedOwners)
+	}
+}
+
+func TestStreamPolicies(t *testing.T) {
+	c := newCache(t)
+	since := revision(t)
+	createPolicy(t, c, "b2")
+
+	s := &stream{}
+	if err := c.StreamPolicies(&pb.ListRequest{}, policyStream{s}); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.policies) != 1 || s.policies[0].Count != 5 || len(s.policies[0].Items) != 5 {
+		t.Errorf("full stream got %d replies, want 1 of 5 items", len(s.policies))
+	}
+
+	s = &stream{}
+	if err := c.StreamPolicies(&pb.ListRequest{SinceRevision: since}, policyStream{s}); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.policies) != 1 || !equal(policyNames(s.policies[0].Items), []string{"bob/b2"}) {
+		t.Errorf("changes got %d replies, want 1 of [bob/b2]", len(s.policies))
+	}
+	if s.policies[0].Revision == "" {
+		t.Errorf("changes should have revision")
+	}
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+)
+
+// ListSecrets lists secrets can be used to authenticate, secrets of disabled users and service accounts
+// are excluded. Offset and limit are ignored if since_revision is given.
+func (c *Cache) ListSecrets(ctx context.Context, r *pb.ListRequest) (*pb.ListSecretsReply, error) {
+	log.L(ctx).Info("list secrets for cache.")
+
+	revision, err := watch.Revision(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if r.SinceRevision != "" {
+		reply, err := c.secretChanges(ctx, r.SinceRevision)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		reply.Revision = revision
+		return reply, nil
+	}
+
+	secrets, err := c.svc.Secrets().ListEnabled(ctx, page(r))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	reply := &pb.ListSecretsReply{Count: secrets.TotalCount, Revision: revision}
+	for _, secret := range secrets.Items {
+		reply.Items = append(reply.Items, toSecretInfo(secret))
+	}
+	return reply, nil
+}
+
+// StreamSecrets is the same as ListSecrets, but sends items in batches, offset and limit are ignored.
+// Deleted items and replaced owners are sent in the first reply.
+func (c *Cache) StreamSecrets(r *pb.ListRequest, stream pb.Cache_StreamSecretsServer) error {
+	ctx := stream.Context()
+	log.L(ctx).Info("stream secrets for cache.")
+
+	revision, err := watch.Revision(ctx)
+	if err != nil {
+		return toStatus(err)
+	}
+	if r.SinceRevision != "" {
+		reply, err := c.secretChanges(ctx, r.SinceRevision)
+		if err != nil {
+			return toStatus(err)
+		}
+		reply.Revision = revision
+		items := reply.Items
+		for {
+			n := len(items)
+			if n > streamBatch {
+				n = streamBatch
+			}
+			reply.Items, items = items[:n], items[n:]
+			if err = stream.Send(reply); err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return nil
+			}
+			reply = &pb.ListSecretsReply{Count: reply.Count, Revision: revision}
+		}
+	}
+
+	for offset := int64(0); ; offset += streamBatch {
+		limit := int64(streamBatch)
+		secrets, err := c.svc.Secrets().ListEnabled(ctx, metav1.ListOperateMeta{Offset: &offset, Limit: &limit})
+		if err != nil {
+			return toStatus(err)
+		}
+
+		reply := &pb.ListSecretsReply{Count: secrets.TotalCount, Revision: revision}
+		for _, secret := range secrets.Items {
+			reply.Items = append(reply.Items, toSecretInfo(secret))
+		}
+		if err = stream.Send(reply); err != nil {
+			return err
+		}
+		if offset+limit >= secrets.TotalCount {
+			return nil
+		}
+	}
+}
+
+// secretChanges returns secrets changed since revision. Changes of users and service accounts make
+// all secrets of them replaced, because they are deleted along with owners, or usable only if owners enabled.
+func (c *Cache) secretChanges(ctx context.Context, revision string) (*pb.ListSecretsReply, error) {
+	owners, err := changedOwners(ctx, revision, false)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := watch.Since(ctx, watch.ResourceSecrets, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &pb.ListSecretsReply{ReplacedOwners: owners}
+	memo := map[string]bool{}
+	replaced := map[string]bool{}
+	for _, owner := range owners {
+		replaced[owner] = true
+		if !c.enabled(ctx, memo, owner) {
+			continue
+		}
+		secrets, err := c.svc.Secrets().List(ctx, owner, all())
+		if err != nil {
+			return nil, err
+		}
+		for _, secret := range secrets.Items {
+			reply.Items = append(reply.Items, toSecretInfo(secret))
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, change := range changes {
+		var s v1.Secret
+		if json.Unmarshal(change.Object, &s) != nil || s.SecretID == "" || replaced[s.Username] || seen[s.SecretID] {
+			continue
+		}
+		seen[s.SecretID] = true
+
+		secret, err := c.svc.Secrets().Get(ctx, s.Username, s.SecretID, metav1.GetOperateMeta{})
+		if err != nil && errors.Code(err) != codes.ErrSecretNotFound {
+			return nil, err
+		}
+		if secret == nil || !c.enabled(ctx, memo, s.Username) {
+			reply.Deleted = append(reply.Deleted, &pb.SecretInfo{SecretId: s.SecretID, Username: s.Username})
+			continue
+		}
+		reply.Items = append(reply.Items, toSecretInfo(secret))
+	}
+
+	reply.Count = int64(len(reply.Items))
+	return reply, nil
+}
+
+func toSecretInfo(secret *v1.Secret) *pb.SecretInfo {
+	return &pb.SecretInfo{
+		Name:        secret.Name,
+		SecretId:    secret.SecretID,
+		Username:    secret.Username,
+		SecretKey:   secret.SecretKey,
+		Expires:     secret.Expires,
+		Description: secret.Description,
+		CreatedAt:   formatTime(secret.CreatedAt),
+		UpdatedAt:   formatTime(secret.UpdatedAt),
+	}
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"testing"
+)
+
+func secretIDs(items []*pb.SecretInfo) []string {
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.SecretId)
+	}
+	return sorted(ids)
+}
+
+func TestListSecrets(t *testing.T) {
+	c := newCache(t)
+	ctx := context.Background()
+
+	user, _ := c.svc.Users().Get(ctx, "bob", metav1.GetOperateMeta{})
+	user.Disabled = true
+	if err := c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := c.ListSecrets(ctx, &pb.ListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := secretIDs(reply.Items); !equal(ids, []string{"a1", "a2", "c1"}) || reply.Count != 3 {
+		t.Errorf("got %v count %d, want [a1 a2 c1] without secrets of disabled bob", ids, reply.Count)
+	}
+}
+
+func TestSecretChanges(t *testing.T) {
+	c := newCache(t)
+	ctx := context.Background()
+	since := revision(t)
+
+	secret, _ := c.svc.Secrets().Get(ctx, "alice", "a1", metav1.GetOperateMeta{})
+	secret.Description = "rotated"
+	if err := c.svc.Secrets().Update(ctx, secret, metav1.UpdateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.svc.Secrets().Delete(ctx, "alice", "a2", metav1.DeleteOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	createSecret(t, c, "c2")
+
+	// Secrets of disabled bob are replaced by nothing.
+	user, _ := c.svc.Users().Get(ctx, "bob", metav1.GetOperateMeta{})
+	user.Disabled = true
+	if err := c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	// Newly created dave has nothing to replace.
+	if err := c.svc.Users().Create(ctx, &v1.User{Username: "dave", Email: "dave@example.com"}, metav1.CreateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := c.ListSecrets(ctx, &pb.ListRequest{SinceRevision: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := secretIDs(reply.Items); !equal(ids, []string{"a1", "c2"}) || reply.Count != 2 {
+		t.Errorf("items got %v count %d, want [a1 c2]", ids, reply.Count)
+	}
+	for _, item := range reply.Items {
+		if item.SecretId == "a1" && item.Description != "rotated" {
+			t.Errorf("a1 got description %q, want rotated", item.Description)
+		}
+	}
+	if ids := secretIDs(reply.Deleted); !equal(ids, []string{"a2"}) {
+		t.Errorf("deleted got %v, want [a2]", ids)
+	}
+	if !equal(reply.ReplacedOwners, []string{"bob"}) {
+		t.Errorf("replaced owners got %v, want [bob]", reply.ReplacedOwners)
+	}
+	if reply.Revision == "" || reply.Revision < since {
+		t.Errorf("revision got %q, want after %s", reply.Revision, since)
+	}
+}
+
+func TestSecretChanges_EnabledOwner(t *testing.T) {
+	c := newCache(t)
+	ctx := context.Background()
+
+	user, _ := c.svc.Users().Get(ctx, "bob", metav1.GetOperateMeta{})
+	user.Disabled = true
+	if err := c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	since := revision(t)
+
+	user.Disabled = false
+	if err := c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := c.ListSecrets(ctx, &pb.ListRequest{SinceRevision: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := secretIDs(reply.Items); !equal(ids, []string{"b1"}) || !equal(reply.ReplacedOwners, []string{"bob"}) {
+		t.Errorf("got items %v owners %v, want secrets of enabled bob back", ids, reply.ReplacedOwners)
+	}
+}
+
+func TestStreamSecrets(t *testing.T) {
+	c := newCache(t)
+	ctx := context.Background()
+	for i := 0; i < streamBatch; i++ {
+		createSecret(t, c, fmt.Sprintf("c%04d", i))
+	}
+	since := revision(t)
+	for i := 0; i < streamBatch; i++ {
+		createSecret(t, c, fmt.Sprintf("a%04d", i))
+	}
+	if err := c.svc.Secrets().Delete(ctx, "bob", "b1", metav1.DeleteOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &stream{}
+	if err := c.StreamSecrets(&pb.ListRequest{}, secretStream{s}); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for _, reply := range s.secrets {
+		n += len(reply.Items)
+		if reply.Count != 2*streamBatch+3 {
+			t.Errorf("count got %d, want %d", reply.Count, 2*streamBatch+3)
+		}
+	}
+	if len(s.secrets) != 3 || n != 2*streamBatch+3 {
+		t.Errorf("full stream got %d replies of %d items, want 3 of %d", len(s.secrets), n, 2*streamBatch+3)
+	}
+
+	s = &stream{}
+	if err := c.StreamSecrets(&pb.ListRequest{SinceRevision: since}, secretStream{s}); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.secrets) != 1 {
+		t.Fatalf("changes got %d replies, want 1", len(s.secrets))
+	}
+	reply := s.secrets[0]
+	if len(reply.Items) != streamBatch || reply.Count != streamBatch || !equal(secretIDs(reply.Deleted), []string{"b1"}) {
+		t.Errorf("changes got %d items count %d deleted %v", len(reply.Items), reply.Count, secretIDs(reply.Deleted))
+	}
+
+	// Changes more than a batch are split, deleted items come first.
+	createSecret(t, c, "a9999")
+	s = &stream{}
+	if err := c.StreamSecrets(&pb.ListRequest{SinceRevision: since}, secretStream{s}); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.secrets) != 2 || len(s.secrets[0].Items) != streamBatch || len(s.secrets[1].Items) != 1 {
+		t.Fatalf("changes got %d replies, want 2 of %d and 1 items", len(s.secrets), streamBatch)
+	}
+	if len(s.secrets[0].Deleted) != 1 || len(s.secrets[1].Deleted) != 0 {
+		t.Errorf("deleted should only be in the first reply")
+	}
+	for _, reply := range s.secrets {
+		if reply.Count != streamBatch+1 || reply.Revision != s.secrets[0].Revision {
+			t.Errorf("reply got count %d revision %s, want %d and the same revision", reply.Count, reply.Revision, streamBatch+1)
+		}
+	}
+}
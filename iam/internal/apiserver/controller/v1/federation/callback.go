@@ -0,0 +1,116 @@
+package federation
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	iamauth "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+	"time"
+)
+
+// Callback verifies id token from upstream provider, maps it to local user and responds token same as login.
+func (c *Controller) Callback(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into federated login callback.")
+
+	if e := ctx.Query("error"); e != "" {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrPermissionDenied, "upstream provider: %s: %s",
+			e, ctx.Query("error_description")), nil)
+		return
+	}
+
+	state := ctx.Query("state")
+	if cookie, _ := ctx.Cookie(stateCookie); state == "" || cookie != state {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrTokenInvalid, "state mismatch."), nil)
+		return
+	}
+	ctx.SetCookie(stateCookie, "", -1, "/login/federated", "", false, true)
+
+	s, err := takeLoginState(ctx, state)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "take login state fail: %s", err.Error()), nil)
+		return
+	}
+	if s == nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrTokenInvalid, "state is invalid, expired or used."), nil)
+		return
+	}
+
+	rawIDToken, err := c.rp.Exchange(ctx, ctx.Query("code"), s.CodeVerifier)
+	if err != nil {
+		log.L(ctx).Warnf("exchange code with upstream provider fail: %s", err.Error())
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrTokenInvalid, "exchange code fail."), nil)
+		return
+	}
+	claims, err := c.rp.VerifyIDToken(ctx, rawIDToken, s.Nonce)
+	if err != nil {
+		log.L(ctx).Warnf("verify id token from upstream provider fail: %s", err.Error())
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrSignatureInvalid, "id token is invalid."), nil)
+		return
+	}
+	id, err := c.mapping.Map(claims)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrValidation, "map id token fail: %s", err.Error()), nil)
+		return
+	}
+
+	user, err := c.federatedUser(ctx, id)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	iamauth.LoginResponse(ctx, user)
+}
+
+// federatedUser finds user linked to id, or creates it if auto provisioning is enabled.
+// Email and groups are synced from upstream provider at every login.
+func (c *Controller) federatedUser(ctx *gin.Context, id *auth.FederatedIdentity) (*v1.User, error) {
+	user, err := c.svc.Users().Get(ctx, id.Username, metav1.GetOperateMeta{})
+	if err != nil || user == nil {
+		if !c.opts.AutoProvision {
+			return nil, errors.WithCode(errors.ErrPermissionDenied, "user %s is not provisioned.", id.Username)
+		}
+
+		log.L(ctx).Infof("provision federated user %s from %s.", id.Username, id.Issuer)
+		// Password is left empty, so that federated user can't login with password.
+		user = &v1.User{
+			Username:         id.Username,
+			Email:            id.Email,
+			Groups:           id.Groups,
+			IdentityProvider: id.Issuer,
+			ExternalID:       id.Subject,
+			LoginAt:          time.Now(),
+		}
+		if err = c.svc.Users().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// User prepared by admin with identityProvider is linked at first login.
+	if user.IdentityProvider == id.Issuer && user.ExternalID == "" {
+		user.ExternalID = id.Subject
+		user.Password = ""
+	}
+	// Local user with same name must not be taken over by upstream identity.
+	if user.IdentityProvider != id.Issuer || user.ExternalID != id.Subject {
+		return nil, errors.WithCode(codes.ErrFederatedUserConflict,
+			"user %s exists but is not linked to the identity of upstream provider.", id.Username)
+	}
+	if user.Disabled {
+		return nil, errors.WithCode(codes.ErrUserDisabled, "user %s is disabled.", id.Username)
+	}
+
+	user.Email = id.Email
+	user.Groups = id.Groups
+	user.LoginAt = time.Now()
+	if err = c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,29 @@
+package federation
+
+import (
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+)
+
+// Controller logs user in with upstream OpenID Connect provider, then issues apiserver token.
+type Controller struct {
+	svc     service.Service
+	opts    *options.FederationOpts
+	rp      *auth.OIDCRelyingParty
+	mapping auth.ClaimMapping
+}
+
+func NewFederationController(store store.Factory, opts *options.FederationOpts) *Controller {
+	return &Controller{
+		svc:  service.NewService(store),
+		opts: opts,
+		rp:   auth.NewOIDCRelyingParty(opts.Issuer, opts.ClientID, opts.ClientSecret, opts.RedirectURL, opts.Scopes),
+		mapping: auth.ClaimMapping{
+			Username: opts.UsernameClaim,
+			Email:    opts.EmailClaim,
+			Groups:   opts.GroupsClaim,
+		},
+	}
+}
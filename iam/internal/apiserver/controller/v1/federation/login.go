@@ -0,0 +1,36 @@
+package federation
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"strings"
+)
+
+// stateCookie binds state to browser which starts login, so that others can't finish it with their code.
+const stateCookie = "iam_federation_state"
+
+// Login redirects user to upstream provider.
+func (c *Controller) Login(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into federated login.")
+
+	state, s, err := newLoginState(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "save login state fail: %s", err.Error()), nil)
+		return
+	}
+
+	u, err := c.rp.AuthCodeURL(ctx, state, s.Nonce, codeChallenge(s.CodeVerifier))
+	if err != nil {
+		log.L(ctx).Errorf("discover upstream provider %s fail: %s", c.opts.Issuer, err.Error())
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "upstream provider is unavailable."), nil)
+		return
+	}
+
+	secure := ctx.Request.TLS != nil || strings.HasPrefix(c.opts.RedirectURL, "https://")
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(stateCookie, state, int(stateTTL.Seconds()), "/login/federated", "", secure, true)
+	ctx.Redirect(http.StatusFound, u)
+}
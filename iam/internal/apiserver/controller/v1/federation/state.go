@@ -0,0 +1,89 @@
+package federation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"time"
+)
+
+const (
+	stateKeyPrefix = "iam.federation-state."
+	stateTTL       = 10 * time.Minute
+	randomLength   = 43
+)
+
+// loginState is saved between redirecting to upstream provider and its callback.
+type loginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+}
+
+// newLoginState returns state token with a new nonce and PKCE code verifier saved in redis.
+func newLoginState(ctx context.Context) (string, *loginState, error) {
+	var s loginState
+	var err error
+	if s.Nonce, err = randomString(); err != nil {
+		return "", nil, err
+	}
+	if s.CodeVerifier, err = randomString(); err != nil {
+		return "", nil, err
+	}
+	token, err := randomString()
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := json.Marshal(&s)
+	if err != nil {
+		return "", nil, err
+	}
+	client := conn.GetRedisClient().UniversalClient()
+	if err = client.Set(ctx, stateKey(token), data, stateTTL).Err(); err != nil {
+		return "", nil, err
+	}
+	return token, &s, nil
+}
+
+// takeLoginState returns and deletes state, nil means state is invalid, expired or used.
+func takeLoginState(ctx context.Context, token string) (*loginState, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	data, err := client.Get(ctx, stateKey(token)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Someone else has taken it in the meantime.
+	n, err := client.Del(ctx, stateKey(token)).Result()
+	if err != nil || n != 1 {
+		return nil, err
+	}
+
+	var s loginState
+	if err = json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func stateKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return stateKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func randomString() (string, error) {
+	return idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, randomLength)
+}
+
+// codeChallenge is S256 of PKCE code verifier.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,51 @@
+package invitation
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+	"time"
+)
+
+// Create issues an invitation, plain code is only returned here.
+// Policies of invitation must be owned by current user.
+func (c *Controller) Create(ctx *gin.Context) {
+	log.L(ctx).Info("create invitation.")
+
+	var r *v1.Invitation
+
+	if err := ctx.ShouldBind(&r); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(r); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if r.Name == "" {
+		r.Name, _ = idutil.GetRandString(idutil.AlphabetL+idutil.Number, 12)
+	}
+	if r.ExpiresAt.IsZero() {
+		r.ExpiresAt = time.Now().Add(c.opts.InvitationTTL)
+	}
+	if !r.ExpiresAt.After(time.Now()) {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrValidation, "expiresAt must be in the future."), nil)
+		return
+	}
+	r.CreatedBy = ctx.GetString(middleware.UserNameKey)
+
+	if err := c.svc.Invitations().Create(ctx, r, metav1.CreateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, r)
+}
@@ -0,0 +1,20 @@
+package invitation
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Delete revokes an invitation, user registered with it is kept.
+func (c *Controller) Delete(ctx *gin.Context) {
+	log.L(ctx).Info("delete invitation.")
+
+	if err := c.svc.Invitations().Delete(ctx, ctx.Param("name"), metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
@@ -0,0 +1,20 @@
+package invitation
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+func (c *Controller) Get(ctx *gin.Context) {
+	log.L(ctx).Info("get invitation.")
+
+	invitation, err := c.svc.Invitations().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, invitation)
+}
@@ -0,0 +1,33 @@
+package invitation
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+)
+
+// Controller manages invitations used by invite-only registration.
+type Controller struct {
+	svc  service.Service
+	opts *options.RegistrationOpts
+}
+
+func NewInvitationController(store store.Factory, opts *options.RegistrationOpts) *Controller {
+	return &Controller{svc: service.NewService(store), opts: opts}
+}
+
+// AdminOnly aborts if current user is not admin, only admin can manage invitations.
+func (c *Controller) AdminOnly(ctx *gin.Context) {
+	username := ctx.GetString(middleware.UserNameKey)
+	if !c.svc.Users().IsAdmin(ctx, username) {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrPermissionDenied, "only admin can manage invitations."), nil)
+		ctx.Abort()
+		return
+	}
+
+	ctx.Next()
+}
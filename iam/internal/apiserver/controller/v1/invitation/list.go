@@ -0,0 +1,33 @@
+package invitation
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+func (c *Controller) List(ctx *gin.Context) {
+	log.L(ctx).Info("list invitations.")
+
+	var meta metav1.ListOperateMeta
+
+	if err := ctx.ShouldBindQuery(&meta); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&meta); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+	invitations, err := c.svc.Invitations().List(ctx, meta)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, invitations)
+}
@@ -0,0 +1,75 @@
+package oauthclient
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+	"net/url"
+)
+
+// Create registers a client, plain secret is only returned here.
+func (c *Controller) Create(ctx *gin.Context) {
+	log.L(ctx).Info("create oauth client.")
+
+	var client *v1.OAuthClient
+
+	if err := ctx.ShouldBind(&client); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(client); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if err := validateRedirectURIs(client.RedirectURIs); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	client.Username = ctx.GetString(middleware.UserNameKey)
+	client.ClientID, _ = idutil.GetRandString(idutil.AlphabetL+idutil.Number, 24)
+
+	var secret string
+	if !client.Public {
+		secret, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 40)
+		hashed, err := auth.Encrypt(secret)
+		if err != nil {
+			web.WriteResponse(ctx, errors.WithCode(errors.ErrEncrypt, "client secret encrypt fail."), nil)
+			return
+		}
+		client.ClientSecret = hashed
+	}
+
+	if err := c.svc.OAuthClients().Create(ctx, client, metav1.CreateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, gin.H{
+		"clientID":     client.ClientID,
+		"clientSecret": secret,
+	})
+}
+
+// validateRedirectURIs requires absolute uri without fragment, see: https://www.rfc-editor.org/rfc/rfc6749#section-3.1.2
+func validateRedirectURIs(uris []string) error {
+	if len(uris) == 0 {
+		return errors.WithCode(errors.ErrValidation, "redirectURIs must not be empty.")
+	}
+	for _, u := range uris {
+		p, err := url.Parse(u)
+		if err != nil || !p.IsAbs() || p.Fragment != "" {
+			return errors.WithCode(errors.ErrValidation, "invalid redirect uri: %s", u)
+		}
+	}
+	return nil
+}
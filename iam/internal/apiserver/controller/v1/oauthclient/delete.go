@@ -0,0 +1,25 @@
+package oauthclient
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+func (c *Controller) Delete(ctx *gin.Context) {
+	log.L(ctx).Info("delete oauth client.")
+
+	client, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if err = c.svc.OAuthClients().Delete(ctx, client.ClientID, metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
@@ -0,0 +1,19 @@
+package oauthclient
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+func (c *Controller) Get(ctx *gin.Context) {
+	log.L(ctx).Info("get oauth client.")
+
+	client, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, client)
+}
@@ -0,0 +1,45 @@
+package oauthclient
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+)
+
+type Controller struct {
+	svc service.Service
+}
+
+func NewOAuthClientController(store store.Factory) *Controller {
+	return &Controller{svc: service.NewService(store)}
+}
+
+// AdminOnly aborts if current user is not admin, only admin can register and change clients.
+func (c *Controller) AdminOnly(ctx *gin.Context) {
+	username := ctx.GetString(middleware.UserNameKey)
+	if !c.svc.Users().IsAdmin(ctx, username) {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrPermissionDenied, "only admin can manage oauth clients."), nil)
+		ctx.Abort()
+		return
+	}
+
+	ctx.Next()
+}
+
+// owned gets client in path, which must be registered by current user, or current user is admin.
+func (c *Controller) owned(ctx *gin.Context) (*v1.OAuthClient, error) {
+	client, err := c.svc.OAuthClients().Get(ctx, ctx.Param("client-id"), metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, err
+	}
+	username := ctx.GetString(middleware.UserNameKey)
+	if client.Username == username || c.svc.Users().IsAdmin(ctx, username) {
+		return client, nil
+	}
+	return nil, errors.WithCode(errors.ErrPermissionDenied, "oauth client %s is not owned by you.", client.ClientID)
+}
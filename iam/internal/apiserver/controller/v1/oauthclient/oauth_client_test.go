@@ -0,0 +1,87 @@
+package oauthclient
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOwned(t *testing.T) {
+	ctx := context.Background()
+	factory := fake.NewFactory()
+	c := NewOAuthClientController(factory)
+
+	for _, user := range []*v1.User{{Username: "root", IsAdmin: "true"}, {Username: "alice"}, {Username: "bob"}} {
+		if err := factory.User().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := &v1.OAuthClient{ClientID: "app", Username: "alice"}
+	if err := factory.OAuthClient().Create(ctx, client, metav1.CreateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		username string
+		allowed  bool
+	}{
+		{"alice", true},
+		{"root", true},
+		{"bob", false},
+	}
+	for _, tt := range tests {
+		gc, _ := gin.CreateTestContext(httptest.NewRecorder())
+		gc.Request = httptest.NewRequest("GET", "/v1/oauth-clients/app", nil)
+		gc.Params = gin.Params{{Key: "client-id", Value: "app"}}
+		gc.Set(middleware.UserNameKey, tt.username)
+
+		_, err := c.owned(gc)
+		if tt.allowed && err != nil {
+			t.Errorf("%s should manage client: %v", tt.username, err)
+		}
+		if !tt.allowed && errors.Code(err) != errors.ErrPermissionDenied {
+			t.Errorf("%s should not manage client of others, got %v", tt.username, err)
+		}
+	}
+
+	if list, _ := c.svc.OAuthClients().List(ctx, "bob", metav1.ListOperateMeta{}); list.TotalCount != 0 {
+		t.Errorf("clients of others should not be listed, got %d", list.TotalCount)
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	ctx := context.Background()
+	factory := fake.NewFactory()
+	c := NewOAuthClientController(factory)
+
+	for _, user := range []*v1.User{{Username: "root", IsAdmin: "true"}, {Username: "alice"}} {
+		if err := factory.User().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		username string
+		allowed  bool
+	}{{"root", true}, {"alice", false}, {"", false}} {
+		w := httptest.NewRecorder()
+		gc, _ := gin.CreateTestContext(w)
+		gc.Request = httptest.NewRequest("POST", "/v1/oauth-clients", nil)
+		gc.Set(middleware.UserNameKey, tt.username)
+
+		c.AdminOnly(gc)
+		if gc.IsAborted() == tt.allowed {
+			t.Errorf("%q: aborted got %v, want %v", tt.username, gc.IsAborted(), !tt.allowed)
+		}
+		if !tt.allowed && w.Code != http.StatusForbidden {
+			t.Errorf("%q: status got %d, want 403", tt.username, w.Code)
+		}
+	}
+}
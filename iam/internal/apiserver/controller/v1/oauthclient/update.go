@@ -0,0 +1,49 @@
+package oauthclient
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Update changes redirect uris and description, client id, secret and type can't be changed.
+func (c *Controller) Update(ctx *gin.Context) {
+	log.L(ctx).Info("update oauth client.")
+
+	var r v1.OAuthClient
+
+	if err := ctx.ShouldBind(&r); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&r); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if err := validateRedirectURIs(r.RedirectURIs); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	client, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	client.RedirectURIs = r.RedirectURIs
+	client.Description = r.Description
+
+	if err = c.svc.OAuthClients().Update(ctx, client, metav1.UpdateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, client)
+}
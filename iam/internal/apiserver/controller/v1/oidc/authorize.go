@@ -0,0 +1,216 @@
+package oidc
+
+import (
+	"github.com/gin-gonic/gin"
+	"html/template"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// authorizeRequest refers to https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
+type authorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+
+	// Following fields are only submitted by login form.
+	Username string `form:"username"`
+	Password string `form:"password"`
+	Code     string `form:"code"`
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in with IAM</title></head>
+<body>
+<h3>Sign in to {{.ClientName}}</h3>
+{{if .Error}}<p style="color:red">{{.Error}}</p>{{end}}
+<form method="post">
+{{range $k, $v := .Hidden}}<input type="hidden" name="{{$k}}" value="{{$v}}">
+{{end}}<p><input name="username" placeholder="Username" value="{{.Username}}" required autofocus></p>
+<p><input name="password" type="password" placeholder="Password" required></p>
+<p><input name="code" placeholder="MFA code, if enabled" autocomplete="one-time-code"></p>
+<p><button type="submit">Sign in</button></p>
+</form>
+</body>
+</html>
+`))
+
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in with IAM</title></head>
+<body><h3>Sign in failed</h3><p>{{.}}</p></body>
+</html>
+`))
+
+// Authorize shows login form, which posts to AuthorizeSubmit.
+func (c *Controller) Authorize(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into oauth authorize.")
+
+	var r authorizeRequest
+	_ = ctx.ShouldBindQuery(&r)
+
+	client, ok := c.validateAuthorize(ctx, &r)
+	if !ok {
+		return
+	}
+
+	renderLogin(ctx, http.StatusOK, client, &r, "")
+}
+
+// AuthorizeSubmit checks user with existing password check and MFA, then redirects back with code.
+func (c *Controller) AuthorizeSubmit(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into oauth authorize submit.")
+
+	var r authorizeRequest
+	_ = ctx.ShouldBind(&r)
+
+	client, ok := c.validateAuthorize(ctx, &r)
+	if !ok {
+		return
+	}
+
+	user, err := auth.Authenticate(ctx, r.Username, r.Password)
+	if err != nil {
+		log.L(ctx).Warnf("oauth authorize: authenticate user %s fail: %s", r.Username, err.Error())
+		renderLogin(ctx, http.StatusUnauthorized, client, &r, "Incorrect username or password.")
+		return
+	}
+	if auth.PasswordExpired(user) {
+		renderLogin(ctx, http.StatusForbidden, client, &r, "Password expired, change it before signing in.")
+		return
+	}
+	if user.MFAEnabled {
+		if !auth.VerifyMFACodeLimited(ctx, user, r.Code) {
+			renderLogin(ctx, http.StatusUnauthorized, client, &r, "MFA code is required or incorrect.")
+			return
+		}
+	} else if auth.MFARequired(user) {
+		renderLogin(ctx, http.StatusForbidden, client, &r, "MFA is required, enrol it before signing in.")
+		return
+	}
+
+	user.LoginAt = time.Now()
+	if err = c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		log.L(ctx).Errorf("oauth authorize: update user %s fail: %s", user.Username, err.Error())
+		redirectError(ctx, &r, errServerError, "update user fail.")
+		return
+	}
+
+	code, err := saveGrant(ctx, codeKeyPrefix, &grant{
+		ClientID:      client.ClientID,
+		Username:      user.Username,
+		Scope:         r.Scope,
+		AuthTime:      user.LoginAt.Unix(),
+		IssuedAt:      time.Now().UnixMilli(),
+		RedirectURI:   r.RedirectURI,
+		Nonce:         r.Nonce,
+		CodeChallenge: r.CodeChallenge,
+	}, c.opts.CodeTTL)
+	if err != nil {
+		log.L(ctx).Errorf("oauth authorize: save code fail: %s", err.Error())
+		redirectError(ctx, &r, errServerError, "issue code fail.")
+		return
+	}
+
+	redirect(ctx, &r, url.Values{"code": {code}})
+}
+
+// validateAuthorize writes error page or redirects back with error if request is invalid.
+func (c *Controller) validateAuthorize(ctx *gin.Context, r *authorizeRequest) (*v1.OAuthClient, bool) {
+	client, err := c.svc.OAuthClients().Get(ctx, r.ClientID, metav1.GetOperateMeta{})
+	if err != nil {
+		renderError(ctx, http.StatusBadRequest, "Unknown client.")
+		return nil, false
+	}
+	// Never redirect to unregistered uri, see: https://www.rfc-editor.org/rfc/rfc6749#section-4.1.2.1
+	if !client.AllowRedirectURI(r.RedirectURI) {
+		renderError(ctx, http.StatusBadRequest, "Redirect uri is not registered.")
+		return nil, false
+	}
+
+	if r.ResponseType != "code" {
+		redirectError(ctx, r, errUnsupportedResponseType, "only code is supported.")
+		return nil, false
+	}
+	if !validScope(r.Scope) {
+		redirectError(ctx, r, errInvalidScope, "scope must contain openid and only supported scopes.")
+		return nil, false
+	}
+	if client.Public && r.CodeChallenge == "" {
+		redirectError(ctx, r, errInvalidRequest, "public client must use PKCE.")
+		return nil, false
+	}
+	if r.CodeChallenge != "" && r.CodeChallengeMethod != codeChallengeS256 {
+		redirectError(ctx, r, errInvalidRequest, "code_challenge_method must be S256.")
+		return nil, false
+	}
+
+	return client, true
+}
+
+func renderLogin(ctx *gin.Context, status int, client *v1.OAuthClient, r *authorizeRequest, msg string) {
+	name := client.Name
+	if name == "" {
+		name = client.ClientID
+	}
+
+	ctx.Header("X-Frame-Options", "DENY")
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(status)
+	_ = loginPage.Execute(ctx.Writer, map[string]interface{}{
+		"ClientName": name,
+		"Error":      msg,
+		"Username":   r.Username,
+		"Hidden": map[string]string{
+			"response_type":         r.ResponseType,
+			"client_id":             r.ClientID,
+			"redirect_uri":          r.RedirectURI,
+			"scope":                 r.Scope,
+			"state":                 r.State,
+			"nonce":                 r.Nonce,
+			"code_challenge":        r.CodeChallenge,
+			"code_challenge_method": r.CodeChallengeMethod,
+		},
+	})
+}
+
+func renderError(ctx *gin.Context, status int, msg string) {
+	ctx.Header("X-Frame-Options", "DENY")
+	ctx.Status(status)
+	_ = errorPage.Execute(ctx.Writer, msg)
+}
+
+func redirectError(ctx *gin.Context, r *authorizeRequest, code, description string) {
+	redirect(ctx, r, url.Values{"error": {code}, "error_description": {description}})
+}
+
+// redirect sends user back to client with params and state.
+func redirect(ctx *gin.Context, r *authorizeRequest, params url.Values) {
+	u, err := url.Parse(r.RedirectURI)
+	if err != nil {
+		renderError(ctx, http.StatusBadRequest, "Invalid redirect uri.")
+		return
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	if r.State != "" {
+		q.Set("state", r.State)
+	}
+	u.RawQuery = q.Encode()
+
+	ctx.Redirect(http.StatusFound, u.String())
+}
@@ -0,0 +1,30 @@
+package oidc
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"net/http"
+)
+
+// Discovery serves /.well-known/openid-configuration, see:
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+func (c *Controller) Discovery(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.JSON(http.StatusOK, gin.H{
+		"issuer":                                c.opts.Issuer,
+		"authorization_endpoint":                c.endpoint("/oauth2/authorize"),
+		"token_endpoint":                        c.endpoint("/oauth2/token"),
+		"userinfo_endpoint":                     c.endpoint("/userinfo"),
+		"jwks_uri":                              c.endpoint("/.well-known/jwks.json"),
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{grantAuthorizationCode, grantRefreshToken},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{auth.SigningAlgorithm()},
+		"scopes_supported":                      supportedScopes,
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{codeChallengeS256},
+		"claims_supported": []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "preferred_username", "email", "groups",
+		},
+	})
+}
@@ -0,0 +1,98 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"time"
+)
+
+const (
+	codeKeyPrefix         = "iam.oauth-code."
+	refreshTokenKeyPrefix = "iam.oauth-refresh-token."
+	grantTokenLength      = 43
+
+	codeChallengeS256 = "S256"
+)
+
+// grant is saved in redis behind an authorization code or a refresh token.
+type grant struct {
+	ClientID string `json:"clientID"`
+	Username string `json:"username"`
+	Scope    string `json:"scope"`
+	AuthTime int64  `json:"authTime"`
+	// IssuedAt is unix milliseconds, used to check whether tokens of user are revoked.
+	IssuedAt int64 `json:"issuedAt"`
+
+	// Following fields are only used by authorization code.
+	RedirectURI   string `json:"redirectURI,omitempty"`
+	Nonce         string `json:"nonce,omitempty"`
+	CodeChallenge string `json:"codeChallenge,omitempty"`
+}
+
+// saveGrant saves grant with hash of a new random token, the raw token is only given to client.
+func saveGrant(ctx context.Context, prefix string, g *grant, ttl time.Duration) (string, error) {
+	token, err := idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, grantTokenLength)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(g)
+	if err != nil {
+		return "", err
+	}
+
+	client := conn.GetRedisClient().UniversalClient()
+	if err = client.Set(ctx, grantKey(prefix, token), data, ttl).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// takeGrant returns grant and deletes it, so that code and refresh token are single-use.
+// nil grant means token is invalid, expired or used.
+func takeGrant(ctx context.Context, prefix, token string) (*grant, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	key := grantKey(prefix, token)
+
+	data, err := client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the one who deletes it can use it.
+	n, err := client.Del(ctx, key).Result()
+	if err != nil || n != 1 {
+		return nil, err
+	}
+
+	var g grant
+	if err = json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func grantKey(prefix, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return prefix + hex.EncodeToString(sum[:])
+}
+
+// verifyCodeChallenge refers to https://www.rfc-editor.org/rfc/rfc7636#section-4.6
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if challenge == "" {
+		return verifier == ""
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:]) == challenge
+}
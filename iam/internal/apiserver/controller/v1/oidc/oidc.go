@@ -0,0 +1,89 @@
+package oidc
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"strings"
+)
+
+// Controller serves OpenID Connect provider with authorization code flow, see:
+// https://openid.net/specs/openid-connect-core-1_0.html
+type Controller struct {
+	svc  service.Service
+	opts *options.OIDCOpts
+}
+
+func NewOIDCController(store store.Factory, opts *options.OIDCOpts) *Controller {
+	// Refresh token must be revoked when password changed.
+	auth.KeepUserRevocationFor(opts.RefreshTokenTTL)
+	return &Controller{svc: service.NewService(store), opts: opts}
+}
+
+const (
+	ScopeOpenID        = "openid"
+	ScopeProfile       = "profile"
+	ScopeEmail         = "email"
+	ScopeGroups        = "groups"
+	ScopeOfflineAccess = "offline_access"
+)
+
+var supportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopeGroups, ScopeOfflineAccess}
+
+// OAuth error codes, see: https://www.rfc-editor.org/rfc/rfc6749#section-5.2
+const (
+	errInvalidRequest          = "invalid_request"
+	errInvalidClient           = "invalid_client"
+	errInvalidGrant            = "invalid_grant"
+	errUnauthorizedClient      = "unauthorized_client"
+	errUnsupportedGrantType    = "unsupported_grant_type"
+	errUnsupportedResponseType = "unsupported_response_type"
+	errInvalidScope            = "invalid_scope"
+	errAccessDenied            = "access_denied"
+	errServerError             = "server_error"
+	errInvalidToken            = "invalid_token"
+)
+
+// oauthError writes error in OAuth format rather than web.WriteResponse, because clients are standard libraries.
+func oauthError(c *gin.Context, status int, code, description string) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, gin.H{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func (c *Controller) endpoint(path string) string {
+	return strings.TrimSuffix(c.opts.Issuer, "/") + path
+}
+
+func hasScope(scope, s string) bool {
+	for _, v := range strings.Fields(scope) {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validScope requires openid and only supported scopes.
+func validScope(scope string) bool {
+	if !hasScope(scope, ScopeOpenID) {
+		return false
+	}
+	for _, v := range strings.Fields(scope) {
+		var ok bool
+		for _, s := range supportedScopes {
+			if v == s {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
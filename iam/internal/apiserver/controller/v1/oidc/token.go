@@ -0,0 +1,173 @@
+package oidc
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	grantAuthorizationCode = "authorization_code"
+	grantRefreshToken      = "refresh_token"
+)
+
+// Token exchanges code or refresh token for tokens, see:
+// https://openid.net/specs/openid-connect-core-1_0.html#TokenEndpoint
+func (c *Controller) Token(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into oauth token.")
+
+	client, ok := c.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+
+	var g *grant
+	var err error
+
+	switch ctx.PostForm("grant_type") {
+	case grantAuthorizationCode:
+		g, err = takeGrant(ctx, codeKeyPrefix, ctx.PostForm("code"))
+		if err != nil {
+			log.L(ctx).Errorf("oauth token: take code fail: %s", err.Error())
+			oauthError(ctx, http.StatusInternalServerError, errServerError, "take code fail.")
+			return
+		}
+		if g == nil || g.ClientID != client.ClientID || g.RedirectURI != ctx.PostForm("redirect_uri") {
+			oauthError(ctx, http.StatusBadRequest, errInvalidGrant, "code is invalid, expired or used.")
+			return
+		}
+		if !verifyCodeChallenge(g.CodeChallenge, ctx.PostForm("code_verifier")) {
+			oauthError(ctx, http.StatusBadRequest, errInvalidGrant, "code_verifier doesn't match code_challenge.")
+			return
+		}
+	case grantRefreshToken:
+		g, err = takeGrant(ctx, refreshTokenKeyPrefix, ctx.PostForm("refresh_token"))
+		if err != nil {
+			log.L(ctx).Errorf("oauth token: take refresh token fail: %s", err.Error())
+			oauthError(ctx, http.StatusInternalServerError, errServerError, "take refresh token fail.")
+			return
+		}
+		if g == nil || g.ClientID != client.ClientID {
+			oauthError(ctx, http.StatusBadRequest, errInvalidGrant, "refresh token is invalid, expired or used.")
+			return
+		}
+		// Nonce is only in the id token issued with code.
+		g.Nonce = ""
+	default:
+		oauthError(ctx, http.StatusBadRequest, errUnsupportedGrantType, "grant_type must be authorization_code or refresh_token.")
+		return
+	}
+
+	if revoked, err := auth.UserRevoked(ctx, g.Username, time.UnixMilli(g.IssuedAt)); err != nil || revoked {
+		oauthError(ctx, http.StatusBadRequest, errInvalidGrant, "grant has been revoked.")
+		return
+	}
+
+	user, err := c.svc.Users().Get(ctx, g.Username, metav1.GetOperateMeta{})
+	if err != nil {
+		oauthError(ctx, http.StatusBadRequest, errInvalidGrant, "user not found.")
+		return
+	}
+	// Disabled user can't login, so it must not refresh either.
+	if user.Disabled {
+		oauthError(ctx, http.StatusBadRequest, errInvalidGrant, "user is disabled.")
+		return
+	}
+
+	c.issueTokens(ctx, client, user, g)
+}
+
+// authenticateClient supports client_secret_basic, client_secret_post and none for public client.
+func (c *Controller) authenticateClient(ctx *gin.Context) (*v1.OAuthClient, bool) {
+	clientID, secret, basic := ctx.Request.BasicAuth()
+	if basic {
+		// Credentials in basic are form-urlencoded, see: https://www.rfc-editor.org/rfc/rfc6749#section-2.3.1
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID = ctx.PostForm("client_id")
+		secret = ctx.PostForm("client_secret")
+	}
+
+	client, err := c.svc.OAuthClients().Get(ctx, clientID, metav1.GetOperateMeta{})
+	if err == nil && (client.Public && secret == "" || client.CompareSecret(secret)) {
+		return client, true
+	}
+
+	if basic {
+		ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	oauthError(ctx, http.StatusUnauthorized, errInvalidClient, "client authentication failed.")
+	return nil, false
+}
+
+// issueTokens returns access token for client, id token and a new refresh token. Access token only works
+// at userinfo, it can't be used as login token of apiserver.
+func (c *Controller) issueTokens(ctx *gin.Context, client *v1.OAuthClient, user *v1.User, g *grant) {
+	accessToken, expire, err := auth.GenerateClientToken(user, client.ClientID)
+	if err != nil {
+		log.L(ctx).Errorf("oauth token: generate access token fail: %s", err.Error())
+		oauthError(ctx, http.StatusInternalServerError, errServerError, "generate access token fail.")
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":                c.opts.Issuer,
+		"sub":                user.Username,
+		"aud":                client.ClientID,
+		"exp":                now.Add(c.opts.IDTokenTTL).Unix(),
+		"iat":                now.Unix(),
+		"auth_time":          g.AuthTime,
+		"preferred_username": user.Username,
+		"groups":             userGroups(user),
+	}
+	if g.Nonce != "" {
+		claims["nonce"] = g.Nonce
+	}
+	if hasScope(g.Scope, ScopeEmail) && user.Email != "" {
+		claims["email"] = user.Email
+	}
+	idToken, err := auth.SignClaims(claims)
+	if err != nil {
+		log.L(ctx).Errorf("oauth token: sign id token fail: %s", err.Error())
+		oauthError(ctx, http.StatusInternalServerError, errServerError, "sign id token fail.")
+		return
+	}
+
+	refreshToken, err := saveGrant(ctx, refreshTokenKeyPrefix, &grant{
+		ClientID: g.ClientID,
+		Username: g.Username,
+		Scope:    g.Scope,
+		AuthTime: g.AuthTime,
+		IssuedAt: g.IssuedAt,
+	}, c.opts.RefreshTokenTTL)
+	if err != nil {
+		log.L(ctx).Errorf("oauth token: save refresh token fail: %s", err.Error())
+		oauthError(ctx, http.StatusInternalServerError, errServerError, "issue refresh token fail.")
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+	ctx.JSON(http.StatusOK, gin.H{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int64(time.Until(expire).Seconds()),
+		"refresh_token": refreshToken,
+		"id_token":      idToken,
+		"scope":         g.Scope,
+	})
+}
+
+func userGroups(user *v1.User) []string {
+	if user.Groups == nil {
+		return []string{}
+	}
+	return user.Groups
+}
@@ -0,0 +1,58 @@
+package oidc
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"strings"
+)
+
+// BearerAuth authenticates access token issued by Token, login tokens of apiserver are refused, see:
+// https://www.rfc-editor.org/rfc/rfc6750#section-2.1
+func (c *Controller) BearerAuth(ctx *gin.Context) {
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if token == "" || token == ctx.GetHeader("Authorization") {
+		ctx.Header("WWW-Authenticate", `Bearer realm="oauth"`)
+		oauthError(ctx, http.StatusUnauthorized, errInvalidRequest, "bearer token is required.")
+		ctx.Abort()
+		return
+	}
+
+	username, err := auth.ParseClientToken(ctx, token)
+	if err != nil {
+		log.L(ctx).Warnf("oauth userinfo: %s", err.Error())
+		ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		oauthError(ctx, http.StatusUnauthorized, errInvalidToken, "access token is invalid, expired or revoked.")
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set(middleware.UserNameKey, username)
+	ctx.Next()
+}
+
+// UserInfo returns claims of user authenticated by access token, see:
+// https://openid.net/specs/openid-connect-core-1_0.html#UserInfo
+func (c *Controller) UserInfo(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into oauth userinfo.")
+
+	user, err := c.svc.Users().Get(ctx, ctx.GetString(middleware.UserNameKey), metav1.GetOperateMeta{})
+	if err != nil || user.Disabled {
+		ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		oauthError(ctx, http.StatusUnauthorized, errInvalidToken, "user not found.")
+		return
+	}
+
+	res := gin.H{
+		"sub":                user.Username,
+		"preferred_username": user.Username,
+		"groups":             userGroups(user),
+	}
+	if user.Email != "" {
+		res["email"] = user.Email
+	}
+	ctx.JSON(http.StatusOK, res)
+}
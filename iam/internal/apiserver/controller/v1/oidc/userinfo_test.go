@@ -0,0 +1,89 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	redistest.Use()
+
+	opts := options.NewJwtOpts()
+	opts.Key = "test-secret"
+	auth.GetJwtSchemeOr(opts)
+
+	os.Exit(m.Run())
+}
+
+func TestUserInfo(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+	c := NewOIDCController(fake.NewFactory(), options.NewOIDCOpts())
+
+	for _, user := range []*v1.User{
+		{Username: "alice", Email: "alice@example.com", Groups: []string{"dev"}},
+		{Username: "bob", Disabled: true},
+	} {
+		if err := c.svc.Users().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	aliceToken, _, _ := auth.GenerateClientToken(&v1.User{Username: "alice"}, "app")
+	bobToken, _, _ := auth.GenerateClientToken(&v1.User{Username: "bob"}, "app")
+	loginToken, _ := auth.SignClaims(map[string]interface{}{
+		"aud":      "apiserver.iam.com",
+		"exp":      time.Now().Add(time.Hour).Unix(),
+		"jti":      "login",
+		"username": "alice",
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"client token", "Bearer " + aliceToken, http.StatusOK},
+		{"login token", "Bearer " + loginToken, http.StatusUnauthorized},
+		{"disabled user", "Bearer " + bobToken, http.StatusUnauthorized},
+		{"no token", "", http.StatusUnauthorized},
+		{"basic", "Basic YWxpY2U6cGFzcw==", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		gc, _ := gin.CreateTestContext(w)
+		gc.Request = httptest.NewRequest("GET", "/userinfo", nil)
+		if tt.header != "" {
+			gc.Request.Header.Set("Authorization", tt.header)
+		}
+
+		c.BearerAuth(gc)
+		if !gc.IsAborted() {
+			c.UserInfo(gc)
+		}
+		if w.Code != tt.code {
+			t.Errorf("%s: got %d %s, want %d", tt.name, w.Code, w.Body.String(), tt.code)
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+
+		var res map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &res)
+		if res["sub"] != "alice" || res["email"] != "alice@example.com" {
+			t.Errorf("%s: got %v", tt.name, res)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package password
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+)
+
+// ConfirmSchema serves as router: /v1/password-reset/confirm
+type ConfirmSchema struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// Confirm sets new password with a reset token.
+func (c *Controller) Confirm(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into password-reset confirm.")
+
+	var s ConfirmSchema
+
+	if err := ctx.ShouldBind(&s); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&s); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	// Token is consumed after all checks passed, so that user can retry with a better password.
+	if err := validator.CheckPasswordErr(s.NewPassword); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrValidation, err.Error()), nil)
+		return
+	}
+
+	username, err := lookupResetToken(ctx, s.Token)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "lookup reset token fail: %s", err.Error()), nil)
+		return
+	}
+	if username == "" {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrTokenInvalid, "reset token is invalid or expired."), nil)
+		return
+	}
+
+	user, err := c.svc.Users().Get(ctx, username, metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	policy := validator.GetPasswordPolicy()
+	if user.PasswordReused(s.NewPassword, policy.HistoryCount) {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrPasswordReused, "password was used in last %d times.", policy.HistoryCount), nil)
+		return
+	}
+
+	hashed, err := auth.Encrypt(s.NewPassword)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrEncrypt, "password encrypt fail."), nil)
+		return
+	}
+	user.RotatePassword(hashed, policy.HistoryCount)
+
+	// Token is single-use, only the one who deletes it can change password.
+	var ok bool
+	ok, err = consumeResetToken(ctx, s.Token)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "consume reset token fail: %s", err.Error()), nil)
+		return
+	}
+	if !ok {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrTokenInvalid, "reset token is invalid or expired."), nil)
+		return
+	}
+
+	if err = c.svc.Users().ChangePassword(ctx, user); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	// Tokens issued with old password must not be used anymore.
+	if err = auth2.RevokeUser(ctx, user.Username); err != nil {
+		log.L(ctx).Errorf("revoke tokens of user %s fail: %s", user.Username, err.Error())
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
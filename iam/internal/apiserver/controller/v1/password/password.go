@@ -0,0 +1,19 @@
+package password
+
+import (
+	"istomyang.github.com/like-iam/component/pkg/notify"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"time"
+)
+
+// Controller serves self-service password reset, which needs no authentication.
+type Controller struct {
+	svc      service.Service
+	notifier notify.Notifier
+	tokenTTL time.Duration
+}
+
+func NewPasswordController(store store.Factory, notifier notify.Notifier, tokenTTL time.Duration) *Controller {
+	return &Controller{svc: service.NewService(store), notifier: notifier, tokenTTL: tokenTTL}
+}
@@ -0,0 +1,162 @@
+package password
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/component/pkg/notify"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	redistest.Use()
+
+	opts := options.NewJwtOpts()
+	opts.Key = "test-secret"
+	auth2.GetJwtSchemeOr(opts)
+
+	os.Exit(m.Run())
+}
+
+// notifier keeps messages instead of sending them.
+type notifier struct {
+	msgs []*notify.Message
+}
+
+func (n *notifier) Notify(_ context.Context, msg *notify.Message) error {
+	n.msgs = append(n.msgs, msg)
+	return nil
+}
+
+// token returns token in the last message.
+func (n *notifier) token(t *testing.T) string {
+	if len(n.msgs) == 0 {
+		t.Fatalf("no message is sent")
+	}
+	for _, line := range strings.Split(n.msgs[len(n.msgs)-1].Body, "\n") {
+		if len(line) == resetTokenLength && !strings.Contains(line, " ") {
+			return line
+		}
+	}
+	t.Fatalf("no token in message")
+	return ""
+}
+
+func newController(t *testing.T) (*Controller, *notifier) {
+	redistest.Use()
+	n := &notifier{}
+	c := NewPasswordController(fake.NewFactory(), n, time.Minute)
+
+	hashed, _ := auth.Encrypt("Old-pass1")
+	for _, user := range []*v1.User{
+		{Username: "alice", Email: "alice@example.com", Password: hashed},
+		{Username: "bob"},
+		{Username: "carol", Email: "carol@example.com", IdentityProvider: "github"},
+	} {
+		if err := c.svc.Users().Create(context.Background(), user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c, n
+}
+
+func serve(handler gin.HandlerFunc, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("POST", "/v1/password-reset", bytes.NewReader(data))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	handler(ctx)
+	return w
+}
+
+func TestReset(t *testing.T) {
+	c, n := newController(t)
+
+	// Unknown users, users without email and federated users get the same response without message.
+	for _, username := range []string{"nobody", "bob", "carol"} {
+		if w := serve(c.Reset, ResetSchema{Username: username}); w.Code != http.StatusOK {
+			t.Errorf("%s: got %d, want 200", username, w.Code)
+		}
+	}
+	if len(n.msgs) != 0 {
+		t.Fatalf("got %d messages, want none", len(n.msgs))
+	}
+
+	if w := serve(c.Reset, ResetSchema{Username: "alice"}); w.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", w.Code)
+	}
+	if len(n.msgs) != 1 || n.msgs[0].To != "alice@example.com" {
+		t.Fatalf("got %d messages, want one to alice@example.com", len(n.msgs))
+	}
+	token := n.token(t)
+
+	// Only hash of token is stored.
+	client := conn.GetRedisClient().UniversalClient()
+	if n := client.Exists(context.Background(), resetTokenKeyPrefix+token).Val(); n != 0 {
+		t.Errorf("raw token should not be stored")
+	}
+	if ttl := client.TTL(context.Background(), resetTokenKey(token)).Val(); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("ttl of hashed token got %s, want in a minute", ttl)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	c, n := newController(t)
+	ctx := context.Background()
+
+	old := validator.GetPasswordPolicy()
+	policy := *old
+	policy.HistoryCount = 3
+	validator.SetPasswordPolicy(&policy)
+	defer validator.SetPasswordPolicy(old)
+
+	serve(c.Reset, ResetSchema{Username: "alice"})
+	token := n.token(t)
+	issuedAt := time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		code     int
+	}{
+		{"weak password keeps token", token, "weak", http.StatusBadRequest},
+		{"reused password keeps token", token, "Old-pass1", http.StatusBadRequest},
+		{"wrong token", "wrong", "New-pass1", http.StatusUnauthorized},
+		{"ok", token, "New-pass1", http.StatusOK},
+		{"token is single-use", token, "New-pass2", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		w := serve(c.Confirm, ConfirmSchema{Token: tt.token, NewPassword: tt.password})
+		if w.Code != tt.code {
+			t.Errorf("%s: got %d %s, want %d", tt.name, w.Code, w.Body.String(), tt.code)
+		}
+	}
+
+	user, err := c.svc.Users().Get(ctx, "alice", metav1.GetOperateMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.Compare("New-pass1") {
+		t.Errorf("password should be changed")
+	}
+	if revoked, _ := auth2.UserRevoked(ctx, "alice", issuedAt); !revoked {
+		t.Errorf("tokens issued before reset should be revoked")
+	}
+}
@@ -0,0 +1,70 @@
+package password
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/notify"
+	"istomyang.github.com/like-iam/log"
+)
+
+// ResetSchema serves as router: /v1/password-reset
+type ResetSchema struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// Reset issues a reset token and sends it to user's email.
+// It always responds success, so that caller can't know whether user exists.
+func (c *Controller) Reset(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into password-reset.")
+
+	var s ResetSchema
+
+	if err := ctx.ShouldBind(&s); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&s); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	user, err := c.svc.Users().Get(ctx, s.Username, metav1.GetOperateMeta{})
+	if err != nil {
+		log.L(ctx).Warnf("password reset for unknown user %s: %s", s.Username, err.Error())
+		web.WriteResponse(ctx, nil, nil)
+		return
+	}
+	if user.Federated() {
+		log.L(ctx).Warnf("password reset for federated user %s.", s.Username)
+		web.WriteResponse(ctx, nil, nil)
+		return
+	}
+	if user.Email == "" {
+		log.L(ctx).Warnf("password reset for user %s without email.", s.Username)
+		web.WriteResponse(ctx, nil, nil)
+		return
+	}
+
+	token, err := issueResetToken(ctx, user.Username, c.tokenTTL)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "issue reset token fail: %s", err.Error()), nil)
+		return
+	}
+
+	msg := &notify.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse this token to reset your password in %s:\n\n%s\n\n"+
+			"If you did not ask for it, just ignore this message.", user.Username, c.tokenTTL, token),
+	}
+	if err = c.notifier.Notify(ctx, msg); err != nil {
+		log.L(ctx).Errorf("send password reset token to user %s fail: %s", user.Username, err.Error())
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
@@ -0,0 +1,52 @@
+package password
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"time"
+)
+
+const (
+	resetTokenKeyPrefix = "iam.password-reset."
+	resetTokenLength    = 32
+)
+
+// issueResetToken saves hash of a new token with username in redis, the raw token is only sent to user.
+func issueResetToken(ctx context.Context, username string, ttl time.Duration) (string, error) {
+	token, err := idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, resetTokenLength)
+	if err != nil {
+		return "", err
+	}
+
+	client := conn.GetRedisClient().UniversalClient()
+	if err = client.Set(ctx, resetTokenKey(token), username, ttl).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// lookupResetToken returns username of token, empty username means token is invalid or expired.
+func lookupResetToken(ctx context.Context, token string) (string, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	username, err := client.Get(ctx, resetTokenKey(token)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return username, err
+}
+
+// consumeResetToken deletes token, false means token has been used by others in the meantime.
+func consumeResetToken(ctx context.Context, token string) (bool, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	n, err := client.Del(ctx, resetTokenKey(token)).Result()
+	return n == 1, err
+}
+
+func resetTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return resetTokenKeyPrefix + hex.EncodeToString(sum[:])
+}
@@ -3,11 +3,22 @@ package policy
 import (
 	"github.com/gin-gonic/gin"
 	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
 	"istomyang.github.com/like-iam/log"
 )
 
 func (c *Controller) List(ctx *gin.Context) {
 	log.L(ctx).Info("list policy.")
 
+	// Users only watch their own policies.
+	if watch.Requested(ctx) {
+		username := ctx.GetString(middleware.UserNameKey)
+		watch.Serve(ctx, watch.ResourcePolicies, func(owner string) bool {
+			return owner == username
+		})
+		return
+	}
+
 	web.WriteResponse(ctx, nil, nil)
 }
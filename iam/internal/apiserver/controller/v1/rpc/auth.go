@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/log"
+	"strings"
+)
+
+// unauthenticated lists services which are called by other components in private network as before.
+var unauthenticated = []string{
+	"/proto.Cache/",
+}
+
+// UnaryInterceptor authenticates bearer token in `authorization` metadata, same as the one issued by login,
+// and puts username into context as gin context does, so that service layer sees the same actor.
+func UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for _, prefix := range unauthenticated {
+			if strings.HasPrefix(info.FullMethod, prefix) {
+				return handler(ctx, req)
+			}
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, ToStatus(errors.WithCode(errors.ErrMissingHeader, "authorization metadata is required."))
+		}
+		if !strings.HasPrefix(values[0], "Bearer ") {
+			return nil, ToStatus(errors.WithCode(errors.ErrInvalidAuthHeader, "authorization metadata must be bearer token."))
+		}
+
+		username, err := auth.AuthenticateToken(ctx, strings.TrimPrefix(values[0], "Bearer "))
+		if err != nil {
+			log.L(ctx).Warnf("authenticate grpc call %s fail: %s", info.FullMethod, err.Error())
+			return nil, ToStatus(errors.WithCode(errors.ErrTokenInvalid, err.Error()))
+		}
+
+		return handler(context.WithValue(ctx, middleware.UserNameKey, username), req)
+	}
+}
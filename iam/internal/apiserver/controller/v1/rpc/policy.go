@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Policies manages policies of current user, policy content is ladon policy in json.
+type Policies struct {
+	svc service.Service
+	pb.UnimplementedPoliciesServer
+}
+
+func NewPolicies(store store.Factory) *Policies {
+	return &Policies{svc: service.NewService(store)}
+}
+
+func (p *Policies) CreatePolicy(ctx context.Context, r *pb.Policy) (*pb.Policy, error) {
+	log.L(ctx).Info("create policy.")
+
+	policy := &v1.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: r.Name},
+		Username:   username(ctx),
+	}
+	if err := policy.Policy.Load(r.Policy); err != nil {
+		return nil, ToStatus(errors.WithCode(errors.ErrValidation, "policy must be ladon policy in json: %s", err.Error()))
+	}
+	if err := p.svc.Policies().Create(ctx, policy, metav1.CreateOperateMeta{}); err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return toPolicy(policy), nil
+}
+
+func (p *Policies) GetPolicy(ctx context.Context, r *pb.GetRequest) (*pb.Policy, error) {
+	log.L(ctx).Info("get policy.")
+
+	policy, err := p.svc.Policies().Get(ctx, username(ctx), r.Name, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return toPolicy(policy), nil
+}
+
+func (p *Policies) ListPolicies(ctx context.Context, r *pb.ListRequest) (*pb.PolicyList, error) {
+	log.L(ctx).Info("list policy.")
+
+	policies, err := p.svc.Policies().List(ctx, username(ctx), listMeta(r))
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+
+	reply := &pb.PolicyList{Count: policies.TotalCount}
+	for _, policy := range policies.Items {
+		reply.Items = append(reply.Items, toPolicy(policy))
+	}
+	return reply, nil
+}
+
+func (p *Policies) UpdatePolicy(ctx context.Context, r *pb.Policy) (*pb.Policy, error) {
+	log.L(ctx).Info("update policy.")
+
+	policy, err := p.svc.Policies().Get(ctx, username(ctx), r.Name, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+
+	policy.Policy = v1.AuthzPolicy{}
+	if err = policy.Policy.Load(r.Policy); err != nil {
+		return nil, ToStatus(errors.WithCode(errors.ErrValidation, "policy must be ladon policy in json: %s", err.Error()))
+	}
+	if err = p.svc.Policies().Update(ctx, policy, metav1.UpdateOperateMeta{}); err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return toPolicy(policy), nil
+}
+
+func (p *Policies) DeletePolicy(ctx context.Context, r *pb.DeleteRequest) (*pb.DeleteReply, error) {
+	log.L(ctx).Info("delete policy.")
+
+	if err := p.svc.Policies().Delete(ctx, username(ctx), r.Name, metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return &pb.DeleteReply{}, nil
+}
+
+func toPolicy(policy *v1.Policy) *pb.Policy {
+	return &pb.Policy{
+		InstanceId: policy.InstanceID,
+		Name:       policy.Name,
+		Username:   policy.Username,
+		Policy:     policy.Policy.String(),
+		CreatedAt:  formatTime(policy.CreatedAt),
+		UpdatedAt:  formatTime(policy.UpdatedAt),
+	}
+}
@@ -0,0 +1,126 @@
+// Package rpc serves users, secrets and policies in grpc, it shares service layer with http controllers.
+package rpc
+
+import (
+	"context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// errorDomain is put into ErrorInfo detail, Reason of it is the code of error.
+const errorDomain = "apiserver.iam.com"
+
+// grpcCodes maps codes not registered to grpc codes, registered ones follow their http status.
+var grpcCodes = map[int]grpccodes.Code{
+	errors.ErrBind:              grpccodes.InvalidArgument,
+	errors.ErrValidation:        grpccodes.InvalidArgument,
+	errors.ErrTokenInvalid:      grpccodes.Unauthenticated,
+	errors.ErrPageNotFound:      grpccodes.NotFound,
+	errors.ErrSignatureInvalid:  grpccodes.Unauthenticated,
+	errors.ErrExpired:           grpccodes.Unauthenticated,
+	errors.ErrInvalidAuthHeader: grpccodes.Unauthenticated,
+	errors.ErrMissingHeader:     grpccodes.Unauthenticated,
+	errors.ErrPermissionDenied:  grpccodes.PermissionDenied,
+
+	codes.ErrUserNotFound:      grpccodes.NotFound,
+	codes.ErrUserAlreadyExist:  grpccodes.AlreadyExists,
+	codes.ErrUserDisabled:      grpccodes.PermissionDenied,
+	codes.ErrReachMaxCount:     grpccodes.ResourceExhausted,
+	codes.ErrSecretNotFound:    grpccodes.NotFound,
+	codes.ErrSecretAlreadyExit: grpccodes.AlreadyExists,
+	codes.ErrPolicyNotFound:    grpccodes.NotFound,
+	codes.ErrPolicyAlreadyExit: grpccodes.AlreadyExists,
+}
+
+// ToStatus converts err returned by service into grpc status, like web.WriteResponse does for http.
+// Code of err is carried in ErrorInfo detail, so that clients can tell errors as http clients do.
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := errors.Code(err)
+	coder := errors.AsCode(err)
+
+	c, ok := grpcCodes[code]
+	if !ok {
+		c = fromHTTPStatus(coder.HTTPCode())
+	}
+	// Like http, message of error out of known codes is not exposed.
+	message := coder.Message()
+	if ok {
+		message = err.Error()
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   strconv.Itoa(code),
+		Domain:   errorDomain,
+		Metadata: map[string]string{},
+	}
+	if ref := coder.Reference(); ref != "" {
+		info.Metadata["reference"] = ref
+	}
+	s, e := status.New(c, message).WithDetails(info)
+	if e != nil {
+		return status.Error(c, message)
+	}
+	return s.Err()
+}
+
+func fromHTTPStatus(code int) grpccodes.Code {
+	switch code {
+	case http.StatusOK:
+		return grpccodes.OK
+	case http.StatusBadRequest:
+		return grpccodes.InvalidArgument
+	case http.StatusUnauthorized:
+		return grpccodes.Unauthenticated
+	case http.StatusForbidden:
+		return grpccodes.PermissionDenied
+	case http.StatusNotFound:
+		return grpccodes.NotFound
+	case http.StatusConflict:
+		return grpccodes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return grpccodes.ResourceExhausted
+	default:
+		return grpccodes.Internal
+	}
+}
+
+// username returns current user set by UnaryInterceptor.
+func username(ctx context.Context) string {
+	name, _ := ctx.Value(middleware.UserNameKey).(string)
+	return name
+}
+
+// listMeta returns the first page if limit or offset is not given.
+func listMeta(r *pb.ListRequest) metav1.ListOperateMeta {
+	limit, offset := int64(20), int64(0)
+	if r.Limit != nil {
+		limit = r.GetLimit()
+	}
+	if r.Offset != nil {
+		offset = r.GetOffset()
+	}
+	return metav1.ListOperateMeta{Limit: &limit, Offset: &offset}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	stderrors "errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/test/redistest"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	redistest.Use()
+
+	opts := options.NewJwtOpts()
+	opts.Key = "test-secret"
+	auth2.GetJwtSchemeOr(opts)
+
+	os.Exit(m.Run())
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    grpccodes.Code
+		message string
+		reason  int
+	}{
+		{"permission", errors.WithCode(errors.ErrPermissionDenied, "only admin."), grpccodes.PermissionDenied, "only admin.", errors.ErrPermissionDenied},
+		{"validation", errors.WithCode(errors.ErrValidation, "bad name."), grpccodes.InvalidArgument, "bad name.", errors.ErrValidation},
+		{"not found", errors.WithCode(codes.ErrUserNotFound, "user a not found."), grpccodes.NotFound, "user a not found.", codes.ErrUserNotFound},
+		{"exist", errors.WithCode(codes.ErrSecretAlreadyExit, "secret exists."), grpccodes.AlreadyExists, "secret exists.", codes.ErrSecretAlreadyExit},
+		{"max count", errors.WithCode(codes.ErrReachMaxCount, "too many."), grpccodes.ResourceExhausted, "too many.", codes.ErrReachMaxCount},
+		{"unknown", stderrors.New("dial mysql: refused"), grpccodes.Internal, "An internal server error occurs.", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := status.FromError(ToStatus(tt.err))
+			if !ok {
+				t.Fatalf("should be grpc status")
+			}
+			if s.Code() != tt.code {
+				t.Errorf("code got %v, want %v", s.Code(), tt.code)
+			}
+			if s.Message() != tt.message {
+				t.Errorf("message got %q, want %q", s.Message(), tt.message)
+			}
+
+			details := s.Details()
+			if len(details) != 1 {
+				t.Fatalf("details got %d, want 1", len(details))
+			}
+			info, ok := details[0].(*errdetails.ErrorInfo)
+			if !ok {
+				t.Fatalf("detail got %T, want ErrorInfo", details[0])
+			}
+			if info.Reason != strconv.Itoa(tt.reason) || info.Domain != errorDomain {
+				t.Errorf("error info got %s/%s, want %d/%s", info.Domain, info.Reason, tt.reason, errorDomain)
+			}
+		})
+	}
+}
+
+func TestToStatus_Passthrough(t *testing.T) {
+	if ToStatus(nil) != nil {
+		t.Errorf("nil should stay nil")
+	}
+
+	err := status.Error(grpccodes.Unauthenticated, "token expired.")
+	if got := ToStatus(err); got != err {
+		t.Errorf("grpc status should be returned as is, got %v", got)
+	}
+}
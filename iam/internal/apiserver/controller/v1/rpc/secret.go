@@ -0,0 +1,109 @@
+package rpc
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Secrets manages secrets of current user, secrets are identified by secret id as http api does.
+type Secrets struct {
+	svc service.Service
+	pb.UnimplementedSecretsServer
+}
+
+func NewSecrets(store store.Factory) *Secrets {
+	return &Secrets{svc: service.NewService(store)}
+}
+
+func (s *Secrets) CreateSecret(ctx context.Context, r *pb.Secret) (*pb.Secret, error) {
+	log.L(ctx).Info("create secret.")
+
+	secret := &v1.Secret{
+		ObjectMeta:  metav1.ObjectMeta{Name: r.Name},
+		Username:    username(ctx),
+		Expires:     r.Expires,
+		Description: r.Description,
+	}
+	secret.SecretID, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 36)
+	secret.SecretKey, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 32)
+
+	if err := s.svc.Secrets().Create(ctx, secret, metav1.CreateOperateMeta{}); err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return toSecret(secret), nil
+}
+
+func (s *Secrets) GetSecret(ctx context.Context, r *pb.GetRequest) (*pb.Secret, error) {
+	log.L(ctx).Info("get secret.")
+
+	secret, err := s.svc.Secrets().Get(ctx, username(ctx), r.Name, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return toSecret(secret), nil
+}
+
+func (s *Secrets) ListSecrets(ctx context.Context, r *pb.ListRequest) (*pb.SecretList, error) {
+	log.L(ctx).Info("list secrets.")
+
+	secrets, err := s.svc.Secrets().List(ctx, username(ctx), listMeta(r))
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+
+	reply := &pb.SecretList{Count: secrets.TotalCount}
+	for _, secret := range secrets.Items {
+		reply.Items = append(reply.Items, toSecret(secret))
+	}
+	return reply, nil
+}
+
+// UpdateSecret only updates expires and description, secret id and key never change.
+func (s *Secrets) UpdateSecret(ctx context.Context, r *pb.Secret) (*pb.Secret, error) {
+	log.L(ctx).Info("update secret.")
+
+	secret, err := s.svc.Secrets().Get(ctx, username(ctx), r.SecretId, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+
+	secret.Expires = r.Expires
+	secret.Description = r.Description
+	if err = s.svc.Secrets().Update(ctx, secret, metav1.UpdateOperateMeta{}); err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return toSecret(secret), nil
+}
+
+func (s *Secrets) DeleteSecret(ctx context.Context, r *pb.DeleteRequest) (*pb.DeleteReply, error) {
+	log.L(ctx).Info("delete secret.")
+
+	if err := s.svc.Secrets().Delete(ctx, username(ctx), r.Name, metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return &pb.DeleteReply{}, nil
+}
+
+func toSecret(secret *v1.Secret) *pb.Secret {
+	return &pb.Secret{
+		InstanceId:  secret.InstanceID,
+		Name:        secret.Name,
+		Username:    secret.Username,
+		SecretId:    secret.SecretID,
+		SecretKey:   secret.SecretKey,
+		Expires:     secret.Expires,
+		Description: secret.Description,
+		CreatedAt:   formatTime(secret.CreatedAt),
+		UpdatedAt:   formatTime(secret.UpdatedAt),
+	}
+}
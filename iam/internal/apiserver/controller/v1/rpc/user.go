@@ -0,0 +1,157 @@
+package rpc
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+	"strconv"
+)
+
+// Users manages users, only admin can create, list and delete users, others can get and update themselves.
+type Users struct {
+	svc service.Service
+	pb.UnimplementedUsersServer
+}
+
+func NewUsers(store store.Factory) *Users {
+	return &Users{svc: service.NewService(store)}
+}
+
+func (u *Users) CreateUser(ctx context.Context, r *pb.User) (*pb.User, error) {
+	log.L(ctx).Info("create a user.")
+
+	if !u.svc.Users().IsAdmin(ctx, username(ctx)) {
+		return nil, ToStatus(errors.WithCode(errors.ErrPermissionDenied, "only admin can create users."))
+	}
+
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: r.Name},
+		Username:   r.Username,
+		Password:   r.Password,
+		Email:      r.Email,
+		Groups:     r.Groups,
+	}
+	if r.IsAdmin {
+		user.IsAdmin = strconv.FormatBool(r.IsAdmin)
+	}
+
+	if err := u.svc.Users().Register(ctx, user); err != nil {
+		return nil, ToStatus(err)
+	}
+
+	return toUser(user), nil
+}
+
+func (u *Users) GetUser(ctx context.Context, r *pb.GetRequest) (*pb.User, error) {
+	log.L(ctx).Info("get a user.")
+
+	if r.Name != username(ctx) && !u.svc.Users().IsAdmin(ctx, username(ctx)) {
+		return nil, ToStatus(errors.WithCode(errors.ErrPermissionDenied, "only admin can get other users."))
+	}
+
+	user, err := u.svc.Users().Get(ctx, r.Name, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+	if user == nil {
+		return nil, ToStatus(errors.WithCode(codes.ErrUserNotFound, "user %s not found.", r.Name))
+	}
+
+	return toUser(user), nil
+}
+
+func (u *Users) ListUsers(ctx context.Context, r *pb.ListRequest) (*pb.UserList, error) {
+	log.L(ctx).Info("list users.")
+
+	if !u.svc.Users().IsAdmin(ctx, username(ctx)) {
+		return nil, ToStatus(errors.WithCode(errors.ErrPermissionDenied, "only admin can list users."))
+	}
+
+	users, err := u.svc.Users().List(ctx, listMeta(r))
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+
+	reply := &pb.UserList{Count: users.TotalCount}
+	for _, user := range users.Items {
+		reply.Items = append(reply.Items, toUser(user))
+	}
+	return reply, nil
+}
+
+// UpdateUser updates email, and privileges if current user is admin. Password is changed by http api,
+// which checks the old one.
+func (u *Users) UpdateUser(ctx context.Context, r *pb.User) (*pb.User, error) {
+	log.L(ctx).Info("update a user.")
+
+	admin := u.svc.Users().IsAdmin(ctx, username(ctx))
+	if r.Username != username(ctx) && !admin {
+		return nil, ToStatus(errors.WithCode(errors.ErrPermissionDenied, "only admin can update other users."))
+	}
+
+	// Message carries all fields, so privileges are only patched by admin.
+	patch := &v1.UserPatch{Email: &r.Email}
+	if admin {
+		isAdmin := ""
+		if r.IsAdmin {
+			isAdmin = strconv.FormatBool(r.IsAdmin)
+		}
+		patch.IsAdmin, patch.Groups, patch.Disabled = &isAdmin, &r.Groups, &r.Disabled
+	}
+
+	user, err := u.svc.Users().Patch(ctx, r.Username, patch, admin)
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+
+	// Disabled user can't login, tokens issued before must not work either.
+	if user.Disabled {
+		if err = auth2.RevokeUser(ctx, user.Username); err != nil {
+			log.L(ctx).Errorf("revoke tokens of user %s fail: %s", user.Username, err.Error())
+		}
+	}
+
+	return toUser(user), nil
+}
+
+func (u *Users) DeleteUser(ctx context.Context, r *pb.DeleteRequest) (*pb.DeleteReply, error) {
+	log.L(ctx).Info("delete a user.")
+
+	if !u.svc.Users().IsAdmin(ctx, username(ctx)) {
+		return nil, ToStatus(errors.WithCode(errors.ErrPermissionDenied, "only admin can delete users."))
+	}
+
+	if err := u.svc.Users().Delete(ctx, r.Name, metav1.DeleteOperateMeta{}); err != nil {
+		return nil, ToStatus(err)
+	}
+
+	if err := auth2.RevokeUser(ctx, r.Name); err != nil {
+		log.L(ctx).Errorf("revoke tokens of user %s fail: %s", r.Name, err.Error())
+	}
+
+	return &pb.DeleteReply{}, nil
+}
+
+// toUser never carries password.
+func toUser(user *v1.User) *pb.User {
+	return &pb.User{
+		InstanceId: user.InstanceID,
+		Name:       user.Name,
+		Username:   user.Username,
+		Email:      user.Email,
+		IsAdmin:    user.Admin(),
+		Groups:     user.Groups,
+		MfaEnabled: user.MFAEnabled,
+		Disabled:   user.Disabled,
+		LoginAt:    formatTime(user.LoginAt),
+		CreatedAt:  formatTime(user.CreatedAt),
+		UpdatedAt:  formatTime(user.UpdatedAt),
+	}
+}
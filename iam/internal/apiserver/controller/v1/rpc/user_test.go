@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"istomyang.github.com/like-iam/test/redistest"
+	"testing"
+	"time"
+)
+
+func TestUpdateUser_DisableRevokesTokens(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	u := NewUsers(fake.NewFactory())
+	for _, user := range []*v1.User{
+		{Username: "root", Email: "root@example.com", IsAdmin: "true"},
+		{Username: "alice", Email: "alice@example.com"},
+		{Username: "bob", Email: "bob@example.com"},
+	} {
+		if err := u.svc.Users().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	issuedAt := time.Now().Add(-time.Second)
+	admin := context.WithValue(ctx, middleware.UserNameKey, "root")
+
+	if _, err := u.UpdateUser(admin, &pb.User{Username: "bob", Email: "bob@example.org"}); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := auth2.UserRevoked(ctx, "bob", issuedAt); revoked {
+		t.Errorf("tokens of enabled user should stay valid")
+	}
+
+	reply, err := u.UpdateUser(admin, &pb.User{Username: "alice", Email: "alice@example.com", Disabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Disabled {
+		t.Fatalf("user should be disabled")
+	}
+	if revoked, _ := auth2.UserRevoked(ctx, "alice", issuedAt); !revoked {
+		t.Errorf("tokens of disabled user should be revoked")
+	}
+}
@@ -0,0 +1,160 @@
+package scim
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type attribute struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	MultiValued   bool        `json:"multiValued"`
+	Required      bool        `json:"required"`
+	CaseExact     bool        `json:"caseExact"`
+	Mutability    string      `json:"mutability"`
+	Returned      string      `json:"returned"`
+	Uniqueness    string      `json:"uniqueness"`
+	SubAttributes []attribute `json:"subAttributes,omitempty"`
+}
+
+// attr returns a single-valued, optional and readWrite string attribute, modify it by fields.
+func attr(name string) attribute {
+	return attribute{Name: name, Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "none"}
+}
+
+func (a attribute) typed(t string) attribute {
+	a.Type = t
+	return a
+}
+
+func (a attribute) multi(sub ...attribute) attribute {
+	a.MultiValued = true
+	a.Type = "complex"
+	a.SubAttributes = sub
+	return a
+}
+
+func (a attribute) complex(sub ...attribute) attribute {
+	a.Type = "complex"
+	a.SubAttributes = sub
+	return a
+}
+
+func (a attribute) with(mutability, returned string) attribute {
+	a.Mutability, a.Returned = mutability, returned
+	return a
+}
+
+func (a attribute) unique() attribute {
+	a.Required, a.Uniqueness = true, "server"
+	return a
+}
+
+var schemas = []gin.H{
+	{
+		"schemas":     []string{schemaSchema},
+		"id":          schemaUser,
+		"name":        "User",
+		"description": "User Account",
+		"attributes": []attribute{
+			attr("userName").with("immutable", "default").unique(),
+			attr("name").complex(attr("formatted"), attr("givenName"), attr("familyName")),
+			attr("displayName"),
+			attr("emails").multi(attr("value"), attr("type"), attr("primary").typed("boolean")),
+			attr("active").typed("boolean"),
+			attr("password").with("writeOnly", "never"),
+			attr("groups").multi(attr("value"), attr("$ref").typed("reference"), attr("display")).with("readOnly", "default"),
+		},
+		"meta": gin.H{"resourceType": "Schema", "location": basePath + "/Schemas/" + schemaUser},
+	},
+	{
+		"schemas":     []string{schemaSchema},
+		"id":          schemaGroup,
+		"name":        "Group",
+		"description": "Group",
+		"attributes": []attribute{
+			attr("displayName").with("immutable", "default").unique(),
+			attr("members").multi(attr("value").with("immutable", "default"), attr("$ref").typed("reference"), attr("display")),
+		},
+		"meta": gin.H{"resourceType": "Schema", "location": basePath + "/Schemas/" + schemaGroup},
+	},
+}
+
+var resourceTypes = []gin.H{
+	{
+		"schemas":  []string{schemaResourceType},
+		"id":       "User",
+		"name":     "User",
+		"endpoint": "/Users",
+		"schema":   schemaUser,
+		"meta":     gin.H{"resourceType": "ResourceType", "location": basePath + "/ResourceTypes/User"},
+	},
+	{
+		"schemas":  []string{schemaResourceType},
+		"id":       "Group",
+		"name":     "Group",
+		"endpoint": "/Groups",
+		"schema":   schemaGroup,
+		"meta":     gin.H{"resourceType": "ResourceType", "location": basePath + "/ResourceTypes/Group"},
+	},
+}
+
+func (c *Controller) ServiceProviderConfig(ctx *gin.Context) {
+	write(ctx, http.StatusOK, gin.H{
+		"schemas":        []string{schemaServiceProviderConfig},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": c.opts.MaxResults},
+		"changePassword": gin.H{"supported": true},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the bearer token configured by --scim.token.",
+			"primary":     true,
+		}},
+		"meta": gin.H{"resourceType": "ServiceProviderConfig", "location": basePath + "/ServiceProviderConfig"},
+	})
+}
+
+func (c *Controller) ListSchemas(ctx *gin.Context) {
+	c.listAll(ctx, schemas)
+}
+
+func (c *Controller) GetSchema(ctx *gin.Context) {
+	getByID(ctx, schemas, "schema")
+}
+
+func (c *Controller) ListResourceTypes(ctx *gin.Context) {
+	c.listAll(ctx, resourceTypes)
+}
+
+func (c *Controller) GetResourceType(ctx *gin.Context) {
+	getByID(ctx, resourceTypes, "resource type")
+}
+
+// listAll responds all items in a page, discovery endpoints ignore pagination and filter.
+func (c *Controller) listAll(ctx *gin.Context, items []gin.H) {
+	var resources []interface{}
+	for _, i := range items {
+		resources = append(resources, i)
+	}
+	write(ctx, http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func getByID(ctx *gin.Context, items []gin.H, kind string) {
+	for _, i := range items {
+		if i["id"] == ctx.Param("id") {
+			write(ctx, http.StatusOK, i)
+			return
+		}
+	}
+	writeError(ctx, notFound("%s %s not found.", kind, ctx.Param("id")))
+}
@@ -0,0 +1,363 @@
+package scim
+
+import (
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"strconv"
+	"strings"
+)
+
+// attributes holds values of a resource to match filter, keys are lower-cased paths like "emails.value".
+type attributes map[string][]string
+
+// caseExact lists attributes compared case-sensitively, others are not.
+var caseExact = map[string]bool{
+	"id":         true,
+	"externalid": true,
+}
+
+// filter is parsed from filter parameter defined in RFC 7644 3.4.2.2,
+// complex attribute filters such as emails[type eq "work"] are not supported.
+type filter interface {
+	match(attrs attributes) bool
+}
+
+type compareFilter struct {
+	path  string
+	op    string
+	value string
+}
+
+func (f *compareFilter) match(attrs attributes) bool {
+	values := attrs[f.path]
+	if f.op == "pr" {
+		for _, v := range values {
+			if v != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if f.op == "ne" {
+		return !(&compareFilter{path: f.path, op: "eq", value: f.value}).match(attrs)
+	}
+
+	want := f.value
+	for _, v := range values {
+		if !caseExact[f.path] {
+			v, want = strings.ToLower(v), strings.ToLower(f.value)
+		}
+		if compare(v, f.op, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare(v, op, want string) bool {
+	switch op {
+	case "eq":
+		return v == want
+	case "co":
+		return strings.Contains(v, want)
+	case "sw":
+		return strings.HasPrefix(v, want)
+	case "ew":
+		return strings.HasSuffix(v, want)
+	case "gt":
+		return v > want
+	case "ge":
+		return v >= want
+	case "lt":
+		return v < want
+	case "le":
+		return v <= want
+	}
+	return false
+}
+
+type logicalFilter struct {
+	and         bool
+	left, right filter
+}
+
+func (f *logicalFilter) match(attrs attributes) bool {
+	if f.and {
+		return f.left.match(attrs) && f.right.match(attrs)
+	}
+	return f.left.match(attrs) || f.right.match(attrs)
+}
+
+type notFilter struct {
+	f filter
+}
+
+func (f *notFilter) match(attrs attributes) bool {
+	return !f.f.match(attrs)
+}
+
+var operators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"pr": true, "gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// parseFilter parses expression, empty expression matches everything and returns nil.
+func parseFilter(expr string) (filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, badRequest(errInvalidFilter, "unexpected %s in filter.", p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		switch ch := expr[i]; {
+		case ch == ' ':
+			i++
+		case ch == '(' || ch == ')':
+			tokens = append(tokens, token{text: string(ch)})
+			i++
+		case ch == '"':
+			j := i + 1
+			for ; j < len(expr) && expr[j] != '"'; j++ {
+				if expr[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(expr) {
+				return nil, badRequest(errInvalidFilter, "unterminated string in filter.")
+			}
+			s, err := strconv.Unquote(expr[i : j+1])
+			if err != nil {
+				return nil, badRequest(errInvalidFilter, "invalid string %s in filter.", expr[i:j+1])
+			}
+			tokens = append(tokens, token{text: s, quoted: true})
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(expr) && expr[j] != ' ' && expr[j] != '(' && expr[j] != ')'; j++ {
+			}
+			tokens = append(tokens, token{text: expr[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+// keyword consumes next token if it's word, case-insensitively.
+func (p *filterParser) keyword(word string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, badRequest(errInvalidFilter, "filter ends unexpectedly.")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) parseOr() (filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		var right filter
+		if right, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		var right filter
+		if right, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filter, error) {
+	if p.keyword("not") {
+		if !p.keyword("(") {
+			return nil, badRequest(errInvalidFilter, "not must be followed by (.")
+		}
+		f, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return &notFilter{f: f}, nil
+	}
+	if p.keyword("(") {
+		return p.parseGroup()
+	}
+	return p.parseCompare()
+}
+
+// parseGroup parses expression after ( till ).
+func (p *filterParser) parseGroup() (filter, error) {
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyword(")") {
+		return nil, badRequest(errInvalidFilter, "missing ) in filter.")
+	}
+	return f, nil
+}
+
+func (p *filterParser) parseCompare() (filter, error) {
+	path, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if path.quoted || strings.ContainsAny(path.text, "[]") {
+		return nil, badRequest(errInvalidFilter, "unsupported attribute path %s in filter.", path.text)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	f := &compareFilter{path: normalizePath(path.text), op: strings.ToLower(op.text)}
+	if op.quoted || !operators[f.op] {
+		return nil, badRequest(errInvalidFilter, "unsupported operator %s in filter.", op.text)
+	}
+	if f.op == "pr" {
+		return f, nil
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	f.value = value.text
+	if !value.quoted {
+		// true, false, null and numbers.
+		f.value = strings.ToLower(value.text)
+	}
+	return f, nil
+}
+
+// normalizePath lower-cases path and strips schema urn before it.
+func normalizePath(path string) string {
+	path = strings.ToLower(path)
+	for _, schema := range []string{schemaUser, schemaGroup} {
+		path = strings.TrimPrefix(path, strings.ToLower(schema)+":")
+	}
+	return path
+}
+
+// equalFilter returns value if f is `path eq value`, used to look up resource directly instead of listing.
+func equalFilter(f filter, path string) (string, bool) {
+	c, ok := f.(*compareFilter)
+	if !ok || c.op != "eq" || c.path != path {
+		return "", false
+	}
+	return c.value, true
+}
+
+// userFields maps attributes of user to fields of store.Filter, "active" is the opposite of disabled.
+var userFields = map[string]string{
+	"id":                store.UserFieldUsername,
+	"username":          store.UserFieldUsername,
+	"emails":            store.UserFieldEmail,
+	"emails.value":      store.UserFieldEmail,
+	"groups":            store.UserFieldGroups,
+	"groups.value":      store.UserFieldGroups,
+	"meta.created":      store.UserFieldCreatedAt,
+	"meta.lastmodified": store.UserFieldUpdatedAt,
+	"externalid":        store.FieldExtendPrefix + extendExternalID,
+	"displayname":       store.FieldExtendPrefix + extendDisplayName,
+	"name.givenname":    store.FieldExtendPrefix + extendGivenName,
+	"name.familyname":   store.FieldExtendPrefix + extendFamilyName,
+}
+
+// toUserFilter converts f into filter of store, so that users are filtered and paged by store.
+func toUserFilter(f filter) (*store.Filter, error) {
+	switch f := f.(type) {
+	case *logicalFilter:
+		left, err := toUserFilter(f.left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := toUserFilter(f.right)
+		if err != nil {
+			return nil, err
+		}
+		op := store.FilterOr
+		if f.and {
+			op = store.FilterAnd
+		}
+		return &store.Filter{Op: op, Filters: []*store.Filter{left, right}}, nil
+	case *notFilter:
+		sub, err := toUserFilter(f.f)
+		if err != nil {
+			return nil, err
+		}
+		return &store.Filter{Op: store.FilterNot, Filters: []*store.Filter{sub}}, nil
+	case *compareFilter:
+		if f.path == "active" {
+			return activeFilter(f)
+		}
+		field, ok := userFields[f.path]
+		if !ok {
+			return nil, badRequest(errInvalidFilter, "unsupported attribute %s in filter.", f.path)
+		}
+		if field == store.UserFieldGroups && f.op != "eq" && f.op != "ne" && f.op != "pr" {
+			return nil, badRequest(errInvalidFilter, "unsupported operator %s of %s in filter.", f.op, f.path)
+		}
+		return &store.Filter{Op: f.op, Field: field, Value: f.value, CaseExact: caseExact[f.path]}, nil
+	}
+	return nil, badRequest(errInvalidFilter, "unsupported filter.")
+}
+
+// activeFilter converts filter of active into filter of disabled, every user has active.
+func activeFilter(f *compareFilter) (*store.Filter, error) {
+	if f.op == "pr" {
+		return &store.Filter{Op: store.FilterPresent, Field: store.UserFieldUsername}, nil
+	}
+	active, err := strconv.ParseBool(f.value)
+	if err != nil || f.op != "eq" && f.op != "ne" {
+		return nil, badRequest(errInvalidFilter, "active can only be compared with true or false by eq or ne.")
+	}
+	return &store.Filter{Op: f.op, Field: store.UserFieldDisabled, Value: strconv.FormatBool(!active)}, nil
+}
@@ -0,0 +1,113 @@
+package scim
+
+import (
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	alice := attributes{
+		"id":           {"alice"},
+		"externalid":   {"A-1"},
+		"username":     {"Alice"},
+		"emails.value": {"alice@example.com"},
+		"active":       {"true"},
+		"meta.created": {"2024-01-02T00:00:00Z"},
+	}
+
+	tests := []struct {
+		expr    string
+		match   bool
+		invalid bool
+	}{
+		{`userName eq "alice"`, true, false},
+		{`USERNAME EQ "ALICE"`, true, false},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, true, false},
+		{`externalId eq "a-1"`, false, false},
+		{`externalId eq "A-1"`, true, false},
+		{`userName ne "bob"`, true, false},
+		{`emails.value co "example"`, true, false},
+		{`emails.value sw "alice@"`, true, false},
+		{`emails.value ew ".org"`, false, false},
+		{`displayName pr`, false, false},
+		{`active eq true`, true, false},
+		{`meta.created gt "2024-01-01T00:00:00Z"`, true, false},
+		{`userName eq "bob" or active eq true`, true, false},
+		{`userName eq "alice" and active eq false`, false, false},
+		{`not (userName eq "alice")`, false, false},
+		{`(userName eq "bob" or userName eq "alice") and emails.value pr`, true, false},
+		{`userName eq "a\"b"`, false, false},
+		{``, true, false},
+
+		{`userName eq`, false, true},
+		{`userName xx "alice"`, false, true},
+		{`userName eq "alice`, false, true},
+		{`(userName eq "alice"`, false, true},
+		{`not userName eq "alice"`, false, true},
+		{`emails[type eq "work"] pr`, false, true},
+		{`userName eq "alice" extra`, false, true},
+	}
+
+	for _, tt := range tests {
+		f, err := parseFilter(tt.expr)
+		if tt.invalid {
+			e, ok := err.(*scimError)
+			if !ok || e.scimType != errInvalidFilter {
+				t.Errorf("%s: got err %v, want invalidFilter", tt.expr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v", tt.expr, err)
+			continue
+		}
+		if got := f == nil || f.match(alice); got != tt.match {
+			t.Errorf("%s: match got %v, want %v", tt.expr, got, tt.match)
+		}
+	}
+}
+
+func TestToUserFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want *store.Filter
+	}{
+		{`userName eq "alice"`, &store.Filter{Op: store.FilterEq, Field: store.UserFieldUsername, Value: "alice"}},
+		{`id eq "alice"`, &store.Filter{Op: store.FilterEq, Field: store.UserFieldUsername, Value: "alice", CaseExact: true}},
+		{`externalId eq "A-1"`, &store.Filter{Op: store.FilterEq, Field: store.FieldExtendPrefix + extendExternalID, Value: "A-1", CaseExact: true}},
+		{`emails.value co "example"`, &store.Filter{Op: store.FilterContain, Field: store.UserFieldEmail, Value: "example"}},
+		{`active eq true`, &store.Filter{Op: store.FilterEq, Field: store.UserFieldDisabled, Value: "false"}},
+		{`active ne false`, &store.Filter{Op: store.FilterNe, Field: store.UserFieldDisabled, Value: "true"}},
+		{`meta.lastModified ge "2024-01-01T00:00:00Z"`, &store.Filter{Op: store.FilterGe, Field: store.UserFieldUpdatedAt, Value: "2024-01-01T00:00:00Z"}},
+		{`groups eq "ops" and not (name.givenName pr)`, &store.Filter{Op: store.FilterAnd, Filters: []*store.Filter{
+			{Op: store.FilterEq, Field: store.UserFieldGroups, Value: "ops"},
+			{Op: store.FilterNot, Filters: []*store.Filter{{Op: store.FilterPresent, Field: store.FieldExtendPrefix + extendGivenName}}},
+		}}},
+		{`meta.location pr`, nil},
+		{`groups co "op"`, nil},
+		{`active gt true`, nil},
+		{`active eq "yes"`, nil},
+	}
+
+	for _, tt := range tests {
+		f, err := parseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		got, err := toUserFilter(f)
+		if tt.want == nil {
+			if e, ok := err.(*scimError); !ok || e.scimType != errInvalidFilter {
+				t.Errorf("%s: got err %v, want invalidFilter", tt.expr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package scim
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+)
+
+type scimGroup struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []ref    `json:"members,omitempty"`
+	Meta        *meta    `json:"meta,omitempty"`
+}
+
+// toSCIMGroup maps group, id of group is its name, which can't be changed.
+// members are left empty if not loaded.
+func toSCIMGroup(ctx *gin.Context, group *v1.Group, members []*v1.User) *scimGroup {
+	r := &scimGroup{
+		Schemas:     []string{schemaGroup},
+		ID:          group.Name,
+		ExternalID:  group.ExternalID,
+		DisplayName: group.Name,
+		Meta:        newMeta(ctx, "Group", "Groups", group.Name, group.CreatedAt, group.UpdatedAt),
+	}
+	for _, m := range members {
+		r.Members = append(r.Members, ref{Value: m.Username, Ref: location(ctx, "Users", m.Username), Display: m.Username})
+	}
+	return r
+}
+
+func (g *scimGroup) attributes() attributes {
+	attrs := attributes{
+		"id":                {g.ID},
+		"externalid":        {g.ExternalID},
+		"displayname":       {g.DisplayName},
+		"meta.created":      {formatTime(g.Meta.Created)},
+		"meta.lastmodified": {formatTime(g.Meta.LastModified)},
+	}
+	for _, m := range g.Members {
+		attrs["members"] = append(attrs["members"], m.Value)
+		attrs["members.value"] = append(attrs["members.value"], m.Value)
+	}
+	return attrs
+}
+
+func (g *scimGroup) hasMember(username string) bool {
+	for _, m := range g.Members {
+		if m.Value == username {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *scimGroup) usernames() []string {
+	var usernames []string
+	for _, m := range g.Members {
+		usernames = append(usernames, m.Value)
+	}
+	return usernames
+}
+
+func (c *Controller) getGroup(ctx *gin.Context, id string) (*v1.Group, error) {
+	group, err := c.svc.Groups().Get(ctx, id, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, notFound("group %s not found.", id)
+	}
+	return group, nil
+}
+
+// loadGroup maps group with its members.
+func (c *Controller) loadGroup(ctx *gin.Context, group *v1.Group) (*scimGroup, error) {
+	members, err := c.svc.Groups().Members(ctx, group.Name)
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMGroup(ctx, group, members), nil
+}
+
+func (c *Controller) ListGroups(ctx *gin.Context) {
+	log.L(ctx).Info("scim list groups.")
+
+	f, err := parseFilter(ctx.Query("filter"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	var groups []*v1.Group
+	if displayName, ok := equalFilter(f, "displayname"); ok {
+		if group, err := c.getGroup(ctx, displayName); err == nil {
+			groups = append(groups, group)
+		}
+	} else {
+		limit, offset := int64(-1), int64(0)
+		list, err := c.svc.Groups().List(ctx, metav1.ListOperateMeta{Limit: &limit, Offset: &offset})
+		if err != nil {
+			writeError(ctx, err)
+			return
+		}
+		groups = list.Items
+	}
+
+	// Loading members is expensive, clients such as Azure AD exclude them when looking up groups.
+	withMembers := !excluded(ctx, "members")
+	var resources []interface{}
+	for _, group := range groups {
+		r := toSCIMGroup(ctx, group, nil)
+		if withMembers {
+			if r, err = c.loadGroup(ctx, group); err != nil {
+				writeError(ctx, err)
+				return
+			}
+		}
+		if f == nil || f.match(r.attributes()) {
+			resources = append(resources, r)
+		}
+	}
+	c.list(ctx, resources)
+}
+
+func (c *Controller) GetGroup(ctx *gin.Context) {
+	log.L(ctx).Info("scim get a group.")
+
+	group, err := c.getGroup(ctx, ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	r := toSCIMGroup(ctx, group, nil)
+	if !excluded(ctx, "members") {
+		if r, err = c.loadGroup(ctx, group); err != nil {
+			writeError(ctx, err)
+			return
+		}
+	}
+	write(ctx, http.StatusOK, r)
+}
+
+func (c *Controller) CreateGroup(ctx *gin.Context) {
+	log.L(ctx).Info("scim create a group.")
+
+	var r scimGroup
+	if err := ctx.ShouldBindJSON(&r); err != nil {
+		writeError(ctx, badRequest(errInvalidSyntax, err.Error()))
+		return
+	}
+	if r.DisplayName == "" {
+		writeError(ctx, badRequest(errInvalidValue, "displayName is required."))
+		return
+	}
+	if _, err := c.getGroup(ctx, r.DisplayName); err == nil {
+		writeError(ctx, conflict("group %s already exists.", r.DisplayName))
+		return
+	}
+	if err := c.checkMembers(ctx, &r); err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	group := &v1.Group{
+		ObjectMeta: metav1.ObjectMeta{Name: r.DisplayName},
+		ExternalID: r.ExternalID,
+	}
+	if err := c.svc.Groups().Create(ctx, group, metav1.CreateOperateMeta{}); err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	c.saveMembers(ctx, http.StatusCreated, group, &r)
+}
+
+func (c *Controller) ReplaceGroup(ctx *gin.Context) {
+	log.L(ctx).Info("scim replace a group.")
+
+	group, err := c.getGroup(ctx, ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	var r scimGroup
+	if err = ctx.ShouldBindJSON(&r); err != nil {
+		writeError(ctx, badRequest(errInvalidSyntax, err.Error()))
+		return
+	}
+
+	c.updateGroup(ctx, group, &r)
+}
+
+func (c *Controller) PatchGroup(ctx *gin.Context) {
+	log.L(ctx).Info("scim patch a group.")
+
+	group, err := c.getGroup(ctx, ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	var r patchRequest
+	if err = ctx.ShouldBindJSON(&r); err != nil {
+		writeError(ctx, badRequest(errInvalidSyntax, err.Error()))
+		return
+	}
+
+	patched, err := c.loadGroup(ctx, group)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	for _, op := range r.Operations {
+		if err = patched.patch(op); err != nil {
+			writeError(ctx, err)
+			return
+		}
+	}
+
+	c.updateGroup(ctx, group, patched)
+}
+
+func (c *Controller) updateGroup(ctx *gin.Context, group *v1.Group, r *scimGroup) {
+	if r.DisplayName != "" && r.DisplayName != group.Name {
+		writeError(ctx, badRequest(errMutability, "displayName can't be changed."))
+		return
+	}
+
+	if err := c.checkMembers(ctx, r); err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	group.ExternalID = r.ExternalID
+	if err := c.svc.Groups().Update(ctx, group, metav1.UpdateOperateMeta{}); err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	c.saveMembers(ctx, http.StatusOK, group, r)
+}
+
+// checkMembers fails if any member of r is not found.
+func (c *Controller) checkMembers(ctx *gin.Context, r *scimGroup) error {
+	for _, username := range r.usernames() {
+		if _, err := c.getUser(ctx, username); err != nil {
+			return badRequest(errInvalidValue, "member %s not found.", username)
+		}
+	}
+	return nil
+}
+
+// saveMembers makes members of r the only members of group, and responds group.
+func (c *Controller) saveMembers(ctx *gin.Context, status int, group *v1.Group, r *scimGroup) {
+	if err := c.svc.Groups().SetMembers(ctx, group.Name, r.usernames()); err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	res, err := c.loadGroup(ctx, group)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	if status == http.StatusCreated {
+		ctx.Header("Location", res.Meta.Location)
+	}
+	write(ctx, status, res)
+}
+
+func (c *Controller) DeleteGroup(ctx *gin.Context) {
+	log.L(ctx).Info("scim delete a group.")
+
+	group, err := c.getGroup(ctx, ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	if err = c.svc.Groups().Delete(ctx, group.Name, metav1.DeleteOperateMeta{}); err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,247 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations" binding:"required"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// normalize lower-cases op and path, op is case-insensitive since some clients send "Replace".
+func (o patchOperation) normalize() (string, string, error) {
+	op := strings.ToLower(o.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return "", "", badRequest(errInvalidSyntax, "unsupported op %s.", o.Op)
+	}
+	path := normalizePath(o.Path)
+	if op == "remove" && path == "" {
+		return "", "", badRequest(errNoTarget, "path is required to remove.")
+	}
+	if op != "remove" && len(o.Value) == 0 {
+		return "", "", badRequest(errInvalidValue, "value is required to %s.", op)
+	}
+	return op, path, nil
+}
+
+// splitValuePath splits `members[value eq "x"]` into path and filter.
+func splitValuePath(path string) (string, filter, error) {
+	i := strings.Index(path, "[")
+	if i < 0 {
+		return path, nil, nil
+	}
+	if !strings.HasSuffix(path, "]") {
+		return "", nil, badRequest(errInvalidPath, "invalid path %s.", path)
+	}
+	f, err := parseFilter(path[i+1 : len(path)-1])
+	if err != nil {
+		return "", nil, badRequest(errInvalidPath, "invalid filter in path %s.", path)
+	}
+	return path[:i], f, nil
+}
+
+// patch applies operation to user, changes are saved by caller.
+func (u *scimUser) patch(o patchOperation) error {
+	op, path, err := o.normalize()
+	if err != nil {
+		return err
+	}
+
+	// Without path, value is an object of attributes to add or replace.
+	if path == "" {
+		var attrs map[string]json.RawMessage
+		if err = json.Unmarshal(o.Value, &attrs); err != nil {
+			return badRequest(errInvalidValue, "value must be an object without path.")
+		}
+		for k, v := range attrs {
+			if err = u.patch(patchOperation{Op: op, Path: k, Value: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if op == "remove" {
+		return u.remove(path)
+	}
+	return u.set(path, o.Value)
+}
+
+func (u *scimUser) set(path string, value json.RawMessage) error {
+	var err error
+	switch path {
+	case "username":
+		err = json.Unmarshal(value, &u.UserName)
+	case "externalid":
+		err = json.Unmarshal(value, &u.ExternalID)
+	case "displayname":
+		err = json.Unmarshal(value, &u.DisplayName)
+	case "password":
+		err = json.Unmarshal(value, &u.Password)
+	case "active":
+		var active bool
+		active, err = decodeBool(value)
+		u.Active = &active
+	case "name":
+		err = json.Unmarshal(value, &u.Name)
+	case "name.givenname", "name.familyname", "name.formatted":
+		if u.Name == nil {
+			u.Name = &name{}
+		}
+		switch path {
+		case "name.givenname":
+			err = json.Unmarshal(value, &u.Name.GivenName)
+		case "name.familyname":
+			err = json.Unmarshal(value, &u.Name.FamilyName)
+		default:
+			err = json.Unmarshal(value, &u.Name.Formatted)
+		}
+	case "emails":
+		err = json.Unmarshal(value, &u.Emails)
+	case "emails.value", `emails[type eq "work"].value`, `emails[primary eq true].value`:
+		// Only one email is stored.
+		var v string
+		err = json.Unmarshal(value, &v)
+		u.Emails = []email{{Value: v, Type: "work", Primary: true}}
+	case "groups":
+		return badRequest(errMutability, "groups is read-only, patch members of group instead.")
+	default:
+		return badRequest(errInvalidPath, "unsupported path %s.", path)
+	}
+	if err != nil {
+		return badRequest(errInvalidValue, "invalid value of %s: %s", path, err.Error())
+	}
+	return nil
+}
+
+func (u *scimUser) remove(path string) error {
+	switch path {
+	case "externalid":
+		u.ExternalID = ""
+	case "displayname":
+		u.DisplayName = ""
+	case "name":
+		u.Name = nil
+	case "name.givenname", "name.familyname", "name.formatted":
+		if u.Name != nil {
+			return u.set(path, json.RawMessage(`""`))
+		}
+	case "emails", "emails.value", `emails[type eq "work"]`, `emails[type eq "work"].value`:
+		u.Emails = nil
+	default:
+		return badRequest(errInvalidPath, "unsupported path %s to remove.", path)
+	}
+	return nil
+}
+
+// decodeBool accepts boolean, or string of it which is sent by some clients.
+func decodeBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(s)
+}
+
+// patch applies operation to group, changes are saved by caller.
+func (g *scimGroup) patch(o patchOperation) error {
+	op, path, err := o.normalize()
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		var attrs map[string]json.RawMessage
+		if err = json.Unmarshal(o.Value, &attrs); err != nil {
+			return badRequest(errInvalidValue, "value must be an object without path.")
+		}
+		for k, v := range attrs {
+			if err = g.patch(patchOperation{Op: op, Path: k, Value: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, f, err := splitValuePath(path)
+	if err != nil {
+		return err
+	}
+	switch path {
+	case "displayname":
+		if op == "remove" {
+			return badRequest(errMutability, "displayName is required.")
+		}
+		err = json.Unmarshal(o.Value, &g.DisplayName)
+	case "externalid":
+		if op == "remove" {
+			g.ExternalID = ""
+			return nil
+		}
+		err = json.Unmarshal(o.Value, &g.ExternalID)
+	case "members":
+		return g.patchMembers(op, f, o.Value)
+	default:
+		return badRequest(errInvalidPath, "unsupported path %s.", path)
+	}
+	if err != nil {
+		return badRequest(errInvalidValue, "invalid value of %s: %s", path, err.Error())
+	}
+	return nil
+}
+
+func (g *scimGroup) patchMembers(op string, f filter, value json.RawMessage) error {
+	var members []ref
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &members); err != nil {
+			return badRequest(errInvalidValue, "members must be a list of {\"value\": username}.")
+		}
+	}
+
+	switch op {
+	case "add":
+		for _, m := range members {
+			if !g.hasMember(m.Value) {
+				g.Members = append(g.Members, ref{Value: m.Value})
+			}
+		}
+	case "replace":
+		g.Members = members
+	case "remove":
+		// Members are selected by filter in path, or listed in value, otherwise all are removed.
+		remove := func(m ref) bool { return true }
+		if f != nil {
+			remove = func(m ref) bool { return f.match(attributes{"value": {m.Value}}) }
+		} else if len(members) > 0 {
+			remove = func(m ref) bool {
+				for _, r := range members {
+					if r.Value == m.Value {
+						return true
+					}
+				}
+				return false
+			}
+		}
+		var kept []ref
+		for _, m := range g.Members {
+			if !remove(m) {
+				kept = append(kept, m)
+			}
+		}
+		g.Members = kept
+	}
+	return nil
+}
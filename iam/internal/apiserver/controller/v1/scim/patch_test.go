@@ -0,0 +1,128 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUserPatch(t *testing.T) {
+	active := true
+	base := func() *scimUser {
+		return &scimUser{
+			UserName:    "alice",
+			ExternalID:  "A-1",
+			DisplayName: "Alice",
+			Name:        &name{GivenName: "Alice", FamilyName: "Liddell"},
+			Emails:      []email{{Value: "alice@example.com", Type: "work", Primary: true}},
+			Active:      &active,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		op       patchOperation
+		want     func(u *scimUser)
+		scimType string
+	}{
+		{"replace active", patchOperation{Op: "replace", Path: "active", Value: json.RawMessage(`false`)},
+			func(u *scimUser) { inactive := false; u.Active = &inactive }, ""},
+		{"replace active in string", patchOperation{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)},
+			func(u *scimUser) { inactive := false; u.Active = &inactive }, ""},
+		{"replace without path", patchOperation{Op: "replace", Value: json.RawMessage(`{"displayName":"Al","active":false}`)},
+			func(u *scimUser) { inactive := false; u.DisplayName, u.Active = "Al", &inactive }, ""},
+		{"add given name", patchOperation{Op: "add", Path: "name.givenName", Value: json.RawMessage(`"Ally"`)},
+			func(u *scimUser) { u.Name.GivenName = "Ally" }, ""},
+		{"replace work email", patchOperation{Op: "replace", Path: `emails[type eq "work"].value`, Value: json.RawMessage(`"a@example.org"`)},
+			func(u *scimUser) { u.Emails = []email{{Value: "a@example.org", Type: "work", Primary: true}} }, ""},
+		{"schema urn path", patchOperation{Op: "replace", Path: "urn:ietf:params:scim:schemas:core:2.0:User:displayName", Value: json.RawMessage(`"Al"`)},
+			func(u *scimUser) { u.DisplayName = "Al" }, ""},
+		{"remove external id", patchOperation{Op: "remove", Path: "externalId"},
+			func(u *scimUser) { u.ExternalID = "" }, ""},
+		{"remove emails", patchOperation{Op: "remove", Path: "emails"},
+			func(u *scimUser) { u.Emails = nil }, ""},
+		{"remove name", patchOperation{Op: "remove", Path: "name"},
+			func(u *scimUser) { u.Name = nil }, ""},
+
+		{"unknown op", patchOperation{Op: "move", Path: "displayName", Value: json.RawMessage(`"x"`)}, nil, errInvalidSyntax},
+		{"remove without path", patchOperation{Op: "remove"}, nil, errNoTarget},
+		{"add without value", patchOperation{Op: "add", Path: "displayName"}, nil, errInvalidValue},
+		{"groups are read-only", patchOperation{Op: "add", Path: "groups", Value: json.RawMessage(`[{"value":"ops"}]`)}, nil, errMutability},
+		{"unknown path", patchOperation{Op: "replace", Path: "nickName", Value: json.RawMessage(`"x"`)}, nil, errInvalidPath},
+		{"remove username", patchOperation{Op: "remove", Path: "userName"}, nil, errInvalidPath},
+		{"invalid value", patchOperation{Op: "replace", Path: "active", Value: json.RawMessage(`"maybe"`)}, nil, errInvalidValue},
+		{"non-object without path", patchOperation{Op: "replace", Value: json.RawMessage(`"x"`)}, nil, errInvalidValue},
+	}
+
+	for _, tt := range tests {
+		u := base()
+		err := u.patch(tt.op)
+		if tt.scimType != "" {
+			if e, ok := err.(*scimError); !ok || e.scimType != tt.scimType {
+				t.Errorf("%s: got err %v, want %s", tt.name, err, tt.scimType)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v", tt.name, err)
+			continue
+		}
+		want := base()
+		tt.want(want)
+		if !reflect.DeepEqual(u, want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, u, want)
+		}
+	}
+}
+
+func TestGroupPatch(t *testing.T) {
+	members := func(names ...string) []ref {
+		var refs []ref
+		for _, n := range names {
+			refs = append(refs, ref{Value: n})
+		}
+		return refs
+	}
+
+	tests := []struct {
+		name     string
+		op       patchOperation
+		want     []ref
+		scimType string
+	}{
+		{"add members", patchOperation{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"carol"},{"value":"alice"}]`)},
+			members("alice", "bob", "carol"), ""},
+		{"replace members", patchOperation{Op: "replace", Path: "members", Value: json.RawMessage(`[{"value":"carol"}]`)},
+			members("carol"), ""},
+		{"remove member by filter", patchOperation{Op: "remove", Path: `members[value eq "alice"]`},
+			members("bob"), ""},
+		{"remove member by value", patchOperation{Op: "remove", Path: "members", Value: json.RawMessage(`[{"value":"bob"}]`)},
+			members("alice"), ""},
+		{"remove all members", patchOperation{Op: "remove", Path: "members"}, nil, ""},
+		{"add members without path", patchOperation{Op: "add", Value: json.RawMessage(`{"members":[{"value":"carol"}]}`)},
+			members("alice", "bob", "carol"), ""},
+
+		{"remove display name", patchOperation{Op: "remove", Path: "displayName"}, nil, errMutability},
+		{"invalid filter in path", patchOperation{Op: "remove", Path: `members[value eq]`}, nil, errInvalidPath},
+		{"unclosed path", patchOperation{Op: "remove", Path: `members[value eq "alice"`}, nil, errInvalidPath},
+		{"invalid members", patchOperation{Op: "add", Path: "members", Value: json.RawMessage(`"alice"`)}, nil, errInvalidValue},
+	}
+
+	for _, tt := range tests {
+		g := &scimGroup{DisplayName: "ops", Members: members("alice", "bob")}
+		err := g.patch(tt.op)
+		if tt.scimType != "" {
+			if e, ok := err.(*scimError); !ok || e.scimType != tt.scimType {
+				t.Errorf("%s: got err %v, want %s", tt.name, err, tt.scimType)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(g.Members, tt.want) {
+			t.Errorf("%s: members got %v, want %v", tt.name, g.Members, tt.want)
+		}
+	}
+}
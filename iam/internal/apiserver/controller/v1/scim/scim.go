@@ -0,0 +1,229 @@
+package scim
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schemas defined in RFC 7643 and RFC 7644.
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	schemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Values of scimType in error response.
+const (
+	errInvalidFilter = "invalidFilter"
+	errInvalidSyntax = "invalidSyntax"
+	errInvalidPath   = "invalidPath"
+	errInvalidValue  = "invalidValue"
+	errNoTarget      = "noTarget"
+	errMutability    = "mutability"
+	errUniqueness    = "uniqueness"
+)
+
+const (
+	contentType = "application/scim+json"
+	basePath    = "/scim/v2"
+
+	// client is the username of provisioning client, shown in logs.
+	client = "scim"
+)
+
+// Controller serves SCIM 2.0 provisioning endpoints, users are mapped onto v1.User and groups onto v1.Group.
+type Controller struct {
+	svc  service.Service
+	opts *options.SCIMOpts
+}
+
+func NewSCIMController(store store.Factory, opts *options.SCIMOpts) *Controller {
+	return &Controller{svc: service.NewService(store), opts: opts}
+}
+
+// Authenticate checks bearer token of provisioning client.
+func (c *Controller) Authenticate(ctx *gin.Context) {
+	header := ctx.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") ||
+		subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(c.opts.Token)) != 1 {
+		writeError(ctx, &scimError{status: http.StatusUnauthorized, detail: "bearer token is invalid."})
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set(middleware.UserNameKey, client)
+
+	ctx.Next()
+}
+
+type meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+func newMeta(ctx *gin.Context, resourceType, endpoint, id string, created, modified time.Time) *meta {
+	m := &meta{
+		ResourceType: resourceType,
+		Location:     location(ctx, endpoint, id),
+	}
+	if !created.IsZero() {
+		m.Created = &created
+	}
+	if !modified.IsZero() {
+		m.LastModified = &modified
+	}
+	return m
+}
+
+// ref is a reference to other resource, such as members of group.
+type ref struct {
+	Value   string `json:"value"`
+	Ref     string `json:"$ref,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type listResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// scimError is an error responded in SCIM format.
+type scimError struct {
+	status   int
+	scimType string
+	detail   string
+}
+
+func (e *scimError) Error() string {
+	return e.detail
+}
+
+func badRequest(scimType, format string, args ...interface{}) *scimError {
+	return &scimError{status: http.StatusBadRequest, scimType: scimType, detail: fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...interface{}) *scimError {
+	return &scimError{status: http.StatusNotFound, detail: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...interface{}) *scimError {
+	return &scimError{status: http.StatusConflict, scimType: errUniqueness, detail: fmt.Sprintf(format, args...)}
+}
+
+func internalError(err error) *scimError {
+	return &scimError{status: http.StatusInternalServerError, detail: err.Error()}
+}
+
+func write(ctx *gin.Context, status int, data interface{}) {
+	ctx.Header("Content-Type", contentType)
+	ctx.JSON(status, data)
+}
+
+// fromCode converts coded error of service, rules of creating users are checked there.
+func fromCode(err error) *scimError {
+	switch errors.Code(err) {
+	case errors.ErrValidation:
+		return badRequest(errInvalidValue, "%s", err.Error())
+	case codes.ErrUserAlreadyExist:
+		return conflict("%s", err.Error())
+	default:
+		return internalError(err)
+	}
+}
+
+// writeError responds err in SCIM format, errors not from this package are converted by fromCode.
+func writeError(ctx *gin.Context, err error) {
+	e, ok := err.(*scimError)
+	if !ok {
+		e = fromCode(err)
+	}
+	write(ctx, e.status, gin.H{
+		"schemas":  []string{schemaError},
+		"status":   strconv.Itoa(e.status),
+		"scimType": e.scimType,
+		"detail":   e.detail,
+	})
+}
+
+func location(ctx *gin.Context, endpoint, id string) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s/%s/%s", scheme, ctx.Request.Host, basePath, endpoint, id)
+}
+
+// page returns 1-based startIndex and count of a list request.
+func (c *Controller) page(ctx *gin.Context) (int, int) {
+	start, err := strconv.Atoi(ctx.Query("startIndex"))
+	if err != nil || start < 1 {
+		start = 1
+	}
+	count, err := strconv.Atoi(ctx.Query("count"))
+	if err != nil || count > c.opts.MaxResults {
+		count = c.opts.MaxResults
+	}
+	if count < 0 {
+		count = 0
+	}
+	return start, count
+}
+
+// list responds a page of resources.
+func (c *Controller) list(ctx *gin.Context, resources []interface{}) {
+	start, count := c.page(ctx)
+	total := len(resources)
+
+	from := start - 1
+	if from > total {
+		from = total
+	}
+	to := from + count
+	if to > total {
+		to = total
+	}
+
+	writeList(ctx, start, total, resources[from:to])
+}
+
+// writeList responds page of resources starting at start, total is count of all resources matched.
+func writeList(ctx *gin.Context, start, total int, page []interface{}) {
+	write(ctx, http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   start,
+		ItemsPerPage: len(page),
+		Resources:    append([]interface{}{}, page...),
+	})
+}
+
+// excluded tells whether attr is listed in excludedAttributes.
+func excluded(ctx *gin.Context, attr string) bool {
+	for _, a := range strings.Split(ctx.Query("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(a), attr) {
+			return true
+		}
+	}
+	return false
+}
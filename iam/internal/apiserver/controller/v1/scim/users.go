@@ -0,0 +1,331 @@
+package scim
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	iamauth "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Keys in v1.User.Extend of attributes which have no column.
+const (
+	extendExternalID  = "scimExternalId"
+	extendDisplayName = "scimDisplayName"
+	extendGivenName   = "scimGivenName"
+	extendFamilyName  = "scimFamilyName"
+)
+
+type scimUser struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []email  `json:"emails,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+	// Password is write-only.
+	Password string `json:"password,omitempty"`
+	Groups   []ref  `json:"groups,omitempty"`
+	Meta     *meta  `json:"meta,omitempty"`
+}
+
+type name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// toSCIMUser maps user, id of user is its username, which can't be changed.
+func toSCIMUser(ctx *gin.Context, user *v1.User) *scimUser {
+	active := !user.Disabled
+	r := &scimUser{
+		Schemas:     []string{schemaUser},
+		ID:          user.Username,
+		ExternalID:  extendString(user.Extend, extendExternalID),
+		UserName:    user.Username,
+		DisplayName: extendString(user.Extend, extendDisplayName),
+		Active:      &active,
+		Meta:        newMeta(ctx, "User", "Users", user.Username, user.CreatedAt, user.UpdatedAt),
+	}
+	if given, family := extendString(user.Extend, extendGivenName), extendString(user.Extend, extendFamilyName); given != "" || family != "" {
+		r.Name = &name{GivenName: given, FamilyName: family}
+	}
+	if user.Email != "" {
+		r.Emails = []email{{Value: user.Email, Type: "work", Primary: true}}
+	}
+	for _, g := range user.Groups {
+		r.Groups = append(r.Groups, ref{Value: g, Ref: location(ctx, "Groups", g), Display: g})
+	}
+	return r
+}
+
+func (u *scimUser) attributes() attributes {
+	attrs := attributes{
+		"id":                {u.ID},
+		"externalid":        {u.ExternalID},
+		"username":          {u.UserName},
+		"displayname":       {u.DisplayName},
+		"active":            {strconv.FormatBool(u.Active == nil || *u.Active)},
+		"meta.created":      {formatTime(u.Meta.Created)},
+		"meta.lastmodified": {formatTime(u.Meta.LastModified)},
+	}
+	if u.Name != nil {
+		attrs["name.givenname"] = []string{u.Name.GivenName}
+		attrs["name.familyname"] = []string{u.Name.FamilyName}
+	}
+	for _, e := range u.Emails {
+		attrs["emails"] = append(attrs["emails"], e.Value)
+		attrs["emails.value"] = append(attrs["emails.value"], e.Value)
+	}
+	for _, g := range u.Groups {
+		attrs["groups"] = append(attrs["groups"], g.Value)
+		attrs["groups.value"] = append(attrs["groups.value"], g.Value)
+	}
+	return attrs
+}
+
+// primaryEmail returns primary email, or the first one.
+func (u *scimUser) primaryEmail() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// apply copies attributes of r into user, password is left to caller.
+func (u *scimUser) apply(user *v1.User) {
+	if user.Extend == nil {
+		user.Extend = metav1.Extend{}
+	}
+	user.Extend[extendExternalID] = u.ExternalID
+	user.Extend[extendDisplayName] = u.DisplayName
+	user.Extend[extendGivenName], user.Extend[extendFamilyName] = "", ""
+	if u.Name != nil {
+		user.Extend[extendGivenName], user.Extend[extendFamilyName] = u.Name.GivenName, u.Name.FamilyName
+	}
+	user.Email = u.primaryEmail()
+	if u.Active != nil {
+		user.Disabled = !*u.Active
+	}
+}
+
+func extendString(e metav1.Extend, key string) string {
+	s, _ := e[key].(string)
+	return s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// getUser finds user with id, service account is not exposed.
+func (c *Controller) getUser(ctx *gin.Context, id string) (*v1.User, error) {
+	if _, ok := v1.ServiceAccountName(id); ok {
+		return nil, notFound("user %s not found.", id)
+	}
+	user, err := c.svc.Users().Get(ctx, id, metav1.GetOperateMeta{})
+	if err != nil || user == nil {
+		return nil, notFound("user %s not found.", id)
+	}
+	return user, nil
+}
+
+func (c *Controller) ListUsers(ctx *gin.Context) {
+	log.L(ctx).Info("scim list users.")
+
+	f, err := parseFilter(ctx.Query("filter"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	var sf *store.Filter
+	if f != nil {
+		if sf, err = toUserFilter(f); err != nil {
+			writeError(ctx, err)
+			return
+		}
+	}
+
+	start, count := c.page(ctx)
+	offset, limit := int64(start-1), int64(count)
+	users, err := c.svc.Users().ListByFilter(ctx, sf, metav1.ListOperateMeta{Offset: &offset, Limit: &limit})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	var resources []interface{}
+	for _, user := range users.Items {
+		resources = append(resources, toSCIMUser(ctx, user))
+	}
+	writeList(ctx, start, int(users.TotalCount), resources)
+}
+
+func (c *Controller) GetUser(ctx *gin.Context) {
+	log.L(ctx).Info("scim get a user.")
+
+	user, err := c.getUser(ctx, ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	write(ctx, http.StatusOK, toSCIMUser(ctx, user))
+}
+
+func (c *Controller) CreateUser(ctx *gin.Context) {
+	log.L(ctx).Info("scim create a user.")
+
+	var r scimUser
+	if err := ctx.ShouldBindJSON(&r); err != nil {
+		writeError(ctx, badRequest(errInvalidSyntax, err.Error()))
+		return
+	}
+	if r.UserName == "" {
+		writeError(ctx, badRequest(errInvalidValue, "userName is required."))
+		return
+	}
+
+	// Password is left empty if not given, so that user can only login with upstream provider.
+	user := &v1.User{Username: r.UserName, Password: r.Password}
+	r.apply(user)
+
+	if err := c.svc.Users().Provision(ctx, user); err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	res := toSCIMUser(ctx, user)
+	ctx.Header("Location", res.Meta.Location)
+	write(ctx, http.StatusCreated, res)
+}
+
+func (c *Controller) ReplaceUser(ctx *gin.Context) {
+	log.L(ctx).Info("scim replace a user.")
+
+	user, err := c.getUser(ctx, ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	var r scimUser
+	if err = ctx.ShouldBindJSON(&r); err != nil {
+		writeError(ctx, badRequest(errInvalidSyntax, err.Error()))
+		return
+	}
+
+	c.updateUser(ctx, user, &r)
+}
+
+func (c *Controller) PatchUser(ctx *gin.Context) {
+	log.L(ctx).Info("scim patch a user.")
+
+	user, err := c.getUser(ctx, ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	var r patchRequest
+	if err = ctx.ShouldBindJSON(&r); err != nil {
+		writeError(ctx, badRequest(errInvalidSyntax, err.Error()))
+		return
+	}
+
+	patched := toSCIMUser(ctx, user)
+	for _, op := range r.Operations {
+		if err = patched.patch(op); err != nil {
+			writeError(ctx, err)
+			return
+		}
+	}
+
+	c.updateUser(ctx, user, patched)
+}
+
+// updateUser saves user replaced with r, tokens are revoked if user is disabled or password is changed.
+func (c *Controller) updateUser(ctx *gin.Context, user *v1.User, r *scimUser) {
+	if r.UserName != "" && r.UserName != user.Username {
+		writeError(ctx, badRequest(errMutability, "userName can't be changed."))
+		return
+	}
+
+	disabled := user.Disabled
+	r.apply(user)
+	if r.Password != "" {
+		if err := setPassword(user, r.Password); err != nil {
+			writeError(ctx, err)
+			return
+		}
+	}
+
+	if err := c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	if r.Password != "" || user.Disabled && !disabled {
+		if err := iamauth.RevokeUser(ctx, user.Username); err != nil {
+			log.L(ctx).Errorf("revoke tokens of user %s fail: %s", user.Username, err.Error())
+		}
+	}
+
+	write(ctx, http.StatusOK, toSCIMUser(ctx, user))
+}
+
+// setPassword is an admin reset, so password history is not checked.
+func setPassword(user *v1.User, password string) error {
+	if err := validator.CheckPasswordErr(password); err != nil {
+		return badRequest(errInvalidValue, err.Error())
+	}
+	hashed, err := auth.Encrypt(password)
+	if err != nil {
+		return internalError(err)
+	}
+	user.RotatePassword(hashed, validator.GetPasswordPolicy().HistoryCount)
+	return nil
+}
+
+func (c *Controller) DeleteUser(ctx *gin.Context) {
+	log.L(ctx).Info("scim delete a user.")
+
+	user, err := c.getUser(ctx, ctx.Param("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	if err = c.svc.Users().Delete(ctx, user.Username, metav1.DeleteOperateMeta{}); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	if err = iamauth.RevokeUser(ctx, user.Username); err != nil {
+		log.L(ctx).Errorf("revoke tokens of user %s fail: %s", user.Username, err.Error())
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestListUsers(t *testing.T) {
+	redistest.Use()
+	opts := options.NewSCIMOpts()
+	opts.MaxResults = 2
+	c := NewSCIMController(fake.NewFactory(), opts)
+	for _, user := range []*v1.User{
+		{Username: "alice", Email: "alice@example.com", Groups: []string{"ops"}},
+		{Username: "bob", Email: "bob@example.org", Disabled: true},
+		{Username: "carol", Email: "carol@example.com", Groups: []string{"ops"},
+			ObjectMeta: metav1.ObjectMeta{Extend: metav1.Extend{extendExternalID: "C-3"}}},
+		{Username: "dave", Email: "dave@example.com"},
+	} {
+		if err := c.svc.Users().Create(context.Background(), user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		total int
+		ids   []string
+	}{
+		{"", 4, []string{"alice", "bob"}},
+		{"startIndex=3", 4, []string{"carol", "dave"}},
+		{"startIndex=4&count=10", 4, []string{"dave"}},
+		{"count=0", 4, nil},
+		{"startIndex=9", 4, nil},
+		{`filter=emails.value ew ".com"&startIndex=2&count=1`, 3, []string{"carol"}},
+		{`filter=active eq false`, 1, []string{"bob"}},
+		{`filter=groups eq "ops" and not (userName eq "alice")`, 1, []string{"carol"}},
+		{`filter=externalId eq "C-3"`, 1, []string{"carol"}},
+		{`filter=externalId eq "c-3"`, 0, nil},
+		{`filter=userName eq "ALICE"`, 1, []string{"alice"}},
+	}
+
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/scim/v2/Users?"+query.Encode(), nil)
+
+		c.ListUsers(ctx)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status got %d, body %s", tt.query, w.Code, w.Body.String())
+			continue
+		}
+		var resp struct {
+			TotalResults int
+			ItemsPerPage int
+			Resources    []scimUser
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, r := range resp.Resources {
+			ids = append(ids, r.ID)
+		}
+		if resp.TotalResults != tt.total || resp.ItemsPerPage != len(tt.ids) || !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("%s: got total %d, %d items %v, want total %d, items %v",
+				tt.query, resp.TotalResults, resp.ItemsPerPage, ids, tt.total, tt.ids)
+		}
+	}
+}
+
+func TestListUsers_InvalidFilter(t *testing.T) {
+	c := NewSCIMController(fake.NewFactory(), options.NewSCIMOpts())
+	for _, filter := range []string{`userName eq`, `meta.location pr`, `groups sw "o"`} {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/scim/v2/Users?filter="+url.QueryEscape(filter), nil)
+
+		c.ListUsers(ctx)
+
+		var resp struct{ ScimType string }
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.ScimType != errInvalidFilter {
+			t.Errorf("%s: got %d %s", filter, w.Code, w.Body.String())
+		}
+	}
+}
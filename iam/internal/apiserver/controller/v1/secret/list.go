@@ -1,7 +1,21 @@
 package secret
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
+	"istomyang.github.com/like-iam/log"
+)
 
 func (c *Controller) List(ctx *gin.Context) {
+	log.L(ctx).Info("list secrets.")
 
+	// Users only watch their own secrets.
+	if watch.Requested(ctx) {
+		username := ctx.GetString(middleware.UserNameKey)
+		watch.Serve(ctx, watch.ResourceSecrets, func(owner string) bool {
+			return owner == username
+		})
+		return
+	}
 }
@@ -0,0 +1,56 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Create creates account owned by current user, owner group must be one of user's groups.
+func (c *Controller) Create(ctx *gin.Context) {
+	log.L(ctx).Info("create service account.")
+
+	var account *v1.ServiceAccount
+
+	if err := ctx.ShouldBind(&account); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(account); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if account.Name == "" {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrValidation, "metadata.name must not be empty."), nil)
+		return
+	}
+
+	if account.OwnerGroup != "" {
+		groups, err := c.groups(ctx)
+		if err != nil {
+			web.WriteResponse(ctx, err, nil)
+			return
+		}
+		if !contains(groups, account.OwnerGroup) {
+			web.WriteResponse(ctx, errors.WithCode(errors.ErrPermissionDenied, "you are not a member of group %s.", account.OwnerGroup), nil)
+			return
+		}
+	}
+
+	account.Owner = ctx.GetString(middleware.UserNameKey)
+	account.Disabled = false
+
+	if err := c.svc.ServiceAccounts().Create(ctx, account, metav1.CreateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, account)
+}
@@ -0,0 +1,26 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Delete removes account and all its secrets.
+func (c *Controller) Delete(ctx *gin.Context) {
+	log.L(ctx).Info("delete service account.")
+
+	account, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if err = c.svc.ServiceAccounts().Delete(ctx, account.Name, metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
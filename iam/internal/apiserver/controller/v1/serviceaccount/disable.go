@@ -0,0 +1,37 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Disable stops account from authenticating, secrets are kept so that it can be enabled again.
+func (c *Controller) Disable(ctx *gin.Context) {
+	log.L(ctx).Info("disable service account.")
+
+	c.setDisabled(ctx, true)
+}
+
+func (c *Controller) Enable(ctx *gin.Context) {
+	log.L(ctx).Info("enable service account.")
+
+	c.setDisabled(ctx, false)
+}
+
+func (c *Controller) setDisabled(ctx *gin.Context, disabled bool) {
+	account, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	account.Disabled = disabled
+	if err = c.svc.ServiceAccounts().Update(ctx, account, metav1.UpdateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, account)
+}
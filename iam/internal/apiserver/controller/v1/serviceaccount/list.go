@@ -0,0 +1,42 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+)
+
+// List returns accounts owned by current user or its groups.
+func (c *Controller) List(ctx *gin.Context) {
+	log.L(ctx).Info("list service accounts.")
+
+	var meta metav1.ListOperateMeta
+
+	if err := ctx.ShouldBindQuery(&meta); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&meta); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	groups, err := c.groups(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	accounts, err := c.svc.ServiceAccounts().List(ctx, ctx.GetString(middleware.UserNameKey), groups, meta)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, accounts)
+}
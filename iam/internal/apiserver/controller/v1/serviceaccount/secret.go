@@ -0,0 +1,91 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+// CreateSecret issues secret whose username is principal of account, secret key is returned.
+func (c *Controller) CreateSecret(ctx *gin.Context) {
+	log.L(ctx).Info("create service account secret.")
+
+	var secret *v1.Secret
+
+	if err := ctx.ShouldBind(&secret); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	account, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	secret.Username = account.Principal()
+	secret.SecretID, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 36)
+	secret.SecretKey, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 32)
+
+	if err = c.svc.Secrets().Create(ctx, secret, metav1.CreateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, secret)
+}
+
+func (c *Controller) ListSecrets(ctx *gin.Context) {
+	log.L(ctx).Info("list service account secrets.")
+
+	var meta metav1.ListOperateMeta
+
+	if err := ctx.ShouldBindQuery(&meta); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&meta); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	account, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	secrets, err := c.svc.Secrets().List(ctx, account.Principal(), meta)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+	for _, s := range secrets.Items {
+		s.SecretKey = ""
+	}
+
+	web.WriteResponse(ctx, nil, secrets)
+}
+
+func (c *Controller) DeleteSecret(ctx *gin.Context) {
+	log.L(ctx).Info("delete service account secret.")
+
+	account, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if err = c.svc.Secrets().Delete(ctx, account.Principal(), ctx.Param("secret-id"), metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
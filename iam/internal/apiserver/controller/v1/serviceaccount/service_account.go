@@ -0,0 +1,64 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+)
+
+type Controller struct {
+	svc service.Service
+}
+
+func NewServiceAccountController(store store.Factory) *Controller {
+	return &Controller{svc: service.NewService(store)}
+}
+
+// groups returns groups of current user, service account itself has no groups.
+func (c *Controller) groups(ctx *gin.Context) ([]string, error) {
+	username := ctx.GetString(middleware.UserNameKey)
+	if _, ok := v1.ServiceAccountName(username); ok {
+		return nil, nil
+	}
+	user, err := c.svc.Users().Get(ctx, username, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.WithCode(errors.ErrPermissionDenied, "user %s not found.", username)
+	}
+	return user.Groups, nil
+}
+
+// owned gets account in path, which must be owned by current user or one of its groups.
+func (c *Controller) owned(ctx *gin.Context) (*v1.ServiceAccount, error) {
+	account, err := c.svc.ServiceAccounts().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, err
+	}
+	if account.Owner == ctx.GetString(middleware.UserNameKey) {
+		return account, nil
+	}
+
+	groups, err := c.groups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if account.OwnerGroup != "" && contains(groups, account.OwnerGroup) {
+		return account, nil
+	}
+	return nil, errors.WithCode(errors.ErrPermissionDenied, "service account %s is not owned by you.", account.Name)
+}
+
+func contains(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package serviceaccount
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http/httptest"
+	"testing"
+)
+
+func newContext(username, account string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/v1/service-accounts/"+account, nil)
+	c.Params = gin.Params{{Key: "name", Value: account}}
+	c.Set(middleware.UserNameKey, username)
+	return c
+}
+
+func TestOwned_GroupCannotBeSelfGranted(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	c := NewServiceAccountController(fake.NewFactory())
+	for _, user := range []*v1.User{
+		{Username: "alice", Email: "alice@example.com"},
+		{Username: "bob", Email: "bob@example.com", Groups: []string{"ops"}},
+	} {
+		if err := c.svc.Users().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	account := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer"}, Owner: "carol", OwnerGroup: "ops"}
+	if err := c.svc.ServiceAccounts().Create(ctx, account, metav1.CreateOperateMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.owned(newContext("bob", "deployer")); err != nil {
+		t.Fatalf("member of owner group should own account: %v", err)
+	}
+	if _, err := c.owned(newContext("alice", "deployer")); errors.Code(err) != errors.ErrPermissionDenied {
+		t.Fatalf("user out of owner group should not own account, got %v", err)
+	}
+
+	// Groups are privileges, alice can't join ops by herself.
+	groups := []string{"ops"}
+	_, err := c.svc.Users().Patch(ctx, "alice", &v1.UserPatch{Groups: &groups}, false)
+	if errors.Code(err) != errors.ErrPermissionDenied {
+		t.Fatalf("user should not change own groups, got %v", err)
+	}
+	if _, err = c.owned(newContext("alice", "deployer")); errors.Code(err) != errors.ErrPermissionDenied {
+		t.Errorf("denied patch must not grant ownership, got %v", err)
+	}
+
+	// Admin adds alice to ops.
+	if _, err = c.svc.Users().Patch(ctx, "alice", &v1.UserPatch{Groups: &groups}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.owned(newContext("alice", "deployer")); err != nil {
+		t.Errorf("user added to owner group by admin should own account: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package serviceaccount
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Update changes description and owner group, name and owner can't be changed.
+func (c *Controller) Update(ctx *gin.Context) {
+	log.L(ctx).Info("update service account.")
+
+	var r v1.ServiceAccount
+
+	if err := ctx.ShouldBind(&r); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&r); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	account, err := c.owned(ctx)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if r.OwnerGroup != "" && r.OwnerGroup != account.OwnerGroup {
+		groups, err := c.groups(ctx)
+		if err != nil {
+			web.WriteResponse(ctx, err, nil)
+			return
+		}
+		if !contains(groups, r.OwnerGroup) {
+			web.WriteResponse(ctx, errors.WithCode(errors.ErrPermissionDenied, "you are not a member of group %s.", r.OwnerGroup), nil)
+			return
+		}
+	}
+
+	account.OwnerGroup = r.OwnerGroup
+	account.Description = r.Description
+
+	if err = c.svc.ServiceAccounts().Update(ctx, account, metav1.UpdateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, account)
+}
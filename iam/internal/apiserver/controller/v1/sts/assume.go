@@ -0,0 +1,69 @@
+package sts
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ory/ladon"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+	"time"
+)
+
+// Assume issues temporary credential of current principal, optionally down-scoped by session policy.
+func (c *Controller) Assume(ctx *gin.Context) {
+	log.L(ctx).Info("assume temporary credential.")
+
+	var r v1.AssumeRequest
+
+	if err := ctx.ShouldBind(&r); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&r); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	ttl, err := c.opts.Duration(r.DurationSeconds)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrValidation, err.Error()), nil)
+		return
+	}
+	defaultSessionPolicy(r.Policy)
+
+	cred := &v1.TemporaryCredential{
+		Username:    ctx.GetString(middleware.UserNameKey),
+		SessionName: r.SessionName,
+		Expires:     time.Now().Add(ttl).Unix(),
+		Policy:      r.Policy,
+	}
+	id, _ := idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 32)
+	cred.SecretID = v1.TemporarySecretIDPrefix + id
+	cred.SecretKey, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 32)
+	cred.SessionToken, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, 64)
+
+	if err = saveCredential(ctx, cred, ttl); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrDatabase, err.Error()), nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, cred)
+}
+
+// defaultSessionPolicy fills subjects to match the caller, structure is checked by validator.
+func defaultSessionPolicy(p *ladon.DefaultPolicy) {
+	if p == nil {
+		return
+	}
+	if len(p.Subjects) == 0 {
+		p.Subjects = []string{"<.*>"}
+	}
+	if p.ID == "" {
+		p.ID = "session"
+	}
+}
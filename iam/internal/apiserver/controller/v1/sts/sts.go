@@ -0,0 +1,29 @@
+package sts
+
+import (
+	"context"
+	"encoding/json"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/pkg"
+	"time"
+)
+
+type Controller struct {
+	opts *options.STSOpts
+}
+
+func NewSTSController(opts *options.STSOpts) *Controller {
+	return &Controller{opts: opts}
+}
+
+// saveCredential saves credential in redis until it expires, authzserver finds it by secret id.
+func saveCredential(ctx context.Context, cred *v1.TemporaryCredential, ttl time.Duration) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	client := conn.GetRedisClient().UniversalClient()
+	return client.Set(ctx, pkg.TemporaryCredentialKeyPrefix+cred.SecretID, data, ttl).Err()
+}
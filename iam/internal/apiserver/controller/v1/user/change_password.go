@@ -6,7 +6,10 @@ import (
 	"istomyang.github.com/like-iam/component-base/auth"
 	"istomyang.github.com/like-iam/component-base/errors"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
 	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
 	"istomyang.github.com/like-iam/log"
 )
 
@@ -19,15 +22,20 @@ type ChangePasswordSchema struct {
 func (c *Controller) ChangePassword(ctx *gin.Context) {
 	log.L(ctx).Info("router enters into change-password.")
 
-	var s *ChangePasswordSchema
+	var s ChangePasswordSchema
 
 	var err error
 
-	if err = ctx.ShouldBind(&c); err != nil {
+	if err = ctx.ShouldBind(&s); err != nil {
 		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
 		return
 	}
 
+	if err = validator.Validate(&s); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
 	var user *v1.User
 	user, err = c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
 	if err != nil {
@@ -35,21 +43,39 @@ func (c *Controller) ChangePassword(ctx *gin.Context) {
 		return
 	}
 
-	if !auth.Compare(user.Password, s.OldPassword) {
+	if !user.Compare(s.OldPassword) {
 		web.WriteResponse(ctx, errors.WithCode(errors.ErrPasswordIncorrect, "password is incorrect."), nil)
 		return
 	}
 
-	user.Password, err = auth.Encrypt(s.NewPassword)
+	if err = validator.CheckPasswordErr(s.NewPassword); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrValidation, err.Error()), nil)
+		return
+	}
+
+	policy := validator.GetPasswordPolicy()
+	if user.PasswordReused(s.NewPassword, policy.HistoryCount) {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrPasswordReused, "password was used in last %d times.", policy.HistoryCount), nil)
+		return
+	}
+
+	var hashed string
+	hashed, err = auth.Encrypt(s.NewPassword)
 	if err != nil {
 		web.WriteResponse(ctx, errors.WithCode(errors.ErrEncrypt, "password encrypt fail."), nil)
 		return
 	}
+	user.RotatePassword(hashed, policy.HistoryCount)
 
-	if err = c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+	if err = c.svc.Users().ChangePassword(ctx, user); err != nil {
 		web.WriteResponse(ctx, err, nil)
 		return
 	}
 
+	// Tokens issued with old password must not be used anymore.
+	if err = auth2.RevokeUser(ctx, user.Username); err != nil {
+		log.L(ctx).Errorf("revoke tokens of user %s fail: %s", user.Username, err.Error())
+	}
+
 	web.WriteResponse(ctx, nil, nil)
 }
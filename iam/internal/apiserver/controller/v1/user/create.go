@@ -3,37 +3,83 @@ package user
 import (
 	"github.com/gin-gonic/gin"
 	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
-	"istomyang.github.com/like-iam/component-base/auth"
 	"istomyang.github.com/like-iam/component-base/errors"
-	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
 	"istomyang.github.com/like-iam/component-base/validator"
 	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
 	"istomyang.github.com/like-iam/log"
-	"time"
 )
 
+// CreateSchema serves as router: POST /v1/users
+type CreateSchema struct {
+	v1.User `json:",inline"`
+
+	// InvitationCode is required if registration is invite-only, groups and policies of invitation are given to user.
+	InvitationCode string `json:"invitationCode,omitempty"`
+}
+
 func (c *Controller) Create(ctx *gin.Context) {
 	log.L(ctx).Info("create a user.")
 
-	var r *v1.User
+	// Admin creates users regardless of registration mode, and gives privileges to them.
+	username := ctx.GetString(middleware.UserNameKey)
+	admin := username != "" && c.svc.Users().IsAdmin(ctx, username)
+
+	if !admin && c.registration.Mode == options.RegistrationDisabled {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrRegistrationDisabled, "registration is disabled."), nil)
+		return
+	}
 
-	if err := ctx.ShouldBind(&r); err != nil {
+	var s CreateSchema
+
+	if err := ctx.ShouldBind(&s); err != nil {
 		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
 		return
 	}
 
-	if err := validator.CheckPasswordErr(r.Password); err != nil {
+	if err := validator.Validate(&s); err != nil {
 		web.WriteResponse(ctx, err, nil)
 		return
 	}
+	r := &s.User
 
-	r.Password, _ = auth.Encrypt(r.Password)
-	r.LoginAt = time.Now()
+	if !admin && c.registration.Mode == options.RegistrationInviteOnly && s.InvitationCode == "" {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrInvitationInvalid, "invitation code is required."), nil)
+		return
+	}
+
+	// Privileges are only given by admin or invitation.
+	if !admin {
+		r.IsAdmin, r.Groups, r.Disabled = "", nil, false
+	}
 
-	if err := c.svc.Users().Create(ctx, r, metav1.CreateOperateMeta{}); err != nil {
+	var invitation *v1.Invitation
+	if s.InvitationCode != "" {
+		var err error
+		if invitation, err = c.svc.Invitations().Redeem(ctx, s.InvitationCode, r.Username, r.Email); err != nil {
+			web.WriteResponse(ctx, err, nil)
+			return
+		}
+		r.Groups = append(r.Groups, invitation.Groups...)
+	}
+
+	if err := c.svc.Users().Register(ctx, r); err != nil {
+		if invitation != nil {
+			if e := c.svc.Invitations().Release(ctx, invitation); e != nil {
+				log.L(ctx).Errorf("release invitation %s fail: %s", invitation.Name, e.Error())
+			}
+		}
 		web.WriteResponse(ctx, err, nil)
 		return
 	}
 
+	if invitation != nil {
+		if err := c.svc.Invitations().AssignPolicies(ctx, invitation, r.Username); err != nil {
+			log.L(ctx).Errorf("assign policies of invitation %s to user %s fail: %s", invitation.Name, r.Username, err.Error())
+		}
+	}
+
 	web.WriteResponse(ctx, nil, nil)
 }
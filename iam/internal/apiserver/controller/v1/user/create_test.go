@@ -0,0 +1,66 @@
+package user
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store/fake"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+	factory := fake.NewFactory()
+
+	for _, user := range []*v1.User{{Username: "root", IsAdmin: "true"}, {Username: "alice"}} {
+		if err := factory.User().Create(ctx, user, metav1.CreateOperateMeta{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mode    string
+		caller  string
+		created bool
+		admin   bool
+	}{
+		{"anonymous-disabled", options.RegistrationDisabled, "", false, false},
+		{"user-disabled", options.RegistrationDisabled, "alice", false, false},
+		{"admin-disabled", options.RegistrationDisabled, "root", true, true},
+		{"anonymous-invite", options.RegistrationInviteOnly, "", false, false},
+		{"admin-invite", options.RegistrationInviteOnly, "root", true, true},
+		{"anonymous-open", options.RegistrationOpen, "", true, false},
+	}
+	for _, tt := range tests {
+		c := NewUserController(factory, &options.RegistrationOpts{Mode: tt.mode})
+
+		body := `{"username":"` + tt.name + `","password":"Passw0rd!x","isAdmin":"true","groups":["ops"]}`
+		gc, _ := gin.CreateTestContext(httptest.NewRecorder())
+		gc.Request = httptest.NewRequest("POST", "/v1/users", strings.NewReader(body))
+		gc.Request.Header.Set("Content-Type", "application/json")
+		if tt.caller != "" {
+			gc.Set(middleware.UserNameKey, tt.caller)
+		}
+
+		c.Create(gc)
+		user, err := factory.User().Get(ctx, tt.name, metav1.GetOperateMeta{})
+		if created := err == nil && user != nil; created != tt.created {
+			t.Errorf("%s: created got %v, want %v", tt.name, created, tt.created)
+			continue
+		}
+		if tt.created && user.Admin() != tt.admin {
+			t.Errorf("%s: admin got %v, want %v", tt.name, user.Admin(), tt.admin)
+		}
+		if tt.created && tt.admin && len(user.Groups) != 1 {
+			t.Errorf("%s: groups got %v, want [ops]", tt.name, user.Groups)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
 	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
 	"istomyang.github.com/like-iam/log"
 )
 
@@ -11,11 +12,17 @@ func (c *Controller) Delete(ctx *gin.Context) {
 
 	log.L(ctx).Info("delete a user.")
 
-	err := c.svc.Users().Delete(ctx, ctx.Param("name"), metav1.DeleteOperateMeta{})
+	name := ctx.Param("name")
+
+	err := c.svc.Users().Delete(ctx, name, metav1.DeleteOperateMeta{})
 	if err != nil {
 		web.WriteResponse(ctx, err, nil)
 		return
 	}
 
+	if err = auth2.RevokeUser(ctx, name); err != nil {
+		log.L(ctx).Errorf("revoke tokens of user %s fail: %s", name, err.Error())
+	}
+
 	web.WriteResponse(ctx, nil, nil)
 }
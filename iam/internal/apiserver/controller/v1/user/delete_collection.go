@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
 	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
 	"istomyang.github.com/like-iam/log"
 )
 
@@ -17,5 +18,11 @@ func (c *Controller) DeleteCollection(ctx *gin.Context) {
 		return
 	}
 
+	for _, name := range ctx.QueryArray("names") {
+		if err = auth2.RevokeUser(ctx, name); err != nil {
+			log.L(ctx).Errorf("revoke tokens of user %s fail: %s", name, err.Error())
+		}
+	}
+
 	web.WriteResponse(ctx, nil, nil)
 }
@@ -4,19 +4,37 @@ import (
 	"github.com/gin-gonic/gin"
 	"istomyang.github.com/like-iam/component-base/errors"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
 	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
 	"istomyang.github.com/like-iam/log"
 )
 
 func (c *Controller) List(ctx *gin.Context) {
 	log.L(ctx).Info("list users.")
 
+	// Admin watches all users, others only watch themselves.
+	if watch.Requested(ctx) {
+		username := ctx.GetString(middleware.UserNameKey)
+		admin := c.svc.Users().IsAdmin(ctx, username)
+		watch.Serve(ctx, watch.ResourceUsers, func(owner string) bool {
+			return admin || owner == username
+		})
+		return
+	}
+
 	var meta metav1.ListOperateMeta
 
 	if err := ctx.ShouldBindQuery(&meta); err != nil {
 		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
 		return
 	}
+
+	if err := validator.Validate(&meta); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
 	userList, err := c.svc.Users().List(ctx, meta)
 	if err != nil {
 		web.WriteResponse(ctx, err, nil)
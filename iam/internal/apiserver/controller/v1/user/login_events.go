@@ -0,0 +1,40 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/log"
+)
+
+// ListLoginEvents lists login attempts of user, newest first.
+func (c *Controller) ListLoginEvents(ctx *gin.Context) {
+	log.L(ctx).Info("list login events of a user.")
+
+	var meta metav1.ListOperateMeta
+	if err := ctx.ShouldBindQuery(&meta); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&meta); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	user, err := c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	events, err := c.svc.LoginEvents().List(ctx, user.Username, meta)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, events)
+}
@@ -0,0 +1,53 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+)
+
+// DisableMFA removes TOTP secret and recovery codes, user required MFA must enrol again at next login.
+func (c *Controller) DisableMFA(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into mfa disable.")
+
+	var s MFACodeSchema
+
+	if err := ctx.ShouldBind(&s); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&s); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	user, err := c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if !user.MFAEnabled {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrMFANotEnabled, "mfa is not enabled."), nil)
+		return
+	}
+	if !auth2.VerifyMFACode(user, s.Code) {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrMFACodeInvalid, "mfa code is invalid."), nil)
+		return
+	}
+
+	user.ResetMFA()
+
+	if err = c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
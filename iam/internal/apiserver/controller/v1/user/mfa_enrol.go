@@ -0,0 +1,45 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+)
+
+// EnrolMFA generates a new TOTP secret, which takes effect after VerifyMFA.
+func (c *Controller) EnrolMFA(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into mfa enrol.")
+
+	user, err := c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if user.MFAEnabled {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrMFAAlreadyEnabled, "mfa has been enabled, disable it first."), nil)
+		return
+	}
+
+	var secret string
+	if secret, err = auth.GenerateTOTPSecret(); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "generate totp secret fail: %s", err.Error()), nil)
+		return
+	}
+	user.MFASecret = secret
+
+	if err = c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, gin.H{
+		"secret": secret,
+		"uri":    auth.TOTPProvisioningURI(auth2.GetMFAOpts().Issuer, user.Username, secret),
+	})
+}
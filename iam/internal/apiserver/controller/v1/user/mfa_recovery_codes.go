@@ -0,0 +1,82 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+)
+
+const recoveryCodeLength = 10
+
+// RegenerateRecoveryCodes replaces all recovery codes, old ones can't be used anymore.
+func (c *Controller) RegenerateRecoveryCodes(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into mfa recovery-codes.")
+
+	var s MFACodeSchema
+
+	if err := ctx.ShouldBind(&s); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&s); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	user, err := c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if !user.MFAEnabled {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrMFANotEnabled, "mfa is not enabled."), nil)
+		return
+	}
+	if !auth2.VerifyMFACode(user, s.Code) {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrMFACodeInvalid, "mfa code is invalid."), nil)
+		return
+	}
+
+	var recoveryCodes []string
+	if recoveryCodes, user.RecoveryCodes, err = generateRecoveryCodes(); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrEncrypt, "generate recovery codes fail: %s", err.Error()), nil)
+		return
+	}
+
+	if err = c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, gin.H{"recoveryCodes": recoveryCodes})
+}
+
+// generateRecoveryCodes returns plain codes shown to user once and their hashes saved in db.
+func generateRecoveryCodes() ([]string, []string, error) {
+	n := auth2.GetMFAOpts().RecoveryCodes
+
+	plain := make([]string, 0, n)
+	hashed := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		code, err := idutil.GetRandString(idutil.AlphabetL+idutil.Number, recoveryCodeLength)
+		if err != nil {
+			return nil, nil, err
+		}
+		h, err := auth.Encrypt(code)
+		if err != nil {
+			return nil, nil, err
+		}
+		plain = append(plain, code)
+		hashed = append(hashed, h)
+	}
+	return plain, hashed, nil
+}
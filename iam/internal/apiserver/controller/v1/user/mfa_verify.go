@@ -0,0 +1,68 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+)
+
+// MFACodeSchema serves as router: /:username/mfa/*
+type MFACodeSchema struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// VerifyMFA enables MFA with the first code from authenticator app, and returns recovery codes.
+func (c *Controller) VerifyMFA(ctx *gin.Context) {
+	log.L(ctx).Info("router enters into mfa verify.")
+
+	var s MFACodeSchema
+
+	if err := ctx.ShouldBind(&s); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&s); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	user, err := c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if user.MFAEnabled {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrMFAAlreadyEnabled, "mfa has been enabled."), nil)
+		return
+	}
+	if user.MFASecret == "" {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrMFANotEnabled, "mfa enrolment not started."), nil)
+		return
+	}
+
+	if !auth2.VerifyTOTP(user, s.Code) {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrMFACodeInvalid, "mfa code is invalid."), nil)
+		return
+	}
+
+	var recoveryCodes []string
+	if recoveryCodes, user.RecoveryCodes, err = generateRecoveryCodes(); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrEncrypt, "generate recovery codes fail: %s", err.Error()), nil)
+		return
+	}
+	user.MFAEnabled = true
+
+	if err = c.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, gin.H{"recoveryCodes": recoveryCodes})
+}
@@ -0,0 +1,28 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/log"
+)
+
+// RevokeTokens revokes all jwt tokens issued to user, user must login again.
+func (c *Controller) RevokeTokens(ctx *gin.Context) {
+	log.L(ctx).Info("revoke tokens of a user.")
+
+	user, err := c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if err = auth2.RevokeUser(ctx, user.Username); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "revoke tokens fail: %s", err.Error()), nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
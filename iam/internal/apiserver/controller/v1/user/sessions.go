@@ -0,0 +1,62 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"istomyang.github.com/like-iam/log"
+)
+
+// ListSessions lists where user is logged in.
+func (c *Controller) ListSessions(ctx *gin.Context) {
+	log.L(ctx).Info("list sessions of a user.")
+
+	user, err := c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	sessions, err := auth2.ListSessions(ctx, user.Username)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "list sessions fail: %s", err.Error()), nil)
+		return
+	}
+
+	current := auth2.CurrentSessionID(ctx)
+	for _, s := range sessions {
+		s.Current = s.ID == current
+	}
+
+	web.WriteResponse(ctx, nil, &v1.SessionList{
+		ListMeta: metav1.ListMeta{TotalCount: int64(len(sessions))},
+		Items:    sessions,
+	})
+}
+
+// RevokeSession logs user out from a session, its tokens are rejected since next request.
+func (c *Controller) RevokeSession(ctx *gin.Context) {
+	log.L(ctx).Info("revoke a session of user.")
+
+	user, err := c.svc.Users().Get(ctx, ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	found, err := auth2.RevokeSession(ctx, user.Username, ctx.Param("id"))
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrUnknown, "revoke session fail: %s", err.Error()), nil)
+		return
+	}
+	if !found {
+		web.WriteResponse(ctx, errors.WithCode(codes.ErrSessionNotFound, "session %s not found.", ctx.Param("id")), nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
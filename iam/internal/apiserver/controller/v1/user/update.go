@@ -4,26 +4,42 @@ import (
 	"github.com/gin-gonic/gin"
 	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
 	"istomyang.github.com/like-iam/component-base/errors"
-	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
 	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	auth2 "istomyang.github.com/like-iam/iam/internal/apiserver/auth"
 	"istomyang.github.com/like-iam/log"
 )
 
+// Update merges fields in body into user, privileges are only changed by admin.
 func (c *Controller) Update(ctx *gin.Context) {
-	log.L(ctx).Info("get a user.")
+	log.L(ctx).Info("update a user.")
 
-	var user v1.User
+	var patch v1.UserPatch
 
-	err := ctx.ShouldBind(&user)
-	if err != nil {
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
 		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
 		return
 	}
 
-	if err := c.svc.Users().Update(ctx, &user, metav1.UpdateOperateMeta{}); err != nil {
+	if err := validator.Validate(&patch); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	admin := c.svc.Users().IsAdmin(ctx, ctx.GetString(middleware.UserNameKey))
+	user, err := c.svc.Users().Patch(ctx, ctx.Param("name"), &patch, admin)
+	if err != nil {
 		web.WriteResponse(ctx, err, nil)
 		return
 	}
 
+	// Disabled user can't login, tokens issued before must not work either.
+	if user.Disabled {
+		if err = auth2.RevokeUser(ctx, user.Username); err != nil {
+			log.L(ctx).Errorf("revoke tokens of user %s fail: %s", user.Username, err.Error())
+		}
+	}
+
 	web.WriteResponse(ctx, nil, nil)
 }
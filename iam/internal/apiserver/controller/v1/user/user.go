@@ -1,14 +1,43 @@
 package user
 
 import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/component/pkg/options"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
 )
 
 type Controller struct {
-	svc service.Service
+	svc          service.Service
+	registration *options.RegistrationOpts
 }
 
-func NewUserController(store store.Factory) *Controller {
-	return &Controller{svc: service.NewService(store)}
+func NewUserController(store store.Factory, registration *options.RegistrationOpts) *Controller {
+	return &Controller{svc: service.NewService(store), registration: registration}
+}
+
+// SelfOrAdmin aborts unless current user is the one of path, or admin.
+func (c *Controller) SelfOrAdmin(ctx *gin.Context) {
+	username := ctx.GetString(middleware.UserNameKey)
+	if username != ctx.Param("name") && !c.svc.Users().IsAdmin(ctx, username) {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrPermissionDenied, "only admin can manage other users."), nil)
+		ctx.Abort()
+		return
+	}
+
+	ctx.Next()
+}
+
+// AdminOnly aborts if current user is not admin.
+func (c *Controller) AdminOnly(ctx *gin.Context) {
+	if !c.svc.Users().IsAdmin(ctx, ctx.GetString(middleware.UserNameKey)) {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrPermissionDenied, "only admin can manage other users."), nil)
+		ctx.Abort()
+		return
+	}
+
+	ctx.Next()
 }
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Create registers a webhook, signing secret is only returned here.
+func (c *Controller) Create(ctx *gin.Context) {
+	log.L(ctx).Info("create webhook.")
+
+	var webhook *v1.Webhook
+
+	if err := ctx.ShouldBind(&webhook); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(webhook); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if err := c.validate(webhook); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	webhook.Username = ctx.GetString(middleware.UserNameKey)
+	if webhook.Name == "" {
+		webhook.Name, _ = idutil.GetRandString(idutil.AlphabetL+idutil.Number, 12)
+	}
+	if _, err := c.svc.Webhooks().Get(ctx, webhook.Username, webhook.Name, metav1.GetOperateMeta{}); err == nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrValidation, "webhook %s already exists.", webhook.Name), nil)
+		return
+	}
+
+	if err := c.svc.Webhooks().Create(ctx, webhook, metav1.CreateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, webhook)
+}
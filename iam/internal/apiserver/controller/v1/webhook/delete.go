@@ -0,0 +1,21 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Delete removes webhook and its delivery log, pending deliveries are dropped.
+func (c *Controller) Delete(ctx *gin.Context) {
+	log.L(ctx).Info("delete webhook.")
+
+	if err := c.svc.Webhooks().Delete(ctx, ctx.GetString(middleware.UserNameKey), ctx.Param("name"), metav1.DeleteOperateMeta{Unscoped: true}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, nil)
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+	"strconv"
+)
+
+// ListDeliveries returns delivery log of webhook, newest first.
+func (c *Controller) ListDeliveries(ctx *gin.Context) {
+	log.L(ctx).Info("list webhook deliveries.")
+
+	var meta metav1.ListOperateMeta
+
+	if err := ctx.ShouldBindQuery(&meta); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&meta); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+	defaultPage(&meta)
+
+	deliveries, err := c.svc.Webhooks().ListDeliveries(ctx, ctx.GetString(middleware.UserNameKey), ctx.Param("name"), meta)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, deliveries)
+}
+
+// Redeliver sends payload of a delivery again, no matter it succeeded or failed.
+func (c *Controller) Redeliver(ctx *gin.Context) {
+	log.L(ctx).Info("redeliver webhook delivery.")
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrValidation, "invalid delivery id: %s", ctx.Param("id")), nil)
+		return
+	}
+
+	delivery, err := c.svc.Webhooks().Redeliver(ctx, ctx.GetString(middleware.UserNameKey), ctx.Param("name"), id)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	web.WriteResponse(ctx, nil, delivery)
+}
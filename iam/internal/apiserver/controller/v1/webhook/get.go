@@ -0,0 +1,22 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+)
+
+func (c *Controller) Get(ctx *gin.Context) {
+	log.L(ctx).Info("get webhook.")
+
+	webhook, err := c.svc.Webhooks().Get(ctx, ctx.GetString(middleware.UserNameKey), ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	webhook.Secret = ""
+	web.WriteResponse(ctx, nil, webhook)
+}
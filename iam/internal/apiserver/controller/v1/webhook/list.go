@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+)
+
+func (c *Controller) List(ctx *gin.Context) {
+	log.L(ctx).Info("list webhooks.")
+
+	var meta metav1.ListOperateMeta
+
+	if err := ctx.ShouldBindQuery(&meta); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&meta); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+	defaultPage(&meta)
+
+	webhooks, err := c.svc.Webhooks().List(ctx, ctx.GetString(middleware.UserNameKey), meta)
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	for _, webhook := range webhooks.Items {
+		webhook.Secret = ""
+	}
+	web.WriteResponse(ctx, nil, webhooks)
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/log"
+)
+
+// Update changes url, events, disabled and description, signing secret can't be changed.
+func (c *Controller) Update(ctx *gin.Context) {
+	log.L(ctx).Info("update webhook.")
+
+	var r v1.Webhook
+
+	if err := ctx.ShouldBind(&r); err != nil {
+		web.WriteResponse(ctx, errors.WithCode(errors.ErrBind, err.Error()), nil)
+		return
+	}
+
+	if err := validator.Validate(&r); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	if err := c.validate(&r); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	webhook, err := c.svc.Webhooks().Get(ctx, ctx.GetString(middleware.UserNameKey), ctx.Param("name"), metav1.GetOperateMeta{})
+	if err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	webhook.URL = r.URL
+	webhook.Events = r.Events
+	webhook.Disabled = r.Disabled
+	webhook.Description = r.Description
+
+	if err = c.svc.Webhooks().Update(ctx, webhook, metav1.UpdateOperateMeta{}); err != nil {
+		web.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	webhook.Secret = ""
+	web.WriteResponse(ctx, nil, webhook)
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/service"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	deliverer "istomyang.github.com/like-iam/iam/internal/apiserver/webhook"
+	"net/url"
+	"strings"
+)
+
+type Controller struct {
+	svc  service.Service
+	opts *options.WebhookOpts
+}
+
+func NewWebhookController(store store.Factory, opts *options.WebhookOpts) *Controller {
+	return &Controller{svc: service.NewService(store), opts: opts}
+}
+
+// defaultPage returns the first page if limit or offset is not given.
+func defaultPage(meta *metav1.ListOperateMeta) {
+	if meta.Limit == nil {
+		limit := int64(20)
+		meta.Limit = &limit
+	}
+	if meta.Offset == nil {
+		offset := int64(0)
+		meta.Offset = &offset
+	}
+}
+
+var eventTypes = []string{
+	v1.EventUserCreated, v1.EventUserUpdated, v1.EventUserDeleted,
+	v1.EventSecretCreated, v1.EventSecretUpdated, v1.EventSecretDeleted,
+	v1.EventPolicyCreated, v1.EventPolicyUpdated, v1.EventPolicyDeleted,
+	v1.EventServiceAccountCreated, v1.EventServiceAccountUpdated, v1.EventServiceAccountDeleted,
+}
+
+// validate requires http or https url to public address, and filters matching at least one known event type.
+func (c *Controller) validate(webhook *v1.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.WithCode(errors.ErrValidation, "invalid webhook url: %s", webhook.URL)
+	}
+	if err = deliverer.CheckURL(webhook.URL, c.opts.AllowPrivateNetwork); err != nil {
+		return errors.WithCode(errors.ErrValidation, "invalid webhook url: %s", err.Error())
+	}
+
+	probe := &v1.Webhook{}
+	for _, e := range webhook.Events {
+		probe.Events = []string{e}
+		known := false
+		for _, t := range eventTypes {
+			if probe.Subscribed(t) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return errors.WithCode(errors.ErrValidation, "unknown event %s, must be one of %s or <resource>.*.", e, strings.Join(eventTypes, ", "))
+		}
+	}
+	return nil
+}
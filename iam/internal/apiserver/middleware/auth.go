@@ -0,0 +1,15 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// NewOptionalAuthMiddleFunc runs auth for requests with `Authorization` header, anonymous ones go on without
+// username, so handlers can serve both.
+func NewOptionalAuthMiddleFunc(auth gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
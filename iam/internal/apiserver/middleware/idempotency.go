@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"time"
+)
+
+const idempotencyKeyPrefix = "iam.idempotency."
+
+func NewIdempotencyMiddleFunc(ttl time.Duration) gin.HandlerFunc {
+	return middleware.Idempotency(idempotencyKeyPrefix, ttl, func() redis.UniversalClient {
+		return conn.NewRedisClientOr(nil).UniversalClient()
+	})
+}
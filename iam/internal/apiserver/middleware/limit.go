@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"time"
+)
+
+const signupLimitKeyPrefix = "iam.signup-limit."
+
+// NewSignupLimitMiddleFunc limits signups from one client ip, max <= 0 means no limit.
+func NewSignupLimitMiddleFunc(max int, window time.Duration) gin.HandlerFunc {
+	if max <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return middleware.LimitByClient(signupLimitKeyPrefix, int64(max), window, func() redis.UniversalClient {
+		return conn.NewRedisClientOr(nil).UniversalClient()
+	})
+}
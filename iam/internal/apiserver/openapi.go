@@ -0,0 +1,138 @@
+package apiserver
+
+import (
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/openapi"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/password"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/user"
+	// Codes of apiserver are registered in init.
+	_ "istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"time"
+)
+
+// Bodies written by gin.H in handlers.
+var (
+	tokenResponse = struct {
+		Code             int       `json:"code"`
+		Token            string    `json:"token"`
+		Expire           time.Time `json:"expire"`
+		PasswordExpired  bool      `json:"passwordExpired,omitempty"`
+		MFASetupRequired bool      `json:"mfaSetupRequired,omitempty"`
+		Message          string    `json:"message,omitempty"`
+	}{}
+	mfaEnrolment = struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}{}
+	recoveryCodes = struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}{}
+	oauthClientCredential = struct {
+		ClientID     string `json:"clientID"`
+		ClientSecret string `json:"clientSecret"`
+	}{}
+)
+
+// newOpenAPI describes routes installed by installRouter.
+func newOpenAPI() *openapi.Spec {
+	list := metav1.ListOperateMeta{}
+
+	return &openapi.Spec{
+		Title:       "iam-apiserver",
+		Description: "Manages users, secrets and policies of IAM.",
+		Version:     "v1",
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			"basic":  {Type: "http", Scheme: "basic"},
+			"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Token issued by /login."},
+		},
+		Codes: openapi.RegisteredCodes(),
+		Routes: map[string]openapi.Route{
+			"GET /healthz": {Summary: "Health check.", Public: true},
+
+			"GET /login":                    {Summary: "Login with basic auth, returns token.", Response: tokenResponse},
+			"POST /login/mfa":               {Summary: "Complete login with mfa token and code.", Response: tokenResponse, Public: true},
+			"GET /logout":                   {Summary: "Revoke current token."},
+			"GET /refresh":                  {Summary: "Refresh current token.", Response: tokenResponse},
+			"GET /.well-known/jwks.json":    {Summary: "Public keys to verify tokens.", Response: auth.JWKSet{}, Public: true},
+			"GET /login/federated":          {Summary: "Redirect to upstream identity provider.", Public: true},
+			"GET /login/federated/callback": {Summary: "Callback of upstream identity provider.", Public: true},
+
+			"GET /.well-known/openid-configuration": {Summary: "OpenID provider metadata.", Public: true},
+			"GET /oauth2/authorize":                 {Summary: "OAuth2 authorization endpoint.", Public: true},
+			"POST /oauth2/authorize":                {Summary: "Submit consent of authorization.", Public: true},
+			"POST /oauth2/token":                    {Summary: "OAuth2 token endpoint.", Public: true},
+			"GET /userinfo":                         {Summary: "OpenID userinfo endpoint."},
+			"POST /userinfo":                        {Summary: "OpenID userinfo endpoint."},
+
+			"POST /v1/password-reset":         {Summary: "Send password reset token to email.", Request: password.ResetSchema{}, Public: true},
+			"POST /v1/password-reset/confirm": {Summary: "Set new password with reset token.", Request: password.ConfirmSchema{}, Public: true},
+
+			"POST /v1/users":                          {Summary: "Sign up, admin creates users in any registration mode.", Request: user.CreateSchema{}, Public: true},
+			"GET /v1/users":                           {Summary: "List users, watch changes if watch=true.", Query: list, Response: v1.UserList{}},
+			"GET /v1/users/:name":                     {Summary: "Get user.", Response: v1.User{}},
+			"PUT /v1/users/:name":                     {Summary: "Update user, privileges are only changed by admin.", Request: v1.UserPatch{}},
+			"PUT /v1/users/:name/change-password":     {Summary: "Change password.", Request: user.ChangePasswordSchema{}},
+			"POST /v1/users/:name/mfa":                {Summary: "Start mfa enrolment.", Response: mfaEnrolment},
+			"POST /v1/users/:name/mfa/verify":         {Summary: "Verify mfa enrolment.", Request: user.MFACodeSchema{}, Response: recoveryCodes},
+			"POST /v1/users/:name/mfa/recovery-codes": {Summary: "Regenerate recovery codes.", Request: user.MFACodeSchema{}, Response: recoveryCodes},
+			"DELETE /v1/users/:name/mfa":              {Summary: "Disable mfa.", Request: user.MFACodeSchema{}},
+			"DELETE /v1/users/:name/tokens":           {Summary: "Revoke all tokens of user."},
+			"GET /v1/users/:name/sessions":            {Summary: "List sessions.", Response: v1.SessionList{}},
+			"DELETE /v1/users/:name/sessions/:id":     {Summary: "Revoke session."},
+			"GET /v1/users/:name/login-events":        {Summary: "List login events.", Query: list, Response: v1.LoginEventList{}},
+			"DELETE /v1/users":                        {Summary: "Delete users."},
+			"DELETE /v1/users/:name":                  {Summary: "Delete user."},
+
+			"POST /v1/policies":         {Summary: "Create policy.", Request: v1.Policy{}},
+			"GET /v1/policies":          {Summary: "List policies, watch changes if watch=true.", Query: list, Response: v1.PolicyList{}},
+			"GET /v1/policies/:name":    {Summary: "Get policy.", Response: v1.Policy{}},
+			"PUT /v1/policies":          {Summary: "Update policy.", Request: v1.Policy{}},
+			"DELETE /v1/policies":       {Summary: "Delete policies."},
+			"DELETE /v1/policies/:name": {Summary: "Delete policy."},
+
+			"POST /v1/secrets":         {Summary: "Create secret.", Request: v1.Secret{}},
+			"GET /v1/secrets":          {Summary: "List secrets, watch changes if watch=true.", Query: list, Response: v1.SecretList{}},
+			"GET /v1/secrets/:name":    {Summary: "Get secret.", Response: v1.Secret{}},
+			"PUT /v1/secrets":          {Summary: "Update secret.", Request: v1.Secret{}},
+			"DELETE /v1/secrets":       {Summary: "Delete secrets."},
+			"DELETE /v1/secrets/:name": {Summary: "Delete secret."},
+
+			"POST /v1/serviceaccounts":                            {Summary: "Create service account.", Request: v1.ServiceAccount{}, Response: v1.ServiceAccount{}},
+			"GET /v1/serviceaccounts":                             {Summary: "List service accounts.", Query: list, Response: v1.ServiceAccountList{}},
+			"GET /v1/serviceaccounts/:name":                       {Summary: "Get service account.", Response: v1.ServiceAccount{}},
+			"PUT /v1/serviceaccounts/:name":                       {Summary: "Update service account.", Request: v1.ServiceAccount{}, Response: v1.ServiceAccount{}},
+			"DELETE /v1/serviceaccounts/:name":                    {Summary: "Delete service account."},
+			"POST /v1/serviceaccounts/:name/disable":              {Summary: "Disable service account.", Response: v1.ServiceAccount{}},
+			"POST /v1/serviceaccounts/:name/enable":               {Summary: "Enable service account.", Response: v1.ServiceAccount{}},
+			"POST /v1/serviceaccounts/:name/secrets":              {Summary: "Create secret of service account.", Request: v1.Secret{}, Response: v1.Secret{}},
+			"GET /v1/serviceaccounts/:name/secrets":               {Summary: "List secrets of service account.", Query: list, Response: v1.SecretList{}},
+			"DELETE /v1/serviceaccounts/:name/secrets/:secret-id": {Summary: "Delete secret of service account."},
+
+			"POST /v1/sts/assume": {Summary: "Exchange secret for temporary credential.", Request: v1.AssumeRequest{}, Response: v1.TemporaryCredential{}},
+
+			"POST /v1/batch": {Summary: "Create, update or delete users, secrets and policies in one transaction.",
+				Request: v1.BatchRequest{}, Response: v1.BatchResponse{}},
+
+			"POST /v1/invitations":         {Summary: "Create invitation.", Request: v1.Invitation{}, Response: v1.Invitation{}},
+			"GET /v1/invitations":          {Summary: "List invitations.", Query: list, Response: v1.InvitationList{}},
+			"GET /v1/invitations/:name":    {Summary: "Get invitation.", Response: v1.Invitation{}},
+			"DELETE /v1/invitations/:name": {Summary: "Delete invitation."},
+
+			"POST /v1/webhooks":                                {Summary: "Create webhook.", Request: v1.Webhook{}, Response: v1.Webhook{}},
+			"GET /v1/webhooks":                                 {Summary: "List webhooks.", Query: list, Response: v1.WebhookList{}},
+			"GET /v1/webhooks/:name":                           {Summary: "Get webhook.", Response: v1.Webhook{}},
+			"PUT /v1/webhooks/:name":                           {Summary: "Update webhook.", Request: v1.Webhook{}, Response: v1.Webhook{}},
+			"DELETE /v1/webhooks/:name":                        {Summary: "Delete webhook."},
+			"GET /v1/webhooks/:name/deliveries":                {Summary: "List deliveries of webhook.", Query: list, Response: v1.WebhookDeliveryList{}},
+			"POST /v1/webhooks/:name/deliveries/:id/redeliver": {Summary: "Redeliver event.", Response: v1.WebhookDelivery{}},
+
+			"POST /v1/oauth-clients":              {Summary: "Register oauth client, admin only.", Request: v1.OAuthClient{}, Response: oauthClientCredential},
+			"GET /v1/oauth-clients":               {Summary: "List oauth clients registered by current user.", Query: list, Response: v1.OAuthClientList{}},
+			"GET /v1/oauth-clients/:client-id":    {Summary: "Get oauth client.", Response: v1.OAuthClient{}},
+			"PUT /v1/oauth-clients/:client-id":    {Summary: "Update oauth client, admin only.", Request: v1.OAuthClient{}, Response: v1.OAuthClient{}},
+			"DELETE /v1/oauth-clients/:client-id": {Summary: "Delete oauth client, admin only."},
+		},
+	}
+}
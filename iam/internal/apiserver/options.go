@@ -7,29 +7,53 @@ import (
 )
 
 type Options struct {
-	httpSvrOptions     *generaloptions.ServerOpts
-	insecureSvrOptions *generaloptions.InsecureServerOpts
-	secureSvrOptions   *generaloptions.SecureServerOpts
-	mysqlOptions       *generaloptions.MySQLOpts
-	redisOptions       *generaloptions.RedisOpts
-	jwtOptions         *generaloptions.JwtOpts
-	gRPCOptions        *generaloptions.GRPCOpts
-	featureOptions     *generaloptions.FeatureOptions
+	httpSvrOptions      *generaloptions.ServerOpts
+	insecureSvrOptions  *generaloptions.InsecureServerOpts
+	secureSvrOptions    *generaloptions.SecureServerOpts
+	mysqlOptions        *generaloptions.MySQLOpts
+	redisOptions        *generaloptions.RedisOpts
+	jwtOptions          *generaloptions.JwtOpts
+	gRPCOptions         *generaloptions.GRPCOpts
+	featureOptions      *generaloptions.FeatureOptions
+	passwordOptions     *generaloptions.PasswordPolicyOpts
+	notifierOptions     *generaloptions.NotifierOpts
+	mfaOptions          *generaloptions.MFAOpts
+	oidcOptions         *generaloptions.OIDCOpts
+	federationOptions   *generaloptions.FederationOpts
+	ldapOptions         *generaloptions.LDAPOpts
+	signatureOptions    *generaloptions.SignatureOpts
+	stsOptions          *generaloptions.STSOpts
+	scimOptions         *generaloptions.SCIMOpts
+	registrationOptions *generaloptions.RegistrationOpts
+	idempotencyOptions  *generaloptions.IdempotencyOpts
+	webhookOptions      *generaloptions.WebhookOpts
 
 	Log *log.Options
 }
 
 func NewOptions(basename string) *Options {
 	return &Options{
-		httpSvrOptions:     generaloptions.NewServerOpts(),
-		insecureSvrOptions: generaloptions.NewInsecureServerOpts(),
-		secureSvrOptions:   generaloptions.NewSecureServerOpts(),
-		mysqlOptions:       generaloptions.NewMySQLOpts(),
-		redisOptions:       generaloptions.NewRedisOpts(),
-		jwtOptions:         generaloptions.NewJwtOpts(),
-		gRPCOptions:        generaloptions.NewGRPCOpts(),
-		featureOptions:     generaloptions.NewFeatureOptions(),
-		Log:                log.NewOptions(basename, nil),
+		httpSvrOptions:      generaloptions.NewServerOpts(),
+		insecureSvrOptions:  generaloptions.NewInsecureServerOpts(),
+		secureSvrOptions:    generaloptions.NewSecureServerOpts(),
+		mysqlOptions:        generaloptions.NewMySQLOpts(),
+		redisOptions:        generaloptions.NewRedisOpts(),
+		jwtOptions:          generaloptions.NewJwtOpts(),
+		gRPCOptions:         generaloptions.NewGRPCOpts(),
+		featureOptions:      generaloptions.NewFeatureOptions(),
+		passwordOptions:     generaloptions.NewPasswordPolicyOpts(),
+		notifierOptions:     generaloptions.NewNotifierOpts(),
+		mfaOptions:          generaloptions.NewMFAOpts(),
+		oidcOptions:         generaloptions.NewOIDCOpts(),
+		federationOptions:   generaloptions.NewFederationOpts(),
+		ldapOptions:         generaloptions.NewLDAPOpts(),
+		signatureOptions:    generaloptions.NewSignatureOpts(),
+		stsOptions:          generaloptions.NewSTSOpts(),
+		scimOptions:         generaloptions.NewSCIMOpts(),
+		registrationOptions: generaloptions.NewRegistrationOpts(),
+		idempotencyOptions:  generaloptions.NewIdempotencyOpts(),
+		webhookOptions:      generaloptions.NewWebhookOpts(),
+		Log:                 log.NewOptions(basename, nil),
 	}
 }
 
@@ -42,6 +66,18 @@ func (o *Options) Flags(appFss *app.NamedFlagSets) {
 	o.jwtOptions.AddFlags(appFss.AddFlagSet("jwt"))
 	o.gRPCOptions.AddFlags(appFss.AddFlagSet("gRPC"))
 	o.featureOptions.AddFlags(appFss.AddFlagSet("feature"))
+	o.passwordOptions.AddFlags(appFss.AddFlagSet("password policy"))
+	o.notifierOptions.AddFlags(appFss.AddFlagSet("notifier"))
+	o.mfaOptions.AddFlags(appFss.AddFlagSet("mfa"))
+	o.oidcOptions.AddFlags(appFss.AddFlagSet("oidc"))
+	o.federationOptions.AddFlags(appFss.AddFlagSet("federation"))
+	o.ldapOptions.AddFlags(appFss.AddFlagSet("ldap"))
+	o.signatureOptions.AddFlags(appFss.AddFlagSet("signature"))
+	o.stsOptions.AddFlags(appFss.AddFlagSet("sts"))
+	o.scimOptions.AddFlags(appFss.AddFlagSet("scim"))
+	o.registrationOptions.AddFlags(appFss.AddFlagSet("registration"))
+	o.idempotencyOptions.AddFlags(appFss.AddFlagSet("idempotency"))
+	o.webhookOptions.AddFlags(appFss.AddFlagSet("webhook"))
 	o.Log.AddFlags(appFss.AddFlagSet("log"))
 }
 
@@ -55,6 +91,18 @@ func (o *Options) Validate() []error {
 	errs = append(errs, o.jwtOptions.Validate()...)
 	errs = append(errs, o.gRPCOptions.Validate()...)
 	errs = append(errs, o.featureOptions.Validate()...)
+	errs = append(errs, o.passwordOptions.Validate()...)
+	errs = append(errs, o.notifierOptions.Validate()...)
+	errs = append(errs, o.mfaOptions.Validate()...)
+	errs = append(errs, o.oidcOptions.Validate()...)
+	errs = append(errs, o.federationOptions.Validate()...)
+	errs = append(errs, o.ldapOptions.Validate()...)
+	errs = append(errs, o.signatureOptions.Validate()...)
+	errs = append(errs, o.stsOptions.Validate()...)
+	errs = append(errs, o.scimOptions.Validate()...)
+	errs = append(errs, o.registrationOptions.Validate()...)
+	errs = append(errs, o.idempotencyOptions.Validate()...)
+	errs = append(errs, o.webhookOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
 	return errs
 }
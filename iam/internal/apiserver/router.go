@@ -5,20 +5,80 @@ import (
 	"istomyang.github.com/like-iam/component-base/errors"
 	"istomyang.github.com/like-iam/component-base/web"
 	auth2 "istomyang.github.com/like-iam/component/pkg/middleware/auth"
+	"istomyang.github.com/like-iam/component/pkg/notify"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/auth"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/batch"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/federation"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/invitation"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/oauthclient"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/oidc"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/password"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/policy"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/scim"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/secret"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/serviceaccount"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/sts"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/user"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/controller/v1/webhook"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/middleware"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
 )
 
-func installRouter(g *gin.Engine) {
+func installRouter(g *gin.Engine, options *Options) {
 
 	jwtCtrl := auth.GetJwtSchemeOr(nil).(*auth2.JwtScheme)
-	g.GET("/login", jwtCtrl.LoginHandler)
+	g.GET("/login", auth.LoginHandler())
 	g.GET("/logout", jwtCtrl.LogoutHandler)
-	g.GET("/refresh", jwtCtrl.RefreshHandler)
+	g.GET("/refresh", auth.RefreshHandler())
+	g.POST("/login/mfa", auth.LoginHandler())
+	g.GET("/.well-known/jwks.json", auth.JWKSHandler())
+
+	if options.federationOptions.Enabled() {
+		federationCtrl := federation.NewFederationController(store.Client(), options.federationOptions)
+
+		g.GET("/login/federated", federationCtrl.Login)
+		g.GET("/login/federated/callback", federationCtrl.Callback)
+	}
+
+	// Clients verify id token with public keys in jwks, shared key of HS256 must not be handed to them.
+	if auth.Asymmetric() {
+		oidcCtrl := oidc.NewOIDCController(store.Client(), options.oidcOptions)
+
+		g.GET("/.well-known/openid-configuration", oidcCtrl.Discovery)
+		g.GET("/oauth2/authorize", oidcCtrl.Authorize)
+		g.POST("/oauth2/authorize", oidcCtrl.AuthorizeSubmit)
+		g.POST("/oauth2/token", oidcCtrl.Token)
+		g.GET("/userinfo", oidcCtrl.BearerAuth, oidcCtrl.UserInfo)
+		g.POST("/userinfo", oidcCtrl.BearerAuth, oidcCtrl.UserInfo)
+	} else {
+		log.Warn("oidc provider is disabled, it requires --jwt.signing-algorithm other than HS256.")
+	}
+
+	if options.scimOptions.Enabled() {
+		scimCtrl := scim.NewSCIMController(store.Client(), options.scimOptions)
+
+		s := g.Group("/scim/v2", scimCtrl.Authenticate)
+		s.GET("/ServiceProviderConfig", scimCtrl.ServiceProviderConfig)
+		s.GET("/Schemas", scimCtrl.ListSchemas)
+		s.GET("/Schemas/:id", scimCtrl.GetSchema)
+		s.GET("/ResourceTypes", scimCtrl.ListResourceTypes)
+		s.GET("/ResourceTypes/:id", scimCtrl.GetResourceType)
+
+		s.GET("/Users", scimCtrl.ListUsers)
+		s.POST("/Users", scimCtrl.CreateUser)
+		s.GET("/Users/:id", scimCtrl.GetUser)
+		s.PUT("/Users/:id", scimCtrl.ReplaceUser)
+		s.PATCH("/Users/:id", scimCtrl.PatchUser)
+		s.DELETE("/Users/:id", scimCtrl.DeleteUser)
+
+		s.GET("/Groups", scimCtrl.ListGroups)
+		s.POST("/Groups", scimCtrl.CreateGroup)
+		s.GET("/Groups/:id", scimCtrl.GetGroup)
+		s.PUT("/Groups/:id", scimCtrl.ReplaceGroup)
+		s.PATCH("/Groups/:id", scimCtrl.PatchGroup)
+		s.DELETE("/Groups/:id", scimCtrl.DeleteGroup)
+	}
 
 	g.NoRoute(auth.GetAutoScheme().AuthFunc(), func(c *gin.Context) {
 		web.WriteResponse(c, errors.WithCode(errors.ErrPageNotFound, "page not found"), nil)
@@ -26,26 +86,49 @@ func installRouter(g *gin.Engine) {
 
 	v1 := g.Group("/v1")
 	{
-		userCtrl := user.NewUserController(store.Client())
+		passwordCtrl := password.NewPasswordController(store.Client(), notify.GetNotifierOr(nil), options.passwordOptions.ResetTokenTTL)
+
+		v1.POST("/password-reset", passwordCtrl.Reset)
+		v1.POST("/password-reset/confirm", passwordCtrl.Confirm)
+	}
+
+	{
+		userCtrl := user.NewUserController(store.Client(), options.registrationOptions)
 
 		users := v1.Group("/users")
-		users.POST("", userCtrl.Create)
+		// Anyone can sign up as registration mode allows, admin signs in to create users in any mode.
+		users.POST("",
+			middleware.NewOptionalAuthMiddleFunc(auth.GetAutoScheme().AuthFunc()),
+			middleware.NewSignupLimitMiddleFunc(options.registrationOptions.RateLimit, options.registrationOptions.RateWindow),
+			middleware.NewPublishPolicyMiddleFunc(),
+			userCtrl.Create)
 
-		users.Use(auth.GetAutoScheme().AuthFunc())
+		users.Use(auth.GetAutoScheme().AuthFunc(), auth.GetImpersonateScheme().AuthFunc())
 		users.GET("", userCtrl.List)
-		users.GET(":name", userCtrl.Get)
-		users.PUT(":name", userCtrl.Update)
-		users.PUT(":name/change-password", userCtrl.ChangePassword)
-		users.DELETE("", userCtrl.DeleteCollection)
-		users.DELETE(":name", userCtrl.Delete)
+		users.GET(":name", userCtrl.SelfOrAdmin, userCtrl.Get)
+		users.PUT(":name", userCtrl.SelfOrAdmin, userCtrl.Update)
+		users.PUT(":name/change-password", userCtrl.SelfOrAdmin, userCtrl.ChangePassword)
+		users.POST(":name/mfa", userCtrl.SelfOrAdmin, userCtrl.EnrolMFA)
+		users.POST(":name/mfa/verify", userCtrl.SelfOrAdmin, userCtrl.VerifyMFA)
+		users.POST(":name/mfa/recovery-codes", userCtrl.SelfOrAdmin, userCtrl.RegenerateRecoveryCodes)
+		users.DELETE(":name/mfa", userCtrl.SelfOrAdmin, userCtrl.DisableMFA)
+		users.DELETE(":name/tokens", userCtrl.SelfOrAdmin, userCtrl.RevokeTokens)
+		users.GET(":name/sessions", userCtrl.SelfOrAdmin, userCtrl.ListSessions)
+		users.DELETE(":name/sessions/:id", userCtrl.SelfOrAdmin, userCtrl.RevokeSession)
+		users.GET(":name/login-events", userCtrl.SelfOrAdmin, userCtrl.ListLoginEvents)
+		users.DELETE("", userCtrl.AdminOnly, userCtrl.DeleteCollection)
+		users.DELETE(":name", userCtrl.SelfOrAdmin, userCtrl.Delete)
 	}
 
-	v1.Use(auth.GetAutoScheme().AuthFunc())
+	v1.Use(auth.GetAutoScheme().AuthFunc(), auth.GetImpersonateScheme().AuthFunc())
+
+	// Idempotency must be before publish, so that replayed response doesn't publish again.
+	idempotency := middleware.NewIdempotencyMiddleFunc(options.idempotencyOptions.TTL)
 
 	{
 		policyCtrl := policy.NewPolicyController(store.Client())
 
-		policies := v1.Group("/policies", middleware.NewPublishPolicyMiddleFunc())
+		policies := v1.Group("/policies", idempotency, middleware.NewPublishPolicyMiddleFunc())
 		policies.POST("", policyCtrl.Create)
 		policies.GET("", policyCtrl.List)
 		policies.GET(":name", policyCtrl.Get)
@@ -57,7 +140,7 @@ func installRouter(g *gin.Engine) {
 	{
 		secretCtrl := secret.NewSecretController(store.Client())
 
-		secrets := v1.Group("/secrets", middleware.NewPublishSecretMiddleFunc())
+		secrets := v1.Group("/secrets", idempotency, middleware.NewPublishSecretMiddleFunc())
 		secrets.POST("", secretCtrl.Create)
 		secrets.GET("", secretCtrl.List)
 		secrets.GET(":name", secretCtrl.Get)
@@ -65,4 +148,67 @@ func installRouter(g *gin.Engine) {
 		secrets.DELETE("", secretCtrl.DeleteCollection)
 		secrets.DELETE(":name", secretCtrl.Delete)
 	}
+
+	{
+		serviceAccountCtrl := serviceaccount.NewServiceAccountController(store.Client())
+
+		serviceAccounts := v1.Group("/serviceaccounts", idempotency, middleware.NewPublishSecretMiddleFunc())
+		serviceAccounts.POST("", serviceAccountCtrl.Create)
+		serviceAccounts.GET("", serviceAccountCtrl.List)
+		serviceAccounts.GET(":name", serviceAccountCtrl.Get)
+		serviceAccounts.PUT(":name", serviceAccountCtrl.Update)
+		serviceAccounts.DELETE(":name", serviceAccountCtrl.Delete)
+		serviceAccounts.POST(":name/disable", serviceAccountCtrl.Disable)
+		serviceAccounts.POST(":name/enable", serviceAccountCtrl.Enable)
+		serviceAccounts.POST(":name/secrets", serviceAccountCtrl.CreateSecret)
+		serviceAccounts.GET(":name/secrets", serviceAccountCtrl.ListSecrets)
+		serviceAccounts.DELETE(":name/secrets/:secret-id", serviceAccountCtrl.DeleteSecret)
+	}
+
+	{
+		stsCtrl := sts.NewSTSController(options.stsOptions)
+
+		v1.POST("/sts/assume", stsCtrl.Assume)
+	}
+
+	{
+		batchCtrl := batch.NewBatchController(store.Client())
+
+		v1.POST("/batch", idempotency, middleware.NewPublishSecretMiddleFunc(), middleware.NewPublishPolicyMiddleFunc(), batchCtrl.Execute)
+	}
+
+	{
+		invitationCtrl := invitation.NewInvitationController(store.Client(), options.registrationOptions)
+
+		invitations := v1.Group("/invitations", invitationCtrl.AdminOnly)
+		invitations.POST("", invitationCtrl.Create)
+		invitations.GET("", invitationCtrl.List)
+		invitations.GET(":name", invitationCtrl.Get)
+		invitations.DELETE(":name", invitationCtrl.Delete)
+	}
+
+	{
+		webhookCtrl := webhook.NewWebhookController(store.Client(), options.webhookOptions)
+
+		webhooks := v1.Group("/webhooks")
+		webhooks.POST("", webhookCtrl.Create)
+		webhooks.GET("", webhookCtrl.List)
+		webhooks.GET(":name", webhookCtrl.Get)
+		webhooks.PUT(":name", webhookCtrl.Update)
+		webhooks.DELETE(":name", webhookCtrl.Delete)
+		webhooks.GET(":name/deliveries", webhookCtrl.ListDeliveries)
+		webhooks.POST(":name/deliveries/:id/redeliver", webhookCtrl.Redeliver)
+	}
+
+	{
+		oauthClientCtrl := oauthclient.NewOAuthClientController(store.Client())
+
+		// Clients receive tokens of any user who signs in, so only admin can register them.
+		oauthClients := v1.Group("/oauth-clients")
+		oauthClients.POST("", oauthClientCtrl.AdminOnly, oauthClientCtrl.Create)
+		oauthClients.GET("", oauthClientCtrl.List)
+		oauthClients.GET(":client-id", oauthClientCtrl.Get)
+		oauthClients.PUT(":client-id", oauthClientCtrl.AdminOnly, oauthClientCtrl.Update)
+		oauthClients.DELETE(":client-id", oauthClientCtrl.AdminOnly, oauthClientCtrl.Delete)
+	}
 }
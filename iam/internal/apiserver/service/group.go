@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+)
+
+type GroupSvc interface {
+	Create(ctx context.Context, group *v1.Group, opts metav1.CreateOperateMeta) error
+	Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOperateMeta) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOperateMeta) error
+	Get(ctx context.Context, name string, opts metav1.GetOperateMeta) (*v1.Group, error)
+	List(ctx context.Context, opts metav1.ListOperateMeta) (*v1.GroupList, error)
+
+	// Members returns users in group.
+	Members(ctx context.Context, name string) ([]*v1.User, error)
+	// AddMembers puts users into group, all of them must exist.
+	AddMembers(ctx context.Context, name string, usernames []string) error
+	// RemoveMembers takes users out of group, users not in group are ignored.
+	RemoveMembers(ctx context.Context, name string, usernames []string) error
+	// SetMembers makes users the only members of group.
+	SetMembers(ctx context.Context, name string, usernames []string) error
+}
+
+type groupSvc struct {
+	svc *service
+}
+
+func newGroupSvc(svc *service) GroupSvc {
+	return &groupSvc{svc: svc}
+}
+
+func (g *groupSvc) Create(ctx context.Context, group *v1.Group, opts metav1.CreateOperateMeta) error {
+	return g.svc.store.Group().Create(ctx, group, opts)
+}
+
+func (g *groupSvc) Update(ctx context.Context, group *v1.Group, opts metav1.UpdateOperateMeta) error {
+	return g.svc.store.Group().Update(ctx, group, opts)
+}
+
+func (g *groupSvc) Delete(ctx context.Context, name string, opts metav1.DeleteOperateMeta) error {
+	return g.svc.store.Group().Delete(ctx, name, opts)
+}
+
+func (g *groupSvc) Get(ctx context.Context, name string, opts metav1.GetOperateMeta) (*v1.Group, error) {
+	return g.svc.store.Group().Get(ctx, name, opts)
+}
+
+func (g *groupSvc) List(ctx context.Context, opts metav1.ListOperateMeta) (*v1.GroupList, error) {
+	return g.svc.store.Group().List(ctx, opts)
+}
+
+func (g *groupSvc) Members(ctx context.Context, name string) ([]*v1.User, error) {
+	return g.svc.store.User().ListByGroup(ctx, name)
+}
+
+func (g *groupSvc) AddMembers(ctx context.Context, name string, usernames []string) error {
+	for _, username := range usernames {
+		user, err := g.svc.store.User().Get(ctx, username, metav1.GetOperateMeta{})
+		if err != nil || user == nil {
+			return errors.WithCode(codes.ErrUserNotFound, "user %s not found.", username)
+		}
+		if user.HasGroup(name) {
+			continue
+		}
+		user.Groups = append(user.Groups, name)
+		if err = g.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *groupSvc) RemoveMembers(ctx context.Context, name string, usernames []string) error {
+	for _, username := range usernames {
+		user, err := g.svc.store.User().Get(ctx, username, metav1.GetOperateMeta{})
+		if err != nil || user == nil || !user.HasGroup(name) {
+			continue
+		}
+		if err = g.removeFrom(ctx, user, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *groupSvc) SetMembers(ctx context.Context, name string, usernames []string) error {
+	members, err := g.Members(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		keep[username] = true
+	}
+	for _, user := range members {
+		if keep[user.Username] {
+			continue
+		}
+		if err = g.removeFrom(ctx, user, name); err != nil {
+			return err
+		}
+	}
+	return g.AddMembers(ctx, name, usernames)
+}
+
+func (g *groupSvc) removeFrom(ctx context.Context, user *v1.User, name string) error {
+	var groups []string
+	for _, n := range user.Groups {
+		if n != name {
+			groups = append(groups, n)
+		}
+	}
+	user.Groups = groups
+	return g.svc.Users().Update(ctx, user, metav1.UpdateOperateMeta{})
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"strings"
+)
+
+const invitationCodeLength = 32
+
+type InvitationSvc interface {
+	// Create generates code of invitation, it can only be read from Code of invitation this time.
+	Create(ctx context.Context, invitation *v1.Invitation, opts metav1.CreateOperateMeta) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOperateMeta) error
+	Get(ctx context.Context, name string, opts metav1.GetOperateMeta) (*v1.Invitation, error)
+	List(ctx context.Context, opts metav1.ListOperateMeta) (*v1.InvitationList, error)
+
+	// Redeem marks invitation of code used by user with email, and returns it.
+	Redeem(ctx context.Context, code string, username string, email string) (*v1.Invitation, error)
+	// Release makes invitation usable again, used when registration fails after Redeem.
+	Release(ctx context.Context, invitation *v1.Invitation) error
+	// AssignPolicies copies policies of invitation to user.
+	AssignPolicies(ctx context.Context, invitation *v1.Invitation, username string) error
+}
+
+type invitationSvc struct {
+	svc *service
+}
+
+func newInvitationSvc(svc *service) InvitationSvc {
+	return &invitationSvc{svc: svc}
+}
+
+func (i *invitationSvc) Create(ctx context.Context, invitation *v1.Invitation, opts metav1.CreateOperateMeta) error {
+	for _, name := range invitation.Policies {
+		if _, err := i.svc.store.Policy().Get(ctx, invitation.CreatedBy, name, metav1.GetOperateMeta{}); err != nil {
+			return err
+		}
+	}
+
+	code, err := idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, invitationCodeLength)
+	if err != nil {
+		return errors.WithCode(errors.ErrUnknown, "generate invitation code fail.")
+	}
+	invitation.Code, invitation.CodeHash = code, hashInvitationCode(code)
+	invitation.UsedBy, invitation.UsedAt = "", nil
+
+	return i.svc.store.Invitation().Create(ctx, invitation, opts)
+}
+
+func (i *invitationSvc) Delete(ctx context.Context, name string, opts metav1.DeleteOperateMeta) error {
+	return i.svc.store.Invitation().Delete(ctx, name, opts)
+}
+
+func (i *invitationSvc) Get(ctx context.Context, name string, opts metav1.GetOperateMeta) (*v1.Invitation, error) {
+	return i.svc.store.Invitation().Get(ctx, name, opts)
+}
+
+func (i *invitationSvc) List(ctx context.Context, opts metav1.ListOperateMeta) (*v1.InvitationList, error) {
+	return i.svc.store.Invitation().List(ctx, opts)
+}
+
+func (i *invitationSvc) Redeem(ctx context.Context, code string, username string, email string) (*v1.Invitation, error) {
+	invitation, err := i.svc.store.Invitation().GetByCodeHash(ctx, hashInvitationCode(code), metav1.GetOperateMeta{})
+	if err != nil || !invitation.Usable() {
+		return nil, errors.WithCode(codes.ErrInvitationInvalid, "invitation code is invalid, used or expired.")
+	}
+	if invitation.Email != "" && !strings.EqualFold(invitation.Email, email) {
+		return nil, errors.WithCode(codes.ErrInvitationInvalid, "invitation is not for %s.", email)
+	}
+
+	ok, err := i.svc.store.Invitation().Consume(ctx, invitation.Name, username)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.WithCode(codes.ErrInvitationInvalid, "invitation code is invalid, used or expired.")
+	}
+	return invitation, nil
+}
+
+func (i *invitationSvc) Release(ctx context.Context, invitation *v1.Invitation) error {
+	return i.svc.store.Invitation().Release(ctx, invitation.Name)
+}
+
+func (i *invitationSvc) AssignPolicies(ctx context.Context, invitation *v1.Invitation, username string) error {
+	for _, name := range invitation.Policies {
+		template, err := i.svc.store.Policy().Get(ctx, invitation.CreatedBy, name, metav1.GetOperateMeta{})
+		if err != nil {
+			return err
+		}
+		policy := &v1.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: template.Name},
+			Username:   username,
+			Policy:     template.Policy,
+		}
+		policy.Policy.Subjects = []string{username}
+		if err = i.svc.Policies().Create(ctx, policy, metav1.CreateOperateMeta{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashInvitationCode is what stored, so that codes can't be read from database.
+func hashInvitationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+)
+
+type LoginEventSvc interface {
+	List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.LoginEventList, error)
+}
+
+type loginEventSvc struct {
+	svc *service
+}
+
+func newLoginEventSvc(svc *service) LoginEventSvc {
+	return &loginEventSvc{svc: svc}
+}
+
+func (l *loginEventSvc) List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.LoginEventList, error) {
+	return l.svc.store.LoginEvent().List(ctx, username, opts)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+)
+
+type OAuthClientSvc interface {
+	Create(ctx context.Context, client *v1.OAuthClient, opts metav1.CreateOperateMeta) error
+	Update(ctx context.Context, client *v1.OAuthClient, opts metav1.UpdateOperateMeta) error
+	Delete(ctx context.Context, clientID string, opts metav1.DeleteOperateMeta) error
+	Get(ctx context.Context, clientID string, opts metav1.GetOperateMeta) (*v1.OAuthClient, error)
+	List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.OAuthClientList, error)
+}
+
+type oauthClientSvc struct {
+	svc *service
+}
+
+func newOAuthClientSvc(svc *service) OAuthClientSvc {
+	return &oauthClientSvc{svc: svc}
+}
+
+func (o *oauthClientSvc) Create(ctx context.Context, client *v1.OAuthClient, opts metav1.CreateOperateMeta) error {
+	return o.svc.store.OAuthClient().Create(ctx, client, opts)
+}
+
+func (o *oauthClientSvc) Update(ctx context.Context, client *v1.OAuthClient, opts metav1.UpdateOperateMeta) error {
+	return o.svc.store.OAuthClient().Update(ctx, client, opts)
+}
+
+func (o *oauthClientSvc) Delete(ctx context.Context, clientID string, opts metav1.DeleteOperateMeta) error {
+	return o.svc.store.OAuthClient().Delete(ctx, clientID, opts)
+}
+
+func (o *oauthClientSvc) Get(ctx context.Context, clientID string, opts metav1.GetOperateMeta) (*v1.OAuthClient, error) {
+	return o.svc.store.OAuthClient().Get(ctx, clientID, opts)
+}
+
+func (o *oauthClientSvc) List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.OAuthClientList, error) {
+	return o.svc.store.OAuthClient().List(ctx, username, opts)
+}
@@ -3,16 +3,22 @@ package service
 import (
 	"context"
 	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
 )
 
 type PolicySvc interface {
+	// Create validates policy, and refuses one whose name is taken by another policy of the user.
 	Create(ctx context.Context, policy *v1.Policy, opts metav1.CreateOperateMeta) error
+	// Update validates policy before saving it.
 	Update(ctx context.Context, policy *v1.Policy, opts metav1.UpdateOperateMeta) error
 	Delete(ctx context.Context, username string, name string, opts metav1.DeleteOperateMeta) error
 	DeleteCollection(ctx context.Context, username string, names []string, opts metav1.DeleteOperateMeta) error
 	Get(ctx context.Context, username string, name string, opts metav1.GetOperateMeta) (*v1.Policy, error)
 	List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.PolicyList, error)
+	ListAll(ctx context.Context, opts metav1.ListOperateMeta) (*v1.PolicyList, error)
 }
 
 type policySvc struct {
@@ -24,19 +30,46 @@ func newPolicySvc(svc *service) PolicySvc {
 }
 
 func (p *policySvc) Create(ctx context.Context, policy *v1.Policy, opts metav1.CreateOperateMeta) error {
-	return p.svc.store.Policy().Create(ctx, policy, opts)
+	if err := validator.Validate(policy); err != nil {
+		return err
+	}
+	if old, err := p.svc.store.Policy().Get(ctx, policy.Username, policy.Name, metav1.GetOperateMeta{}); err == nil && old != nil {
+		return errors.WithCode(codes.ErrPolicyAlreadyExit, "policy %s already exists.", policy.Name)
+	}
+	if err := p.svc.store.Policy().Create(ctx, policy, opts); err != nil {
+		return err
+	}
+	p.svc.emit(ctx, v1.EventPolicyCreated, policy.Username, policy.Name, policy)
+	return nil
 }
 
 func (p *policySvc) Update(ctx context.Context, policy *v1.Policy, opts metav1.UpdateOperateMeta) error {
-	return p.svc.store.Policy().Update(ctx, policy, opts)
+	if err := validator.Validate(policy); err != nil {
+		return err
+	}
+	if err := p.svc.store.Policy().Update(ctx, policy, opts); err != nil {
+		return err
+	}
+	p.svc.emit(ctx, v1.EventPolicyUpdated, policy.Username, policy.Name, policy)
+	return nil
 }
 
 func (p *policySvc) Delete(ctx context.Context, username string, name string, opts metav1.DeleteOperateMeta) error {
-	return p.svc.store.Policy().Delete(ctx, username, name, opts)
+	if err := p.svc.store.Policy().Delete(ctx, username, name, opts); err != nil {
+		return err
+	}
+	p.svc.emit(ctx, v1.EventPolicyDeleted, username, name, &v1.Policy{ObjectMeta: metav1.ObjectMeta{Name: name}, Username: username})
+	return nil
 }
 
 func (p *policySvc) DeleteCollection(ctx context.Context, username string, names []string, opts metav1.DeleteOperateMeta) error {
-	return p.svc.store.Policy().DeleteCollection(ctx, username, names, opts)
+	if err := p.svc.store.Policy().DeleteCollection(ctx, username, names, opts); err != nil {
+		return err
+	}
+	for _, name := range names {
+		p.svc.emit(ctx, v1.EventPolicyDeleted, username, name, &v1.Policy{ObjectMeta: metav1.ObjectMeta{Name: name}, Username: username})
+	}
+	return nil
 }
 
 func (p *policySvc) Get(ctx context.Context, username string, name string, opts metav1.GetOperateMeta) (*v1.Policy, error) {
@@ -46,3 +79,7 @@ func (p *policySvc) Get(ctx context.Context, username string, name string, opts
 func (p *policySvc) List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.PolicyList, error) {
 	return p.svc.store.Policy().List(ctx, username, opts)
 }
+
+func (p *policySvc) ListAll(ctx context.Context, opts metav1.ListOperateMeta) (*v1.PolicyList, error) {
+	return p.svc.store.Policy().ListAll(ctx, opts)
+}
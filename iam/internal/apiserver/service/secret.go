@@ -4,15 +4,21 @@ import (
 	"context"
 	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
 )
 
 type SecretSvc interface {
+	// Create validates secret before saving it, secret id and key are set by caller.
 	Create(ctx context.Context, secret *v1.Secret, opts metav1.CreateOperateMeta) error
+	// Update validates secret before saving it.
 	Update(ctx context.Context, secret *v1.Secret, opts metav1.UpdateOperateMeta) error
 	Delete(ctx context.Context, username, secretID string, opts metav1.DeleteOperateMeta) error
 	DeleteCollection(ctx context.Context, username string, secretIDs []string, opts metav1.DeleteOperateMeta) error
 	Get(ctx context.Context, username, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error)
+	GetByID(ctx context.Context, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error)
 	List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.SecretList, error)
+	// ListEnabled lists secrets can be used to authenticate, ones of disabled owners are excluded.
+	ListEnabled(ctx context.Context, opts metav1.ListOperateMeta) (*v1.SecretList, error)
 }
 
 type secretSvc struct {
@@ -24,25 +30,57 @@ func newSecretSvc(svc *service) SecretSvc {
 }
 
 func (s *secretSvc) Create(ctx context.Context, secret *v1.Secret, opts metav1.CreateOperateMeta) error {
-	return s.svc.store.Secret().Create(ctx, secret, opts)
+	if err := validator.Validate(secret); err != nil {
+		return err
+	}
+	if err := s.svc.store.Secret().Create(ctx, secret, opts); err != nil {
+		return err
+	}
+	s.svc.emit(ctx, v1.EventSecretCreated, secret.Username, secret.SecretID, secret)
+	return nil
 }
 
 func (s *secretSvc) Update(ctx context.Context, secret *v1.Secret, opts metav1.UpdateOperateMeta) error {
-	return s.svc.store.Secret().Update(ctx, secret, opts)
+	if err := validator.Validate(secret); err != nil {
+		return err
+	}
+	if err := s.svc.store.Secret().Update(ctx, secret, opts); err != nil {
+		return err
+	}
+	s.svc.emit(ctx, v1.EventSecretUpdated, secret.Username, secret.SecretID, secret)
+	return nil
 }
 
 func (s *secretSvc) Delete(ctx context.Context, username, secretID string, opts metav1.DeleteOperateMeta) error {
-	return s.svc.store.Secret().Delete(ctx, username, secretID, opts)
+	if err := s.svc.store.Secret().Delete(ctx, username, secretID, opts); err != nil {
+		return err
+	}
+	s.svc.emit(ctx, v1.EventSecretDeleted, username, secretID, &v1.Secret{Username: username, SecretID: secretID})
+	return nil
 }
 
 func (s *secretSvc) DeleteCollection(ctx context.Context, username string, secretIDs []string, opts metav1.DeleteOperateMeta) error {
-	return s.svc.store.Secret().DeleteCollection(ctx, username, secretIDs, opts)
+	if err := s.svc.store.Secret().DeleteCollection(ctx, username, secretIDs, opts); err != nil {
+		return err
+	}
+	for _, secretID := range secretIDs {
+		s.svc.emit(ctx, v1.EventSecretDeleted, username, secretID, &v1.Secret{Username: username, SecretID: secretID})
+	}
+	return nil
 }
 
 func (s *secretSvc) Get(ctx context.Context, username, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error) {
 	return s.svc.store.Secret().Get(ctx, username, secretID, opts)
 }
 
+func (s *secretSvc) GetByID(ctx context.Context, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error) {
+	return s.svc.store.Secret().GetByID(ctx, secretID, opts)
+}
+
 func (s *secretSvc) List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.SecretList, error) {
 	return s.svc.store.Secret().List(ctx, username, opts)
 }
+
+func (s *secretSvc) ListEnabled(ctx context.Context, opts metav1.ListOperateMeta) (*v1.SecretList, error) {
+	return s.svc.store.Secret().ListEnabled(ctx, opts)
+}
@@ -1,15 +1,32 @@
 package service
 
-import "istomyang.github.com/like-iam/iam/internal/apiserver/store"
+import (
+	"context"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+)
 
 type Service interface {
 	Users() UserSvc
 	Secrets() SecretSvc
 	Policies() PolicySvc
+	OAuthClients() OAuthClientSvc
+	ServiceAccounts() ServiceAccountSvc
+	LoginEvents() LoginEventSvc
+	Groups() GroupSvc
+	Invitations() InvitationSvc
+	Webhooks() WebhookSvc
+
+	// Transaction runs fn with a service whose changes are in one store transaction, events of changes
+	// are emitted only after it commits. Transaction of service given to fn is nested, see store.Factory.
+	Transaction(ctx context.Context, fn func(tx Service) error) error
 }
 
 type service struct {
 	store store.Factory
+
+	// pending saves events emitted in transaction, nil if not in transaction. They are emitted by
+	// service out of transaction, whose store is still usable.
+	pending *[]func(s *service)
 }
 
 func NewService(factory store.Factory) Service {
@@ -27,3 +44,48 @@ func (s *service) Secrets() SecretSvc {
 func (s *service) Policies() PolicySvc {
 	return newPolicySvc(s)
 }
+
+func (s *service) OAuthClients() OAuthClientSvc {
+	return newOAuthClientSvc(s)
+}
+
+func (s *service) ServiceAccounts() ServiceAccountSvc {
+	return newServiceAccountSvc(s)
+}
+
+func (s *service) LoginEvents() LoginEventSvc {
+	return newLoginEventSvc(s)
+}
+
+func (s *service) Groups() GroupSvc {
+	return newGroupSvc(s)
+}
+
+func (s *service) Invitations() InvitationSvc {
+	return newInvitationSvc(s)
+}
+
+func (s *service) Webhooks() WebhookSvc {
+	return newWebhookSvc(s)
+}
+
+func (s *service) Transaction(ctx context.Context, fn func(tx Service) error) error {
+	var pending []func(s *service)
+	err := s.store.Transaction(ctx, func(tx store.Factory) error {
+		pending = pending[:0]
+		return fn(&service{store: tx, pending: &pending})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Nested transaction hands events to the outer one, which may still roll back.
+	if s.pending != nil {
+		*s.pending = append(*s.pending, pending...)
+		return nil
+	}
+	for _, emit := range pending {
+		emit(s)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+)
+
+type ServiceAccountSvc interface {
+	Create(ctx context.Context, account *v1.ServiceAccount, opts metav1.CreateOperateMeta) error
+	Update(ctx context.Context, account *v1.ServiceAccount, opts metav1.UpdateOperateMeta) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOperateMeta) error
+	Get(ctx context.Context, name string, opts metav1.GetOperateMeta) (*v1.ServiceAccount, error)
+	List(ctx context.Context, owner string, groups []string, opts metav1.ListOperateMeta) (*v1.ServiceAccountList, error)
+}
+
+type serviceAccountSvc struct {
+	svc *service
+}
+
+func newServiceAccountSvc(svc *service) ServiceAccountSvc {
+	return &serviceAccountSvc{svc: svc}
+}
+
+func (s *serviceAccountSvc) Create(ctx context.Context, account *v1.ServiceAccount, opts metav1.CreateOperateMeta) error {
+	if err := s.svc.store.ServiceAccount().Create(ctx, account, opts); err != nil {
+		return err
+	}
+	s.svc.emit(ctx, v1.EventServiceAccountCreated, account.Owner, account.Name, account)
+	return nil
+}
+
+func (s *serviceAccountSvc) Update(ctx context.Context, account *v1.ServiceAccount, opts metav1.UpdateOperateMeta) error {
+	if err := s.svc.store.ServiceAccount().Update(ctx, account, opts); err != nil {
+		return err
+	}
+	s.svc.emit(ctx, v1.EventServiceAccountUpdated, account.Owner, account.Name, account)
+	return nil
+}
+
+// Delete also deletes secrets of account, so event of it tells their deletion.
+func (s *serviceAccountSvc) Delete(ctx context.Context, name string, opts metav1.DeleteOperateMeta) error {
+	var owner string
+	if account, err := s.svc.store.ServiceAccount().Get(ctx, name, metav1.GetOperateMeta{}); err == nil && account != nil {
+		owner = account.Owner
+	}
+	if err := s.svc.store.ServiceAccount().Delete(ctx, name, opts); err != nil {
+		return err
+	}
+	s.svc.emit(ctx, v1.EventServiceAccountDeleted, owner, name, &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name}, Owner: owner})
+	return nil
+}
+
+func (s *serviceAccountSvc) Get(ctx context.Context, name string, opts metav1.GetOperateMeta) (*v1.ServiceAccount, error) {
+	return s.svc.store.ServiceAccount().Get(ctx, name, opts)
+}
+
+func (s *serviceAccountSvc) List(ctx context.Context, owner string, groups []string, opts metav1.ListOperateMeta) (*v1.ServiceAccountList, error) {
+	return s.svc.store.ServiceAccount().List(ctx, owner, groups, opts)
+}
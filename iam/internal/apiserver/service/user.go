@@ -3,8 +3,14 @@ package service
 import (
 	"context"
 	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	"istomyang.github.com/like-iam/component-base/errors"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/validator"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
 	"sync"
+	"time"
 )
 
 type UserSvc interface {
@@ -15,7 +21,24 @@ type UserSvc interface {
 	Get(ctx context.Context, username string, opts metav1.GetOperateMeta) (*v1.User, error)
 	List(ctx context.Context, opts metav1.ListOperateMeta) (*v1.UserList, error)
 	ListWithBadPerformance(ctx context.Context, opts metav1.ListOperateMeta) (*v1.UserList, error)
+	// ListAll returns all users without counting their policies.
+	ListAll(ctx context.Context) ([]*v1.User, error)
+	// ListByFilter returns a page of users matching filter without counting their policies.
+	ListByFilter(ctx context.Context, filter *store.Filter, opts metav1.ListOperateMeta) (*v1.UserList, error)
 	ChangePassword(ctx context.Context, user *v1.User) error
+	// Patch merges patch into saved user, only admin can change privileges.
+	Patch(ctx context.Context, username string, patch *v1.UserPatch, admin bool) (*v1.User, error)
+
+	// Register creates user whose Password is plain text, every api creating users goes through it, so that
+	// they enforce the same rules: username must not be taken or be principal of service account, password
+	// is checked by password policy and hashed, and user is neither enrolled in MFA nor linked to upstream
+	// identity, which is only done by federated login. IsAdmin and Groups are kept, caller must clear them
+	// if it isn't admin.
+	Register(ctx context.Context, user *v1.User) error
+	// Provision is Register for provisioning client, user without password can only login with upstream provider.
+	Provision(ctx context.Context, user *v1.User) error
+	// IsAdmin tells whether user exists and is admin.
+	IsAdmin(ctx context.Context, username string) bool
 }
 
 type userSvc struct {
@@ -27,19 +50,37 @@ func newUserSvc(svc *service) UserSvc {
 }
 
 func (u *userSvc) Create(ctx context.Context, user *v1.User, opts metav1.CreateOperateMeta) error {
-	return u.svc.store.User().Create(ctx, user, opts)
+	if err := u.svc.store.User().Create(ctx, user, opts); err != nil {
+		return err
+	}
+	u.svc.emit(ctx, v1.EventUserCreated, user.Username, user.Username, withoutPassword(user))
+	return nil
 }
 
 func (u *userSvc) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOperateMeta) error {
-	return u.svc.store.User().Update(ctx, user, opts)
+	if err := u.svc.store.User().Update(ctx, user, opts); err != nil {
+		return err
+	}
+	u.svc.emit(ctx, v1.EventUserUpdated, user.Username, user.Username, withoutPassword(user))
+	return nil
 }
 
 func (u *userSvc) Delete(ctx context.Context, username string, opts metav1.DeleteOperateMeta) error {
-	return u.svc.store.User().Delete(ctx, username, opts)
+	if err := u.svc.store.User().Delete(ctx, username, opts); err != nil {
+		return err
+	}
+	u.svc.emit(ctx, v1.EventUserDeleted, username, username, &v1.User{Username: username})
+	return nil
 }
 
 func (u *userSvc) DeleteCollection(ctx context.Context, usernames []string, opts metav1.DeleteOperateMeta) error {
-	return u.svc.store.User().DeleteCollection(ctx, usernames, opts)
+	if err := u.svc.store.User().DeleteCollection(ctx, usernames, opts); err != nil {
+		return err
+	}
+	for _, username := range usernames {
+		u.svc.emit(ctx, v1.EventUserDeleted, username, username, &v1.User{Username: username})
+	}
+	return nil
 }
 
 func (u *userSvc) Get(ctx context.Context, username string, opts metav1.GetOperateMeta) (*v1.User, error) {
@@ -114,6 +155,96 @@ func (u *userSvc) ListWithBadPerformance(ctx context.Context, opts metav1.ListOp
 	return userList, nil
 }
 
+func (u *userSvc) ListAll(ctx context.Context) ([]*v1.User, error) {
+	limit, offset := int64(-1), int64(0)
+	userList, err := u.svc.store.User().List(ctx, metav1.ListOperateMeta{Limit: &limit, Offset: &offset})
+	if err != nil {
+		return nil, err
+	}
+	return userList.Items, nil
+}
+
+func (u *userSvc) ListByFilter(ctx context.Context, filter *store.Filter, opts metav1.ListOperateMeta) (*v1.UserList, error) {
+	return u.svc.store.User().ListByFilter(ctx, filter, opts)
+}
+
 func (u *userSvc) ChangePassword(ctx context.Context, user *v1.User) error {
-	return u.svc.store.User().Update(ctx, user, metav1.UpdateOperateMeta{})
+	return u.Update(ctx, user, metav1.UpdateOperateMeta{})
+}
+
+func (u *userSvc) Patch(ctx context.Context, username string, patch *v1.UserPatch, admin bool) (*v1.User, error) {
+	if patch.Privileged() && !admin {
+		return nil, errors.WithCode(errors.ErrPermissionDenied, "only admin can change privileges of users.")
+	}
+
+	user, err := u.Get(ctx, username, metav1.GetOperateMeta{})
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.WithCode(codes.ErrUserNotFound, "user %s not found.", username)
+	}
+
+	patch.Apply(user)
+	if err = validator.Validate(user); err != nil {
+		return nil, err
+	}
+
+	if err = u.Update(ctx, user, metav1.UpdateOperateMeta{}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u *userSvc) Register(ctx context.Context, user *v1.User) error {
+	return u.register(ctx, user, true)
+}
+
+func (u *userSvc) Provision(ctx context.Context, user *v1.User) error {
+	return u.register(ctx, user, false)
+}
+
+func (u *userSvc) register(ctx context.Context, user *v1.User, passwordRequired bool) error {
+	// Principal of service account must not be taken by user.
+	if _, ok := v1.ServiceAccountName(user.Username); ok {
+		return errors.WithCode(errors.ErrValidation, "username must not start with %s.", v1.ServiceAccountPrefix)
+	}
+	if err := validator.Validate(user); err != nil {
+		return err
+	}
+	if passwordRequired || user.Password != "" {
+		if err := validator.CheckPasswordErr(user.Password); err != nil {
+			return errors.WithCode(errors.ErrValidation, "%s", err.Error())
+		}
+	}
+	if old, err := u.Get(ctx, user.Username, metav1.GetOperateMeta{}); err == nil && old != nil {
+		return errors.WithCode(codes.ErrUserAlreadyExist, "user %s already exists.", user.Username)
+	}
+
+	user.IdentityProvider, user.ExternalID = "", ""
+	user.MFAEnabled, user.MFASecret, user.RecoveryCodes = false, "", nil
+	user.PasswordHistory = nil
+	if user.Password != "" {
+		hashed, err := auth.Encrypt(user.Password)
+		if err != nil {
+			return errors.WithCode(errors.ErrEncrypt, "%s", err.Error())
+		}
+		user.Password = hashed
+		user.PasswordChangedAt = time.Now()
+	}
+	user.LoginAt = time.Now()
+
+	return u.Create(ctx, user, metav1.CreateOperateMeta{})
+}
+
+func (u *userSvc) IsAdmin(ctx context.Context, username string) bool {
+	user, err := u.Get(ctx, username, metav1.GetOperateMeta{})
+	return err == nil && user != nil && user.Admin()
+}
+
+// withoutPassword copies user for watchers, hash of password must not leave server.
+func withoutPassword(user *v1.User) *v1.User {
+	u := *user
+	u.Password = ""
+	return &u
 }
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/watch"
+	"istomyang.github.com/like-iam/log"
+	"time"
+)
+
+const webhookSecretLength = 32
+
+type WebhookSvc interface {
+	// Create generates signing secret of webhook if not given.
+	Create(ctx context.Context, webhook *v1.Webhook, opts metav1.CreateOperateMeta) error
+	Update(ctx context.Context, webhook *v1.Webhook, opts metav1.UpdateOperateMeta) error
+	Delete(ctx context.Context, username, name string, opts metav1.DeleteOperateMeta) error
+	Get(ctx context.Context, username, name string, opts metav1.GetOperateMeta) (*v1.Webhook, error)
+	List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.WebhookList, error)
+
+	ListDeliveries(ctx context.Context, username, name string, opts metav1.ListOperateMeta) (*v1.WebhookDeliveryList, error)
+	// Redeliver sends payload of a delivery again as a new delivery, and returns it.
+	Redeliver(ctx context.Context, username, name string, id uint64) (*v1.WebhookDelivery, error)
+}
+
+type webhookSvc struct {
+	svc *service
+}
+
+func newWebhookSvc(svc *service) WebhookSvc {
+	return &webhookSvc{svc: svc}
+}
+
+func (w *webhookSvc) Create(ctx context.Context, webhook *v1.Webhook, opts metav1.CreateOperateMeta) error {
+	if webhook.Secret == "" {
+		webhook.Secret, _ = idutil.GetRandString(idutil.AlphabetL+idutil.AlphabetU+idutil.Number, webhookSecretLength)
+	}
+	return w.svc.store.Webhook().Create(ctx, webhook, opts)
+}
+
+func (w *webhookSvc) Update(ctx context.Context, webhook *v1.Webhook, opts metav1.UpdateOperateMeta) error {
+	return w.svc.store.Webhook().Update(ctx, webhook, opts)
+}
+
+func (w *webhookSvc) Delete(ctx context.Context, username, name string, opts metav1.DeleteOperateMeta) error {
+	return w.svc.store.Webhook().Delete(ctx, username, name, opts)
+}
+
+func (w *webhookSvc) Get(ctx context.Context, username, name string, opts metav1.GetOperateMeta) (*v1.Webhook, error) {
+	return w.svc.store.Webhook().Get(ctx, username, name, opts)
+}
+
+func (w *webhookSvc) List(ctx context.Context, username string, opts metav1.ListOperateMeta) (*v1.WebhookList, error) {
+	return w.svc.store.Webhook().List(ctx, username, opts)
+}
+
+func (w *webhookSvc) ListDeliveries(ctx context.Context, username, name string, opts metav1.ListOperateMeta) (*v1.WebhookDeliveryList, error) {
+	return w.svc.store.Webhook().ListDeliveries(ctx, username, name, opts)
+}
+
+func (w *webhookSvc) Redeliver(ctx context.Context, username, name string, id uint64) (*v1.WebhookDelivery, error) {
+	old, err := w.svc.store.Webhook().GetDelivery(ctx, username, name, id)
+	if err != nil {
+		return nil, err
+	}
+	delivery := &v1.WebhookDelivery{
+		Webhook:       old.Webhook,
+		Username:      old.Username,
+		EventID:       old.EventID,
+		Event:         old.Event,
+		Payload:       old.Payload,
+		Status:        v1.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err = w.svc.store.Webhook().CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// emit logs change of obj owned by username for watchers, and queues event to subscribed webhooks after
+// mutation succeeds, webhooks of others only receive it if their owners are admin. Only logging blocks caller,
+// so that watchers get changes in order.
+func (s *service) emit(ctx context.Context, event, username, name string, obj interface{}) {
+	if s.pending != nil {
+		*s.pending = append(*s.pending, func(s *service) {
+			s.emitNow(ctx, event, username, name, obj)
+		})
+		return
+	}
+	s.emitNow(ctx, event, username, name, obj)
+}
+
+func (s *service) emitNow(ctx context.Context, event, username, name string, obj interface{}) {
+	watch.Publish(ctx, event, username, obj)
+
+	e := &v1.WebhookEvent{
+		Type:      event,
+		Username:  username,
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+	e.ID, _ = idutil.GetRandString(idutil.AlphabetL+idutil.Number, 24)
+	// Context of request may be reused once request ends.
+	e.Actor, _ = ctx.Value(middleware.UserNameKey).(string)
+
+	go s.queue(context.Background(), e)
+}
+
+func (s *service) queue(ctx context.Context, e *v1.WebhookEvent) {
+	webhooks, err := s.store.Webhook().ListEnabled(ctx)
+	if err != nil {
+		log.Errorf("list webhooks for event %s fail: %s", e.ID, err.Error())
+		return
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		log.Errorf("encode event %s fail: %s", e.ID, err.Error())
+		return
+	}
+
+	admins := map[string]bool{}
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(e.Type) {
+			continue
+		}
+		if webhook.Username != e.Username {
+			admin, ok := admins[webhook.Username]
+			if !ok {
+				admin = newUserSvc(s).IsAdmin(ctx, webhook.Username)
+				admins[webhook.Username] = admin
+			}
+			if !admin {
+				continue
+			}
+		}
+
+		delivery := &v1.WebhookDelivery{
+			Webhook:       webhook.Name,
+			Username:      webhook.Username,
+			EventID:       e.ID,
+			Event:         e.Type,
+			Payload:       string(payload),
+			Status:        v1.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err = s.store.Webhook().CreateDelivery(ctx, delivery); err != nil {
+			log.Errorf("queue event %s to webhook %s fail: %s", e.ID, webhook.Name, err.Error())
+		}
+	}
+}
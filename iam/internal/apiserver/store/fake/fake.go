@@ -1,6 +1,7 @@
 package fake
 
 import (
+	"context"
 	"fmt"
 	"github.com/ory/ladon"
 	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
@@ -13,23 +14,94 @@ const ResourceCount = 1000
 
 type datastore struct {
 	sync.RWMutex
-	users    []*v1.User
-	secrets  []*v1.Secret
-	policies []*v1.Policy
+	users      []*v1.User
+	secrets    []*v1.Secret
+	policies   []*v1.Policy
+	webhooks   []*v1.Webhook
+	deliveries []*v1.WebhookDelivery
+
+	serviceAccounts []*v1.ServiceAccount
+	oauthClients    []*v1.OAuthClient
+	loginEvents     []*v1.LoginEvent
 }
 
 func (s *datastore) User() store.UserStore {
-	return nil
+	return newUser(s)
 }
 
 func (s *datastore) Secret() store.SecretStore {
-	return nil
+	return newSecret(s)
 }
 
 func (s *datastore) Policy() store.PolicyStore {
+	return newPolicy(s)
+}
+
+func (s *datastore) OAuthClient() store.OAuthClientStore {
+	return newOAuthClient(s)
+}
+
+func (s *datastore) ServiceAccount() store.ServiceAccountStore {
+	return newServiceAccount(s)
+}
+
+func (s *datastore) LoginEvent() store.LoginEventStore {
+	return newLoginEvent(s)
+}
+
+func (s *datastore) Group() store.GroupStore {
+	return nil
+}
+
+func (s *datastore) Invitation() store.InvitationStore {
+	return nil
+}
+
+func (s *datastore) Webhook() store.WebhookStore {
+	return newWebhook(s)
+}
+
+// Transaction runs fn directly and restores a copy of data if it fails, which works as savepoint when
+// nested. It isn't isolated from concurrent changes.
+func (s *datastore) Transaction(c context.Context, fn func(tx store.Factory) error) error {
+	s.RLock()
+	saved := s.copy()
+	s.RUnlock()
+
+	if err := fn(s); err != nil {
+		s.Lock()
+		s.users, s.secrets, s.policies = saved.users, saved.secrets, saved.policies
+		s.webhooks, s.deliveries = saved.webhooks, saved.deliveries
+		s.serviceAccounts, s.oauthClients = saved.serviceAccounts, saved.oauthClients
+		s.Unlock()
+		return err
+	}
 	return nil
 }
 
+// copy copies items too, since stores return and replace pointers saved in datastore.
+func (s *datastore) copy() *datastore {
+	return &datastore{
+		users:      copyItems(s.users),
+		secrets:    copyItems(s.secrets),
+		policies:   copyItems(s.policies),
+		webhooks:   copyItems(s.webhooks),
+		deliveries: copyItems(s.deliveries),
+
+		serviceAccounts: copyItems(s.serviceAccounts),
+		oauthClients:    copyItems(s.oauthClients),
+	}
+}
+
+func copyItems[T any](items []*T) []*T {
+	r := make([]*T, len(items))
+	for i, v := range items {
+		c := *v
+		r[i] = &c
+	}
+	return r
+}
+
 func (s *datastore) Run() error {
 	return nil
 }
@@ -43,9 +115,14 @@ var (
 	once    sync.Once
 )
 
+// NewFactory returns an empty store, every call has its own data, used in tests.
+func NewFactory() store.Factory {
+	return &datastore{}
+}
+
 func GetFakeFactory() (store.Factory, error) {
 	once.Do(func() {
-		var r *datastore
+		r := &datastore{}
 		r.users = createUsers(ResourceCount)
 		r.secrets = createSecrets(ResourceCount)
 		r.policies = createPolicies(ResourceCount)
@@ -107,3 +184,18 @@ func createPolicies(count int) []*v1.Policy {
 
 	return rs
 }
+
+// pageRange returns range of items in page of n items, nil or negative offset and limit are canceled like mysql.
+func pageRange(n int, opts metav1.ListOperateMeta) (int, int) {
+	from, to := 0, n
+	if opts.Offset != nil && *opts.Offset > 0 {
+		from = int(*opts.Offset)
+	}
+	if from > n {
+		from = n
+	}
+	if opts.Limit != nil && *opts.Limit >= 0 && from+int(*opts.Limit) < n {
+		to = from + int(*opts.Limit)
+	}
+	return from, to
+}
@@ -0,0 +1,58 @@
+package fake
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"time"
+)
+
+type loginEvent struct {
+	db *datastore
+}
+
+func newLoginEvent(ds *datastore) store.LoginEventStore {
+	return &loginEvent{db: ds}
+}
+
+func (l *loginEvent) Create(c context.Context, event *v1.LoginEvent, opts metav1.CreateOperateMeta) error {
+	l.db.Lock()
+	defer l.db.Unlock()
+
+	event.ID = uint64(len(l.db.loginEvents) + 1)
+	l.db.loginEvents = append(l.db.loginEvents, event)
+
+	return nil
+}
+
+func (l *loginEvent) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.LoginEventList, error) {
+	l.db.RLock()
+	defer l.db.RUnlock()
+
+	events := l.newest(username, time.Time{})
+	from, to := pageRange(len(events), opts)
+	return &v1.LoginEventList{ListMeta: metav1.ListMeta{TotalCount: int64(len(events))}, Items: events[from:to]}, nil
+}
+
+func (l *loginEvent) ListSince(c context.Context, username string, since time.Time, limit int) ([]*v1.LoginEvent, error) {
+	l.db.RLock()
+	defer l.db.RUnlock()
+
+	events := l.newest(username, since)
+	if limit >= 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// newest returns events of user created after since, newest first.
+func (l *loginEvent) newest(username string, since time.Time) []*v1.LoginEvent {
+	var events []*v1.LoginEvent
+	for i := len(l.db.loginEvents) - 1; i >= 0; i-- {
+		if e := l.db.loginEvents[i]; e.Username == username && e.CreatedAt.After(since) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
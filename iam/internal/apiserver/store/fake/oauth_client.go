@@ -0,0 +1,86 @@
+package fake
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+)
+
+type oauthClient struct {
+	db *datastore
+}
+
+func newOAuthClient(ds *datastore) store.OAuthClientStore {
+	return &oauthClient{db: ds}
+}
+
+func (o *oauthClient) Create(c context.Context, client *v1.OAuthClient, opts metav1.CreateOperateMeta) error {
+	o.db.Lock()
+	defer o.db.Unlock()
+
+	client.ID = uint64(len(o.db.oauthClients) + 1)
+	o.db.oauthClients = append(o.db.oauthClients, client)
+
+	return nil
+}
+
+func (o *oauthClient) Update(c context.Context, client *v1.OAuthClient, opts metav1.UpdateOperateMeta) error {
+	o.db.Lock()
+	defer o.db.Unlock()
+
+	for i, v := range o.db.oauthClients {
+		if v.ClientID == client.ClientID {
+			o.db.oauthClients[i] = client
+			return nil
+		}
+	}
+
+	return errors.WithCode(codes.ErrOAuthClientNotFound, "oauth client %s not found.", client.ClientID)
+}
+
+func (o *oauthClient) Delete(c context.Context, clientID string, opts metav1.DeleteOperateMeta) error {
+	o.db.Lock()
+	defer o.db.Unlock()
+
+	for i, v := range o.db.oauthClients {
+		if v.ClientID == clientID {
+			o.db.oauthClients = append(o.db.oauthClients[:i], o.db.oauthClients[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.WithCode(codes.ErrOAuthClientNotFound, "oauth client %s not found.", clientID)
+}
+
+func (o *oauthClient) Get(c context.Context, clientID string, opts metav1.GetOperateMeta) (*v1.OAuthClient, error) {
+	o.db.Lock()
+	defer o.db.Unlock()
+
+	for _, v := range o.db.oauthClients {
+		if v.ClientID == clientID {
+			return v, nil
+		}
+	}
+
+	return nil, errors.WithCode(codes.ErrOAuthClientNotFound, "oauth client %s not found.", clientID)
+}
+
+func (o *oauthClient) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.OAuthClientList, error) {
+	o.db.Lock()
+	defer o.db.Unlock()
+
+	var r []*v1.OAuthClient
+	for _, v := range o.db.oauthClients {
+		if v.Username == username {
+			r = append(r, v)
+		}
+	}
+
+	return &v1.OAuthClientList{
+		ListMeta: metav1.ListMeta{TotalCount: int64(len(r))},
+		Items:    r,
+	}, nil
+}
@@ -67,7 +67,7 @@ func (p *policy) Delete(c context.Context, username string, name string, opts me
 		}
 	}
 
-	return errors.WithCode(codes.ErrSecretNotFound, "secret not found.")
+	return errors.WithCode(codes.ErrPolicyNotFound, "policy not found.")
 }
 
 func (p *policy) DeleteCollection(c context.Context, username string, names []string, opts metav1.DeleteOperateMeta) error {
@@ -96,12 +96,12 @@ func (p *policy) Get(c context.Context, username string, name string, opts metav
 	defer p.db.Unlock()
 
 	for _, v := range p.db.policies {
-		if v.Username == username && v.Name == name {
+		if v.Username == username && v.Name == name && !v.DeletedAt.Valid {
 			return v, nil
 		}
 	}
 
-	return nil, errors.WithCode(codes.ErrSecretNotFound, "policy name `%s` in user `%s` not found", name, username)
+	return nil, errors.WithCode(codes.ErrPolicyNotFound, "policy name `%s` in user `%s` not found", name, username)
 }
 
 func (p *policy) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.PolicyList, error) {
@@ -110,7 +110,7 @@ func (p *policy) List(c context.Context, username string, opts metav1.ListOperat
 
 	var r []*v1.Policy
 	for _, v := range p.db.policies {
-		if strings.Contains(v.Username, username) {
+		if strings.Contains(v.Username, username) && !v.DeletedAt.Valid {
 			r = append(r, v)
 		}
 	}
@@ -121,6 +121,24 @@ func (p *policy) List(c context.Context, username string, opts metav1.ListOperat
 	}, nil
 }
 
+func (p *policy) ListAll(c context.Context, opts metav1.ListOperateMeta) (*v1.PolicyList, error) {
+	p.db.Lock()
+	defer p.db.Unlock()
+
+	var r []*v1.Policy
+	for _, v := range p.db.policies {
+		if !v.DeletedAt.Valid {
+			r = append(r, v)
+		}
+	}
+
+	from, to := pageRange(len(r), opts)
+	return &v1.PolicyList{
+		ListMeta: metav1.ListMeta{TotalCount: int64(len(r))},
+		Items:    r[from:to],
+	}, nil
+}
+
 func (p *policy) ClearOutdated(c context.Context, maxReserveDays int) (int64, error) {
 	ps := make([]*v1.Policy, 0)
 	for _, v := range p.db.policies {
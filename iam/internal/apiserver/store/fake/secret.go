@@ -6,6 +6,7 @@ import (
 	"istomyang.github.com/like-iam/component-base/errors"
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
 	"istomyang.github.com/like-iam/component-base/util/idutil"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
 	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
 	"strings"
 )
@@ -14,6 +15,10 @@ type secret struct {
 	db *datastore
 }
 
+func newSecret(ds *datastore) store.SecretStore {
+	return &secret{db: ds}
+}
+
 func (s *secret) Create(c context.Context, secret *v1.Secret, opts metav1.CreateOperateMeta) error {
 	s.db.Lock()
 	defer s.db.Unlock()
@@ -104,8 +109,47 @@ func (s *secret) List(c context.Context, username string, opts metav1.ListOperat
 		}
 	}
 
+	from, to := pageRange(len(r), opts)
+	return &v1.SecretList{
+		ListMeta: metav1.ListMeta{TotalCount: int64(len(r))},
+		Items:    r[from:to],
+	}, nil
+}
+
+func (s *secret) GetByID(c context.Context, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	for _, v := range s.db.secrets {
+		if v.SecretID == secretID {
+			return v, nil
+		}
+	}
+
+	return nil, errors.WithCode(codes.ErrSecretNotFound, "secret-id `%s` not found", secretID)
+}
+
+func (s *secret) ListEnabled(c context.Context, opts metav1.ListOperateMeta) (*v1.SecretList, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	disabled := map[string]bool{}
+	for _, v := range s.db.users {
+		if v.Disabled {
+			disabled[v.Username] = true
+		}
+	}
+
+	var r []*v1.Secret
+	for _, v := range s.db.secrets {
+		if !disabled[v.Username] {
+			r = append(r, v)
+		}
+	}
+
+	from, to := pageRange(len(r), opts)
 	return &v1.SecretList{
 		ListMeta: metav1.ListMeta{TotalCount: int64(len(r))},
-		Items:    r,
+		Items:    r[from:to],
 	}, nil
 }
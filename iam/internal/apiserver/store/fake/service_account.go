@@ -0,0 +1,106 @@
+package fake
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+)
+
+type serviceAccount struct {
+	db *datastore
+}
+
+func newServiceAccount(ds *datastore) store.ServiceAccountStore {
+	return &serviceAccount{db: ds}
+}
+
+func (s *serviceAccount) Create(c context.Context, account *v1.ServiceAccount, opts metav1.CreateOperateMeta) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	for _, v := range s.db.serviceAccounts {
+		if v.Name == account.Name {
+			return errors.WithCode(errors.ErrValidation, "service account %s has already existed.", account.Name)
+		}
+	}
+
+	account.ID = uint64(len(s.db.serviceAccounts) + 1)
+	s.db.serviceAccounts = append(s.db.serviceAccounts, account)
+
+	return nil
+}
+
+func (s *serviceAccount) Update(c context.Context, account *v1.ServiceAccount, opts metav1.UpdateOperateMeta) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	for i, v := range s.db.serviceAccounts {
+		if v.Name == account.Name {
+			s.db.serviceAccounts[i] = account
+			return nil
+		}
+	}
+
+	return errors.WithCode(codes.ErrServiceAccountNotFound, "service account %s not found.", account.Name)
+}
+
+func (s *serviceAccount) Delete(c context.Context, name string, opts metav1.DeleteOperateMeta) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	principal := v1.ServiceAccountPrincipal(name)
+	var ss []*v1.Secret
+	for _, v := range s.db.secrets {
+		if v.Username != principal {
+			ss = append(ss, v)
+		}
+	}
+	s.db.secrets = ss
+
+	for i, v := range s.db.serviceAccounts {
+		if v.Name == name {
+			s.db.serviceAccounts = append(s.db.serviceAccounts[:i], s.db.serviceAccounts[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.WithCode(codes.ErrServiceAccountNotFound, "service account %s not found.", name)
+}
+
+func (s *serviceAccount) Get(c context.Context, name string, opts metav1.GetOperateMeta) (*v1.ServiceAccount, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	for _, v := range s.db.serviceAccounts {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+
+	return nil, errors.WithCode(codes.ErrServiceAccountNotFound, "service account %s not found.", name)
+}
+
+func (s *serviceAccount) List(c context.Context, owner string, groups []string, opts metav1.ListOperateMeta) (*v1.ServiceAccountList, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	inGroups := map[string]bool{}
+	for _, g := range groups {
+		inGroups[g] = true
+	}
+
+	var r []*v1.ServiceAccount
+	for _, v := range s.db.serviceAccounts {
+		if v.Owner == owner || v.OwnerGroup != "" && inGroups[v.OwnerGroup] {
+			r = append(r, v)
+		}
+	}
+
+	return &v1.ServiceAccountList{
+		ListMeta: metav1.ListMeta{TotalCount: int64(len(r))},
+		Items:    r,
+	}, nil
+}
@@ -7,7 +7,9 @@ import (
 	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
 	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
 	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type user struct {
@@ -133,3 +135,61 @@ func (u *user) List(c context.Context, opts metav1.ListOperateMeta) (*v1.UserLis
 		Items:    us,
 	}, nil
 }
+
+func (u *user) ListByGroup(c context.Context, group string) ([]*v1.User, error) {
+	u.db.Lock()
+	defer u.db.Unlock()
+
+	var us []*v1.User
+
+	for _, v := range u.db.users {
+		if v.HasGroup(group) {
+			us = append(us, v)
+		}
+	}
+
+	return us, nil
+}
+
+func (u *user) ListByFilter(c context.Context, filter *store.Filter, opts metav1.ListOperateMeta) (*v1.UserList, error) {
+	u.db.Lock()
+	defer u.db.Unlock()
+
+	var us []*v1.User
+	for _, v := range u.db.users {
+		if filter == nil || filter.Match(userValues(v)) {
+			us = append(us, v)
+		}
+	}
+
+	list := &v1.UserList{ListMeta: metav1.ListMeta{TotalCount: int64(len(us))}}
+	from, to := pageRange(len(us), opts)
+	list.Items = us[from:to]
+	return list, nil
+}
+
+// userValues returns values of fields of user for store.Filter.
+func userValues(user *v1.User) func(field string) []string {
+	return func(field string) []string {
+		switch field {
+		case store.UserFieldUsername:
+			return []string{user.Username}
+		case store.UserFieldEmail:
+			return []string{user.Email}
+		case store.UserFieldGroups:
+			return user.Groups
+		case store.UserFieldDisabled:
+			return []string{strconv.FormatBool(user.Disabled)}
+		case store.UserFieldCreatedAt:
+			return []string{user.CreatedAt.UTC().Format(time.RFC3339)}
+		case store.UserFieldUpdatedAt:
+			return []string{user.UpdatedAt.UTC().Format(time.RFC3339)}
+		}
+		if key := strings.TrimPrefix(field, store.FieldExtendPrefix); key != field {
+			if s, ok := user.Extend[key].(string); ok {
+				return []string{s}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,192 @@
+package fake
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"time"
+)
+
+type webhook struct {
+	db *datastore
+}
+
+func newWebhook(ds *datastore) store.WebhookStore {
+	return &webhook{db: ds}
+}
+
+func (w *webhook) Create(c context.Context, webhook *v1.Webhook, opts metav1.CreateOperateMeta) error {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	webhook.ID = uint64(len(w.db.webhooks) + 1)
+	w.db.webhooks = append(w.db.webhooks, webhook)
+
+	return nil
+}
+
+func (w *webhook) Update(c context.Context, webhook *v1.Webhook, opts metav1.UpdateOperateMeta) error {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	for i, v := range w.db.webhooks {
+		if v.Username == webhook.Username && v.Name == webhook.Name {
+			w.db.webhooks[i] = webhook
+			return nil
+		}
+	}
+
+	return errors.WithCode(codes.ErrWebhookNotFound, "webhook %s not found.", webhook.Name)
+}
+
+func (w *webhook) Delete(c context.Context, username, name string, opts metav1.DeleteOperateMeta) error {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	var ds []*v1.WebhookDelivery
+	for _, v := range w.db.deliveries {
+		if v.Username != username || v.Webhook != name {
+			ds = append(ds, v)
+		}
+	}
+	w.db.deliveries = ds
+
+	for i, v := range w.db.webhooks {
+		if v.Username == username && v.Name == name {
+			w.db.webhooks = append(w.db.webhooks[:i], w.db.webhooks[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.WithCode(codes.ErrWebhookNotFound, "webhook %s not found.", name)
+}
+
+func (w *webhook) Get(c context.Context, username, name string, opts metav1.GetOperateMeta) (*v1.Webhook, error) {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	for _, v := range w.db.webhooks {
+		if v.Username == username && v.Name == name {
+			return v, nil
+		}
+	}
+
+	return nil, errors.WithCode(codes.ErrWebhookNotFound, "webhook %s not found.", name)
+}
+
+func (w *webhook) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.WebhookList, error) {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	var r []*v1.Webhook
+	for _, v := range w.db.webhooks {
+		if v.Username == username {
+			r = append(r, v)
+		}
+	}
+
+	return &v1.WebhookList{
+		ListMeta: metav1.ListMeta{TotalCount: int64(len(r))},
+		Items:    r,
+	}, nil
+}
+
+func (w *webhook) ListEnabled(c context.Context) ([]*v1.Webhook, error) {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	var r []*v1.Webhook
+	for _, v := range w.db.webhooks {
+		if !v.Disabled {
+			r = append(r, v)
+		}
+	}
+
+	return r, nil
+}
+
+func (w *webhook) CreateDelivery(c context.Context, delivery *v1.WebhookDelivery) error {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	delivery.ID = uint64(len(w.db.deliveries) + 1)
+	delivery.CreatedAt = time.Now()
+	w.db.deliveries = append(w.db.deliveries, delivery)
+
+	return nil
+}
+
+func (w *webhook) UpdateDelivery(c context.Context, delivery *v1.WebhookDelivery) error {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	for i, v := range w.db.deliveries {
+		if v.ID == delivery.ID {
+			w.db.deliveries[i] = delivery
+			return nil
+		}
+	}
+
+	return errors.WithCode(codes.ErrWebhookDeliveryNotFound, "delivery %d not found.", delivery.ID)
+}
+
+func (w *webhook) GetDelivery(c context.Context, username, name string, id uint64) (*v1.WebhookDelivery, error) {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	for _, v := range w.db.deliveries {
+		if v.ID == id && v.Username == username && v.Webhook == name {
+			return v, nil
+		}
+	}
+
+	return nil, errors.WithCode(codes.ErrWebhookDeliveryNotFound, "delivery %d not found.", id)
+}
+
+func (w *webhook) ListDeliveries(c context.Context, username, name string, opts metav1.ListOperateMeta) (*v1.WebhookDeliveryList, error) {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	var r []*v1.WebhookDelivery
+	for i := len(w.db.deliveries) - 1; i >= 0; i-- {
+		if v := w.db.deliveries[i]; v.Username == username && v.Webhook == name {
+			r = append(r, v)
+		}
+	}
+
+	return &v1.WebhookDeliveryList{
+		ListMeta: metav1.ListMeta{TotalCount: int64(len(r))},
+		Items:    r,
+	}, nil
+}
+
+func (w *webhook) ListDue(c context.Context, now time.Time, limit int) ([]*v1.WebhookDelivery, error) {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	var r []*v1.WebhookDelivery
+	for _, v := range w.db.deliveries {
+		if len(r) < limit && v.Status == v1.DeliveryPending && !v.NextAttemptAt.After(now) {
+			r = append(r, v)
+		}
+	}
+
+	return r, nil
+}
+
+func (w *webhook) Claim(c context.Context, id uint64, now, until time.Time) (bool, error) {
+	w.db.Lock()
+	defer w.db.Unlock()
+
+	for _, v := range w.db.deliveries {
+		if v.ID == id && v.Status == v1.DeliveryPending && !v.NextAttemptAt.After(now) {
+			v.NextAttemptAt = until
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
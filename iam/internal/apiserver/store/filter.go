@@ -0,0 +1,137 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+// Operators of Filter, they follow filter of SCIM, see RFC 7644 3.4.2.2.
+const (
+	FilterAnd = "and"
+	FilterOr  = "or"
+	FilterNot = "not"
+
+	FilterEq      = "eq"
+	FilterNe      = "ne"
+	FilterContain = "co"
+	FilterStart   = "sw"
+	FilterEnd     = "ew"
+	FilterPresent = "pr"
+	FilterGt      = "gt"
+	FilterGe      = "ge"
+	FilterLt      = "lt"
+	FilterLe      = "le"
+)
+
+// Fields of user in Filter.
+const (
+	UserFieldUsername  = "username"
+	UserFieldEmail     = "email"
+	UserFieldGroups    = "groups"
+	UserFieldDisabled  = "disabled"
+	UserFieldCreatedAt = "createdAt"
+	UserFieldUpdatedAt = "updatedAt"
+
+	// FieldExtendPrefix is followed by key in Extend of object, like "extend.scimExternalId".
+	FieldExtendPrefix = "extend."
+)
+
+// Filter is a condition pushed down to store, so that store can page the result. Leaf compares values of
+// Field with Value by Op, branch combines its Filters by FilterAnd, FilterOr or FilterNot.
+// Value of time field is in RFC3339, value of bool field is true or false.
+type Filter struct {
+	Op    string
+	Field string
+	Value string
+
+	// CaseExact compares strings case-sensitively.
+	CaseExact bool
+
+	Filters []*Filter
+}
+
+// Match evaluates f with values of fields in memory, an absent field has no value.
+func (f *Filter) Match(values func(field string) []string) bool {
+	switch f.Op {
+	case FilterAnd:
+		for _, sub := range f.Filters {
+			if !sub.Match(values) {
+				return false
+			}
+		}
+		return true
+	case FilterOr:
+		for _, sub := range f.Filters {
+			if sub.Match(values) {
+				return true
+			}
+		}
+		return false
+	case FilterNot:
+		return len(f.Filters) == 1 && !f.Filters[0].Match(values)
+	case FilterNe:
+		return !(&Filter{Op: FilterEq, Field: f.Field, Value: f.Value, CaseExact: f.CaseExact}).Match(values)
+	}
+
+	for _, v := range values(f.Field) {
+		if f.Op == FilterPresent {
+			if v != "" {
+				return true
+			}
+			continue
+		}
+		if compare(v, f.Op, f.Value, f.CaseExact) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare(v, op, want string, caseExact bool) bool {
+	// Times are compared by instant, they may be in different zones.
+	order, isTime := compareTime(v, want)
+	if !caseExact {
+		v, want = strings.ToLower(v), strings.ToLower(want)
+	}
+	if !isTime {
+		order = strings.Compare(v, want)
+	}
+
+	switch op {
+	case FilterEq:
+		return order == 0
+	case FilterContain:
+		return strings.Contains(v, want)
+	case FilterStart:
+		return strings.HasPrefix(v, want)
+	case FilterEnd:
+		return strings.HasSuffix(v, want)
+	case FilterGt:
+		return order > 0
+	case FilterGe:
+		return order >= 0
+	case FilterLt:
+		return order < 0
+	case FilterLe:
+		return order <= 0
+	}
+	return false
+}
+
+func compareTime(v, want string) (int, bool) {
+	t1, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return 0, false
+	}
+	t2, err := time.Parse(time.RFC3339, want)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case t1.Before(t2):
+		return -1, true
+	case t1.After(t2):
+		return 1, true
+	}
+	return 0, true
+}
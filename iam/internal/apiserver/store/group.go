@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+)
+
+type GroupStore interface {
+	Create(c context.Context, group *v1.Group, opts metav1.CreateOperateMeta) error
+	Update(c context.Context, group *v1.Group, opts metav1.UpdateOperateMeta) error
+	// Delete removes group and its name from groups of members.
+	Delete(c context.Context, name string, opts metav1.DeleteOperateMeta) error
+	Get(c context.Context, name string, opts metav1.GetOperateMeta) (*v1.Group, error)
+	List(c context.Context, opts metav1.ListOperateMeta) (*v1.GroupList, error)
+}
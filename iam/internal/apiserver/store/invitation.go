@@ -0,0 +1,19 @@
+package store
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+)
+
+type InvitationStore interface {
+	Create(c context.Context, invitation *v1.Invitation, opts metav1.CreateOperateMeta) error
+	Delete(c context.Context, name string, opts metav1.DeleteOperateMeta) error
+	Get(c context.Context, name string, opts metav1.GetOperateMeta) (*v1.Invitation, error)
+	GetByCodeHash(c context.Context, hash string, opts metav1.GetOperateMeta) (*v1.Invitation, error)
+	List(c context.Context, opts metav1.ListOperateMeta) (*v1.InvitationList, error)
+	// Consume marks invitation used by username, false means it has been used by others.
+	Consume(c context.Context, name string, username string) (bool, error)
+	// Release marks invitation unused, so that it can be used again after registration fails.
+	Release(c context.Context, name string) error
+}
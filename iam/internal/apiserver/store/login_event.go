@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"time"
+)
+
+type LoginEventStore interface {
+	Create(c context.Context, event *v1.LoginEvent, opts metav1.CreateOperateMeta) error
+	// List returns events of user, newest first.
+	List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.LoginEventList, error)
+	// ListSince returns at most limit events of user created after since, newest first.
+	ListSince(c context.Context, username string, since time.Time, limit int) ([]*v1.LoginEvent, error)
+}
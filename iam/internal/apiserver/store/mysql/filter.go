@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"encoding/json"
+	"istomyang.github.com/like-iam/component-base/errors"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// userColumns maps fields of store.Filter to columns of users, extend fields are read from json.
+var userColumns = map[string]string{
+	store.UserFieldUsername:  "username",
+	store.UserFieldEmail:     "email",
+	store.UserFieldDisabled:  "disabled",
+	store.UserFieldCreatedAt: "createdAt",
+	store.UserFieldUpdatedAt: "updatedAt",
+}
+
+// whereUser builds condition of users from filter.
+func whereUser(f *store.Filter) (string, []interface{}, error) {
+	switch f.Op {
+	case store.FilterAnd, store.FilterOr:
+		var conds []string
+		var args []interface{}
+		for _, sub := range f.Filters {
+			cond, subArgs, err := whereUser(sub)
+			if err != nil {
+				return "", nil, err
+			}
+			conds, args = append(conds, "("+cond+")"), append(args, subArgs...)
+		}
+		if len(conds) == 0 {
+			return "", nil, errors.WithCode(errors.ErrValidation, "%s of nothing in filter.", f.Op)
+		}
+		return strings.Join(conds, " "+strings.ToUpper(f.Op)+" "), args, nil
+	case store.FilterNot:
+		if len(f.Filters) != 1 {
+			return "", nil, errors.WithCode(errors.ErrValidation, "not must have one filter.")
+		}
+		cond, args, err := whereUser(f.Filters[0])
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + cond + ")", args, nil
+	}
+
+	if f.Field == store.UserFieldGroups {
+		return whereGroups(f)
+	}
+
+	column, args := userColumns[f.Field], []interface{}{}
+	switch {
+	case column != "":
+	case strings.HasPrefix(f.Field, store.FieldExtendPrefix):
+		path, _ := json.Marshal(strings.TrimPrefix(f.Field, store.FieldExtendPrefix))
+		column, args = "JSON_UNQUOTE(JSON_EXTRACT(extendShadow, ?))", []interface{}{"$." + string(path)}
+	default:
+		return "", nil, errors.WithCode(errors.ErrValidation, "unsupported field %s in filter.", f.Field)
+	}
+
+	value, err := filterValue(f)
+	if err != nil {
+		return "", nil, err
+	}
+	_, isString := value.(string)
+	// Columns are in case-insensitive collation.
+	if f.CaseExact {
+		column = "BINARY " + column
+	}
+
+	switch f.Op {
+	case store.FilterPresent:
+		if !isString {
+			return column + " IS NOT NULL", args, nil
+		}
+		return column + " IS NOT NULL AND " + column + " <> ''", append(args, args...), nil
+	case store.FilterEq:
+		return column + " = ?", append(args, value), nil
+	case store.FilterNe:
+		// Absent field is not equal to anything.
+		return "NOT (" + column + " <=> ?)", append(args, value), nil
+	}
+	if f.Field == store.UserFieldDisabled {
+		return "", nil, errors.WithCode(errors.ErrValidation, "unsupported operator %s of %s in filter.", f.Op, f.Field)
+	}
+
+	switch f.Op {
+	case store.FilterGt:
+		return column + " > ?", append(args, value), nil
+	case store.FilterGe:
+		return column + " >= ?", append(args, value), nil
+	case store.FilterLt:
+		return column + " < ?", append(args, value), nil
+	case store.FilterLe:
+		return column + " <= ?", append(args, value), nil
+	}
+	if !isString {
+		return "", nil, errors.WithCode(errors.ErrValidation, "unsupported operator %s of %s in filter.", f.Op, f.Field)
+	}
+
+	switch f.Op {
+	case store.FilterContain:
+		return column + " LIKE ?", append(args, "%"+escapeLike(f.Value)+"%"), nil
+	case store.FilterStart:
+		return column + " LIKE ?", append(args, escapeLike(f.Value)+"%"), nil
+	case store.FilterEnd:
+		return column + " LIKE ?", append(args, "%"+escapeLike(f.Value)), nil
+	}
+	return "", nil, errors.WithCode(errors.ErrValidation, "unsupported operator %s in filter.", f.Op)
+}
+
+// whereGroups supports eq, ne and pr, groups are saved in json like listByGroup finds.
+func whereGroups(f *store.Filter) (string, []interface{}, error) {
+	quoted, _ := json.Marshal(f.Value)
+	switch f.Op {
+	case store.FilterEq:
+		return "`groups` LIKE ?", []interface{}{"%" + escapeLike(string(quoted)) + "%"}, nil
+	case store.FilterNe:
+		return "`groups` IS NULL OR `groups` NOT LIKE ?", []interface{}{"%" + escapeLike(string(quoted)) + "%"}, nil
+	case store.FilterPresent:
+		return "`groups` IS NOT NULL AND `groups` NOT IN ('', '[]', 'null')", nil, nil
+	}
+	return "", nil, errors.WithCode(errors.ErrValidation, "unsupported operator %s of groups in filter.", f.Op)
+}
+
+// filterValue converts value into type of column, it's zero value of the type for pr.
+func filterValue(f *store.Filter) (interface{}, error) {
+	switch f.Field {
+	case store.UserFieldDisabled:
+		if f.Op == store.FilterPresent {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(f.Value)
+		if err != nil {
+			return nil, errors.WithCode(errors.ErrValidation, "%s must be true or false in filter.", f.Field)
+		}
+		return b, nil
+	case store.UserFieldCreatedAt, store.UserFieldUpdatedAt:
+		if f.Op == store.FilterPresent {
+			return time.Time{}, nil
+		}
+		t, err := time.Parse(time.RFC3339, f.Value)
+		if err != nil {
+			return nil, errors.WithCode(errors.ErrValidation, "%s must be in RFC3339 in filter.", f.Field)
+		}
+		return t, nil
+	}
+	return f.Value, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWhereUser(t *testing.T) {
+	created, _ := time.Parse(time.RFC3339, "2024-01-01T00:00:00Z")
+
+	tests := []struct {
+		name  string
+		f     *store.Filter
+		where string
+		args  []interface{}
+	}{
+		{"eq", &store.Filter{Op: store.FilterEq, Field: store.UserFieldUsername, Value: "alice"},
+			"username = ?", []interface{}{"alice"}},
+		{"case exact", &store.Filter{Op: store.FilterEq, Field: store.UserFieldUsername, Value: "alice", CaseExact: true},
+			"BINARY username = ?", []interface{}{"alice"}},
+		{"ne", &store.Filter{Op: store.FilterNe, Field: store.UserFieldEmail, Value: "a@example.com"},
+			"NOT (email <=> ?)", []interface{}{"a@example.com"}},
+		{"co escapes like", &store.Filter{Op: store.FilterContain, Field: store.UserFieldEmail, Value: "a_b%"},
+			"email LIKE ?", []interface{}{`%a\_b\%%`}},
+		{"sw", &store.Filter{Op: store.FilterStart, Field: store.UserFieldUsername, Value: "al"},
+			"username LIKE ?", []interface{}{"al%"}},
+		{"pr", &store.Filter{Op: store.FilterPresent, Field: store.UserFieldEmail},
+			"email IS NOT NULL AND email <> ''", []interface{}{}},
+		{"bool", &store.Filter{Op: store.FilterEq, Field: store.UserFieldDisabled, Value: "true"},
+			"disabled = ?", []interface{}{true}},
+		{"time", &store.Filter{Op: store.FilterGt, Field: store.UserFieldCreatedAt, Value: "2024-01-01T00:00:00Z"},
+			"createdAt > ?", []interface{}{created}},
+		{"extend", &store.Filter{Op: store.FilterEq, Field: store.FieldExtendPrefix + "scimExternalId", Value: "A-1", CaseExact: true},
+			"BINARY JSON_UNQUOTE(JSON_EXTRACT(extendShadow, ?)) = ?", []interface{}{`$."scimExternalId"`, "A-1"}},
+		{"extend pr", &store.Filter{Op: store.FilterPresent, Field: store.FieldExtendPrefix + "scimDisplayName"},
+			"JSON_UNQUOTE(JSON_EXTRACT(extendShadow, ?)) IS NOT NULL AND JSON_UNQUOTE(JSON_EXTRACT(extendShadow, ?)) <> ''",
+			[]interface{}{`$."scimDisplayName"`, `$."scimDisplayName"`}},
+		{"groups", &store.Filter{Op: store.FilterEq, Field: store.UserFieldGroups, Value: "ops"},
+			"`groups` LIKE ?", []interface{}{`%"ops"%`}},
+		{"and or not", &store.Filter{Op: store.FilterAnd, Filters: []*store.Filter{
+			{Op: store.FilterOr, Filters: []*store.Filter{
+				{Op: store.FilterEq, Field: store.UserFieldUsername, Value: "a"},
+				{Op: store.FilterEq, Field: store.UserFieldUsername, Value: "b"},
+			}},
+			{Op: store.FilterNot, Filters: []*store.Filter{{Op: store.FilterEq, Field: store.UserFieldDisabled, Value: "true"}}},
+		}}, "((username = ?) OR (username = ?)) AND (NOT (disabled = ?))", []interface{}{"a", "b", true}},
+
+		{"unknown field", &store.Filter{Op: store.FilterEq, Field: "password", Value: "x"}, "", nil},
+		{"like on bool", &store.Filter{Op: store.FilterContain, Field: store.UserFieldDisabled, Value: "t"}, "", nil},
+		{"like on time", &store.Filter{Op: store.FilterStart, Field: store.UserFieldUpdatedAt, Value: "2024"}, "", nil},
+		{"invalid bool", &store.Filter{Op: store.FilterEq, Field: store.UserFieldDisabled, Value: "yes"}, "", nil},
+		{"invalid time", &store.Filter{Op: store.FilterLt, Field: store.UserFieldCreatedAt, Value: "yesterday"}, "", nil},
+		{"groups gt", &store.Filter{Op: store.FilterGt, Field: store.UserFieldGroups, Value: "a"}, "", nil},
+		{"empty and", &store.Filter{Op: store.FilterAnd}, "", nil},
+	}
+
+	for _, tt := range tests {
+		where, args, err := whereUser(tt.f)
+		if tt.where == "" {
+			if err == nil {
+				t.Errorf("%s: want err, got %s", tt.name, where)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v", tt.name, err)
+			continue
+		}
+		if where != tt.where || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: got %s %v, want %s %v", tt.name, where, args, tt.where, tt.args)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"context"
+	"gorm.io/gorm"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+)
+
+type group struct {
+	db *gorm.DB
+}
+
+func newGroup(ds *datastore) store.GroupStore {
+	return &group{db: ds.db}
+}
+
+func (g *group) Create(c context.Context, group *v1.Group, opts metav1.CreateOperateMeta) error {
+	return g.db.WithContext(c).Create(&group).Error
+}
+
+func (g *group) Update(c context.Context, group *v1.Group, opts metav1.UpdateOperateMeta) error {
+	return g.db.WithContext(c).Save(&group).Error
+}
+
+func (g *group) Delete(c context.Context, name string, opts metav1.DeleteOperateMeta) error {
+	db := g.db
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+	return db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		members, err := listByGroup(tx, name)
+		if err != nil {
+			return err
+		}
+		for _, u := range members {
+			var groups []string
+			for _, n := range u.Groups {
+				if n != name {
+					groups = append(groups, n)
+				}
+			}
+			u.Groups = groups
+			if err = tx.Save(u).Error; err != nil {
+				return errors.WithCode(errors.ErrDatabase, err.Error())
+			}
+		}
+		if err = tx.Where("name = ?", name).Delete(&v1.Group{}).Error; err != nil {
+			return errors.WithCode(errors.ErrDatabase, err.Error())
+		}
+		return nil
+	})
+}
+
+func (g *group) Get(c context.Context, name string, opts metav1.GetOperateMeta) (*v1.Group, error) {
+	r := &v1.Group{}
+	err := g.db.WithContext(c).Where("name = ?", name).First(&r).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(codes.ErrGroupNotFound, err.Error())
+		}
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+
+	return r, nil
+}
+
+func (g *group) List(c context.Context, opts metav1.ListOperateMeta) (*v1.GroupList, error) {
+	var r v1.GroupList
+	d := g.db.WithContext(c).Where("name LIKE ?", "%"+opts.FieldSelector+"%").
+		Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"context"
+	"gorm.io/gorm"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"time"
+)
+
+type invitation struct {
+	db *gorm.DB
+}
+
+func newInvitation(ds *datastore) store.InvitationStore {
+	return &invitation{db: ds.db}
+}
+
+func (i *invitation) Create(c context.Context, invitation *v1.Invitation, opts metav1.CreateOperateMeta) error {
+	return i.db.WithContext(c).Create(&invitation).Error
+}
+
+func (i *invitation) Delete(c context.Context, name string, opts metav1.DeleteOperateMeta) error {
+	db := i.db
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+	if err := db.WithContext(c).Where("name = ?", name).Delete(&v1.Invitation{}).Error; err != nil {
+		return errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+	return nil
+}
+
+func (i *invitation) Get(c context.Context, name string, opts metav1.GetOperateMeta) (*v1.Invitation, error) {
+	return i.get(c, "name = ?", name)
+}
+
+func (i *invitation) GetByCodeHash(c context.Context, hash string, opts metav1.GetOperateMeta) (*v1.Invitation, error) {
+	return i.get(c, "codeHash = ?", hash)
+}
+
+func (i *invitation) get(c context.Context, query string, arg string) (*v1.Invitation, error) {
+	r := &v1.Invitation{}
+	err := i.db.WithContext(c).Where(query, arg).First(&r).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(codes.ErrInvitationNotFound, err.Error())
+		}
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+
+	return r, nil
+}
+
+func (i *invitation) List(c context.Context, opts metav1.ListOperateMeta) (*v1.InvitationList, error) {
+	var r v1.InvitationList
+	d := i.db.WithContext(c).Where("name LIKE ?", "%"+opts.FieldSelector+"%").
+		Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
+
+func (i *invitation) Consume(c context.Context, name string, username string) (bool, error) {
+	d := i.db.WithContext(c).Model(&v1.Invitation{}).
+		Where("name = ? and usedBy = ''", name).
+		UpdateColumns(map[string]interface{}{"usedBy": username, "usedAt": time.Now()})
+	if d.Error != nil {
+		return false, errors.WithCode(errors.ErrDatabase, d.Error.Error())
+	}
+	return d.RowsAffected == 1, nil
+}
+
+func (i *invitation) Release(c context.Context, name string) error {
+	err := i.db.WithContext(c).Model(&v1.Invitation{}).
+		Where("name = ?", name).
+		UpdateColumns(map[string]interface{}{"usedBy": "", "usedAt": nil}).Error
+	if err != nil {
+		return errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"context"
+	"gorm.io/gorm"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"time"
+)
+
+type loginEvent struct {
+	db *gorm.DB
+}
+
+func newLoginEvent(ds *datastore) store.LoginEventStore {
+	return &loginEvent{db: ds.db}
+}
+
+func (l *loginEvent) Create(c context.Context, event *v1.LoginEvent, opts metav1.CreateOperateMeta) error {
+	return l.db.WithContext(c).Create(&event).Error
+}
+
+func (l *loginEvent) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.LoginEventList, error) {
+	var r v1.LoginEventList
+	d := l.db.WithContext(c).Where("username = ?", username).
+		Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
+
+func (l *loginEvent) ListSince(c context.Context, username string, since time.Time, limit int) ([]*v1.LoginEvent, error) {
+	var r []*v1.LoginEvent
+	if err := l.db.WithContext(c).Where("username = ? and createdAt > ?", username, since).
+		Limit(limit).
+		Order("id desc").
+		Find(&r).Error; err != nil {
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+	return r, nil
+}
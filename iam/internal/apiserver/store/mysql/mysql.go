@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"fmt"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -26,6 +27,36 @@ func (s *datastore) Policy() store.PolicyStore {
 	return newPolicy(s)
 }
 
+func (s *datastore) OAuthClient() store.OAuthClientStore {
+	return newOAuthClient(s)
+}
+
+func (s *datastore) ServiceAccount() store.ServiceAccountStore {
+	return newServiceAccount(s)
+}
+
+func (s *datastore) LoginEvent() store.LoginEventStore {
+	return newLoginEvent(s)
+}
+
+func (s *datastore) Group() store.GroupStore {
+	return newGroup(s)
+}
+
+func (s *datastore) Invitation() store.InvitationStore {
+	return newInvitation(s)
+}
+
+func (s *datastore) Webhook() store.WebhookStore {
+	return newWebhook(s)
+}
+
+func (s *datastore) Transaction(c context.Context, fn func(tx store.Factory) error) error {
+	return s.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		return fn(&datastore{db: tx})
+	})
+}
+
 func (s *datastore) Run() error {
 	return nil
 }
@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"context"
+	"gorm.io/gorm"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+)
+
+type oauthClient struct {
+	db *gorm.DB
+}
+
+func newOAuthClient(ds *datastore) store.OAuthClientStore {
+	return &oauthClient{db: ds.db}
+}
+
+func (o *oauthClient) Create(c context.Context, client *v1.OAuthClient, opts metav1.CreateOperateMeta) error {
+	return o.db.WithContext(c).Create(&client).Error
+}
+
+func (o *oauthClient) Update(c context.Context, client *v1.OAuthClient, opts metav1.UpdateOperateMeta) error {
+	return o.db.WithContext(c).Save(&client).Error
+}
+
+func (o *oauthClient) Delete(c context.Context, clientID string, opts metav1.DeleteOperateMeta) error {
+	db := o.db
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+	err := db.WithContext(c).Where("clientID = ?", clientID).Delete(&v1.OAuthClient{}).Error
+	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+	return err
+}
+
+func (o *oauthClient) Get(c context.Context, clientID string, opts metav1.GetOperateMeta) (*v1.OAuthClient, error) {
+	r := &v1.OAuthClient{}
+	err := o.db.WithContext(c).Where("clientID = ?", clientID).First(&r).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(codes.ErrOAuthClientNotFound, err.Error())
+		}
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+
+	return r, nil
+}
+
+func (o *oauthClient) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.OAuthClientList, error) {
+	var r v1.OAuthClientList
+	d := o.db.WithContext(c).Where("username = ? and name LIKE ?", username, "%"+opts.FieldSelector+"%").
+		Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
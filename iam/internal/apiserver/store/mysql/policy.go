@@ -79,11 +79,24 @@ func (p *policy) Get(c context.Context, username string, name string, opts metav
 
 func (p *policy) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.PolicyList, error) {
 	var r v1.PolicyList
-	d := p.db.Where("username LIKE ?", "%"+opts.FieldSelector+"%").
+	d := p.db.WithContext(c).Where("username = ?", username).
 		Limit(int(*opts.Limit)).
 		Offset(int(*opts.Offset)).
 		Order("id desc").
-		Find(&r).
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
+
+func (p *policy) ListAll(c context.Context, opts metav1.ListOperateMeta) (*v1.PolicyList, error) {
+	var r v1.PolicyList
+	d := p.db.WithContext(c).
+		Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
 		Offset(-1).
 		Limit(-1).
 		Count(&r.TotalCount)
@@ -30,7 +30,7 @@ func (s *secret) Delete(c context.Context, username, secretID string, opts metav
 	if opts.Unscoped {
 		s.db = s.db.Unscoped()
 	}
-	err := s.db.WithContext(c).Where("username = ? and secret-id = ?", username, secretID).Delete(&v1.Secret{}).Error
+	err := s.db.WithContext(c).Where("username = ? and `secret-id` = ?", username, secretID).Delete(&v1.Secret{}).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.WithCode(errors.ErrDatabase, err.Error())
 	}
@@ -49,7 +49,7 @@ func (s *secret) DeleteCollection(c context.Context, username string, secretIDs
 	if opts.Unscoped {
 		s.db = s.db.Unscoped()
 	}
-	err = s.db.WithContext(c).Where("username = ? and secret-id in (?)", username, secretIDs).Delete(&v1.Secret{}).Error
+	err = s.db.WithContext(c).Where("username = ? and `secret-id` in (?)", username, secretIDs).Delete(&v1.Secret{}).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.WithCode(errors.ErrDatabase, err.Error())
 	}
@@ -65,7 +65,20 @@ func (s *secret) deleteCollectionByUser(c context.Context, usernames []string, o
 
 func (s *secret) Get(c context.Context, username, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error) {
 	se := &v1.Secret{}
-	err := s.db.Where("username = ? and secret-id = ?", username, secretID).First(&se).Error
+	err := s.db.Where("username = ? and `secret-id` = ?", username, secretID).First(&se).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(codes.ErrSecretNotFound, err.Error())
+		}
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+
+	return se, nil
+}
+
+func (s *secret) GetByID(c context.Context, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error) {
+	se := &v1.Secret{}
+	err := s.db.WithContext(c).Where("`secret-id` = ?", secretID).First(&se).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.WithCode(codes.ErrSecretNotFound, err.Error())
@@ -78,11 +91,27 @@ func (s *secret) Get(c context.Context, username, secretID string, opts metav1.G
 
 func (s *secret) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.SecretList, error) {
 	var r v1.SecretList
-	d := s.db.Where("username LIKE ?", "%"+opts.FieldSelector+"%").
+	d := s.db.WithContext(c).Where("username = ?", username).
+		Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
+
+func (s *secret) ListEnabled(c context.Context, opts metav1.ListOperateMeta) (*v1.SecretList, error) {
+	var r v1.SecretList
+	disabledUsers := s.db.Model(&v1.User{}).Select("username").Where("disabled = ?", true)
+	disabledAccounts := s.db.Model(&v1.ServiceAccount{}).Select("concat(?, name)", v1.ServiceAccountPrefix).Where("disabled = ?", true)
+	d := s.db.WithContext(c).
+		Where("username not in (?) and username not in (?)", disabledUsers, disabledAccounts).
 		Limit(int(*opts.Limit)).
 		Offset(int(*opts.Offset)).
 		Order("id desc").
-		Find(&r).
+		Find(&r.Items).
 		Offset(-1).
 		Limit(-1).
 		Count(&r.TotalCount)
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"context"
+	"gorm.io/gorm"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+)
+
+type serviceAccount struct {
+	db *gorm.DB
+}
+
+func newServiceAccount(ds *datastore) store.ServiceAccountStore {
+	return &serviceAccount{db: ds.db}
+}
+
+func (s *serviceAccount) Create(c context.Context, account *v1.ServiceAccount, opts metav1.CreateOperateMeta) error {
+	return s.db.WithContext(c).Create(&account).Error
+}
+
+func (s *serviceAccount) Update(c context.Context, account *v1.ServiceAccount, opts metav1.UpdateOperateMeta) error {
+	return s.db.WithContext(c).Save(&account).Error
+}
+
+// Delete removes account with its secrets.
+func (s *serviceAccount) Delete(c context.Context, name string, opts metav1.DeleteOperateMeta) error {
+	db := s.db
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+	return db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("username = ?", v1.ServiceAccountPrincipal(name)).Delete(&v1.Secret{}).Error; err != nil {
+			return errors.WithCode(errors.ErrDatabase, err.Error())
+		}
+		if err := tx.Where("name = ?", name).Delete(&v1.ServiceAccount{}).Error; err != nil {
+			return errors.WithCode(errors.ErrDatabase, err.Error())
+		}
+		return nil
+	})
+}
+
+func (s *serviceAccount) Get(c context.Context, name string, opts metav1.GetOperateMeta) (*v1.ServiceAccount, error) {
+	r := &v1.ServiceAccount{}
+	err := s.db.WithContext(c).Where("name = ?", name).First(&r).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(codes.ErrServiceAccountNotFound, err.Error())
+		}
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+
+	return r, nil
+}
+
+func (s *serviceAccount) List(c context.Context, owner string, groups []string, opts metav1.ListOperateMeta) (*v1.ServiceAccountList, error) {
+	var r v1.ServiceAccountList
+	d := s.db.WithContext(c).Where("name LIKE ?", "%"+opts.FieldSelector+"%")
+	if len(groups) > 0 {
+		d = d.Where("owner = ? or ownerGroup in (?)", owner, groups)
+	} else {
+		d = d.Where("owner = ?", owner)
+	}
+	d = d.Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
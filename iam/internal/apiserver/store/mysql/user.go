@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"encoding/json"
 	"gorm.io/gorm"
 	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
 	"istomyang.github.com/like-iam/component-base/errors"
@@ -75,9 +76,57 @@ func (u *user) List(c context.Context, opts metav1.ListOperateMeta) (*v1.UserLis
 		Limit(int(*opts.Limit)).
 		Offset(int(*opts.Offset)).
 		Order("id desc").
-		Find(&users).
+		Find(&users.Items).
 		Offset(-1).
 		Limit(-1).
 		Count(&users.TotalCount)
 	return &users, d.Error
 }
+
+func (u *user) ListByGroup(c context.Context, group string) ([]*v1.User, error) {
+	return listByGroup(u.db.WithContext(c), group)
+}
+
+// listByGroup finds candidates by quoted name in json of groups, then checks them exactly.
+func listByGroup(db *gorm.DB, group string) ([]*v1.User, error) {
+	quoted, err := json.Marshal(group)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*v1.User
+	if err = db.Where("`groups` LIKE ?", "%"+string(quoted)+"%").Find(&candidates).Error; err != nil {
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+
+	var users []*v1.User
+	for _, u := range candidates {
+		if u.HasGroup(group) {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (u *user) ListByFilter(c context.Context, filter *store.Filter, opts metav1.ListOperateMeta) (*v1.UserList, error) {
+	d := u.db.WithContext(c)
+	if filter != nil {
+		cond, args, err := whereUser(filter)
+		if err != nil {
+			return nil, err
+		}
+		d = d.Where(cond, args...)
+	}
+
+	var users v1.UserList
+	d = d.Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id").
+		Find(&users.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&users.TotalCount)
+	if d.Error != nil {
+		return nil, errors.WithCode(errors.ErrDatabase, d.Error.Error())
+	}
+	return &users, nil
+}
@@ -0,0 +1,134 @@
+package mysql
+
+import (
+	"context"
+	"gorm.io/gorm"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/errors"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/iam/internal/pkg/codes"
+	"time"
+)
+
+type webhook struct {
+	db *gorm.DB
+}
+
+func newWebhook(ds *datastore) store.WebhookStore {
+	return &webhook{db: ds.db}
+}
+
+func (w *webhook) Create(c context.Context, webhook *v1.Webhook, opts metav1.CreateOperateMeta) error {
+	return w.db.WithContext(c).Create(&webhook).Error
+}
+
+func (w *webhook) Update(c context.Context, webhook *v1.Webhook, opts metav1.UpdateOperateMeta) error {
+	return w.db.WithContext(c).Save(&webhook).Error
+}
+
+func (w *webhook) Delete(c context.Context, username, name string, opts metav1.DeleteOperateMeta) error {
+	db := w.db
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+	err := db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("username = ? and name = ?", username, name).Delete(&v1.Webhook{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("username = ? and webhook = ?", username, name).Delete(&v1.WebhookDelivery{}).Error
+	})
+	if err != nil {
+		return errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+	return nil
+}
+
+func (w *webhook) Get(c context.Context, username, name string, opts metav1.GetOperateMeta) (*v1.Webhook, error) {
+	r := &v1.Webhook{}
+	err := w.db.WithContext(c).Where("username = ? and name = ?", username, name).First(&r).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(codes.ErrWebhookNotFound, err.Error())
+		}
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+
+	return r, nil
+}
+
+func (w *webhook) List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.WebhookList, error) {
+	var r v1.WebhookList
+	d := w.db.WithContext(c).Where("username = ?", username).
+		Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
+
+func (w *webhook) ListEnabled(c context.Context) ([]*v1.Webhook, error) {
+	var r []*v1.Webhook
+	if err := w.db.WithContext(c).Where("disabled = ?", false).Find(&r).Error; err != nil {
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+	return r, nil
+}
+
+func (w *webhook) CreateDelivery(c context.Context, delivery *v1.WebhookDelivery) error {
+	return w.db.WithContext(c).Create(&delivery).Error
+}
+
+func (w *webhook) UpdateDelivery(c context.Context, delivery *v1.WebhookDelivery) error {
+	return w.db.WithContext(c).Save(&delivery).Error
+}
+
+func (w *webhook) GetDelivery(c context.Context, username, name string, id uint64) (*v1.WebhookDelivery, error) {
+	r := &v1.WebhookDelivery{}
+	err := w.db.WithContext(c).Where("id = ? and username = ? and webhook = ?", id, username, name).First(&r).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(codes.ErrWebhookDeliveryNotFound, err.Error())
+		}
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+
+	return r, nil
+}
+
+func (w *webhook) ListDeliveries(c context.Context, username, name string, opts metav1.ListOperateMeta) (*v1.WebhookDeliveryList, error) {
+	var r v1.WebhookDeliveryList
+	d := w.db.WithContext(c).Where("username = ? and webhook = ?", username, name).
+		Limit(int(*opts.Limit)).
+		Offset(int(*opts.Offset)).
+		Order("id desc").
+		Find(&r.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&r.TotalCount)
+	return &r, d.Error
+}
+
+func (w *webhook) ListDue(c context.Context, now time.Time, limit int) ([]*v1.WebhookDelivery, error) {
+	var r []*v1.WebhookDelivery
+	if err := w.db.WithContext(c).Where("status = ? and nextAttemptAt <= ?", v1.DeliveryPending, now).
+		Limit(limit).
+		Order("nextAttemptAt").
+		Find(&r).Error; err != nil {
+		return nil, errors.WithCode(errors.ErrDatabase, err.Error())
+	}
+	return r, nil
+}
+
+func (w *webhook) Claim(c context.Context, id uint64, now, until time.Time) (bool, error) {
+	d := w.db.WithContext(c).Model(&v1.WebhookDelivery{}).
+		Where("id = ? and status = ? and nextAttemptAt <= ?", id, v1.DeliveryPending, now).
+		UpdateColumn("nextAttemptAt", until)
+	if d.Error != nil {
+		return false, errors.WithCode(errors.ErrDatabase, d.Error.Error())
+	}
+	return d.RowsAffected == 1, nil
+}
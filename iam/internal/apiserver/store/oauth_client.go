@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+)
+
+type OAuthClientStore interface {
+	Create(c context.Context, client *v1.OAuthClient, opts metav1.CreateOperateMeta) error
+	Update(c context.Context, client *v1.OAuthClient, opts metav1.UpdateOperateMeta) error
+	Delete(c context.Context, clientID string, opts metav1.DeleteOperateMeta) error
+	Get(c context.Context, clientID string, opts metav1.GetOperateMeta) (*v1.OAuthClient, error)
+	// List returns clients registered by username.
+	List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.OAuthClientList, error)
+}
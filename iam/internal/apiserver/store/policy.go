@@ -13,6 +13,8 @@ type PolicyStore interface {
 	DeleteCollection(c context.Context, username string, names []string, opts metav1.DeleteOperateMeta) error
 	Get(c context.Context, username string, name string, opts metav1.GetOperateMeta) (*v1.Policy, error)
 	List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.PolicyList, error)
+	// ListAll lists policies of all users, used to load cache.
+	ListAll(c context.Context, opts metav1.ListOperateMeta) (*v1.PolicyList, error)
 
 	// ClearOutdated cleans outdated policies.
 	// Use DeletedAt field, this means Delete operation just mark item should delete now.
@@ -12,5 +12,9 @@ type SecretStore interface {
 	Delete(c context.Context, username, secretID string, opts metav1.DeleteOperateMeta) error
 	DeleteCollection(c context.Context, username string, secretIDs []string, opts metav1.DeleteOperateMeta) error
 	Get(c context.Context, username, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error)
+	// GetByID finds secret by secret id only, which is unique, used to verify signed request.
+	GetByID(c context.Context, secretID string, opts metav1.GetOperateMeta) (*v1.Secret, error)
 	List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.SecretList, error)
+	// ListEnabled lists secrets of all owners except disabled users and service accounts, used to load cache.
+	ListEnabled(c context.Context, opts metav1.ListOperateMeta) (*v1.SecretList, error)
 }
@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+)
+
+type ServiceAccountStore interface {
+	Create(c context.Context, account *v1.ServiceAccount, opts metav1.CreateOperateMeta) error
+	Update(c context.Context, account *v1.ServiceAccount, opts metav1.UpdateOperateMeta) error
+	Delete(c context.Context, name string, opts metav1.DeleteOperateMeta) error
+	Get(c context.Context, name string, opts metav1.GetOperateMeta) (*v1.ServiceAccount, error)
+	// List returns accounts owned by owner or one of groups.
+	List(c context.Context, owner string, groups []string, opts metav1.ListOperateMeta) (*v1.ServiceAccountList, error)
+}
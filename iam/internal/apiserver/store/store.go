@@ -1,11 +1,23 @@
 package store
 
+import "context"
+
 var client Factory
 
 type Factory interface {
 	User() UserStore
 	Secret() SecretStore
 	Policy() PolicyStore
+	OAuthClient() OAuthClientStore
+	ServiceAccount() ServiceAccountStore
+	LoginEvent() LoginEventStore
+	Group() GroupStore
+	Invitation() InvitationStore
+	Webhook() WebhookStore
+
+	// Transaction runs fn with a factory whose stores are in one transaction, it commits if fn returns nil,
+	// or rolls back. Transaction of factory given to fn is nested and rolls back only its own changes.
+	Transaction(c context.Context, fn func(tx Factory) error) error
 
 	Run() error
 	Close() error
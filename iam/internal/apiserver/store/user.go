@@ -13,4 +13,9 @@ type UserStore interface {
 	DeleteCollection(c context.Context, usernames []string, opts metav1.DeleteOperateMeta) error
 	Get(c context.Context, username string, opts metav1.GetOperateMeta) (*v1.User, error)
 	List(c context.Context, opts metav1.ListOperateMeta) (*v1.UserList, error)
+	// ListByGroup returns all members of group.
+	ListByGroup(c context.Context, group string) ([]*v1.User, error)
+	// ListByFilter returns a page of users matching filter in order of creation, nil filter matches all.
+	// Fields out of UserField* and extend ones are invalid.
+	ListByFilter(c context.Context, filter *Filter, opts metav1.ListOperateMeta) (*v1.UserList, error)
 }
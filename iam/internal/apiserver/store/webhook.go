@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"time"
+)
+
+type WebhookStore interface {
+	Create(c context.Context, webhook *v1.Webhook, opts metav1.CreateOperateMeta) error
+	Update(c context.Context, webhook *v1.Webhook, opts metav1.UpdateOperateMeta) error
+	// Delete removes webhook with its deliveries.
+	Delete(c context.Context, username, name string, opts metav1.DeleteOperateMeta) error
+	Get(c context.Context, username, name string, opts metav1.GetOperateMeta) (*v1.Webhook, error)
+	List(c context.Context, username string, opts metav1.ListOperateMeta) (*v1.WebhookList, error)
+	// ListEnabled returns webhooks of all users which are not disabled.
+	ListEnabled(c context.Context) ([]*v1.Webhook, error)
+
+	CreateDelivery(c context.Context, delivery *v1.WebhookDelivery) error
+	UpdateDelivery(c context.Context, delivery *v1.WebhookDelivery) error
+	GetDelivery(c context.Context, username, name string, id uint64) (*v1.WebhookDelivery, error)
+	// ListDeliveries returns deliveries of webhook, newest first.
+	ListDeliveries(c context.Context, username, name string, opts metav1.ListOperateMeta) (*v1.WebhookDeliveryList, error)
+	// ListDue returns at most limit pending deliveries whose next attempt is before now.
+	ListDue(c context.Context, now time.Time, limit int) ([]*v1.WebhookDelivery, error)
+	// Claim delays next attempt of a due delivery to until, false means it's claimed by another server.
+	Claim(c context.Context, id uint64, now, until time.Time) (bool, error)
+}
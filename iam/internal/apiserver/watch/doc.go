@@ -0,0 +1,3 @@
+// Package watch logs changes of resources in redis streams, and serves them to clients listing with watch=true,
+// and to authzservers syncing cache incrementally.
+package watch
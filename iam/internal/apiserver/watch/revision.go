@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"strconv"
+)
+
+var (
+	ErrRevisionInvalid = errors.New("invalid revision")
+
+	// ErrRevisionExpired means changes after revision have been trimmed from log, caller must list all again.
+	ErrRevisionExpired = errors.New("revision is too old")
+)
+
+// Change is a change of resource read from log.
+type Change struct {
+	Type   string
+	Owner  string
+	Object json.RawMessage
+}
+
+// Revision returns current revision in format of resource version. It's taken by clock of redis
+// instead of the last version of a log, so that it can be used to read logs of all resources.
+func Revision(ctx context.Context) (string, error) {
+	t, err := conn.GetRedisClient().UniversalClient().Time(ctx).Result()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(t.UnixMilli(), 10) + "-0", nil
+}
+
+// Since returns changes of resource logged since revision in order. Changes logged in the same millisecond
+// before revision may be returned again, so that they must be applied idempotently.
+func Since(ctx context.Context, resource, revision string) ([]*Change, error) {
+	if !validVersion(revision) {
+		return nil, ErrRevisionInvalid
+	}
+	rdb := conn.GetRedisClient().UniversalClient()
+	stream := streamPrefix + resource
+
+	if old, err := expired(ctx, rdb, stream, revision); err != nil {
+		return nil, err
+	} else if old {
+		return nil, ErrRevisionExpired
+	}
+
+	msgs, err := rdb.XRange(ctx, stream, revision, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*Change, 0, len(msgs))
+	for _, msg := range msgs {
+		typ, _ := msg.Values["type"].(string)
+		owner, _ := msg.Values["owner"].(string)
+		obj, _ := msg.Values["object"].(string)
+		changes = append(changes, &Change{Type: typ, Owner: owner, Object: json.RawMessage(obj)})
+	}
+	return changes, nil
+}
+
+// expired tells whether changes after version have been trimmed from stream. Log is trimmed only if it's full,
+// a newer first one of a short log means nothing happened before it.
+func expired(ctx context.Context, rdb redis.UniversalClient, stream, version string) (bool, error) {
+	first, err := rdb.XRangeN(ctx, stream, "-", "+", 1).Result()
+	if err != nil {
+		return false, err
+	}
+	if len(first) == 0 || compareVersion(version, first[0].ID) >= 0 {
+		return false, nil
+	}
+	n, err := rdb.XLen(ctx, stream).Result()
+	if err != nil {
+		return false, err
+	}
+	return n >= maxLen, nil
+}
@@ -0,0 +1,228 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resources can be watched, they are also names of log streams.
+const (
+	ResourceUsers    = "users"
+	ResourceSecrets  = "secrets"
+	ResourcePolicies = "policies"
+
+	// ResourceServiceAccounts is only logged for syncing cache, it can't be watched over http.
+	ResourceServiceAccounts = "serviceaccounts"
+)
+
+const (
+	streamPrefix = "iam.watch."
+
+	// maxLen is about how many events are kept for each resource, watching from an older one gets 410.
+	maxLen = 10000
+
+	// block is the max wait of one read, a heartbeat is sent after it to detect closed connection.
+	block = 30 * time.Second
+	batch = 100
+
+	// fromOldest means watching from the oldest event in log.
+	fromOldest = "0"
+)
+
+// events maps type of webhook event to resource and type of watch event.
+var events = map[string][2]string{
+	v1.EventUserCreated:   {ResourceUsers, metav1.WatchAdded},
+	v1.EventUserUpdated:   {ResourceUsers, metav1.WatchModified},
+	v1.EventUserDeleted:   {ResourceUsers, metav1.WatchDeleted},
+	v1.EventSecretCreated: {ResourceSecrets, metav1.WatchAdded},
+	v1.EventSecretUpdated: {ResourceSecrets, metav1.WatchModified},
+	v1.EventSecretDeleted: {ResourceSecrets, metav1.WatchDeleted},
+	v1.EventPolicyCreated: {ResourcePolicies, metav1.WatchAdded},
+	v1.EventPolicyUpdated: {ResourcePolicies, metav1.WatchModified},
+	v1.EventPolicyDeleted: {ResourcePolicies, metav1.WatchDeleted},
+
+	v1.EventServiceAccountCreated: {ResourceServiceAccounts, metav1.WatchAdded},
+	v1.EventServiceAccountUpdated: {ResourceServiceAccounts, metav1.WatchModified},
+	v1.EventServiceAccountDeleted: {ResourceServiceAccounts, metav1.WatchDeleted},
+}
+
+// Publish appends change of obj owned by owner to log, event is type of v1.WebhookEvent such as policy.created.
+func Publish(ctx context.Context, event string, owner string, obj interface{}) {
+	e, ok := events[event]
+	if !ok {
+		return
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		log.Errorf("encode object of event %s fail: %s", event, err.Error())
+		return
+	}
+
+	err = conn.GetRedisClient().UniversalClient().XAdd(ctx, &redis.XAddArgs{
+		Stream: streamPrefix + e[0],
+		MaxLen: maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"type": e[1], "owner": owner, "object": string(data)},
+	}).Err()
+	if err != nil {
+		log.Errorf("log event %s of %s fail: %s", event, owner, err.Error())
+	}
+}
+
+// Requested tells whether client lists with watch=true.
+func Requested(c *gin.Context) bool {
+	return c.Query("watch") == "true"
+}
+
+// Serve streams changes of resource after resourceVersion in query or Last-Event-ID header, or after now if not given.
+// It's server-sent events if client accepts text/event-stream, or newline delimited json.
+// Events whose owner is not allowed are skipped.
+func Serve(c *gin.Context, resource string, allowed func(owner string) bool) {
+	rdb := conn.GetRedisClient().UniversalClient()
+	stream := streamPrefix + resource
+
+	version := c.Query("resourceVersion")
+	if version == "" {
+		version = c.GetHeader("Last-Event-ID")
+	}
+	if version == "" {
+		// Only changes after now, "$" of XREAD can't be used since every read must continue from the last one.
+		version = fromOldest
+		if last, err := rdb.XRevRangeN(c, stream, "+", "-", 1).Result(); err == nil && len(last) > 0 {
+			version = last[0].ID
+		}
+	} else if version != fromOldest {
+		if !validVersion(version) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": "invalid resourceVersion."})
+			return
+		}
+		old, err := expired(c, rdb, stream, version)
+		if err != nil {
+			log.L(c).Errorf("read watch log of %s fail: %s", resource, err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "watch is unavailable."})
+			return
+		}
+		if old {
+			c.JSON(http.StatusGone, gin.H{"code": http.StatusGone, "message": "resourceVersion is too old, list and watch again."})
+			return
+		}
+	}
+
+	sse := strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+	if sse {
+		c.Header("Content-Type", "text/event-stream")
+	} else {
+		c.Header("Content-Type", "application/json")
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	for {
+		res, err := rdb.XRead(ctx, &redis.XReadArgs{Streams: []string{stream, version}, Count: batch, Block: block}).Result()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == redis.Nil {
+			if !heartbeat(c, sse) {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			log.L(c).Errorf("read watch log of %s fail: %s", resource, err.Error())
+			obj, _ := json.Marshal(gin.H{"message": "watch is interrupted, watch again from the last resourceVersion."})
+			write(c, sse, &metav1.WatchEvent{Type: metav1.WatchError, Object: obj})
+			return
+		}
+
+		for _, s := range res {
+			for _, msg := range s.Messages {
+				version = msg.ID
+				if owner, _ := msg.Values["owner"].(string); !allowed(owner) {
+					continue
+				}
+				typ, _ := msg.Values["type"].(string)
+				obj, _ := msg.Values["object"].(string)
+				if !write(c, sse, &metav1.WatchEvent{Type: typ, ResourceVersion: msg.ID, Object: json.RawMessage(obj)}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func write(c *gin.Context, sse bool, e *metav1.WatchEvent) bool {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return false
+	}
+	if sse {
+		_, err = fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", e.ResourceVersion, e.Type, data)
+	} else {
+		_, err = c.Writer.Write(append(data, '\n'))
+	}
+	if err != nil {
+		return false
+	}
+	c.Writer.Flush()
+	return true
+}
+
+// heartbeat is ignored by clients, an sse comment or an empty line.
+func heartbeat(c *gin.Context, sse bool) bool {
+	data := "\n"
+	if sse {
+		data = ":\n\n"
+	}
+	if _, err := c.Writer.WriteString(data); err != nil {
+		return false
+	}
+	c.Writer.Flush()
+	return true
+}
+
+// validVersion checks format of redis stream id, <ms>-<seq>.
+func validVersion(v string) bool {
+	ms, seq, ok := strings.Cut(v, "-")
+	if !ok {
+		return false
+	}
+	if _, err := strconv.ParseUint(ms, 10, 64); err != nil {
+		return false
+	}
+	_, err := strconv.ParseUint(seq, 10, 64)
+	return err == nil
+}
+
+// compareVersion compares valid resource versions.
+func compareVersion(a, b string) int {
+	ams, aseq, _ := strings.Cut(a, "-")
+	bms, bseq, _ := strings.Cut(b, "-")
+	x, _ := strconv.ParseUint(ams, 10, 64)
+	y, _ := strconv.ParseUint(bms, 10, 64)
+	if x == y {
+		x, _ = strconv.ParseUint(aseq, 10, 64)
+		y, _ = strconv.ParseUint(bseq, 10, 64)
+	}
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
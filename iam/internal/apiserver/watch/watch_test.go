@@ -0,0 +1,255 @@
+package watch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/test/redistest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVersion(t *testing.T) {
+	valid := []struct {
+		version string
+		want    bool
+	}{
+		{"1700000000000-0", true},
+		{"1-2", true},
+		{"1700000000000", false},
+		{"a-0", false},
+		{"1-b", false},
+		{"-1-0", false},
+		{"", false},
+	}
+	for _, tt := range valid {
+		if got := validVersion(tt.version); got != tt.want {
+			t.Errorf("validVersion(%q) got %v, want %v", tt.version, got, tt.want)
+		}
+	}
+
+	compare := []struct {
+		a, b string
+		want int
+	}{
+		{"1-0", "1-0", 0},
+		{"1-0", "2-0", -1},
+		{"2-0", "1-9", 1},
+		{"1-2", "1-10", -1},
+		{"10-0", "9-0", 1},
+	}
+	for _, tt := range compare {
+		if got := compareVersion(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersion(%q, %q) got %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSince(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	Publish(ctx, v1.EventSecretCreated, "alice", &v1.Secret{Username: "alice", SecretID: "a1"})
+	rev := revision(t)
+	Publish(ctx, v1.EventSecretUpdated, "alice", &v1.Secret{Username: "alice", SecretID: "a1"})
+	Publish(ctx, v1.EventSecretDeleted, "bob", &v1.Secret{Username: "bob", SecretID: "b1"})
+	Publish(ctx, "unknown.event", "bob", &v1.Secret{Username: "bob", SecretID: "b1"})
+
+	changes, err := Since(ctx, ResourceSecrets, rev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("changes got %d, want 2", len(changes))
+	}
+	if changes[0].Type != metav1.WatchModified || changes[0].Owner != "alice" {
+		t.Errorf("first change got %s of %s, want MODIFIED of alice", changes[0].Type, changes[0].Owner)
+	}
+	var secret v1.Secret
+	if err := json.Unmarshal(changes[1].Object, &secret); err != nil || secret.SecretID != "b1" {
+		t.Errorf("object of second change got %s, want secret b1", changes[1].Object)
+	}
+
+	// Log is short, nothing has been trimmed.
+	if changes, err = Since(ctx, ResourceSecrets, "1-0"); err != nil || len(changes) != 3 {
+		t.Errorf("changes since oldest got %d, %v, want 3", len(changes), err)
+	}
+	if _, err = Since(ctx, ResourceSecrets, "latest"); err != ErrRevisionInvalid {
+		t.Errorf("invalid revision got %v, want %v", err, ErrRevisionInvalid)
+	}
+
+	// Full log has trimmed changes before its first one.
+	fill(t, ResourceSecrets)
+	if _, err = Since(ctx, ResourceSecrets, rev); err != ErrRevisionExpired {
+		t.Errorf("trimmed revision got %v, want %v", err, ErrRevisionExpired)
+	}
+}
+
+func TestServe(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	rev := revision(t)
+	Publish(ctx, v1.EventSecretCreated, "alice", &v1.Secret{Username: "alice", SecretID: "a1"})
+	Publish(ctx, v1.EventSecretCreated, "bob", &v1.Secret{Username: "bob", SecretID: "b1"})
+	Publish(ctx, v1.EventSecretDeleted, "alice", &v1.Secret{Username: "alice", SecretID: "a1"})
+
+	all := serve(t, "/v1/secrets?watch=true&resourceVersion="+rev, nil, func(string) bool { return true })
+	if all.Code != http.StatusOK {
+		t.Fatalf("code got %d, want %d", all.Code, http.StatusOK)
+	}
+	if ct := all.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("content type got %s, want application/json", ct)
+	}
+	events := decode(t, all.Body.String())
+	if len(events) != 3 {
+		t.Fatalf("events got %d, want 3", len(events))
+	}
+
+	// Owner filtering.
+	alice := decode(t, serve(t, "/v1/secrets?watch=true&resourceVersion="+rev, nil, func(owner string) bool {
+		return owner == "alice"
+	}).Body.String())
+	if len(alice) != 2 || alice[0].Type != metav1.WatchAdded || alice[1].Type != metav1.WatchDeleted {
+		t.Errorf("events of alice got %+v, want ADDED and DELETED", alice)
+	}
+
+	// Resume after an event by query or by Last-Event-ID of sse.
+	resumed := decode(t, serve(t, "/v1/secrets?watch=true&resourceVersion="+events[0].ResourceVersion, nil, func(string) bool {
+		return true
+	}).Body.String())
+	if len(resumed) != 2 || resumed[0].ResourceVersion != events[1].ResourceVersion {
+		t.Errorf("resumed events got %+v, want the last 2", resumed)
+	}
+	header := http.Header{"Last-Event-ID": {events[1].ResourceVersion}}
+	resumed = decode(t, serve(t, "/v1/secrets?watch=true", header, func(string) bool { return true }).Body.String())
+	if len(resumed) != 1 || resumed[0].ResourceVersion != events[2].ResourceVersion {
+		t.Errorf("events after Last-Event-ID got %+v, want the last one", resumed)
+	}
+
+	// Without version, only changes after now.
+	if now := serve(t, "/v1/secrets?watch=true", nil, func(string) bool { return true }); now.Body.Len() != 0 {
+		t.Errorf("events without version got %q, want none", now.Body.String())
+	}
+}
+
+func TestServeSSE(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	rev := revision(t)
+	Publish(ctx, v1.EventPolicyCreated, "alice", &v1.Policy{Username: "alice"})
+
+	header := http.Header{"Accept": {"text/event-stream"}}
+	w := serve(t, "/v1/policies?watch=true&resourceVersion="+rev, header, func(string) bool { return true })
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type got %s, want text/event-stream", ct)
+	}
+
+	lines := strings.Split(w.Body.String(), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], "id: ") || lines[1] != "event: "+metav1.WatchAdded ||
+		!strings.HasPrefix(lines[2], "data: ") || lines[3] != "" {
+		t.Fatalf("sse got %q", w.Body.String())
+	}
+	var e metav1.WatchEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &e); err != nil {
+		t.Fatal(err)
+	}
+	if id := strings.TrimPrefix(lines[0], "id: "); e.ResourceVersion != id {
+		t.Errorf("resource version got %s, want id %s", e.ResourceVersion, id)
+	}
+}
+
+func TestServeVersion(t *testing.T) {
+	redistest.Use()
+	ctx := context.Background()
+
+	Publish(ctx, v1.EventUserCreated, "alice", &v1.User{Username: "alice"})
+
+	tests := []struct {
+		version string
+		full    bool
+		code    int
+	}{
+		{"latest", false, http.StatusBadRequest},
+		{"1-0", false, http.StatusOK},
+		{"1-0", true, http.StatusGone},
+		{fromOldest, true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if tt.full {
+			fill(t, ResourceUsers)
+		}
+		w := serve(t, "/v1/users?watch=true&resourceVersion="+tt.version, nil, func(string) bool { return true })
+		if w.Code != tt.code {
+			t.Errorf("resourceVersion %s of full log %v got %d, want %d", tt.version, tt.full, w.Code, tt.code)
+		}
+	}
+}
+
+// serve runs Serve until it blocks for new events.
+func serve(t *testing.T, target string, header http.Header, allowed func(owner string) bool) *httptest.ResponseRecorder {
+	resource := strings.TrimPrefix(strings.SplitN(target, "?", 2)[0], "/v1/")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", target, nil).WithContext(ctx)
+	for k, v := range header {
+		c.Request.Header[http.CanonicalHeaderKey(k)] = v
+	}
+
+	Serve(c, resource, allowed)
+	return w
+}
+
+func decode(t *testing.T, body string) []*metav1.WatchEvent {
+	var events []*metav1.WatchEvent
+	s := bufio.NewScanner(strings.NewReader(body))
+	for s.Scan() {
+		if s.Text() == "" {
+			continue
+		}
+		var e metav1.WatchEvent
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Fatal(fmt.Errorf("decode %q: %w", s.Text(), err))
+		}
+		events = append(events, &e)
+	}
+	return events
+}
+
+// fill fills log of resource up to maxLen, the oldest changes are trimmed.
+func fill(t *testing.T, resource string) {
+	ctx := context.Background()
+	_, err := conn.GetRedisClient().UniversalClient().Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i := 0; i < maxLen; i++ {
+			p.XAdd(ctx, &redis.XAddArgs{Stream: streamPrefix + resource, MaxLen: maxLen, Values: []string{"type", metav1.WatchAdded}})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// revision waits a moment, so that changes published before are older than it.
+func revision(t *testing.T) string {
+	time.Sleep(2 * time.Millisecond)
+	rev, err := Revision(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	return rev
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component-base/auth"
+	metav1 "istomyang.github.com/like-iam/component-base/meta/v1"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"istomyang.github.com/like-iam/iam/internal/apiserver/store"
+	"istomyang.github.com/like-iam/log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	userAgent = "IAM-Webhook/1.0"
+
+	// batchSize is the max deliveries polled at a time.
+	batchSize = 100
+)
+
+// Deliverer posts signed payloads of due deliveries, deliveries are claimed in database,
+// so that several servers can run it together.
+type Deliverer struct {
+	store  store.Factory
+	opts   *options.WebhookOpts
+	client *http.Client
+}
+
+func NewDeliverer(factory store.Factory, opts *options.WebhookOpts) *Deliverer {
+	return &Deliverer{
+		store:  factory,
+		opts:   opts,
+		client: newClient(opts.Timeout, opts.AllowPrivateNetwork),
+	}
+}
+
+// Run polls due deliveries until ctx done.
+func (d *Deliverer) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.Interval)
+	defer ticker.Stop()
+
+	sem := make(chan struct{}, d.opts.Workers)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deliveries, err := d.store.Webhook().ListDue(ctx, time.Now(), batchSize)
+			if err != nil {
+				log.Errorf("list due webhook deliveries fail: %s", err.Error())
+				continue
+			}
+			for _, delivery := range deliveries {
+				// Claim after a worker is free, so that the lease isn't spent in waiting.
+				select {
+				case <-ctx.Done():
+					return
+				case sem <- struct{}{}:
+				}
+
+				now := time.Now()
+				// Lease lasts longer than an attempt, so that a delivery of crashed server is retried later.
+				ok, err := d.store.Webhook().Claim(ctx, delivery.ID, now, now.Add(2*d.opts.Timeout))
+				if err != nil || !ok {
+					if err != nil {
+						log.Errorf("claim webhook delivery %d fail: %s", delivery.ID, err.Error())
+					}
+					<-sem
+					continue
+				}
+
+				go func(delivery *v1.WebhookDelivery) {
+					defer func() { <-sem }()
+					d.deliver(ctx, delivery)
+				}(delivery)
+			}
+		}
+	}
+}
+
+func (d *Deliverer) deliver(ctx context.Context, delivery *v1.WebhookDelivery) {
+	webhook, err := d.store.Webhook().Get(ctx, delivery.Username, delivery.Webhook, metav1.GetOperateMeta{})
+	switch {
+	case err != nil:
+		delivery.Status, delivery.Error = v1.DeliveryFailed, "webhook not found."
+	case webhook.Disabled:
+		delivery.Status, delivery.Error = v1.DeliveryFailed, "webhook is disabled."
+	default:
+		d.attempt(ctx, webhook, delivery)
+	}
+
+	if err = d.store.Webhook().UpdateDelivery(ctx, delivery); err != nil {
+		log.Errorf("save webhook delivery %d fail: %s", delivery.ID, err.Error())
+	}
+}
+
+func (d *Deliverer) attempt(ctx context.Context, webhook *v1.Webhook, delivery *v1.WebhookDelivery) {
+	delivery.Attempts++
+
+	code, err := d.post(ctx, webhook, delivery)
+	delivery.ResponseCode = code
+	if err == nil {
+		now := time.Now()
+		delivery.Status, delivery.Error, delivery.DeliveredAt = v1.DeliverySucceeded, "", &now
+		return
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= d.opts.MaxAttempts {
+		delivery.Status = v1.DeliveryFailed
+		log.Warnf("webhook delivery %d to %s failed after %d attempts: %s", delivery.ID, webhook.Name, delivery.Attempts, err.Error())
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+}
+
+func (d *Deliverer) post(ctx context.Context, webhook *v1.Webhook, delivery *v1.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(auth.HeaderWebhookEvent, delivery.Event)
+	req.Header.Set(auth.HeaderWebhookDelivery, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(auth.HeaderWebhookSignature, auth.SignWebhook(webhook.Secret, body, time.Now()))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("webhook responded %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// backoff is the wait before the next attempt after n attempts.
+func (d *Deliverer) backoff(n int) time.Duration {
+	wait := d.opts.Backoff
+	for i := 1; i < n && wait < d.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.opts.MaxBackoff {
+		wait = d.opts.MaxBackoff
+	}
+	return wait
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// reservedNets are not public but not told by methods of net.IP.
+var reservedNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",       // this network
+		"100.64.0.0/10",   // carrier-grade NAT
+		"192.0.0.0/24",    // IETF protocol assignments
+		"198.18.0.0/15",   // benchmarking
+		"240.0.0.0/4",     // reserved
+		"64:ff9b::/96",    // NAT64, which may reach private IPv4
+		"64:ff9b:1::/48",  // local-use NAT64
+		"2001:db8::/32",   // documentation
+		"::ffff:0:0:0/96", // IPv4-translated
+	} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// PublicIP tells whether ip is reachable in public internet, loopback, private, link-local addresses
+// such as cloud metadata 169.254.169.254, and other reserved ones are not.
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL refuses url to localhost or ip literal not public, unless allowPrivate. Other hostnames are
+// checked when dialing, since they may resolve to any address at any time.
+func CheckURL(rawURL string, allowPrivate bool) error {
+	if allowPrivate {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("host %s is not public", u.Hostname())
+	}
+	if ip := net.ParseIP(host); ip != nil && !PublicIP(ip) {
+		return fmt.Errorf("address %s is not public", ip)
+	}
+	return nil
+}
+
+// newClient never follows redirects, nor uses proxy in environment, and refuses to connect to address not
+// public unless allowPrivate. Address is checked after it's resolved, so DNS rebinding doesn't work.
+func newClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+				return fmt.Errorf("address %s is not public", host)
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// Redirect would reach address not checked by CheckURL, 3xx is taken as failure.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"context"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":                true,
+		"2606:4700::1111":        true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"fe80::1":                false,
+		"fd00:ec2::254":          false,
+		"0.0.0.0":                false,
+		"100.64.0.1":             false,
+		"::ffff:127.0.0.1":       false,
+		"64:ff9b::a00:1":         false,
+		"224.0.0.1":              false,
+		"255.255.255.255":        false,
+		"::":                     false,
+		"::ffff:169.254.169.254": false,
+	}
+	for s, want := range tests {
+		if got := PublicIP(net.ParseIP(s)); got != want {
+			t.Errorf("PublicIP(%s) got %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://hooks.example.com/iam":  true,
+		"http://8.8.8.8/hook":            true,
+		"http://localhost:8080/hook":     false,
+		"http://api.LOCALHOST./hook":     false,
+		"http://127.0.0.1/hook":          false,
+		"http://[::1]:8080/hook":         false,
+		"http://169.254.169.254/latest/": false,
+		"http://[fd00:ec2::254]/latest/": false,
+	}
+	for u, want := range tests {
+		if err := CheckURL(u, false); (err == nil) != want {
+			t.Errorf("CheckURL(%s) got %v, want allowed %v", u, err, want)
+		}
+		if err := CheckURL(u, true); err != nil {
+			t.Errorf("CheckURL(%s) should be allowed with private network: %v", u, err)
+		}
+	}
+}
+
+func newTestDeliverer(allowPrivate bool) *Deliverer {
+	opts := options.NewWebhookOpts()
+	opts.Timeout = time.Second
+	opts.AllowPrivateNetwork = allowPrivate
+	return NewDeliverer(nil, opts)
+}
+
+func TestPost_RefusesPrivateAddress(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer srv.Close()
+
+	webhook := &v1.Webhook{URL: srv.URL, Secret: "secret"}
+	// Hostname resolving to loopback is refused when dialing.
+	named := &v1.Webhook{URL: strings.Replace(srv.URL, "127.0.0.1", "localhost", 1), Secret: "secret"}
+	delivery := &v1.WebhookDelivery{ID: 1, Event: v1.EventUserCreated, Payload: "{}"}
+
+	d := newTestDeliverer(false)
+	for _, w := range []*v1.Webhook{webhook, named} {
+		if _, err := d.post(context.Background(), w, delivery); err == nil || !strings.Contains(err.Error(), "not public") {
+			t.Errorf("post to %s should be refused, got %v", w.URL, err)
+		}
+	}
+	if hits != 0 {
+		t.Fatalf("private server must not be reached, got %d hits", hits)
+	}
+
+	if code, err := newTestDeliverer(true).post(context.Background(), webhook, delivery); err != nil || code != http.StatusOK {
+		t.Errorf("post should succeed when private network allowed, got %d, %v", code, err)
+	}
+}
+
+func TestPost_NoRedirect(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	code, err := newTestDeliverer(true).post(context.Background(), &v1.Webhook{URL: srv.URL},
+		&v1.WebhookDelivery{ID: 1, Event: v1.EventUserCreated, Payload: "{}"})
+	if err == nil || code != http.StatusTemporaryRedirect {
+		t.Errorf("redirect should fail delivery, got %d, %v", code, err)
+	}
+	if redirected {
+		t.Errorf("redirect must not be followed")
+	}
+}
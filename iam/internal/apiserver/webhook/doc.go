@@ -0,0 +1,2 @@
+// Package webhook delivers events queued by service to webhooks.
+package webhook
@@ -31,10 +31,17 @@ func NewAuthorizator(ctx context.Context) (*Authorizator, error) {
 		au = &Authorizator{}
 		au.ctx, au.cancel = context.WithCancel(ctx)
 
-		l := ladon.Ladon{}
-		l.Manager, err = newManager(au.ctx)
-		l.AuditLogger, err = newAuditor()
-		l.Metric, err = newMetric()
+		l := &ladon.Ladon{}
+		if l.Manager, err = newManager(au.ctx); err != nil {
+			return
+		}
+		if l.AuditLogger, err = newAuditor(); err != nil {
+			return
+		}
+		if l.Metric, err = newMetric(); err != nil {
+			return
+		}
+		au.l = l
 	})
 
 	return au, err
@@ -54,3 +61,22 @@ func (authz *Authorizator) Authorize(request *ladon.Request) *authzV1.Response {
 		Allowed: true,
 	}
 }
+
+// AuthorizeWithSession allows request only if both policies of principal and session policies allow it,
+// session policies come from temporary credential.
+func (authz *Authorizator) AuthorizeWithSession(request *ladon.Request, session ladon.Policies) *authzV1.Response {
+	if r := authz.Authorize(request); !r.Allowed {
+		return r
+	}
+
+	if err := authz.l.DoPoliciesAllow(request, session); err != nil {
+		return &authzV1.Response{
+			Allowed: false,
+			Reason:  "denied by session policy: " + err.Error(),
+		}
+	}
+
+	return &authzV1.Response{
+		Allowed: true,
+	}
+}
@@ -126,11 +126,12 @@ func createSvr(options *Options) *server.GeneralApiServer {
 		SecureServerOpts:   options.secureSvrOptions,
 		FeatureOptions:     options.featureOptions,
 		Engine:             engine,
+		OpenAPI:            newOpenAPI(),
 	}
 	svr.Install()
 
 	installMiddlewares(engine)
-	installRouter(engine)
+	installRouter(engine, options)
 
 	return svr
 }
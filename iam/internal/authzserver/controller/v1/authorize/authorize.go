@@ -3,9 +3,12 @@ package authorize
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/ory/ladon"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
 	"istomyang.github.com/like-iam/component-base/errors"
 	"istomyang.github.com/like-iam/component-base/web"
+	"istomyang.github.com/like-iam/component/pkg/middleware"
 	"istomyang.github.com/like-iam/iam/internal/authzserver/authorization"
+	"istomyang.github.com/like-iam/iam/internal/authzserver/service"
 )
 
 type Controller struct {
@@ -23,7 +26,27 @@ func (c *Controller) Authorize(ctx *gin.Context) {
 		return
 	}
 
+	if req.Context == nil {
+		req.Context = ladon.Context{}
+	}
 	req.Context["username"] = ctx.GetString("username")
 
+	// Temporary credential is down-scoped by its session policy.
+	if secretID := ctx.GetString(middleware.SecretIDKey); v1.IsTemporarySecretID(secretID) {
+		cred, err := service.GetService().FindTemporaryCredential(secretID)
+		if err != nil {
+			web.WriteResponse(ctx, errors.WithCode(errors.ErrDatabase, err.Error()), nil)
+			return
+		}
+		if cred == nil {
+			web.WriteResponse(ctx, errors.WithCode(errors.ErrExpired, "temporary credential %s expired.", secretID), nil)
+			return
+		}
+		if cred.Policy != nil {
+			web.WriteResponse(ctx, nil, authorization.GetAuthorizator().AuthorizeWithSession(&req, ladon.Policies{cred.Policy}))
+			return
+		}
+	}
+
 	web.WriteResponse(ctx, nil, authorization.GetAuthorizator().Authorize(&req))
 }
@@ -0,0 +1,26 @@
+package authzserver
+
+import (
+	"github.com/ory/ladon"
+	authzV1 "istomyang.github.com/like-iam/api/authzserver/v1"
+	"istomyang.github.com/like-iam/component/pkg/openapi"
+)
+
+// newOpenAPI describes routes installed by installRouter.
+func newOpenAPI() *openapi.Spec {
+	return &openapi.Spec{
+		Title:       "iam-authzserver",
+		Description: "Authorizes requests with policies of IAM.",
+		Version:     "v1",
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Token signed by secret key, kid is secret id."},
+			"signature": {Type: "apiKey", In: "header", Name: "Authorization",
+				Description: "IAM-HMAC-SHA256 signature of request, temporary credentials must sign with it."},
+		},
+		Codes: openapi.RegisteredCodes(),
+		Routes: map[string]openapi.Route{
+			"GET /healthz":   {Summary: "Health check.", Public: true},
+			"POST /v1/authz": {Summary: "Authorize request.", Request: ladon.Request{}, Response: authzV1.Response{}},
+		},
+	}
+}
@@ -14,6 +14,7 @@ type Options struct {
 	featureOptions     *generaloptions.FeatureOptions
 	redisOptions       *generaloptions.RedisOpts
 	gRPCOptions        *generaloptions.GRPCOpts
+	signatureOptions   *generaloptions.SignatureOpts
 	analyticsOptions   *analytics.Options
 	Log                *log.Options
 	clientCA           string
@@ -27,6 +28,7 @@ func NewOptions(basename string) *Options {
 		featureOptions:     generaloptions.NewFeatureOptions(),
 		redisOptions:       generaloptions.NewRedisOpts(),
 		gRPCOptions:        generaloptions.NewGRPCOpts(),
+		signatureOptions:   generaloptions.NewSignatureOpts(),
 		Log:                log.NewOptions(basename, nil),
 		analyticsOptions:   analytics.NewAnalyticsOptions(),
 	}
@@ -39,6 +41,7 @@ func (o *Options) Flags(appFss *app.NamedFlagSets) {
 	o.featureOptions.AddFlags(appFss.AddFlagSet("feature"))
 	o.redisOptions.AddFlags(appFss.AddFlagSet("redis"))
 	o.gRPCOptions.AddFlags(appFss.AddFlagSet("gRPC"))
+	o.signatureOptions.AddFlags(appFss.AddFlagSet("signature"))
 	o.Log.AddFlags(appFss.AddFlagSet("log"))
 
 	o.analyticsOptions.AddFlags(appFss.AddFlagSet("store"))
@@ -51,6 +54,7 @@ func (o *Options) Validate() []error {
 	errs = append(errs, o.secureSvrOptions.Validate()...)
 	errs = append(errs, o.redisOptions.Validate()...)
 	errs = append(errs, o.gRPCOptions.Validate()...)
+	errs = append(errs, o.signatureOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
 	errs = append(errs, o.featureOptions.Validate()...)
 	errs = append(errs, o.analyticsOptions.Validate()...)
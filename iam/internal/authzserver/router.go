@@ -2,17 +2,19 @@ package authzserver
 
 import (
 	"github.com/gin-gonic/gin"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
+	authbase "istomyang.github.com/like-iam/component-base/auth"
 	"istomyang.github.com/like-iam/component-base/errors"
 	"istomyang.github.com/like-iam/component-base/web"
 	"istomyang.github.com/like-iam/component/pkg/middleware/auth"
 	"istomyang.github.com/like-iam/iam/internal/authzserver/controller/v1/authorize"
 	"istomyang.github.com/like-iam/iam/internal/authzserver/service"
+	"strings"
 )
 
-func installRouter(engine *gin.Engine) {
+func installRouter(engine *gin.Engine, options *Options) {
 
-	// Parse token in the gin context, put username into context.
-	m := auth.NewCacheScheme(func(kid string) (*auth.Secret, error) {
+	getSecret := func(kid string) (*auth.Secret, error) {
 		secret, err := service.GetService().FindSecret(kid)
 		if err != nil {
 			return nil, err
@@ -23,13 +25,42 @@ func installRouter(engine *gin.Engine) {
 			Key:      secret.SecretKey,
 			Expires:  secret.Expires,
 		}, nil
-	}).AuthFunc()
+	}
+
+	// Temporary credential must sign request with its session token, so it's only found by hmac scheme.
+	getSignedSecret := func(kid string) (*auth.Secret, error) {
+		if v1.IsTemporarySecretID(kid) {
+			cred, err := service.GetService().FindTemporaryCredential(kid)
+			if err != nil || cred == nil {
+				return nil, err
+			}
+			return &auth.Secret{
+				Username:     cred.Username,
+				ID:           cred.SecretID,
+				Key:          cred.SecretKey,
+				Expires:      cred.Expires,
+				SessionToken: cred.SessionToken,
+			}, nil
+		}
+		return getSecret(kid)
+	}
+
+	// Parse token or signature in the gin context, put username into context.
+	cache := auth.NewCacheScheme(getSecret).AuthFunc()
+	hmac := auth.NewHMACScheme(getSignedSecret, options.signatureOptions.Skew).AuthFunc()
+	m := func(c *gin.Context) {
+		if strings.HasPrefix(c.GetHeader("Authorization"), authbase.SignAlgorithm+" ") {
+			hmac(c)
+			return
+		}
+		cache(c)
+	}
 
 	engine.NoRoute(m, func(ctx *gin.Context) {
 		web.WriteResponse(ctx, errors.WithCode(errors.ErrPageNotFound, "route not found"), nil)
 	})
 
 	authzCtrl := authorize.NewAuthorizeController()
-	v1 := engine.Group("/v1", m)
-	v1.POST("/authz", authzCtrl.Authorize)
+	g := engine.Group("/v1", m)
+	g.POST("/authz", authzCtrl.Authorize)
 }
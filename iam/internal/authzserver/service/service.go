@@ -2,11 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/go-redis/redis/v8"
 	"github.com/ory/ladon"
+	v1 "istomyang.github.com/like-iam/api/apiserver/v1"
 	pb "istomyang.github.com/like-iam/api/proto/v1"
+	"istomyang.github.com/like-iam/component/pkg/conn"
 	"istomyang.github.com/like-iam/iam/internal/authzserver/service/cache"
 	"istomyang.github.com/like-iam/iam/internal/authzserver/service/subscribe"
+	"istomyang.github.com/like-iam/iam/internal/pkg"
 	"sync"
 )
 
@@ -15,6 +20,9 @@ type Service interface {
 
 	FindSecret(kid string) (*pb.SecretInfo, error)
 
+	// FindTemporaryCredential returns credential issued by apiserver, nil if not found or expired.
+	FindTemporaryCredential(secretID string) (*v1.TemporaryCredential, error)
+
 	Run() error
 	Close() error
 }
@@ -78,6 +86,23 @@ func (s *service) FindSecret(kid string) (*pb.SecretInfo, error) {
 	return s.cache.GetSecret(kid)
 }
 
+func (s *service) FindTemporaryCredential(secretID string) (*v1.TemporaryCredential, error) {
+	client := conn.GetRedisClient().UniversalClient()
+	data, err := client.Get(s.ctx, pkg.TemporaryCredentialKeyPrefix+secretID).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cred v1.TemporaryCredential
+	if err = json.Unmarshal(data, &cred); err != nil {
+		return nil, err
+	}
+	return &cred, nil
+}
+
 func (s *service) Run() error {
 
 	go func() {
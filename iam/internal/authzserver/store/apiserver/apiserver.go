@@ -14,6 +14,10 @@ type datastore struct {
 	pb   pb.CacheClient
 	conn *grpc.ClientConn
 
+	// secrets and policies keep what they synced, so they are created once.
+	secrets  store.SecretStore
+	policies store.PolicyStore
+
 	address string
 	cert    string
 
@@ -38,11 +42,11 @@ func GetApiServerStoreOr(ctx context.Context, address, cert string) (store.Facto
 }
 
 func (s *datastore) Secrets() store.SecretStore {
-	return newSecret(s.ctx, s.pb)
+	return s.secrets
 }
 
 func (s *datastore) Policies() store.PolicyStore {
-	return newPolicy(s.ctx, s.pb)
+	return s.policies
 }
 
 func (s *datastore) Run() error {
@@ -56,6 +60,8 @@ func (s *datastore) Run() error {
 
 	s.conn = conn
 	s.pb = pb.NewCacheClient(conn)
+	s.secrets = newSecret(s.ctx, s.pb)
+	s.policies = newPolicy(s.ctx, s.pb)
 
 	return nil
 }
@@ -0,0 +1,205 @@
+package apiserver
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "istomyang.github.com/like-iam/api/proto/v1"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fakeCache replies lists in order, and records since_revision of requests.
+type fakeCache struct {
+	pb.CacheClient
+
+	secrets  []*pb.ListSecretsReply
+	policies []*pb.ListPoliciesReply
+	errs     []error
+	since    []string
+}
+
+func (f *fakeCache) next() (int, error) {
+	i := len(f.since) - 1
+	if i < len(f.errs) && f.errs[i] != nil {
+		return i, f.errs[i]
+	}
+	return i, nil
+}
+
+func (f *fakeCache) ListSecrets(_ context.Context, r *pb.ListRequest, _ ...grpc.CallOption) (*pb.ListSecretsReply, error) {
+	f.since = append(f.since, r.SinceRevision)
+	i, err := f.next()
+	if err != nil {
+		return nil, err
+	}
+	return f.secrets[i], nil
+}
+
+func (f *fakeCache) ListPolicies(_ context.Context, r *pb.ListRequest, _ ...grpc.CallOption) (*pb.ListPoliciesReply, error) {
+	f.since = append(f.since, r.SinceRevision)
+	i, err := f.next()
+	if err != nil {
+		return nil, err
+	}
+	return f.policies[i], nil
+}
+
+func secretInfo(username, id string) *pb.SecretInfo {
+	return &pb.SecretInfo{Username: username, SecretId: id}
+}
+
+func secretIDs(m map[string]*pb.SecretInfo) []string {
+	var ids []string
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSecretList_Incremental(t *testing.T) {
+	f := &fakeCache{secrets: []*pb.ListSecretsReply{
+		{Revision: "1-0", Items: []*pb.SecretInfo{secretInfo("alice", "a1"), secretInfo("alice", "a2"), secretInfo("bob", "b1")}},
+		{
+			Revision:       "2-0",
+			ReplacedOwners: []string{"alice"},
+			Items:          []*pb.SecretInfo{secretInfo("alice", "a3"), secretInfo("carol", "c1")},
+			Deleted:        []*pb.SecretInfo{secretInfo("bob", "b1")},
+		},
+	}}
+	s := newSecret(context.Background(), f)
+
+	got, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := secretIDs(got); !equal(ids, []string{"a1", "a2", "b1"}) {
+		t.Errorf("full list got %v", ids)
+	}
+
+	got, err = s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := secretIDs(got); !equal(ids, []string{"a3", "c1"}) {
+		t.Errorf("incremental list got %v, want [a3 c1]", ids)
+	}
+	if !equal(f.since, []string{"", "1-0"}) {
+		t.Errorf("since_revision got %v, want [ 1-0]", f.since)
+	}
+}
+
+func TestSecretList_ExpiredRevisionResyncs(t *testing.T) {
+	for _, code := range []codes.Code{codes.OutOfRange, codes.InvalidArgument} {
+		f := &fakeCache{
+			secrets: []*pb.ListSecretsReply{
+				{Revision: "1-0", Items: []*pb.SecretInfo{secretInfo("alice", "a1"), secretInfo("bob", "b1")}},
+				nil,
+				{Revision: "3-0", Items: []*pb.SecretInfo{secretInfo("bob", "b2")}},
+			},
+			errs: []error{nil, status.Error(code, "since_revision is too old, list all again.")},
+		}
+		s := newSecret(context.Background(), f)
+
+		if _, err := s.List(); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.List()
+		if err != nil {
+			t.Fatalf("%s: %v", code, err)
+		}
+		if ids := secretIDs(got); !equal(ids, []string{"b2"}) {
+			t.Errorf("%s: got %v, want [b2]", code, ids)
+		}
+		if !equal(f.since, []string{"", "1-0", ""}) {
+			t.Errorf("%s: since_revision got %v, want [ 1-0 ]", code, f.since)
+		}
+	}
+}
+
+func TestSecretList_ChangesFail(t *testing.T) {
+	f := &fakeCache{
+		secrets: []*pb.ListSecretsReply{{Revision: "1-0", Items: []*pb.SecretInfo{secretInfo("alice", "a1")}}},
+		errs:    []error{nil, status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down")},
+	}
+	s := newSecret(context.Background(), f)
+
+	if _, err := s.List(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.List(); err == nil {
+		t.Errorf("want err when apiserver is unavailable")
+	}
+	if !equal(f.since, []string{"", "1-0", "1-0", "1-0"}) {
+		t.Errorf("since_revision got %v, want retries of 1-0 without full list", f.since)
+	}
+}
+
+func TestPolicyList_Incremental(t *testing.T) {
+	shadow := func(id string) string { return `{"id":"` + id + `","effect":"allow"}` }
+	info := func(username, name string) *pb.PolicyInfo {
+		return &pb.PolicyInfo{Username: username, Name: name, PolicyShadow: shadow(username + "/" + name)}
+	}
+
+	f := &fakeCache{
+		policies: []*pb.ListPoliciesReply{
+			{Revision: "1-0", Items: []*pb.PolicyInfo{info("alice", "p1"), info("alice", "p2"), info("bob", "p1")}},
+			{
+				Revision:       "2-0",
+				ReplacedOwners: []string{"alice"},
+				Items:          []*pb.PolicyInfo{info("carol", "p1")},
+				Deleted:        []*pb.PolicyInfo{{Username: "bob", Name: "p1"}},
+			},
+			nil,
+			{Revision: "4-0", Items: []*pb.PolicyInfo{info("alice", "p3")}},
+		},
+		errs: []error{nil, nil, status.Error(codes.OutOfRange, "too old")},
+	}
+	p := newPolicy(context.Background(), f)
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"full list", []string{"alice/p1", "alice/p2", "bob/p1"}},
+		{"incremental list", []string{"carol/p1"}},
+		{"resync", []string{"alice/p3"}},
+	}
+	for _, tt := range tests {
+		got, err := p.List()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var names []string
+		for username, policies := range got {
+			for _, l := range policies {
+				if !strings.HasPrefix(l.ID, username+"/") {
+					t.Errorf("%s: policy %s is grouped in %s", tt.name, l.ID, username)
+				}
+				names = append(names, l.ID)
+			}
+		}
+		sort.Strings(names)
+		if !equal(names, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, names, tt.want)
+		}
+	}
+	if !equal(f.since, []string{"", "1-0", "2-0", ""}) {
+		t.Errorf("since_revision got %v", f.since)
+	}
+}
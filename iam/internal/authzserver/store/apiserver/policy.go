@@ -9,11 +9,18 @@ import (
 	"istomyang.github.com/like-iam/component-base/errors"
 	"istomyang.github.com/like-iam/iam/internal/authzserver/store"
 	"istomyang.github.com/like-iam/log"
+	"sync"
 )
 
 type policy struct {
 	pb  pb.CacheClient
 	ctx context.Context
+
+	// items and revision are the last synced, List only asks apiserver for changes since revision.
+	// items are keyed by username and name.
+	mu       sync.Mutex
+	items    map[[2]string]*pb.PolicyInfo
+	revision string
 }
 
 func newPolicy(ctx context.Context, pb pb.CacheClient) store.PolicyStore {
@@ -21,6 +28,20 @@ func newPolicy(ctx context.Context, pb pb.CacheClient) store.PolicyStore {
 }
 
 func (p *policy) List() (map[string][]*ladon.DefaultPolicy, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.revision != "" {
+		err := p.listChanges()
+		if err == nil {
+			return p.toPolicies()
+		}
+		if !resyncRequired(err) {
+			return nil, errors.Wrap(err, "list policy changes from apiserver failed after 3 times.")
+		}
+		log.Warnf("list all policies again: %s", err.Error())
+	}
+
 	log.Info("loading list policies.")
 
 	req := pb.ListRequest{
@@ -39,13 +60,58 @@ func (p *policy) List() (map[string][]*ladon.DefaultPolicy, error) {
 		return nil, errors.Wrap(err, "list policies coming from apiserver failed after 3 times.")
 	}
 
-	log.Infof("policies loaded count: &d", policies.Count)
-
-	r := make(map[string][]*ladon.DefaultPolicy)
+	log.Infof("policies loaded count: %d", policies.Count)
 
+	p.items = make(map[[2]string]*pb.PolicyInfo, policies.Count)
 	for _, item := range policies.Items {
 		log.Infof("get policies: %s:%s", item.Username, item.Name)
+		p.items[[2]string{item.Username, item.Name}] = item
+	}
+	p.revision = policies.Revision
+
+	return p.toPolicies()
+}
+
+// listChanges applies changes since the last revision to items.
+func (p *policy) listChanges() error {
+	req := pb.ListRequest{SinceRevision: p.revision}
+
+	var changes *pb.ListPoliciesReply
+	err := retry.Do(func() (err error) {
+		changes, err = p.pb.ListPolicies(p.ctx, &req)
+		return err
+	}, retry.Attempts(3), retry.RetryIf(func(err error) bool { return !resyncRequired(err) }), retry.LastErrorOnly(true))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("policy changes loaded count: %d", changes.Count)
+
+	replaced := make(map[string]bool, len(changes.ReplacedOwners))
+	for _, owner := range changes.ReplacedOwners {
+		replaced[owner] = true
+	}
+	for k := range p.items {
+		if replaced[k[0]] {
+			delete(p.items, k)
+		}
+	}
+	for _, item := range changes.Deleted {
+		delete(p.items, [2]string{item.Username, item.Name})
+	}
+	for _, item := range changes.Items {
+		p.items[[2]string{item.Username, item.Name}] = item
+	}
+	p.revision = changes.Revision
+
+	return nil
+}
+
+// toPolicies groups policies by username.
+func (p *policy) toPolicies() (map[string][]*ladon.DefaultPolicy, error) {
+	r := make(map[string][]*ladon.DefaultPolicy)
 
+	for _, item := range p.items {
 		l := ladon.DefaultPolicy{}
 
 		if err := l.UnmarshalJSON([]byte(item.PolicyShadow)); err != nil {
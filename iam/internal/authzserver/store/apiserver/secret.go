@@ -4,15 +4,23 @@ import (
 	"context"
 	"github.com/AlekSi/pointer"
 	"github.com/avast/retry-go/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	pb "istomyang.github.com/like-iam/api/proto/v1"
 	"istomyang.github.com/like-iam/component-base/errors"
 	"istomyang.github.com/like-iam/iam/internal/authzserver/store"
 	"istomyang.github.com/like-iam/log"
+	"sync"
 )
 
 type secret struct {
 	pb  pb.CacheClient
 	ctx context.Context
+
+	// items and revision are the last synced, List only asks apiserver for changes since revision.
+	mu       sync.Mutex
+	items    map[string]*pb.SecretInfo
+	revision string
 }
 
 func newSecret(ctx context.Context, pb pb.CacheClient) store.SecretStore {
@@ -20,6 +28,19 @@ func newSecret(ctx context.Context, pb pb.CacheClient) store.SecretStore {
 }
 
 func (s *secret) List() (map[string]*pb.SecretInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.revision != "" {
+		err := s.listChanges()
+		if err == nil {
+			return s.copyItems(), nil
+		}
+		if !resyncRequired(err) {
+			return nil, errors.Wrap(err, "list secret changes from apiserver failed after 3 times.")
+		}
+		log.Warnf("list all secrets again: %s", err.Error())
+	}
 
 	log.Info("loading list secrets.")
 
@@ -39,14 +60,67 @@ func (s *secret) List() (map[string]*pb.SecretInfo, error) {
 		return nil, errors.Wrap(err, "list secrets from apiserver failed after 3 times.")
 	}
 
-	log.Infof("secrets loaded count: &d", secrets.Count)
-
-	r := make(map[string]*pb.SecretInfo, secrets.Count)
+	log.Infof("secrets loaded count: %d", secrets.Count)
 
+	s.items = make(map[string]*pb.SecretInfo, secrets.Count)
 	for _, item := range secrets.Items {
 		log.Infof("get secrets: %s:%s", item.Username, item.SecretId)
-		r[item.SecretId] = item
+		s.items[item.SecretId] = item
+	}
+	s.revision = secrets.Revision
+
+	return s.copyItems(), nil
+}
+
+// listChanges applies changes since the last revision to items.
+func (s *secret) listChanges() error {
+	req := pb.ListRequest{SinceRevision: s.revision}
+
+	var changes *pb.ListSecretsReply
+	err := retry.Do(func() (err error) {
+		changes, err = s.pb.ListSecrets(s.ctx, &req)
+		return err
+	}, retry.Attempts(3), retry.RetryIf(func(err error) bool { return !resyncRequired(err) }), retry.LastErrorOnly(true))
+	if err != nil {
+		return err
 	}
 
-	return r, nil
+	log.Infof("secret changes loaded count: %d", changes.Count)
+
+	replaced := make(map[string]bool, len(changes.ReplacedOwners))
+	for _, owner := range changes.ReplacedOwners {
+		replaced[owner] = true
+	}
+	for id, item := range s.items {
+		if replaced[item.Username] {
+			delete(s.items, id)
+		}
+	}
+	for _, item := range changes.Deleted {
+		delete(s.items, item.SecretId)
+	}
+	for _, item := range changes.Items {
+		s.items[item.SecretId] = item
+	}
+	s.revision = changes.Revision
+
+	return nil
+}
+
+// copyItems returns a copy, so that callers can't change items.
+func (s *secret) copyItems() map[string]*pb.SecretInfo {
+	r := make(map[string]*pb.SecretInfo, len(s.items))
+	for k, v := range s.items {
+		r[k] = v
+	}
+	return r
+}
+
+// resyncRequired tells whether apiserver can't serve changes since revision, all must be listed again.
+func resyncRequired(err error) bool {
+	switch status.Code(err) {
+	case codes.OutOfRange, codes.InvalidArgument:
+		return true
+	}
+	return false
 }
@@ -7,7 +7,16 @@ import (
 
 type RecordInfoBytes []byte
 
+// Types of RecordInfo, empty is authorization decision for compatibility.
+const (
+	RecordTypeAuthz           = ""
+	RecordTypeSuspiciousLogin = "suspicious-login"
+)
+
 type RecordInfo struct {
+	// Type tells what the record is about.
+	Type string `json:"type,omitempty" msgpack:"type"`
+
 	Timestamp int64     `json:"timestamp" msgpack:"timestamp"`
 	ExpireAt  time.Time `json:"expireAt" msgpack:"expireAt"`
 
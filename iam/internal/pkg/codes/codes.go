@@ -2,11 +2,32 @@ package codes
 
 // iam-apiserver: user codes.
 const (
-	// ErrUserNotFound - 400: Secret reach the max count.
+	// ErrUserNotFound - 404: User not found.
 	ErrUserNotFound int = iota + 110001
 
-	// ErrUserAlreadyExist - 404: Secret not found.
+	// ErrUserAlreadyExist - 409: User already exist.
 	ErrUserAlreadyExist
+
+	// ErrPasswordReused - 400: Password was used recently.
+	ErrPasswordReused
+
+	// ErrMFAAlreadyEnabled - 400: MFA has been enabled.
+	ErrMFAAlreadyEnabled
+
+	// ErrMFANotEnabled - 400: MFA is not enabled.
+	ErrMFANotEnabled
+
+	// ErrMFACodeInvalid - 401: MFA code is invalid.
+	ErrMFACodeInvalid
+
+	// ErrFederatedUserConflict - 409: User exists but is not linked to the federated identity.
+	ErrFederatedUserConflict
+
+	// ErrSessionNotFound - 404: Session not found.
+	ErrSessionNotFound
+
+	// ErrUserDisabled - 403: User is disabled.
+	ErrUserDisabled
 )
 
 // iam-apiserver: secret codes.
@@ -17,7 +38,7 @@ const (
 	// ErrSecretNotFound - 404: Secret not found.
 	ErrSecretNotFound
 
-	// ErrSecretAlreadyExit - 304: Secret not found.
+	// ErrSecretAlreadyExit - 409: Secret already exist.
 	ErrSecretAlreadyExit
 )
 
@@ -26,6 +47,48 @@ const (
 	// ErrPolicyNotFound - 404: Policy not found.
 	ErrPolicyNotFound int = iota + 110201
 
-	// ErrPolicyAlreadyExit - 304: Secret not found.
+	// ErrPolicyAlreadyExit - 409: Policy already exist.
 	ErrPolicyAlreadyExit
 )
+
+// iam-apiserver: oauth codes.
+const (
+	// ErrOAuthClientNotFound - 404: OAuth client not found.
+	ErrOAuthClientNotFound int = iota + 110301
+)
+
+// iam-apiserver: service account codes.
+const (
+	// ErrServiceAccountNotFound - 404: Service account not found.
+	ErrServiceAccountNotFound int = iota + 110401
+
+	// ErrServiceAccountDisabled - 403: Service account is disabled.
+	ErrServiceAccountDisabled
+)
+
+// iam-apiserver: group codes.
+const (
+	// ErrGroupNotFound - 404: Group not found.
+	ErrGroupNotFound int = iota + 110501
+)
+
+// iam-apiserver: registration codes.
+const (
+	// ErrInvitationNotFound - 404: Invitation not found.
+	ErrInvitationNotFound int = iota + 110601
+
+	// ErrInvitationInvalid - 400: Invitation code is invalid, used or expired.
+	ErrInvitationInvalid
+
+	// ErrRegistrationDisabled - 403: Self-registration is disabled.
+	ErrRegistrationDisabled
+)
+
+// iam-apiserver: webhook codes.
+const (
+	// ErrWebhookNotFound - 404: Webhook not found.
+	ErrWebhookNotFound int = iota + 110701
+
+	// ErrWebhookDeliveryNotFound - 404: Webhook delivery not found.
+	ErrWebhookDeliveryNotFound
+)
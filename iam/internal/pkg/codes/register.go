@@ -0,0 +1,37 @@
+package codes
+
+import "istomyang.github.com/like-iam/component-base/errors"
+
+func init() {
+	for _, c := range []struct {
+		code     int
+		httpCode int
+		message  string
+	}{
+		{ErrUserNotFound, 404, "User not found."},
+		{ErrUserAlreadyExist, 409, "User already exist."},
+		{ErrPasswordReused, 400, "Password was used recently."},
+		{ErrMFAAlreadyEnabled, 400, "MFA has been enabled."},
+		{ErrMFANotEnabled, 400, "MFA is not enabled."},
+		{ErrMFACodeInvalid, 401, "MFA code is invalid."},
+		{ErrFederatedUserConflict, 409, "User exists but is not linked to the federated identity."},
+		{ErrSessionNotFound, 404, "Session not found."},
+		{ErrUserDisabled, 403, "User is disabled."},
+		{ErrReachMaxCount, 400, "Secret reach the max count."},
+		{ErrSecretNotFound, 404, "Secret not found."},
+		{ErrSecretAlreadyExit, 409, "Secret already exist."},
+		{ErrPolicyNotFound, 404, "Policy not found."},
+		{ErrPolicyAlreadyExit, 409, "Policy already exist."},
+		{ErrOAuthClientNotFound, 404, "OAuth client not found."},
+		{ErrServiceAccountNotFound, 404, "Service account not found."},
+		{ErrServiceAccountDisabled, 403, "Service account is disabled."},
+		{ErrGroupNotFound, 404, "Group not found."},
+		{ErrInvitationNotFound, 404, "Invitation not found."},
+		{ErrInvitationInvalid, 400, "Invitation code is invalid, used or expired."},
+		{ErrRegistrationDisabled, 403, "Self-registration is disabled."},
+		{ErrWebhookNotFound, 404, "Webhook not found."},
+		{ErrWebhookDeliveryNotFound, 404, "Webhook delivery not found."},
+	} {
+		errors.MustRegister(errors.NewCoder(c.code, c.httpCode, c.message, ""))
+	}
+}
@@ -0,0 +1,28 @@
+package codes
+
+import (
+	"istomyang.github.com/like-iam/component-base/errors"
+	"net/http"
+	"testing"
+)
+
+func TestRegistered(t *testing.T) {
+	tests := []struct {
+		code   int
+		status int
+	}{
+		{ErrUserNotFound, http.StatusNotFound},
+		{ErrUserAlreadyExist, http.StatusConflict},
+		{ErrMFACodeInvalid, http.StatusUnauthorized},
+		{ErrUserDisabled, http.StatusForbidden},
+		{ErrReachMaxCount, http.StatusBadRequest},
+		{ErrWebhookDeliveryNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		coder := errors.AsCode(errors.WithCode(tt.code, "test"))
+		if coder.Code() != tt.code || coder.HTTPCode() != tt.status {
+			t.Errorf("code %d got %d/%d, want %d/%d", tt.code, coder.Code(), coder.HTTPCode(), tt.code, tt.status)
+		}
+	}
+}
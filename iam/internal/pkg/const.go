@@ -14,3 +14,8 @@ const (
 const (
 	WatcherContextKey = "watcher"
 )
+
+const (
+	// TemporaryCredentialKeyPrefix is prefix of redis key saving temporary credential by its secret id.
+	TemporaryCredentialKeyPrefix = "iam.sts."
+)
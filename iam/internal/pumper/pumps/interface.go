@@ -38,11 +38,20 @@ type PumpConfig struct {
 type Filter struct {
 	Usernames     []string `json:"usernames,omitempty"`
 	SkipUsernames []string `json:"skipUsernames,omitempty"`
+	// Types of record to write, empty means authorization records only, such that pumps not knowing
+	// other types, like suspicious-login, don't mix them up with authorization decisions.
+	Types []string `json:"types,omitempty"`
 }
 
 // ShouldFilter tells this info whether omitted or not.
 // If duplication in SkipUsernames and Usernames, SkipUsernames first.
 func (f *Filter) ShouldFilter(info *analytics.RecordInfo) bool {
+	if len(f.Types) == 0 && info.Type != analytics.RecordTypeAuthz {
+		return true
+	}
+	if len(f.Types) > 0 && !f.in(info.Type, f.Types) {
+		return true
+	}
 	if len(f.SkipUsernames) > 0 && f.in(info.UserName, f.SkipUsernames) {
 		return true
 	}
@@ -38,6 +38,8 @@ const (
 	XRequestIDKey = "X-Request-ID"
 	// UserNameKey is a name of user.
 	UserNameKey = "UserName"
+	// ActorKey is a name of user who acts as UserNameKey, such as admin impersonating other user.
+	ActorKey = "Actor"
 )
 
 // L will extract values from context, adding to common logger fields and returning a clone logger.
@@ -50,9 +52,12 @@ func (t *Logger) L(ctx context.Context) *Logger {
 	if username := ctx.Value(UserNameKey); username != nil {
 		inner = inner.With(zap.Any(UserNameKey, username))
 	}
+	if actor := ctx.Value(ActorKey); actor != nil {
+		inner = inner.With(zap.Any(ActorKey, actor))
+	}
 
 	var level Level
-	if ctx.Value(DebugEnabledKey).(bool) {
+	if debug, _ := ctx.Value(DebugEnabledKey).(bool); debug {
 		level = DebugLevel
 	}
 
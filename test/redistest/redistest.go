@@ -0,0 +1,800 @@
+// Package redistest provides an in-memory redis server for tests, it speaks RESP2 and supports commands
+// used by iam: strings, hashes, sorted sets, streams, expiry and MULTI/EXEC. Scripts and pub/sub are not
+// supported.
+//
+// It lives in the test module, which no binary requires, and refuses to start outside go test.
+package redistest
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"io"
+	"istomyang.github.com/like-iam/component/pkg/conn"
+	"istomyang.github.com/like-iam/component/pkg/options"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type status string
+
+type errorReply string
+
+type item struct {
+	str      string
+	hash     map[string]string
+	zset     map[string]float64
+	stream   []entry
+	expireAt time.Time
+}
+
+// entry is an entry of stream, fields are in pairs of name and value.
+type entry struct {
+	ms, seq int64
+	fields  []string
+}
+
+func (e entry) id() string {
+	return strconv.FormatInt(e.ms, 10) + "-" + strconv.FormatInt(e.seq, 10)
+}
+
+// Server is an in-memory redis server listening on loopback.
+type Server struct {
+	ln net.Listener
+
+	mu   sync.Mutex
+	data map[string]*item
+}
+
+// NewServer starts a server on a random port of loopback, it panics if process is not a test binary.
+func NewServer() (*Server, error) {
+	// Flags of testing are registered before tests and TestMain run.
+	if flag.Lookup("test.v") == nil {
+		panic("redistest: fake redis is only for tests.")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{ln: ln, data: map[string]*item{}}
+	go s.serve()
+	return s, nil
+}
+
+var (
+	shared     *Server
+	sharedOnce sync.Once
+)
+
+// Use starts a server shared by process, and makes conn.GetRedisClient connect to it. It must be called
+// before anything creates redis client, because the client is a singleton.
+func Use() *Server {
+	sharedOnce.Do(func() {
+		var err error
+		if shared, err = NewServer(); err != nil {
+			panic(err)
+		}
+		opts := options.NewRedisOpts()
+		opts.Addrs = []string{shared.Addr()}
+		opts.Timeout = time.Second
+		conn.NewRedisClientOr(opts)
+	})
+	shared.Flush()
+	return shared
+}
+
+// Addr returns address of server.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Client returns a new client of server.
+func (s *Server) Client() *redis.Client {
+	return redis.NewClient(&redis.Options{Addr: s.Addr()})
+}
+
+// Close stops accepting connections.
+func (s *Server) Close() error {
+	return s.ln.Close()
+}
+
+// Flush removes all keys.
+func (s *Server) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = map[string]*item{}
+}
+
+// TTL returns remaining lifetime of key, -1 means no expiry and -2 means key doesn't exist.
+func (s *Server) TTL(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	it := s.get(key)
+	if it == nil {
+		return -2
+	}
+	if it.expireAt.IsZero() {
+		return -1
+	}
+	return time.Until(it.expireAt)
+}
+
+func (s *Server) serve() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer c.Close()
+
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	var queue [][]string
+	multi := false
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		var reply interface{}
+		switch name := strings.ToUpper(args[0]); {
+		case name == "MULTI":
+			multi, queue, reply = true, nil, status("OK")
+		case name == "EXEC" && multi:
+			s.mu.Lock()
+			replies := make([]interface{}, 0, len(queue))
+			for _, cmd := range queue {
+				replies = append(replies, s.exec(cmd))
+			}
+			s.mu.Unlock()
+			multi, queue, reply = false, nil, replies
+		case name == "DISCARD" && multi:
+			multi, queue, reply = false, nil, status("OK")
+		case multi:
+			queue, reply = append(queue, args), status("QUEUED")
+		case name == "XREAD":
+			// It waits without lock so that others can add entries.
+			reply = s.xread(args[1:])
+		default:
+			s.mu.Lock()
+			reply = s.exec(args)
+			s.mu.Unlock()
+		}
+
+		writeReply(w, reply)
+		if err = w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// get returns live item of key, expired one is removed.
+func (s *Server) get(key string) *item {
+	it, ok := s.data[key]
+	if !ok {
+		return nil
+	}
+	if !it.expireAt.IsZero() && !time.Now().Before(it.expireAt) {
+		delete(s.data, key)
+		return nil
+	}
+	return it
+}
+
+func (s *Server) exec(args []string) interface{} {
+	name, args := strings.ToUpper(args[0]), args[1:]
+	handler, ok := commands[name]
+	if !ok {
+		return errorReply("ERR unknown command '" + name + "'")
+	}
+	return handler(s, args)
+}
+
+var commands = map[string]func(s *Server, args []string) interface{}{
+	"PING": func(s *Server, args []string) interface{} {
+		return status("PONG")
+	},
+	"GET": func(s *Server, args []string) interface{} {
+		if len(args) != 1 {
+			return wrongArgs("get")
+		}
+		if it := s.get(args[0]); it != nil {
+			return it.str
+		}
+		return nil
+	},
+	"SET":   set,
+	"SETNX": func(s *Server, args []string) interface{} { return boolInt(set(s, append(args, "NX")) != nil) },
+	"DEL": func(s *Server, args []string) interface{} {
+		var n int64
+		for _, key := range args {
+			if s.get(key) != nil {
+				delete(s.data, key)
+				n++
+			}
+		}
+		return n
+	},
+	"EXISTS": func(s *Server, args []string) interface{} {
+		var n int64
+		for _, key := range args {
+			if s.get(key) != nil {
+				n++
+			}
+		}
+		return n
+	},
+	"INCR": func(s *Server, args []string) interface{} {
+		if len(args) != 1 {
+			return wrongArgs("incr")
+		}
+		return incrBy(s, args[0], 1)
+	},
+	"INCRBY": func(s *Server, args []string) interface{} {
+		if len(args) != 2 {
+			return wrongArgs("incrby")
+		}
+		n, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errorReply("ERR value is not an integer or out of range")
+		}
+		return incrBy(s, args[0], n)
+	},
+	"EXPIRE":  func(s *Server, args []string) interface{} { return expire(s, args, time.Second) },
+	"PEXPIRE": func(s *Server, args []string) interface{} { return expire(s, args, time.Millisecond) },
+	"TTL":     func(s *Server, args []string) interface{} { return ttl(s, args, time.Second) },
+	"PTTL":    func(s *Server, args []string) interface{} { return ttl(s, args, time.Millisecond) },
+	"HSET": func(s *Server, args []string) interface{} {
+		if len(args) < 3 || len(args)%2 != 1 {
+			return wrongArgs("hset")
+		}
+		it := s.get(args[0])
+		if it == nil {
+			it = &item{}
+			s.data[args[0]] = it
+		}
+		if it.hash == nil {
+			it.hash = map[string]string{}
+		}
+		var n int64
+		for i := 1; i < len(args); i += 2 {
+			if _, ok := it.hash[args[i]]; !ok {
+				n++
+			}
+			it.hash[args[i]] = args[i+1]
+		}
+		return n
+	},
+	"HGET": func(s *Server, args []string) interface{} {
+		if len(args) != 2 {
+			return wrongArgs("hget")
+		}
+		if it := s.get(args[0]); it != nil {
+			if v, ok := it.hash[args[1]]; ok {
+				return v
+			}
+		}
+		return nil
+	},
+	"HGETALL": func(s *Server, args []string) interface{} {
+		if len(args) != 1 {
+			return wrongArgs("hgetall")
+		}
+		reply := []interface{}{}
+		if it := s.get(args[0]); it != nil {
+			for k, v := range it.hash {
+				reply = append(reply, k, v)
+			}
+		}
+		return reply
+	},
+	"ZADD": func(s *Server, args []string) interface{} {
+		if len(args) < 3 || len(args)%2 != 1 {
+			return wrongArgs("zadd")
+		}
+		it := s.get(args[0])
+		if it == nil {
+			it = &item{}
+			s.data[args[0]] = it
+		}
+		if it.zset == nil {
+			it.zset = map[string]float64{}
+		}
+		var n int64
+		for i := 1; i < len(args); i += 2 {
+			score, err := strconv.ParseFloat(args[i], 64)
+			if err != nil {
+				return errorReply("ERR value is not a valid float")
+			}
+			if _, ok := it.zset[args[i+1]]; !ok {
+				n++
+			}
+			it.zset[args[i+1]] = score
+		}
+		return n
+	},
+	"ZREM": func(s *Server, args []string) interface{} {
+		if len(args) < 2 {
+			return wrongArgs("zrem")
+		}
+		var n int64
+		if it := s.get(args[0]); it != nil {
+			for _, member := range args[1:] {
+				if _, ok := it.zset[member]; ok {
+					delete(it.zset, member)
+					n++
+				}
+			}
+		}
+		return n
+	},
+	"ZRANGE": func(s *Server, args []string) interface{} {
+		if len(args) != 3 {
+			return wrongArgs("zrange")
+		}
+		it := s.get(args[0])
+		if it == nil {
+			return []interface{}{}
+		}
+		members := make([]string, 0, len(it.zset))
+		for m := range it.zset {
+			members = append(members, m)
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if it.zset[members[i]] != it.zset[members[j]] {
+				return it.zset[members[i]] < it.zset[members[j]]
+			}
+			return members[i] < members[j]
+		})
+		start, _ := strconv.Atoi(args[1])
+		stop, _ := strconv.Atoi(args[2])
+		start, stop = index(start, len(members)), index(stop, len(members))
+		reply := []interface{}{}
+		for i := start; i <= stop && i < len(members); i++ {
+			reply = append(reply, members[i])
+		}
+		return reply
+	},
+	"XADD":      xadd,
+	"XRANGE":    xrange,
+	"XREVRANGE": xrevrange,
+	"XLEN": func(s *Server, args []string) interface{} {
+		if len(args) != 1 {
+			return wrongArgs("xlen")
+		}
+		if it := s.get(args[0]); it != nil {
+			return int64(len(it.stream))
+		}
+		return int64(0)
+	},
+	"TIME": func(s *Server, args []string) interface{} {
+		now := time.Now()
+		return []interface{}{strconv.FormatInt(now.Unix(), 10), strconv.FormatInt(int64(now.Nanosecond()/1000), 10)}
+	},
+	"PUBLISH": func(s *Server, args []string) interface{} {
+		return int64(0)
+	},
+}
+
+// set supports options EX, PX, NX, XX and KEEPTTL.
+func set(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return wrongArgs("set")
+	}
+	key, value := args[0], args[1]
+	var expireAt time.Time
+	var nx, xx, keepTTL bool
+	for i := 2; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); opt {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "KEEPTTL":
+			keepTTL = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				return errorReply("ERR syntax error")
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return errorReply("ERR invalid expire time in 'set' command")
+			}
+			unit := time.Second
+			if opt == "PX" {
+				unit = time.Millisecond
+			}
+			expireAt = time.Now().Add(time.Duration(n) * unit)
+			i++
+		default:
+			return errorReply("ERR syntax error")
+		}
+	}
+
+	old := s.get(key)
+	if nx && old != nil || xx && old == nil {
+		return nil
+	}
+	if keepTTL && old != nil {
+		expireAt = old.expireAt
+	}
+	s.data[key] = &item{str: value, expireAt: expireAt}
+	return status("OK")
+}
+
+// xadd supports option MAXLEN, which trims exactly even if it's approximate, and only auto id.
+func xadd(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return wrongArgs("xadd")
+	}
+	key, args := args[0], args[1:]
+	maxLen := -1
+	if strings.ToUpper(args[0]) == "MAXLEN" {
+		args = args[1:]
+		if len(args) > 0 && (args[0] == "~" || args[0] == "=") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return errorReply("ERR syntax error")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return errorReply("ERR value is not an integer or out of range")
+		}
+		maxLen, args = n, args[1:]
+	}
+	if len(args) < 3 || args[0] != "*" || len(args)%2 != 1 {
+		return wrongArgs("xadd")
+	}
+
+	it := s.get(key)
+	if it == nil {
+		it = &item{}
+		s.data[key] = it
+	}
+	e := entry{ms: time.Now().UnixMilli(), fields: append([]string(nil), args[1:]...)}
+	if n := len(it.stream); n > 0 && it.stream[n-1].ms >= e.ms {
+		e.ms, e.seq = it.stream[n-1].ms, it.stream[n-1].seq+1
+	}
+	it.stream = append(it.stream, e)
+	if maxLen >= 0 && len(it.stream) > maxLen {
+		it.stream = it.stream[len(it.stream)-maxLen:]
+	}
+	return e.id()
+}
+
+// xrange supports ids "-", "+" and ids without sequence, and option COUNT.
+func xrange(s *Server, args []string) interface{} {
+	if len(args) != 3 && len(args) != 5 {
+		return wrongArgs("xrange")
+	}
+	start, ok1 := parseID(args[1], 0)
+	end, ok2 := parseID(args[2], 1<<62)
+	if !ok1 || !ok2 {
+		return errorReply("ERR Invalid stream ID specified as stream command argument")
+	}
+	count := -1
+	if len(args) == 5 {
+		if strings.ToUpper(args[3]) != "COUNT" {
+			return errorReply("ERR syntax error")
+		}
+		count, _ = strconv.Atoi(args[4])
+	}
+
+	reply := []interface{}{}
+	it := s.get(args[0])
+	if it == nil {
+		return reply
+	}
+	for _, e := range it.stream {
+		if count >= 0 && len(reply) >= count {
+			break
+		}
+		if compareID([2]int64{e.ms, e.seq}, start) < 0 || compareID([2]int64{e.ms, e.seq}, end) > 0 {
+			continue
+		}
+		fields := make([]interface{}, 0, len(e.fields))
+		for _, f := range e.fields {
+			fields = append(fields, f)
+		}
+		reply = append(reply, []interface{}{e.id(), fields})
+	}
+	return reply
+}
+
+// xrevrange is xrange from end to start.
+func xrevrange(s *Server, args []string) interface{} {
+	if len(args) != 3 && len(args) != 5 {
+		return wrongArgs("xrevrange")
+	}
+	count := -1
+	if len(args) == 5 {
+		if strings.ToUpper(args[3]) != "COUNT" {
+			return errorReply("ERR syntax error")
+		}
+		count, _ = strconv.Atoi(args[4])
+	}
+	reply, ok := xrange(s, []string{args[0], args[2], args[1]}).([]interface{})
+	if !ok {
+		return reply
+	}
+	for i, j := 0, len(reply)-1; i < j; i, j = i+1, j-1 {
+		reply[i], reply[j] = reply[j], reply[i]
+	}
+	if count >= 0 && len(reply) > count {
+		reply = reply[:count]
+	}
+	return reply
+}
+
+// xread supports options COUNT and BLOCK, and id "$". It polls streams until entries come or block expires.
+func (s *Server) xread(args []string) interface{} {
+	count, block := -1, time.Duration(-1)
+	for len(args) > 0 && strings.ToUpper(args[0]) != "STREAMS" {
+		if len(args) < 2 {
+			return errorReply("ERR syntax error")
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return errorReply("ERR value is not an integer or out of range")
+		}
+		switch strings.ToUpper(args[0]) {
+		case "COUNT":
+			count = n
+		case "BLOCK":
+			block = time.Duration(n) * time.Millisecond
+		default:
+			return errorReply("ERR syntax error")
+		}
+		args = args[2:]
+	}
+	if len(args) < 3 || len(args)%2 != 1 {
+		return wrongArgs("xread")
+	}
+	keys, ids := args[1:len(args)/2+1], args[len(args)/2+1:]
+
+	s.mu.Lock()
+	after := make([][2]int64, len(keys))
+	for i, key := range keys {
+		if ids[i] == "$" {
+			if it := s.get(key); it != nil && len(it.stream) > 0 {
+				e := it.stream[len(it.stream)-1]
+				after[i] = [2]int64{e.ms, e.seq}
+			}
+			continue
+		}
+		id, ok := parseID(ids[i], 0)
+		if !ok {
+			s.mu.Unlock()
+			return errorReply("ERR Invalid stream ID specified as stream command argument")
+		}
+		after[i] = id
+	}
+	s.mu.Unlock()
+
+	deadline := time.Now().Add(block)
+	for {
+		s.mu.Lock()
+		reply := []interface{}{}
+		for i, key := range keys {
+			it := s.get(key)
+			if it == nil {
+				continue
+			}
+			var entries []interface{}
+			for _, e := range it.stream {
+				if count >= 0 && len(entries) >= count {
+					break
+				}
+				if compareID([2]int64{e.ms, e.seq}, after[i]) <= 0 {
+					continue
+				}
+				fields := make([]interface{}, 0, len(e.fields))
+				for _, f := range e.fields {
+					fields = append(fields, f)
+				}
+				entries = append(entries, []interface{}{e.id(), fields})
+			}
+			if len(entries) > 0 {
+				reply = append(reply, []interface{}{key, entries})
+			}
+		}
+		s.mu.Unlock()
+
+		if len(reply) > 0 {
+			return reply
+		}
+		if block < 0 || block > 0 && !time.Now().Before(deadline) {
+			return nil
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// parseID parses id of stream, seq is used if id has no sequence.
+func parseID(id string, seq int64) ([2]int64, bool) {
+	switch id {
+	case "-":
+		return [2]int64{0, 0}, true
+	case "+":
+		return [2]int64{1 << 62, 1 << 62}, true
+	}
+	parts := strings.SplitN(id, "-", 2)
+	ms, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return [2]int64{}, false
+	}
+	if len(parts) == 2 {
+		if seq, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return [2]int64{}, false
+		}
+	}
+	return [2]int64{ms, seq}, true
+}
+
+func compareID(a, b [2]int64) int {
+	switch {
+	case a[0] != b[0]:
+		return boolCompare(a[0] < b[0])
+	case a[1] != b[1]:
+		return boolCompare(a[1] < b[1])
+	}
+	return 0
+}
+
+func boolCompare(less bool) int {
+	if less {
+		return -1
+	}
+	return 1
+}
+
+func incrBy(s *Server, key string, delta int64) interface{} {
+	it := s.get(key)
+	if it == nil {
+		it = &item{str: "0"}
+		s.data[key] = it
+	}
+	n, err := strconv.ParseInt(it.str, 10, 64)
+	if err != nil {
+		return errorReply("ERR value is not an integer or out of range")
+	}
+	n += delta
+	it.str = strconv.FormatInt(n, 10)
+	return n
+}
+
+func expire(s *Server, args []string, unit time.Duration) interface{} {
+	if len(args) != 2 {
+		return wrongArgs("expire")
+	}
+	n, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return errorReply("ERR value is not an integer or out of range")
+	}
+	it := s.get(args[0])
+	if it == nil {
+		return int64(0)
+	}
+	if n <= 0 {
+		delete(s.data, args[0])
+		return int64(1)
+	}
+	it.expireAt = time.Now().Add(time.Duration(n) * unit)
+	return int64(1)
+}
+
+func ttl(s *Server, args []string, unit time.Duration) interface{} {
+	if len(args) != 1 {
+		return wrongArgs("ttl")
+	}
+	it := s.get(args[0])
+	if it == nil {
+		return int64(-2)
+	}
+	if it.expireAt.IsZero() {
+		return int64(-1)
+	}
+	return int64(time.Until(it.expireAt) / unit)
+}
+
+// index converts negative index of range from the end.
+func index(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func wrongArgs(name string) errorReply {
+	return errorReply("ERR wrong number of arguments for '" + name + "' command")
+}
+
+// readCommand reads a command in array of bulk strings, or an inline command.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if line, err = readLine(r); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("expect bulk string, got %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func writeReply(w *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case nil:
+		_, _ = w.WriteString("$-1\r\n")
+	case status:
+		_, _ = fmt.Fprintf(w, "+%s\r\n", v)
+	case errorReply:
+		_, _ = fmt.Fprintf(w, "-%s\r\n", v)
+	case int64:
+		_, _ = fmt.Fprintf(w, ":%d\r\n", v)
+	case string:
+		_, _ = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case []interface{}:
+		_, _ = fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, e := range v {
+			writeReply(w, e)
+		}
+	}
+}